@@ -58,6 +58,9 @@ type ConnectionArgs struct {
 	// OpenID Connect tokens
 	OIDCTokens *oidc.Tokens[*oidc.IDTokenClaims]
 
+	// Identity bearer token (sent instead of relying on the TLS client certificate for authentication)
+	BearerToken string
+
 	// Skip automatic GetServer request upon connection
 	SkipGetServer bool
 
@@ -328,10 +331,12 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 		eventListeners:     make(map[string][]*EventListener),
 	}
 
-	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC}) {
+	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC}) || args.BearerToken != "" {
 		server.RequireAuthenticated(true)
 	}
 
+	server.bearerToken = args.BearerToken
+
 	// Setup the HTTP client
 	httpClient, err := tlsHTTPClient(args.HTTPClient, args.TLSClientCert, args.TLSClientKey, args.TLSCA, args.TLSServerCert, args.InsecureSkipVerify, args.Proxy, args.TransportWrapper)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -177,8 +178,15 @@ type InstanceServer interface {
 	RebuildInstanceFromImage(source ImageServer, image api.Image, instanceName string, req api.InstanceRebuildPost) (op RemoteOperation, err error)
 	GetInstanceUEFIVars(name string) (instanceUEFI *api.InstanceUEFIVars, ETag string, err error)
 	UpdateInstanceUEFIVars(name string, instanceUEFI api.InstanceUEFIVars, ETag string) (err error)
+	GetInstanceHistory(name string) (revisions []api.ConfigRevision, err error)
+	RollbackInstanceConfig(name string, revision int64) (err error)
 
 	ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (op Operation, err error)
+	GetInstanceExecSessions(instanceName string) (sessions []api.InstanceExecSession, err error)
+	GetInstanceExecSession(instanceName string, id string) (session *api.InstanceExecSession, err error)
+	CreateInstanceExecSession(instanceName string, req api.InstanceExecSessionsPost) (session *api.InstanceExecSession, err error)
+	DeleteInstanceExecSession(instanceName string, id string) (err error)
+	AttachInstanceExecSession(instanceName string, id string, req api.InstanceExecSessionAttachPost, args *InstanceExecArgs) (op Operation, err error)
 	ConsoleInstance(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (op Operation, err error)
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
 
@@ -192,6 +200,8 @@ type InstanceServer interface {
 	GetInstanceFileSFTPConn(instanceName string) (net.Conn, error)
 	GetInstanceFileSFTP(instanceName string) (*sftp.Client, error)
 
+	GetInstancePortForward(instanceName string, port int) (conn *websocket.Conn, err error)
+
 	GetInstanceSnapshotNames(instanceName string) (names []string, err error)
 	GetInstanceSnapshots(instanceName string) (snapshots []api.InstanceSnapshot, err error)
 	GetInstanceSnapshot(instanceName string, name string) (snapshot *api.InstanceSnapshot, ETag string, err error)
@@ -213,9 +223,11 @@ type InstanceServer interface {
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
+	GetInstanceProcesses(name string) (processes []api.InstanceProcess, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
+	GetInstanceLogStream(name string, args *InstanceLogStreamArgs) (content io.ReadCloser, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
 
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
@@ -316,10 +328,20 @@ type InstanceServer interface {
 	GetOperationWebsocket(uuid string, secret string) (conn *websocket.Conn, err error)
 	DeleteOperation(uuid string) (err error)
 
+	// Placement group functions ("instance_placement_groups" API extension)
+	GetPlacementGroupNames() (names []string, err error)
+	GetPlacementGroups() (groups []api.PlacementGroup, err error)
+	GetPlacementGroup(name string) (group *api.PlacementGroup, ETag string, err error)
+	CreatePlacementGroup(group api.PlacementGroupsPost) (err error)
+	UpdatePlacementGroup(name string, group api.PlacementGroupPut, ETag string) (err error)
+	DeletePlacementGroup(name string) (err error)
+
 	// Profile functions
 	GetProfileNames() (names []string, err error)
 	GetProfiles() (profiles []api.Profile, err error)
 	GetProfile(name string) (profile *api.Profile, ETag string, err error)
+	GetProfileHistory(name string) (revisions []api.ConfigRevision, err error)
+	RollbackProfileConfig(name string, revision int64) (err error)
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
@@ -330,11 +352,21 @@ type InstanceServer interface {
 	GetProjects() (projects []api.Project, err error)
 	GetProject(name string) (project *api.Project, ETag string, err error)
 	GetProjectState(name string) (project *api.ProjectState, err error)
+	GetProjectHistory(name string) (revisions []api.ConfigRevision, err error)
+	RollbackProjectConfig(name string, revision int64) (err error)
 	CreateProject(project api.ProjectsPost) (err error)
 	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
 	RenameProject(name string, project api.ProjectPost) (op Operation, err error)
 	DeleteProject(name string) (err error)
 
+	// Secret functions ("secrets" API extension)
+	GetSecretNames() (names []string, err error)
+	GetSecrets() (secrets []api.Secret, err error)
+	GetSecret(name string) (secret *api.Secret, ETag string, err error)
+	CreateSecret(secret api.SecretsPost) (err error)
+	UpdateSecret(name string, secret api.SecretPut, ETag string) (err error)
+	DeleteSecret(name string) (err error)
+
 	// Storage pool functions ("storage" API extension)
 	GetStoragePoolNames() (names []string, err error)
 	GetStoragePools() (pools []api.StoragePool, err error)
@@ -422,6 +454,9 @@ type InstanceServer interface {
 	UpdateClusterGroup(name string, group api.ClusterGroupPut, ETag string) error
 	GetClusterGroup(name string) (*api.ClusterGroup, string, error)
 
+	// Cluster database backup functions ("cluster_database_backup" API extension)
+	GetClusterDatabaseBackup(req *BackupFileRequest) (resp *BackupFileResponse, err error)
+
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
 	GetWarnings() (warnings []api.Warning, err error)
@@ -444,6 +479,12 @@ type InstanceServer interface {
 	GetIdentity(authenticationMethod string, nameOrIdentifier string) (identity *api.Identity, ETag string, err error)
 	GetCurrentIdentityInfo() (identityInfo *api.IdentityInfo, ETag string, err error)
 	UpdateIdentity(authenticationMethod string, nameOrIdentifier string, identityPut api.IdentityPut, ETag string) error
+	GetIdentityTokenNames(authenticationMethod string, nameOrIdentifier string) (tokenNames []string, err error)
+	GetIdentityTokens(authenticationMethod string, nameOrIdentifier string) (tokens []api.IdentityToken, err error)
+	GetIdentityToken(authenticationMethod string, nameOrIdentifier string, tokenName string) (token *api.IdentityToken, err error)
+	CreateIdentityToken(authenticationMethod string, nameOrIdentifier string, token api.IdentityTokensPost) (secret *api.IdentityTokenSecret, err error)
+	DeleteIdentityToken(authenticationMethod string, nameOrIdentifier string, tokenName string) error
+	GetIdentityEffectivePermissions(authenticationMethod string, nameOrIdentifier string, entityURL string, entitlement string, identityProviderGroups []string) (permissions []api.IdentityEffectivePermission, err error)
 	GetIdentityProviderGroupNames() (identityProviderGroupNames []string, err error)
 	GetIdentityProviderGroups() (identityProviderGroups []api.IdentityProviderGroup, err error)
 	GetIdentityProviderGroup(identityProviderGroupName string) (identityProviderGroup *api.IdentityProviderGroup, ETag string, err error)
@@ -453,6 +494,8 @@ type InstanceServer interface {
 	DeleteIdentityProviderGroup(identityProviderGroupName string) error
 	GetPermissions(args GetPermissionsArgs) (permissions []api.Permission, err error)
 	GetPermissionsInfo(args GetPermissionsArgs) (permissions []api.PermissionInfo, err error)
+	GetAuthAccessModel() (model *api.AuthAccessModel, ETag string, err error)
+	UpdateAuthAccessModel(model api.AuthAccessModel, prune bool, ETag string) error
 
 	// Internal functions (for internal use)
 	RawQuery(method string, path string, data any, queryETag string) (resp *api.Response, ETag string, err error)
@@ -671,6 +714,18 @@ type InstanceConsoleArgs struct {
 type InstanceConsoleLogArgs struct {
 }
 
+// The InstanceLogStreamArgs struct is used to pass additional options during an instance log stream request.
+type InstanceLogStreamArgs struct {
+	// Whether to keep sending the new log entries
+	Follow bool
+
+	// Only return the log entries from this time onwards, if set
+	Since time.Time
+
+	// Log sources to read, all the sources supported by the instance if empty
+	Sources []string
+}
+
 // The InstanceExecArgs struct is used to pass additional options during instance exec.
 type InstanceExecArgs struct {
 	// Standard input
@@ -50,6 +50,8 @@ type ProtocolLXD struct {
 	project       string
 
 	oidcClient *oidcClient
+
+	bearerToken string
 }
 
 // Disconnect gets rid of any background goroutines.
@@ -158,6 +160,7 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 // User-Agent (if r.httpUserAgent is set).
 // X-LXD-authenticated (if r.requireAuthenticated is set).
 // OIDC Authorization header (if r.oidcClient is set).
+// Bearer token Authorization header (if r.bearerToken is set).
 func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
 		req.Header.Set("User-Agent", r.httpUserAgent)
@@ -169,6 +172,8 @@ func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 
 	if r.oidcClient != nil {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.oidcClient.getAccessToken()))
+	} else if r.bearerToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.bearerToken))
 	}
 }
 
@@ -261,6 +261,113 @@ func (r *ProtocolLXD) UpdateIdentity(authenticationMethod string, nameOrIdentife
 	return nil
 }
 
+// GetIdentityTokenNames returns the names of the bearer tokens of the identity.
+func (r *ProtocolLXD) GetIdentityTokenNames(authenticationMethod string, nameOrIdentifier string) ([]string, error) {
+	err := r.CheckExtension("auth_identity_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []string{}
+	baseURL := api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "tokens").String()
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetIdentityTokens returns the bearer tokens of the identity.
+func (r *ProtocolLXD) GetIdentityTokens(authenticationMethod string, nameOrIdentifier string) ([]api.IdentityToken, error) {
+	err := r.CheckExtension("auth_identity_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []api.IdentityToken{}
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "tokens").WithQuery("recursion", "1").String(), nil, "", &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// GetIdentityToken returns the bearer token of the identity with the given name.
+func (r *ProtocolLXD) GetIdentityToken(authenticationMethod string, nameOrIdentifier string, tokenName string) (*api.IdentityToken, error) {
+	err := r.CheckExtension("auth_identity_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	token := api.IdentityToken{}
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "tokens", tokenName).String(), nil, "", &token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// CreateIdentityToken creates a new bearer token for the identity and returns its secret.
+func (r *ProtocolLXD) CreateIdentityToken(authenticationMethod string, nameOrIdentifier string, token api.IdentityTokensPost) (*api.IdentityTokenSecret, error) {
+	err := r.CheckExtension("auth_identity_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	secret := api.IdentityTokenSecret{}
+	_, err = r.queryStruct(http.MethodPost, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "tokens").String(), token, "", &secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secret, nil
+}
+
+// DeleteIdentityToken revokes the bearer token of the identity with the given name.
+func (r *ProtocolLXD) DeleteIdentityToken(authenticationMethod string, nameOrIdentifier string, tokenName string) error {
+	err := r.CheckExtension("auth_identity_tokens")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "tokens", tokenName).String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetIdentityEffectivePermissions evaluates whether the identity has the given entitlement on the entity with the given
+// URL, and which group permissions grant it. All entitlements that apply to the entity are evaluated if the entitlement
+// is empty. The identity provider groups are only considered for OIDC identities.
+func (r *ProtocolLXD) GetIdentityEffectivePermissions(authenticationMethod string, nameOrIdentifier string, entityURL string, entitlement string, identityProviderGroups []string) ([]api.IdentityEffectivePermission, error) {
+	err := r.CheckExtension("auth_effective_permissions")
+	if err != nil {
+		return nil, err
+	}
+
+	u := api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "effective-permissions").WithQuery("entity", entityURL)
+	if entitlement != "" {
+		u = u.WithQuery("entitlement", entitlement)
+	}
+
+	if len(identityProviderGroups) > 0 {
+		u = u.WithQuery("identity-provider-groups", strings.Join(identityProviderGroups, ","))
+	}
+
+	permissions := []api.IdentityEffectivePermission{}
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
 // GetIdentityProviderGroupNames returns a list of identity provider group names.
 func (r *ProtocolLXD) GetIdentityProviderGroupNames() ([]string, error) {
 	err := r.CheckExtension("access_management")
@@ -370,6 +477,44 @@ func (r *ProtocolLXD) DeleteIdentityProviderGroup(identityProviderGroupName stri
 	return nil
 }
 
+// GetAuthAccessModel returns the groups, identity provider group mappings and group memberships of identities in a
+// single document.
+func (r *ProtocolLXD) GetAuthAccessModel() (*api.AuthAccessModel, string, error) {
+	err := r.CheckExtension("auth_access_model")
+	if err != nil {
+		return nil, "", err
+	}
+
+	model := api.AuthAccessModel{}
+	etag, err := r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "access-model").String(), nil, "", &model)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &model, etag, nil
+}
+
+// UpdateAuthAccessModel applies the given access model in a single transaction. If prune is true, the groups,
+// identity provider groups and group memberships of identities that are absent from the access model are removed.
+func (r *ProtocolLXD) UpdateAuthAccessModel(model api.AuthAccessModel, prune bool, ETag string) error {
+	err := r.CheckExtension("auth_access_model")
+	if err != nil {
+		return err
+	}
+
+	u := api.NewURL().Path("auth", "access-model")
+	if prune {
+		u = u.WithQuery("prune", "true")
+	}
+
+	_, _, err = r.query(http.MethodPut, u.String(), model, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetPermissions returns all permissions available on the server. It does not return information on whether these
 // permissions are assigned to groups.
 func (r *ProtocolLXD) GetPermissions(args GetPermissionsArgs) ([]api.Permission, error) {
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -528,6 +529,50 @@ func (r *ProtocolLXD) UpdateInstanceUEFIVars(name string, instanceUEFI api.Insta
 	return nil
 }
 
+// GetInstanceHistory returns the configuration revisions of the instance, oldest first.
+func (r *ProtocolLXD) GetInstanceHistory(name string) ([]api.ConfigRevision, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("config_history")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []api.ConfigRevision{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/history", path, url.PathEscape(name)), nil, "", &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// RollbackInstanceConfig restores the configuration recorded by the given revision of the instance.
+func (r *ProtocolLXD) RollbackInstanceConfig(name string, revision int64) error {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	err = r.CheckExtension("config_history")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("%s/%s/history", path, url.PathEscape(name)), api.ConfigHistoryPost{Revision: revision}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInstanceFull returns the instance entry for the provided name along with snapshot information.
 func (r *ProtocolLXD) GetInstanceFull(name string) (*api.InstanceFull, string, error) {
 	instance := api.InstanceFull{}
@@ -1766,6 +1811,189 @@ func (r *ProtocolLXD) GetInstanceFileSFTP(instanceName string) (*sftp.Client, er
 	return client, nil
 }
 
+// GetInstancePortForward returns a websocket connection tunnelling a TCP connection to the given port of the
+// instance. The data is exchanged as binary messages and an empty text message marks the end of a stream.
+func (r *ProtocolLXD) GetInstancePortForward(instanceName string, port int) (*websocket.Conn, error) {
+	err := r.CheckExtension("instance_port_forward")
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := r.setQueryAttributes(fmt.Sprintf("/instances/%s/port-forward?port=%d", url.PathEscape(instanceName), port))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.websocket(path)
+}
+
+// GetInstanceExecSessions returns the persistent exec sessions of the instance.
+func (r *ProtocolLXD) GetInstanceExecSessions(instanceName string) ([]api.InstanceExecSession, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []api.InstanceExecSession{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/exec-sessions?recursion=1", path, url.PathEscape(instanceName)), nil, "", &sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetInstanceExecSession returns the persistent exec session of the instance with the given ID.
+func (r *ProtocolLXD) GetInstanceExecSession(instanceName string, id string) (*api.InstanceExecSession, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	session := api.InstanceExecSession{}
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/exec-sessions/%s", path, url.PathEscape(instanceName), url.PathEscape(id)), nil, "", &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CreateInstanceExecSession starts a command inside the instance in a persistent exec session.
+func (r *ProtocolLXD) CreateInstanceExecSession(instanceName string, req api.InstanceExecSessionsPost) (*api.InstanceExecSession, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	session := api.InstanceExecSession{}
+	_, err = r.queryStruct("POST", fmt.Sprintf("%s/%s/exec-sessions", path, url.PathEscape(instanceName)), req, "", &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// DeleteInstanceExecSession terminates the persistent exec session of the instance.
+func (r *ProtocolLXD) DeleteInstanceExecSession(instanceName string, id string) error {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/exec-sessions/%s", path, url.PathEscape(instanceName), url.PathEscape(id)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AttachInstanceExecSession attaches to the persistent exec session of the instance.
+// The recent output of the session is written to args.Stdout before any new output. Reaching the end of args.Stdin
+// detaches from the session. The operation completes once detached, or when the command exits in which case its
+// exit code is in the "return" metadata.
+func (r *ProtocolLXD) AttachInstanceExecSession(instanceName string, id string, req api.InstanceExecSessionAttachPost, args *InstanceExecArgs) (Operation, error) {
+	err := r.CheckExtension("instance_exec_sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure args are equivalent to empty InstanceExecArgs.
+	if args == nil {
+		args = &InstanceExecArgs{}
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/exec-sessions/%s/attach", path, url.PathEscape(instanceName), url.PathEscape(id)), req, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	opAPI := op.Get()
+
+	// Parse the fds
+	fds := map[string]string{}
+
+	value, ok := opAPI.Metadata["fds"]
+	if ok {
+		values := value.(map[string]any)
+		for k, v := range values {
+			fds[k] = v.(string)
+		}
+	}
+
+	if fds[api.SecretNameControl] != "" {
+		conn, err := r.GetOperationWebsocket(opAPI.ID, fds[api.SecretNameControl])
+		if err != nil {
+			return nil, err
+		}
+
+		go func() {
+			_, _, _ = conn.ReadMessage() // Consume pings from server.
+		}()
+
+		if args.Control != nil {
+			// Call the control handler with a connection to the control socket
+			go args.Control(conn)
+		}
+	}
+
+	conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Stdout == nil {
+		args.Stdout = io.Discard
+	}
+
+	// Attach stdin and stdout to the session. Reaching the end of stdin detaches from the session.
+	if args.Stdin != nil {
+		go func() {
+			<-ws.MirrorRead(conn, args.Stdin)
+			_ = conn.Close()
+		}()
+	}
+
+	go func() {
+		<-ws.MirrorWrite(conn, args.Stdout)
+		_ = conn.Close()
+
+		if args.DataDone != nil {
+			close(args.DataDone)
+		}
+	}()
+
+	return op, nil
+}
+
 // GetInstanceSnapshotNames returns a list of snapshot names for the instance.
 func (r *ProtocolLXD) GetInstanceSnapshotNames(instanceName string) ([]string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2234,6 +2462,37 @@ func (r *ProtocolLXD) GetInstanceState(name string) (*api.InstanceState, string,
 	return &state, etag, nil
 }
 
+// GetInstanceProcesses returns the processes running in the instance.
+func (r *ProtocolLXD) GetInstanceProcesses(name string) ([]api.InstanceProcess, error) {
+	err := r.CheckExtension("instance_processes")
+	if err != nil {
+		return nil, err
+	}
+
+	var uri string
+
+	if r.IsAgent() {
+		uri = "/processes"
+	} else {
+		path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		uri = fmt.Sprintf("%s/%s/processes", path, url.PathEscape(name))
+	}
+
+	processes := []api.InstanceProcess{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", uri, nil, "", &processes)
+	if err != nil {
+		return nil, err
+	}
+
+	return processes, nil
+}
+
 // UpdateInstanceState updates the instance to match the requested state.
 func (r *ProtocolLXD) UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2308,6 +2567,66 @@ func (r *ProtocolLXD) GetInstanceLogfile(name string, filename string) (io.ReadC
 	return resp.Body, err
 }
 
+// GetInstanceLogStream returns the log stream of the instance, a sequence of api.InstanceLogEntry encoded as JSON
+// objects separated by new lines.
+//
+// Note that it's the caller's responsibility to close the returned ReadCloser.
+func (r *ProtocolLXD) GetInstanceLogStream(name string, args *InstanceLogStreamArgs) (io.ReadCloser, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("instance_logs_stream")
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if args != nil {
+		if args.Follow {
+			v.Set("follow", "1")
+		}
+
+		if !args.Since.IsZero() {
+			v.Set("since", args.Since.Format(time.RFC3339))
+		}
+
+		if len(args.Sources) > 0 {
+			v.Set("source", strings.Join(args.Sources, ","))
+		}
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0%s/%s/logs/stream?%s", r.httpBaseURL.String(), path, url.PathEscape(name), v.Encode())
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, err
+}
+
 // DeleteInstanceLogfile deletes the requested logfile.
 func (r *ProtocolLXD) DeleteInstanceLogfile(name string, filename string) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2574,6 +2893,13 @@ func (r *ProtocolLXD) ConsoleInstance(instanceName string, console api.InstanceC
 		}
 	}
 
+	if console.Device != "" {
+		err = r.CheckExtension("device_serial")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	useEventListener := r.CheckExtension("operation_wait") != nil
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/console", path, url.PathEscape(instanceName)), console, "", useEventListener)
@@ -60,6 +60,40 @@ func (r *ProtocolLXD) CreateProfile(profile api.ProfilesPost) error {
 	return nil
 }
 
+// GetProfileHistory returns the configuration revisions of the profile, oldest first.
+func (r *ProtocolLXD) GetProfileHistory(name string) ([]api.ConfigRevision, error) {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []api.ConfigRevision{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/profiles/%s/history", url.PathEscape(name)), nil, "", &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// RollbackProfileConfig restores the configuration recorded by the given revision of the profile.
+func (r *ProtocolLXD) RollbackProfileConfig(name string, revision int64) error {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/profiles/%s/history", url.PathEscape(name)), api.ConfigHistoryPost{Revision: revision}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // UpdateProfile updates the profile to match the provided Profile struct.
 func (r *ProtocolLXD) UpdateProfile(name string, profile api.ProfilePut, ETag string) error {
 	// Send the request
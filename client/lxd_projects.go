@@ -82,6 +82,40 @@ func (r *ProtocolLXD) GetProjectState(name string) (*api.ProjectState, error) {
 	return &projectState, nil
 }
 
+// GetProjectHistory returns the configuration revisions of the project, oldest first.
+func (r *ProtocolLXD) GetProjectHistory(name string) ([]api.ConfigRevision, error) {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []api.ConfigRevision{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/projects/%s/history", url.PathEscape(name)), nil, "", &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// RollbackProjectConfig restores the configuration recorded by the given revision of the project.
+func (r *ProtocolLXD) RollbackProjectConfig(name string, revision int64) error {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/projects/%s/history", url.PathEscape(name)), api.ConfigHistoryPost{Revision: revision}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateProject defines a new container project.
 func (r *ProtocolLXD) CreateProject(project api.ProjectsPost) error {
 	err := r.CheckExtension("projects")
@@ -0,0 +1,111 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetSecretNames returns a list of secret names.
+func (r *ProtocolLXD) GetSecretNames() ([]string, error) {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/secrets"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetSecrets returns a list of secret structs.
+func (r *ProtocolLXD) GetSecrets() ([]api.Secret, error) {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []api.Secret{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/secrets?recursion=1", nil, "", &secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// GetSecret returns a secret entry for the provided name.
+func (r *ProtocolLXD) GetSecret(name string) (*api.Secret, string, error) {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := api.Secret{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/secrets/%s", url.PathEscape(name)), nil, "", &secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &secret, etag, nil
+}
+
+// CreateSecret defines a new secret using the provided struct.
+func (r *ProtocolLXD) CreateSecret(secret api.SecretsPost) error {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/secrets", secret, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSecret updates the secret to match the provided struct.
+func (r *ProtocolLXD) UpdateSecret(name string, secret api.SecretPut, ETag string) error {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/secrets/%s", url.PathEscape(name)), secret, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSecret deletes an existing secret.
+func (r *ProtocolLXD) DeleteSecret(name string) error {
+	err := r.CheckExtension("secrets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/secrets/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,1259 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	lxd "github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+// applyStackLabel is the configuration key that marks the resources owned by a stack.
+const applyStackLabel = "user.lxd.stack"
+
+// applyStack is a declarative description of a set of resources.
+type applyStack struct {
+	Name           string                    `yaml:"name"`
+	Project        string                    `yaml:"project"`
+	Projects       []applyStackProject       `yaml:"projects"`
+	Profiles       []applyStackProfile       `yaml:"profiles"`
+	Networks       []applyStackNetwork       `yaml:"networks"`
+	NetworkACLs    []applyStackNetworkACL    `yaml:"network_acls"`
+	StorageVolumes []applyStackStorageVolume `yaml:"storage_volumes"`
+	Instances      []applyStackInstance      `yaml:"instances"`
+}
+
+type applyStackProject struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Config      map[string]string `yaml:"config"`
+}
+
+type applyStackProfile struct {
+	Name        string                       `yaml:"name"`
+	Project     string                       `yaml:"project"`
+	Description string                       `yaml:"description"`
+	Config      map[string]string            `yaml:"config"`
+	Devices     map[string]map[string]string `yaml:"devices"`
+}
+
+type applyStackNetwork struct {
+	Name        string            `yaml:"name"`
+	Project     string            `yaml:"project"`
+	Type        string            `yaml:"type"`
+	Description string            `yaml:"description"`
+	Config      map[string]string `yaml:"config"`
+}
+
+type applyStackNetworkACL struct {
+	Name        string               `yaml:"name"`
+	Project     string               `yaml:"project"`
+	Description string               `yaml:"description"`
+	Config      map[string]string    `yaml:"config"`
+	Egress      []api.NetworkACLRule `yaml:"egress"`
+	Ingress     []api.NetworkACLRule `yaml:"ingress"`
+}
+
+type applyStackStorageVolume struct {
+	Pool        string            `yaml:"pool"`
+	Name        string            `yaml:"name"`
+	Project     string            `yaml:"project"`
+	ContentType string            `yaml:"content_type"`
+	Description string            `yaml:"description"`
+	Config      map[string]string `yaml:"config"`
+}
+
+type applyStackInstance struct {
+	Name        string                       `yaml:"name"`
+	Project     string                       `yaml:"project"`
+	Type        string                       `yaml:"type"`
+	Image       string                       `yaml:"image"`
+	Description string                       `yaml:"description"`
+	Profiles    []string                     `yaml:"profiles"`
+	Config      map[string]string            `yaml:"config"`
+	Devices     map[string]map[string]string `yaml:"devices"`
+	Ephemeral   bool                         `yaml:"ephemeral"`
+	Start       bool                         `yaml:"start"`
+}
+
+// applyAction is a single change of the plan of a stack.
+type applyAction struct {
+	// operation is "+" for a creation, "~" for an update and "-" for a deletion.
+	operation string
+	resource  string
+	details   []string
+
+	apply  func() error
+	revert func() error
+}
+
+type cmdApply struct {
+	global *cmdGlobal
+
+	flagFile   string
+	flagPrune  bool
+	flagDryRun bool
+}
+
+func (c *cmdApply) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Apply a stack of resources")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply a stack of resources
+
+A stack is a YAML document describing projects, profiles, networks, network
+ACLs, custom storage volumes and instances. The differences between the stack
+and the server are shown, then the resources are created or updated in
+dependency order. If a change fails, the changes that were already made are
+reverted.
+
+Only the configuration keys and the descriptions that are listed in the stack
+are changed. An empty value removes a configuration key. Devices, network ACL
+rules and instance profiles replace the existing ones when they are listed.
+
+All resources of the stack are labelled with the "user.lxd.stack"
+configuration key. With --prune, labelled resources that are absent from the
+stack are deleted.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc apply -f stack.yaml --dry-run
+    Show the changes that applying stack.yaml would make.
+
+lxc apply -f stack.yaml --prune
+    Apply stack.yaml and delete the resources that were removed from it.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFile, "file", "f", "", i18n.G("Stack file (\"-\" for standard input)")+"``")
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, i18n.G("Delete the resources of the stack that are absent from it"))
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes without applying them"))
+
+	return cmd
+}
+
+func (c *cmdApply) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagFile == "" {
+		return fmt.Errorf(i18n.G("A stack file must be given with --file"))
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	var contents []byte
+	if c.flagFile == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(c.flagFile)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	stack := applyStack{}
+	err = yaml.UnmarshalStrict(contents, &stack)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse stack: %w"), err)
+	}
+
+	err = stack.validate()
+	if err != nil {
+		return err
+	}
+
+	// Resources without a project go to the project of the remote.
+	if stack.Project == "" {
+		info, err := resource.server.GetConnectionInfo()
+		if err != nil {
+			return err
+		}
+
+		stack.Project = info.Project
+		if stack.Project == "" {
+			stack.Project = api.ProjectDefaultName
+		}
+	}
+
+	planner := applyPlanner{
+		cmd:    c,
+		server: resource.server,
+		remote: resource.remote,
+		stack:  &stack,
+	}
+
+	creates, deletes, err := planner.plan(c.flagPrune)
+	if err != nil {
+		return err
+	}
+
+	if len(creates) == 0 && len(deletes) == 0 {
+		if !c.global.flagQuiet {
+			fmt.Println(i18n.G("The stack is up to date"))
+		}
+
+		return nil
+	}
+
+	if !c.global.flagQuiet {
+		for _, action := range append(creates, deletes...) {
+			fmt.Println(action.operation + " " + action.resource)
+			for _, detail := range action.details {
+				fmt.Println(detail)
+			}
+		}
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	// Revert the creations and updates in reverse order if one of them fails.
+	for i, action := range creates {
+		err := action.apply()
+		if err == nil {
+			continue
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			if creates[j].revert == nil {
+				continue
+			}
+
+			revertErr := creates[j].revert()
+			if revertErr != nil {
+				fmt.Fprintf(os.Stderr, i18n.G("Failed to revert %s: %v")+"\n", creates[j].resource, revertErr)
+			}
+		}
+
+		return fmt.Errorf(i18n.G("Failed to apply %s: %w"), action.resource, err)
+	}
+
+	// Deletions can't be reverted so they are done last.
+	for _, action := range deletes {
+		err := action.apply()
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed to delete %s: %w"), action.resource, err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the stack has a name and that its resources are fully specified.
+func (s *applyStack) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf(i18n.G("The stack must have a name"))
+	}
+
+	for _, p := range s.Projects {
+		if p.Name == "" {
+			return fmt.Errorf(i18n.G("All projects of the stack must have a name"))
+		}
+	}
+
+	for _, profile := range s.Profiles {
+		if profile.Name == "" {
+			return fmt.Errorf(i18n.G("All profiles of the stack must have a name"))
+		}
+	}
+
+	for _, network := range s.Networks {
+		if network.Name == "" {
+			return fmt.Errorf(i18n.G("All networks of the stack must have a name"))
+		}
+	}
+
+	for _, acl := range s.NetworkACLs {
+		if acl.Name == "" {
+			return fmt.Errorf(i18n.G("All network ACLs of the stack must have a name"))
+		}
+	}
+
+	for _, volume := range s.StorageVolumes {
+		if volume.Name == "" || volume.Pool == "" {
+			return fmt.Errorf(i18n.G("All storage volumes of the stack must have a name and a pool"))
+		}
+	}
+
+	for _, inst := range s.Instances {
+		if inst.Name == "" {
+			return fmt.Errorf(i18n.G("All instances of the stack must have a name"))
+		}
+
+		if inst.Image == "" {
+			return fmt.Errorf(i18n.G("Instance %q of the stack must have an image"), inst.Name)
+		}
+	}
+
+	return nil
+}
+
+// applyPlanner computes the actions needed to bring the server in line with a stack.
+type applyPlanner struct {
+	cmd    *cmdApply
+	server lxd.InstanceServer
+	remote string
+	stack  *applyStack
+
+	// projects are the current projects of the server by name.
+	projects map[string]api.Project
+
+	creates []applyAction
+	deletes []applyAction
+}
+
+// plan returns the creations and updates, in dependency order, followed by the deletions, in reverse dependency
+// order, needed to apply the stack.
+func (p *applyPlanner) plan(prune bool) ([]applyAction, []applyAction, error) {
+	projects, err := p.server.GetProjects()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.projects = make(map[string]api.Project, len(projects))
+	for _, project := range projects {
+		p.projects[project.Name] = project
+	}
+
+	steps := []func(prune bool) error{
+		p.planProjects,
+		p.planNetworkACLs,
+		p.planNetworks,
+		p.planStorageVolumes,
+		p.planProfiles,
+		p.planInstances,
+	}
+
+	for _, step := range steps {
+		err := step(prune)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Delete the dependent resources first.
+	for i, j := 0, len(p.deletes)-1; i < j; i, j = i+1, j-1 {
+		p.deletes[i], p.deletes[j] = p.deletes[j], p.deletes[i]
+	}
+
+	return p.creates, p.deletes, nil
+}
+
+// project returns the project of a resource of the stack.
+func (p *applyPlanner) project(name string) string {
+	if name == "" {
+		return p.stack.Project
+	}
+
+	return name
+}
+
+// effectiveProject returns the project holding the resources of the given feature ("profiles", "networks" or
+// "storage.volumes") of a project, taking the changes made by the stack into account.
+func (p *applyPlanner) effectiveProject(name string, feature string) string {
+	if name == api.ProjectDefaultName {
+		return name
+	}
+
+	config := map[string]string{}
+	current, ok := p.projects[name]
+	if ok {
+		config = current.Config
+	} else if feature != "networks" {
+		// New projects have all features but networks enabled by default.
+		config = map[string]string{"features." + feature: "true"}
+	}
+
+	for _, desired := range p.stack.Projects {
+		if desired.Name == name {
+			config = applyConfigMerge(config, desired.Config)
+		}
+	}
+
+	if shared.IsTrue(config["features."+feature]) {
+		return name
+	}
+
+	return api.ProjectDefaultName
+}
+
+// effectiveProjects returns the projects holding the resources of the given feature across the server.
+func (p *applyPlanner) effectiveProjects(feature string) []string {
+	var projects []string
+	for name := range p.projects {
+		project := p.effectiveProject(name, feature)
+		if !shared.ValueInSlice(project, projects) {
+			projects = append(projects, project)
+		}
+	}
+
+	sort.Strings(projects)
+
+	return projects
+}
+
+// config returns the desired configuration of a resource of the stack with the ownership label added.
+func (p *applyPlanner) config(config map[string]string) map[string]string {
+	labelled := make(map[string]string, len(config)+1)
+	for key, value := range config {
+		labelled[key] = value
+	}
+
+	labelled[applyStackLabel] = p.stack.Name
+
+	return labelled
+}
+
+// owned returns whether a resource with the given configuration is owned by the stack.
+func (p *applyPlanner) owned(config map[string]string) bool {
+	return config[applyStackLabel] == p.stack.Name
+}
+
+// resourceName returns the name of a resource as shown in the plan.
+func (p *applyPlanner) resourceName(kind string, name string, project string) string {
+	if project == api.ProjectDefaultName {
+		return kind + " " + name
+	}
+
+	return fmt.Sprintf(i18n.G("%s %s (project %s)"), kind, name, project)
+}
+
+// descriptionDiff returns the detail of a description change. Descriptions are only changed when set in the stack.
+func descriptionDiff(current string, desired string) []string {
+	if desired == "" || desired == current {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("    description: %q -> %q", current, desired)}
+}
+
+func (p *applyPlanner) planProjects(prune bool) error {
+	desiredProjects := map[string]bool{}
+	for _, desired := range p.stack.Projects {
+		desiredProjects[desired.Name] = true
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := "project " + name
+
+		current, ok := p.projects[name]
+		if !ok {
+			req := api.ProjectsPost{Name: name, ProjectPut: api.ProjectPut{Description: desired.Description, Config: applyConfigMerge(nil, config)}}
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   applyConfigDiff(nil, config),
+				apply:     func() error { return p.server.CreateProject(req) },
+				revert:    func() error { return p.server.DeleteProject(name) },
+			})
+
+			continue
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if len(details) == 0 {
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply:     func() error { return p.server.UpdateProject(name, put, "") },
+			revert:    func() error { return p.server.UpdateProject(name, previous, "") },
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	names := make([]string, 0, len(p.projects))
+	for name := range p.projects {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == api.ProjectDefaultName || desiredProjects[name] || !p.owned(p.projects[name].Config) {
+			continue
+		}
+
+		p.deletes = append(p.deletes, applyAction{
+			operation: "-",
+			resource:  "project " + name,
+			apply:     func() error { return p.server.DeleteProject(name) },
+		})
+	}
+
+	return nil
+}
+
+func (p *applyPlanner) planNetworkACLs(prune bool) error {
+	desiredACLs := map[string]bool{}
+	for _, desired := range p.stack.NetworkACLs {
+		project := p.effectiveProject(p.project(desired.Project), "networks")
+		desiredACLs[project+"/"+desired.Name] = true
+		server := p.server.UseProject(project)
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := p.resourceName("network ACL", name, project)
+
+		current, _, err := server.GetNetworkACL(name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if current == nil {
+			req := api.NetworkACLsPost{
+				NetworkACLPost: api.NetworkACLPost{Name: name},
+				NetworkACLPut: api.NetworkACLPut{
+					Description: desired.Description,
+					Config:      applyConfigMerge(nil, config),
+					Egress:      desired.Egress,
+					Ingress:     desired.Ingress,
+				},
+			}
+
+			details := applyConfigDiff(nil, config)
+			details = append(details, applyRulesDiff("egress", nil, desired.Egress)...)
+			details = append(details, applyRulesDiff("ingress", nil, desired.Ingress)...)
+
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   details,
+				apply:     func() error { return server.CreateNetworkACL(req) },
+				revert:    func() error { return server.DeleteNetworkACL(name) },
+			})
+
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if desired.Egress != nil {
+			details = append(details, applyRulesDiff("egress", current.Egress, desired.Egress)...)
+			put.Egress = desired.Egress
+		}
+
+		if desired.Ingress != nil {
+			details = append(details, applyRulesDiff("ingress", current.Ingress, desired.Ingress)...)
+			put.Ingress = desired.Ingress
+		}
+
+		if len(details) == 0 {
+			continue
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply:     func() error { return server.UpdateNetworkACL(name, put, "") },
+			revert:    func() error { return server.UpdateNetworkACL(name, previous, "") },
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	for _, project := range p.effectiveProjects("networks") {
+		server := p.server.UseProject(project)
+		acls, err := server.GetNetworkACLs()
+		if err != nil {
+			return err
+		}
+
+		for _, acl := range acls {
+			if desiredACLs[project+"/"+acl.Name] || !p.owned(acl.Config) {
+				continue
+			}
+
+			name := acl.Name
+			p.deletes = append(p.deletes, applyAction{
+				operation: "-",
+				resource:  p.resourceName("network ACL", name, project),
+				apply:     func() error { return server.DeleteNetworkACL(name) },
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *applyPlanner) planNetworks(prune bool) error {
+	desiredNetworks := map[string]bool{}
+	for _, desired := range p.stack.Networks {
+		project := p.effectiveProject(p.project(desired.Project), "networks")
+		desiredNetworks[project+"/"+desired.Name] = true
+		server := p.server.UseProject(project)
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := p.resourceName("network", name, project)
+
+		current, _, err := server.GetNetwork(name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if current == nil {
+			req := api.NetworksPost{
+				Name:       name,
+				Type:       desired.Type,
+				NetworkPut: api.NetworkPut{Description: desired.Description, Config: applyConfigMerge(nil, config)},
+			}
+
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   applyConfigDiff(nil, config),
+				apply:     func() error { return server.CreateNetwork(req) },
+				revert:    func() error { return server.DeleteNetwork(name) },
+			})
+
+			continue
+		}
+
+		if !current.Managed {
+			return fmt.Errorf(i18n.G("Network %q isn't managed by LXD"), name)
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if len(details) == 0 {
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply:     func() error { return server.UpdateNetwork(name, put, "") },
+			revert:    func() error { return server.UpdateNetwork(name, previous, "") },
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	for _, project := range p.effectiveProjects("networks") {
+		server := p.server.UseProject(project)
+		networks, err := server.GetNetworks()
+		if err != nil {
+			return err
+		}
+
+		for _, network := range networks {
+			if !network.Managed || desiredNetworks[project+"/"+network.Name] || !p.owned(network.Config) {
+				continue
+			}
+
+			name := network.Name
+			p.deletes = append(p.deletes, applyAction{
+				operation: "-",
+				resource:  p.resourceName("network", name, project),
+				apply:     func() error { return server.DeleteNetwork(name) },
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *applyPlanner) planStorageVolumes(prune bool) error {
+	desiredVolumes := map[string]bool{}
+	for _, desired := range p.stack.StorageVolumes {
+		project := p.effectiveProject(p.project(desired.Project), "storage.volumes")
+		desiredVolumes[project+"/"+desired.Pool+"/"+desired.Name] = true
+		server := p.server.UseProject(project)
+		pool := desired.Pool
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := p.resourceName("storage volume", pool+"/"+name, project)
+
+		current, _, err := server.GetStoragePoolVolume(pool, "custom", name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if current == nil {
+			req := api.StorageVolumesPost{
+				Name:             name,
+				Type:             "custom",
+				ContentType:      desired.ContentType,
+				StorageVolumePut: api.StorageVolumePut{Description: desired.Description, Config: applyConfigMerge(nil, config)},
+			}
+
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   applyConfigDiff(nil, config),
+				apply:     func() error { return server.CreateStoragePoolVolume(pool, req) },
+				revert:    func() error { return server.DeleteStoragePoolVolume(pool, "custom", name) },
+			})
+
+			continue
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if len(details) == 0 {
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply:     func() error { return server.UpdateStoragePoolVolume(pool, "custom", name, put, "") },
+			revert:    func() error { return server.UpdateStoragePoolVolume(pool, "custom", name, previous, "") },
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	pools, err := p.server.GetStoragePoolNames()
+	if err != nil {
+		return err
+	}
+
+	for _, project := range p.effectiveProjects("storage.volumes") {
+		server := p.server.UseProject(project)
+		for _, pool := range pools {
+			volumes, err := server.GetStoragePoolVolumes(pool)
+			if err != nil {
+				return err
+			}
+
+			for _, volume := range volumes {
+				if volume.Type != "custom" || desiredVolumes[project+"/"+pool+"/"+volume.Name] || !p.owned(volume.Config) {
+					continue
+				}
+
+				pool := pool
+				name := volume.Name
+				p.deletes = append(p.deletes, applyAction{
+					operation: "-",
+					resource:  p.resourceName("storage volume", pool+"/"+name, project),
+					apply:     func() error { return server.DeleteStoragePoolVolume(pool, "custom", name) },
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *applyPlanner) planProfiles(prune bool) error {
+	desiredProfiles := map[string]bool{}
+	for _, desired := range p.stack.Profiles {
+		project := p.effectiveProject(p.project(desired.Project), "profiles")
+		desiredProfiles[project+"/"+desired.Name] = true
+		server := p.server.UseProject(project)
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := p.resourceName("profile", name, project)
+
+		current, _, err := server.GetProfile(name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if current == nil {
+			req := api.ProfilesPost{
+				Name:       name,
+				ProfilePut: api.ProfilePut{Description: desired.Description, Config: applyConfigMerge(nil, config), Devices: desired.Devices},
+			}
+
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   append(applyConfigDiff(nil, config), applyDevicesDiff(nil, desired.Devices)...),
+				apply:     func() error { return server.CreateProfile(req) },
+				revert:    func() error { return server.DeleteProfile(name) },
+			})
+
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if desired.Devices != nil {
+			details = append(details, applyDevicesDiff(current.Devices, desired.Devices)...)
+			put.Devices = desired.Devices
+		}
+
+		if len(details) == 0 {
+			continue
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply:     func() error { return server.UpdateProfile(name, put, "") },
+			revert:    func() error { return server.UpdateProfile(name, previous, "") },
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	for _, project := range p.effectiveProjects("profiles") {
+		server := p.server.UseProject(project)
+		profiles, err := server.GetProfiles()
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			if profile.Name == "default" || desiredProfiles[project+"/"+profile.Name] || !p.owned(profile.Config) {
+				continue
+			}
+
+			name := profile.Name
+			p.deletes = append(p.deletes, applyAction{
+				operation: "-",
+				resource:  p.resourceName("profile", name, project),
+				apply:     func() error { return server.DeleteProfile(name) },
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *applyPlanner) planInstances(prune bool) error {
+	desiredInstances := map[string]bool{}
+	for _, desired := range p.stack.Instances {
+		desired := desired
+		project := p.project(desired.Project)
+		desiredInstances[project+"/"+desired.Name] = true
+		server := p.server.UseProject(project)
+		name := desired.Name
+		config := p.config(desired.Config)
+		resource := p.resourceName("instance", name, project)
+
+		current, _, err := server.GetInstance(name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if current == nil {
+			req := api.InstancesPost{
+				Name: name,
+				Type: api.InstanceType(desired.Type),
+				InstancePut: api.InstancePut{
+					Description: desired.Description,
+					Config:      applyConfigMerge(nil, config),
+					Devices:     desired.Devices,
+					Profiles:    desired.Profiles,
+					Ephemeral:   desired.Ephemeral,
+				},
+			}
+
+			details := []string{"    image: " + desired.Image}
+			if desired.Profiles != nil {
+				details = append(details, "    profiles: "+applyListString(desired.Profiles))
+			}
+
+			details = append(details, applyConfigDiff(nil, config)...)
+			details = append(details, applyDevicesDiff(nil, desired.Devices)...)
+
+			p.creates = append(p.creates, applyAction{
+				operation: "+",
+				resource:  resource,
+				details:   details,
+				apply:     func() error { return p.createInstance(server, desired, req) },
+				revert:    func() error { return applyDeleteInstance(server, name) },
+			})
+
+			continue
+		}
+
+		previous := current.Writable()
+		put := current.Writable()
+		put.Config = applyConfigMerge(current.Config, config)
+		if desired.Description != "" {
+			put.Description = desired.Description
+		}
+
+		details := append(descriptionDiff(current.Description, desired.Description), applyConfigDiff(current.Config, config)...)
+		if desired.Profiles != nil && !reflect.DeepEqual(current.Profiles, desired.Profiles) {
+			details = append(details, fmt.Sprintf("    profiles: %s -> %s", applyListString(current.Profiles), applyListString(desired.Profiles)))
+			put.Profiles = desired.Profiles
+		}
+
+		if desired.Devices != nil {
+			details = append(details, applyDevicesDiff(current.Devices, desired.Devices)...)
+			put.Devices = desired.Devices
+		}
+
+		update := len(details) > 0
+		start := desired.Start && current.StatusCode != api.Running
+		if start {
+			details = append(details, fmt.Sprintf("    status: %s -> %s", current.Status, api.Running))
+		}
+
+		if len(details) == 0 {
+			continue
+		}
+
+		p.creates = append(p.creates, applyAction{
+			operation: "~",
+			resource:  resource,
+			details:   details,
+			apply: func() error {
+				if update {
+					op, err := server.UpdateInstance(name, put, "")
+					if err != nil {
+						return err
+					}
+
+					err = op.Wait()
+					if err != nil {
+						return err
+					}
+				}
+
+				if start {
+					return applyInstanceState(server, name, "start")
+				}
+
+				return nil
+			},
+			revert: func() error {
+				if start {
+					err := applyInstanceState(server, name, "stop")
+					if err != nil {
+						return err
+					}
+				}
+
+				if update {
+					op, err := server.UpdateInstance(name, previous, "")
+					if err != nil {
+						return err
+					}
+
+					return op.Wait()
+				}
+
+				return nil
+			},
+		})
+	}
+
+	if !prune {
+		return nil
+	}
+
+	projects := make([]string, 0, len(p.projects))
+	for name := range p.projects {
+		projects = append(projects, name)
+	}
+
+	sort.Strings(projects)
+
+	for _, project := range projects {
+		server := p.server.UseProject(project)
+		instances, err := server.GetInstances(api.InstanceTypeAny)
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range instances {
+			if desiredInstances[project+"/"+inst.Name] || !p.owned(inst.Config) {
+				continue
+			}
+
+			name := inst.Name
+			p.deletes = append(p.deletes, applyAction{
+				operation: "-",
+				resource:  p.resourceName("instance", name, project),
+				apply:     func() error { return applyDeleteInstance(server, name) },
+			})
+		}
+	}
+
+	return nil
+}
+
+// createInstance creates an instance of the stack from its image and starts it if requested.
+func (p *applyPlanner) createInstance(server lxd.InstanceServer, desired applyStackInstance, req api.InstancesPost) error {
+	conf := p.cmd.global.conf
+
+	iremote, image, err := conf.ParseRemote(desired.Image)
+	if err != nil {
+		return err
+	}
+
+	iremote, image = guessImage(conf, server, p.remote, iremote, image)
+
+	imgRemote, imgInfo, err := getImgInfo(server, conf, iremote, p.remote, image, &req.Source)
+	if err != nil {
+		return err
+	}
+
+	if conf.Remotes[iremote].Protocol != "simplestreams" && req.Type == "" {
+		req.Type = api.InstanceType(imgInfo.Type)
+	}
+
+	op, err := server.CreateInstanceFromImage(imgRemote, *imgInfo, req)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Retrieving image: %s"),
+		Quiet:  p.cmd.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	if desired.Start {
+		return applyInstanceState(server, req.Name, "start")
+	}
+
+	return nil
+}
+
+// applyInstanceState changes the state of an instance and waits for the change to complete.
+func applyInstanceState(server lxd.InstanceServer, name string, action string) error {
+	op, err := server.UpdateInstanceState(name, api.InstanceStatePut{Action: action, Timeout: -1, Force: action == "stop"}, "")
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// applyDeleteInstance stops an instance if needed and deletes it.
+func applyDeleteInstance(server lxd.InstanceServer, name string) error {
+	inst, _, err := server.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	if inst.StatusCode != api.Stopped {
+		err := applyInstanceState(server, name, "stop")
+		if err != nil {
+			return err
+		}
+
+		// Ephemeral instances are deleted when stopped.
+		if inst.Ephemeral {
+			return nil
+		}
+	}
+
+	op, err := server.DeleteInstance(name)
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// applyListString returns a human readable representation of a list.
+func applyListString(list []string) string {
+	if len(list) == 0 {
+		return i18n.G("(none)")
+	}
+
+	return strings.Join(list, ", ")
+}
+
+// applyConfigMerge returns the configuration resulting from applying the desired configuration keys to the
+// current configuration. Keys with an empty desired value are removed and keys with an "auto" desired value keep
+// their current value if they have one.
+func applyConfigMerge(current map[string]string, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(desired))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for key, value := range desired {
+		if value == "" {
+			delete(merged, key)
+			continue
+		}
+
+		if value == "auto" && merged[key] != "" {
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// applyConfigDiff returns a human readable list of the changes made by applyConfigMerge, sorted by key.
+func applyConfigDiff(current map[string]string, desired map[string]string) []string {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var changes []string
+	for _, key := range keys {
+		value := desired[key]
+		currentValue, ok := current[key]
+
+		switch {
+		case value == "" && ok:
+			changes = append(changes, "-   "+key)
+		case value == "" || value == currentValue || (value == "auto" && currentValue != ""):
+			continue
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+   %s: %q", key, value))
+		default:
+			changes = append(changes, fmt.Sprintf("    %s: %q -> %q", key, currentValue, value))
+		}
+	}
+
+	return changes
+}
+
+// applyDevicesDiff returns a human readable list of the changes needed to replace the current devices with the
+// desired ones, sorted by device name.
+func applyDevicesDiff(current map[string]map[string]string, desired map[string]map[string]string) []string {
+	names := make([]string, 0, len(current)+len(desired))
+	for name := range current {
+		names = append(names, name)
+	}
+
+	for name := range desired {
+		_, ok := current[name]
+		if !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		currentDevice, inCurrent := current[name]
+		desiredDevice, inDesired := desired[name]
+
+		switch {
+		case !inDesired:
+			changes = append(changes, "-   device "+name)
+		case !inCurrent:
+			changes = append(changes, "+   device "+name)
+		case !reflect.DeepEqual(currentDevice, desiredDevice):
+			changes = append(changes, "    device "+name+":")
+			changes = append(changes, applyDeviceConfigDiff(currentDevice, desiredDevice)...)
+		}
+	}
+
+	return changes
+}
+
+// applyDeviceConfigDiff returns a human readable list of the changes to the configuration of a device.
+func applyDeviceConfigDiff(current map[string]string, desired map[string]string) []string {
+	// Keys absent from the desired device are removed.
+	removals := make(map[string]string, len(desired)+len(current))
+	for key := range current {
+		removals[key] = ""
+	}
+
+	for key, value := range desired {
+		removals[key] = value
+	}
+
+	changes := applyConfigDiff(current, removals)
+	for i, change := range changes {
+		changes[i] = change[:1] + "     " + change[4:]
+	}
+
+	return changes
+}
+
+// applyRulesDiff returns a human readable summary of the changes to a list of network ACL rules.
+func applyRulesDiff(direction string, current []api.NetworkACLRule, desired []api.NetworkACLRule) []string {
+	if reflect.DeepEqual(current, desired) || (len(current) == 0 && len(desired) == 0) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf(i18n.G("    %s: %d rules -> %d rules"), direction, len(current), len(desired))}
+}
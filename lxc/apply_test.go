@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyConfigMerge(t *testing.T) {
+	current := map[string]string{
+		"limits.cpu":      "2",
+		"limits.memory":   "1GiB",
+		"volatile.uuid":   "f0e1b1c2",
+		"ipv4.address":    "10.0.0.1/24",
+		"user.deprecated": "yes",
+	}
+
+	desired := map[string]string{
+		"limits.cpu":      "4",
+		"ipv4.address":    "auto",
+		"ipv6.address":    "auto",
+		"user.deprecated": "",
+		"user.stack":      "web",
+	}
+
+	expected := map[string]string{
+		"limits.cpu":    "4",
+		"limits.memory": "1GiB",
+		"volatile.uuid": "f0e1b1c2",
+		"ipv4.address":  "10.0.0.1/24",
+		"ipv6.address":  "auto",
+		"user.stack":    "web",
+	}
+
+	merged := applyConfigMerge(current, desired)
+	if !reflect.DeepEqual(expected, merged) {
+		t.Errorf("Unexpected merged config:\n%v\nexpected:\n%v", merged, expected)
+	}
+
+	// Keys with an empty value aren't set on creation.
+	merged = applyConfigMerge(nil, map[string]string{"limits.cpu": "2", "limits.memory": ""})
+	if !reflect.DeepEqual(map[string]string{"limits.cpu": "2"}, merged) {
+		t.Errorf("Unexpected config on creation: %v", merged)
+	}
+}
+
+func TestApplyConfigDiff(t *testing.T) {
+	current := map[string]string{
+		"limits.cpu":      "2",
+		"limits.memory":   "1GiB",
+		"ipv4.address":    "10.0.0.1/24",
+		"user.deprecated": "yes",
+	}
+
+	// No changes when the desired keys match.
+	changes := applyConfigDiff(current, map[string]string{"limits.cpu": "2", "ipv4.address": "auto", "user.missing": ""})
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	expected := []string{
+		`+   ipv6.address: "auto"`,
+		`    limits.cpu: "2" -> "4"`,
+		`-   user.deprecated`,
+	}
+
+	changes = applyConfigDiff(current, map[string]string{
+		"limits.cpu":      "4",
+		"ipv6.address":    "auto",
+		"user.deprecated": "",
+	})
+
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("Unexpected changes:\n%v\nexpected:\n%v", changes, expected)
+	}
+}
+
+func TestApplyDevicesDiff(t *testing.T) {
+	current := map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "lxdbr0"},
+		"root": {"type": "disk", "path": "/", "pool": "default"},
+		"data": {"type": "disk", "path": "/data", "source": "data"},
+	}
+
+	// No changes between identical devices.
+	changes := applyDevicesDiff(current, current)
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	desired := map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "web"},
+		"root": {"type": "disk", "path": "/", "pool": "default", "size": "10GiB"},
+		"gpu":  {"type": "gpu"},
+	}
+
+	expected := []string{
+		`-   device data`,
+		`    device eth0:`,
+		`      network: "lxdbr0" -> "web"`,
+		`+   device gpu`,
+		`    device root:`,
+		`+     size: "10GiB"`,
+	}
+
+	changes = applyDevicesDiff(current, desired)
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("Unexpected changes:\n%v\nexpected:\n%v", changes, expected)
+	}
+}
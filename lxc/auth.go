@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -42,6 +43,15 @@ func (c *cmdAuth) command() *cobra.Command {
 	identityProviderGroupCmd := cmdIdentityProviderGroup{global: c.global}
 	cmd.AddCommand(identityProviderGroupCmd.command())
 
+	canICmd := cmdAuthCanI{global: c.global}
+	cmd.AddCommand(canICmd.command())
+
+	exportCmd := cmdAuthExport{global: c.global}
+	cmd.AddCommand(exportCmd.command())
+
+	applyCmd := cmdAuthApply{global: c.global}
+	cmd.AddCommand(applyCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -746,6 +756,9 @@ func (c *cmdIdentity) command() *cobra.Command {
 	identityGroupCmd := cmdIdentityGroup{global: c.global}
 	cmd.AddCommand(identityGroupCmd.command())
 
+	identityTokenCmd := cmdIdentityToken{global: c.global}
+	cmd.AddCommand(identityTokenCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -1222,6 +1235,309 @@ func (c *cmdIdentityGroupRemove) run(cmd *cobra.Command, args []string) error {
 	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
 }
 
+type cmdIdentityToken struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityToken) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("token")
+	cmd.Short = i18n.G("Manage bearer tokens for the identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage bearer tokens for the identity
+
+Bearer tokens authenticate requests as the identity when sent in the
+"Authorization: Bearer <secret>" header. They can expire, be restricted to
+specific client addresses and be narrowed down to a subset of the permissions
+of the identity.`))
+
+	identityTokenCreateCmd := cmdIdentityTokenCreate{global: c.global}
+	cmd.AddCommand(identityTokenCreateCmd.command())
+
+	identityTokenDeleteCmd := cmdIdentityTokenDelete{global: c.global}
+	cmd.AddCommand(identityTokenDeleteCmd.command())
+
+	identityTokenListCmd := cmdIdentityTokenList{global: c.global}
+	cmd.AddCommand(identityTokenListCmd.command())
+
+	identityTokenShowCmd := cmdIdentityTokenShow{global: c.global}
+	cmd.AddCommand(identityTokenShowCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// parseIdentityArg parses the `[<remote>:]<authentication_method>/<name_or_identifier>` argument.
+func (c *cmdIdentityToken) parseIdentityArg(arg string) (*remoteResource, string, string, error) {
+	resources, err := c.global.ParseServers(arg)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return nil, "", "", fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID, ok := strings.Cut(resource.name, "/")
+	if !ok {
+		return nil, "", "", fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", arg)
+	}
+
+	return &resource, authenticationMethod, nameOrID, nil
+}
+
+// Create.
+type cmdIdentityTokenCreate struct {
+	global *cmdGlobal
+
+	flagDescription      string
+	flagExpiry           string
+	flagAllowedAddresses []string
+	flagPermissions      []string
+}
+
+func (c *cmdIdentityTokenCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <token>"))
+	cmd.Short = i18n.G("Create a bearer token for an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create a bearer token for an identity
+
+The secret of the token is only displayed once, when the token is created.
+
+Permissions are given as "<entity_type> [<entity_name>] <entitlement> [<key>=<value>...]",
+like for "lxc auth group permission add". When set, the token can only be used for
+operations allowed by both the identity and the listed permissions.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity token create tls/ci ci-runner --expiry 30d --allowed-address 10.0.0.0/24 --permission "project default can_view"
+    Create a token for the "ci" identity that expires in 30 days, can only be used from 10.0.0.0/24 and can only view the default project.`))
+
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Token description")+"``")
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Token expiry (e.g. 1d, 2w)")+"``")
+	cmd.Flags().StringSliceVar(&c.flagAllowedAddresses, "allowed-address", nil, i18n.G("Address or subnet the token can be used from (can be repeated)")+"``")
+	cmd.Flags().StringArrayVar(&c.flagPermissions, "permission", nil, i18n.G("Permission the token is restricted to (can be repeated)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	tokenCmd := cmdIdentityToken{global: c.global}
+	resource, authenticationMethod, nameOrID, err := tokenCmd.parseIdentityArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	token := api.IdentityTokensPost{
+		Name:             args[1],
+		Description:      c.flagDescription,
+		AllowedAddresses: c.flagAllowedAddresses,
+	}
+
+	if c.flagExpiry != "" {
+		token.ExpiresAt, err = shared.GetExpiry(time.Now(), c.flagExpiry)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid expiry: %w"), err)
+		}
+	}
+
+	for _, permissionArg := range c.flagPermissions {
+		// parsePermissionArgs expects the group name as first argument.
+		permission, err := parsePermissionArgs(append([]string{""}, strings.Fields(permissionArg)...))
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid permission %q: %w"), permissionArg, err)
+		}
+
+		token.Permissions = append(token.Permissions, *permission)
+	}
+
+	secret, err := resource.server.CreateIdentityToken(authenticationMethod, nameOrID, token)
+	if err != nil {
+		return err
+	}
+
+	if c.global.flagQuiet {
+		fmt.Println(secret.Secret)
+		return nil
+	}
+
+	fmt.Printf(i18n.G("Identity token %s created")+"\n", secret.Name)
+	fmt.Printf(i18n.G("Secret (only displayed once): %s")+"\n", secret.Secret)
+
+	return nil
+}
+
+// Delete.
+type cmdIdentityTokenDelete struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityTokenDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <token>"))
+	cmd.Aliases = []string{"rm", "revoke"}
+	cmd.Short = i18n.G("Revoke a bearer token of an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Revoke a bearer token of an identity`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	tokenCmd := cmdIdentityToken{global: c.global}
+	resource, authenticationMethod, nameOrID, err := tokenCmd.parseIdentityArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = resource.server.DeleteIdentityToken(authenticationMethod, nameOrID, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Identity token %s revoked")+"\n", args[1])
+	}
+
+	return nil
+}
+
+// List.
+type cmdIdentityTokenList struct {
+	global     *cmdGlobal
+	flagFormat string
+}
+
+func (c *cmdIdentityTokenList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List bearer tokens of an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List bearer tokens of an identity`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	tokenCmd := cmdIdentityToken{global: c.global}
+	resource, authenticationMethod, nameOrID, err := tokenCmd.parseIdentityArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	tokens, err := resource.server.GetIdentityTokens(authenticationMethod, nameOrID)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+	formatDate := func(date time.Time) string {
+		if date.IsZero() {
+			return i18n.G("never")
+		}
+
+		return date.Local().Format(layout)
+	}
+
+	data := [][]string{}
+	for _, token := range tokens {
+		restricted := i18n.G("NO")
+		if len(token.Permissions) > 0 || len(token.AllowedAddresses) > 0 {
+			restricted = i18n.G("YES")
+		}
+
+		data = append(data, []string{token.Name, token.Description, formatDate(token.ExpiresAt), formatDate(token.LastUsedAt), restricted})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("EXPIRES AT"),
+		i18n.G("LAST USED AT"),
+		i18n.G("RESTRICTED"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, tokens)
+}
+
+// Show.
+type cmdIdentityTokenShow struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityTokenShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <token>"))
+	cmd.Short = i18n.G("Show a bearer token of an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show a bearer token of an identity
+
+The secret of the token is never displayed.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	tokenCmd := cmdIdentityToken{global: c.global}
+	resource, authenticationMethod, nameOrID, err := tokenCmd.parseIdentityArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	token, err := resource.server.GetIdentityToken(authenticationMethod, nameOrID, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
 type cmdPermission struct {
 	global *cmdGlobal
 }
@@ -1979,3 +2295,401 @@ func (c *cmdIdentityProviderGroupGroupRemove) run(cmd *cobra.Command, args []str
 	idpGroup.Groups = groups
 	return resource.server.UpdateIdentityProviderGroup(resource.name, idpGroup.Writable(), eTag)
 }
+
+// Can I.
+type cmdAuthCanI struct {
+	global *cmdGlobal
+
+	flagFormat                 string
+	flagIdentityProviderGroups []string
+}
+
+func (c *cmdAuthCanI) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("can-i", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> <entitlement> <entity_type> [<entity_name>] [<key>=<value>...]"))
+	cmd.Short = i18n.G("Check whether an identity has an entitlement on an entity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Check whether an identity has an entitlement on an entity
+
+The decision is shown along with the group permissions that grant the entitlement.
+Identity provider groups are only known while an OIDC identity is authenticated,
+use --identity-provider-group to evaluate the permissions of other OIDC identities
+with their identity provider groups.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth can-i oidc/jane.doe@example.com can_edit instance c1 project=default
+    Check whether the OIDC identity "jane.doe@example.com" can edit instance "c1" in project "default".
+
+lxc auth can-i tls/my-client can_edit server
+    Check whether the TLS identity "my-client" can edit the server configuration.`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().StringArrayVar(&c.flagIdentityProviderGroups, "identity-provider-group", nil, i18n.G("Identity provider group to evaluate the permissions with")+"``")
+
+	return cmd
+}
+
+func (c *cmdAuthCanI) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	tokenCmd := cmdIdentityToken{global: c.global}
+	resource, authenticationMethod, nameOrID, err := tokenCmd.parseIdentityArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Reorder the arguments as expected by parsePermissionArgs (entitlement after the entity name).
+	permissionArgs := []string{args[0], args[2]}
+	if len(args) > 3 {
+		permissionArgs = append(permissionArgs, args[3])
+	}
+
+	permissionArgs = append(permissionArgs, args[1])
+	if len(args) > 4 {
+		permissionArgs = append(permissionArgs, args[4:]...)
+	}
+
+	permission, err := parsePermissionArgs(permissionArgs)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := resource.server.GetIdentityEffectivePermissions(authenticationMethod, nameOrID, permission.EntityReference, permission.Entitlement, c.flagIdentityProviderGroups)
+	if err != nil {
+		return err
+	}
+
+	if len(permissions) != 1 {
+		return fmt.Errorf("Unexpected number of evaluated entitlements: %d", len(permissions))
+	}
+
+	result := permissions[0]
+
+	data := [][]string{}
+	for _, grant := range result.Grants {
+		data = append(data, []string{grant.Group, strings.Join(grant.IdentityProviderGroups, "\n"), grant.Permission.EntityType, grant.Permission.EntityReference, grant.Permission.Entitlement})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	if c.flagFormat == cli.TableFormatTable || c.flagFormat == cli.TableFormatCompact {
+		allowed := i18n.G("no")
+		if result.Allowed {
+			allowed = i18n.G("yes")
+		}
+
+		fmt.Println(allowed)
+		fmt.Printf(i18n.G("Reason: %s")+"\n", result.Reason)
+
+		if len(data) == 0 {
+			return nil
+		}
+
+		fmt.Println("")
+		fmt.Println(i18n.G("Granted by:"))
+	}
+
+	header := []string{
+		i18n.G("GROUP"),
+		i18n.G("IDENTITY PROVIDER GROUPS"),
+		i18n.G("ENTITY TYPE"),
+		i18n.G("ENTITY"),
+		i18n.G("ENTITLEMENT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, result)
+}
+
+// Export.
+type cmdAuthExport struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthExport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Export the access model")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export the access model
+
+The groups and their permissions, the identity provider group mappings and the
+group memberships of identities are exported as a single YAML document that
+can be applied with "lxc auth apply".`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth export > access-model.yaml
+    Export the access model of the default remote to access-model.yaml.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdAuthExport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	model, _, err := resources[0].server.GetAuthAccessModel()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Apply.
+type cmdAuthApply struct {
+	global *cmdGlobal
+
+	flagFile   string
+	flagPrune  bool
+	flagDryRun bool
+}
+
+func (c *cmdAuthApply) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Apply an access model")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply an access model
+
+The differences between the access model and the one of the server are shown,
+then the access model is applied in a single transaction. Groups, identity
+provider groups and identities that are present in the access model are
+created or updated to match it.
+
+With --prune, groups and identity provider groups that are absent from the
+access model are deleted, and identities that are absent from it are removed
+from all groups.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth apply -f access-model.yaml --dry-run
+    Show the changes that applying access-model.yaml would make.
+
+lxc auth apply -f access-model.yaml --prune
+    Apply access-model.yaml and remove all entries that are absent from it.`))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFile, "file", "f", "", i18n.G("Access model file (\"-\" for standard input)")+"``")
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, i18n.G("Remove entries that are absent from the access model"))
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes without applying them"))
+
+	return cmd
+}
+
+func (c *cmdAuthApply) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagFile == "" {
+		return fmt.Errorf(i18n.G("An access model file must be given with --file"))
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	var contents []byte
+	if c.flagFile == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(c.flagFile)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	desired := api.AuthAccessModel{}
+	err = yaml.UnmarshalStrict(contents, &desired)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed to parse access model: %w"), err)
+	}
+
+	current, etag, err := resource.server.GetAuthAccessModel()
+	if err != nil {
+		return err
+	}
+
+	changes := authAccessModelDiff(*current, desired, c.flagPrune)
+	if len(changes) == 0 {
+		if !c.global.flagQuiet {
+			fmt.Println(i18n.G("The access model is up to date"))
+		}
+
+		return nil
+	}
+
+	if !c.global.flagQuiet {
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	// The ETag ensures the server wasn't changed since the differences were computed.
+	return resource.server.UpdateAuthAccessModel(desired, c.flagPrune, etag)
+}
+
+// authAccessModelDiff returns a human readable list of the changes needed to go from the current to the desired
+// access model. Added entries are prefixed with "+", removed entries with "-" and changed entries with "~".
+func authAccessModelDiff(current api.AuthAccessModel, desired api.AuthAccessModel, prune bool) []string {
+	var changes []string
+
+	permissionString := func(permission api.Permission) string {
+		return strings.Join([]string{permission.EntityType, permission.EntityReference, permission.Entitlement}, " ")
+	}
+
+	groupsString := func(groups []string) string {
+		if len(groups) == 0 {
+			return i18n.G("(none)")
+		}
+
+		sorted := append([]string{}, groups...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, ", ")
+	}
+
+	// Groups.
+	currentGroups := make(map[string]api.AuthGroupsPost, len(current.Groups))
+	for _, group := range current.Groups {
+		currentGroups[group.Name] = group
+	}
+
+	desiredGroups := make(map[string]bool, len(desired.Groups))
+	for _, group := range desired.Groups {
+		desiredGroups[group.Name] = true
+
+		currentGroup, ok := currentGroups[group.Name]
+		if !ok {
+			changes = append(changes, "+ group "+group.Name)
+			for _, permission := range group.Permissions {
+				changes = append(changes, "+   "+permissionString(permission))
+			}
+
+			continue
+		}
+
+		var groupChanges []string
+		if currentGroup.Description != group.Description {
+			groupChanges = append(groupChanges, fmt.Sprintf("    description: %q -> %q", currentGroup.Description, group.Description))
+		}
+
+		for _, permission := range group.Permissions {
+			if !shared.ValueInSlice(permission, currentGroup.Permissions) {
+				groupChanges = append(groupChanges, "+   "+permissionString(permission))
+			}
+		}
+
+		for _, permission := range currentGroup.Permissions {
+			if !shared.ValueInSlice(permission, group.Permissions) {
+				groupChanges = append(groupChanges, "-   "+permissionString(permission))
+			}
+		}
+
+		if len(groupChanges) > 0 {
+			changes = append(changes, "~ group "+group.Name)
+			changes = append(changes, groupChanges...)
+		}
+	}
+
+	if prune {
+		for _, group := range current.Groups {
+			if !desiredGroups[group.Name] {
+				changes = append(changes, "- group "+group.Name)
+			}
+		}
+	}
+
+	// Identity provider groups.
+	currentIDPGroups := make(map[string]api.IdentityProviderGroup, len(current.IdentityProviderGroups))
+	for _, idpGroup := range current.IdentityProviderGroups {
+		currentIDPGroups[idpGroup.Name] = idpGroup
+	}
+
+	desiredIDPGroups := make(map[string]bool, len(desired.IdentityProviderGroups))
+	for _, idpGroup := range desired.IdentityProviderGroups {
+		desiredIDPGroups[idpGroup.Name] = true
+
+		currentIDPGroup, ok := currentIDPGroups[idpGroup.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("+ identity provider group %s: %s", idpGroup.Name, groupsString(idpGroup.Groups)))
+		} else if groupsString(currentIDPGroup.Groups) != groupsString(idpGroup.Groups) {
+			changes = append(changes, fmt.Sprintf("~ identity provider group %s: %s -> %s", idpGroup.Name, groupsString(currentIDPGroup.Groups), groupsString(idpGroup.Groups)))
+		}
+	}
+
+	if prune {
+		for _, idpGroup := range current.IdentityProviderGroups {
+			if !desiredIDPGroups[idpGroup.Name] {
+				changes = append(changes, "- identity provider group "+idpGroup.Name)
+			}
+		}
+	}
+
+	// Identities.
+	currentIdentities := make(map[string]api.AuthAccessModelIdentity, len(current.Identities))
+	for _, identity := range current.Identities {
+		currentIdentities[identity.AuthenticationMethod+"/"+identity.Identifier] = identity
+	}
+
+	desiredIdentities := make(map[string]bool, len(desired.Identities))
+	for _, identity := range desired.Identities {
+		key := identity.AuthenticationMethod + "/" + identity.Identifier
+		desiredIdentities[key] = true
+
+		currentGroups := groupsString(currentIdentities[key].Groups)
+		if currentGroups != groupsString(identity.Groups) {
+			changes = append(changes, fmt.Sprintf("~ identity %s: %s -> %s", key, currentGroups, groupsString(identity.Groups)))
+		}
+	}
+
+	if prune {
+		for _, identity := range current.Identities {
+			key := identity.AuthenticationMethod + "/" + identity.Identifier
+			if !desiredIdentities[key] && len(identity.Groups) > 0 {
+				changes = append(changes, fmt.Sprintf("~ identity %s: %s -> %s", key, groupsString(identity.Groups), groupsString(nil)))
+			}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestAuthAccessModelDiff(t *testing.T) {
+	viewer := api.Permission{EntityType: "project", EntityReference: "/1.0/projects/default", Entitlement: "viewer"}
+	operator := api.Permission{EntityType: "project", EntityReference: "/1.0/projects/default", Entitlement: "operator"}
+
+	current := api.AuthAccessModel{
+		Groups: []api.AuthGroupsPost{
+			{AuthGroupPost: api.AuthGroupPost{Name: "viewers"}, AuthGroupPut: api.AuthGroupPut{Permissions: []api.Permission{viewer}}},
+			{AuthGroupPost: api.AuthGroupPost{Name: "legacy"}},
+		},
+		IdentityProviderGroups: []api.IdentityProviderGroup{
+			{Name: "sales", Groups: []string{"viewers"}},
+		},
+		Identities: []api.AuthAccessModelIdentity{
+			{AuthenticationMethod: "oidc", Identifier: "jane@example.com", Groups: []string{"viewers"}},
+			{AuthenticationMethod: "oidc", Identifier: "joe@example.com", Groups: []string{"legacy"}},
+		},
+	}
+
+	desired := api.AuthAccessModel{
+		Groups: []api.AuthGroupsPost{
+			{AuthGroupPost: api.AuthGroupPost{Name: "viewers"}, AuthGroupPut: api.AuthGroupPut{Description: "Viewers", Permissions: []api.Permission{viewer}}},
+			{AuthGroupPost: api.AuthGroupPost{Name: "operators"}, AuthGroupPut: api.AuthGroupPut{Permissions: []api.Permission{operator}}},
+		},
+		IdentityProviderGroups: []api.IdentityProviderGroup{
+			{Name: "sales", Groups: []string{"viewers", "operators"}},
+		},
+		Identities: []api.AuthAccessModelIdentity{
+			{AuthenticationMethod: "oidc", Identifier: "jane@example.com", Groups: []string{"viewers"}},
+		},
+	}
+
+	// No changes between identical access models.
+	changes := authAccessModelDiff(current, current, true)
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	expected := []string{
+		`~ group viewers`,
+		`    description: "" -> "Viewers"`,
+		`+ group operators`,
+		`+   project /1.0/projects/default operator`,
+		`~ identity provider group sales: viewers -> operators, viewers`,
+	}
+
+	changes = authAccessModelDiff(current, desired, false)
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("Unexpected changes without pruning:\n%v\nexpected:\n%v", changes, expected)
+	}
+
+	// Entries that are absent from the desired access model are only removed when pruning.
+	expected = []string{
+		`~ group viewers`,
+		`    description: "" -> "Viewers"`,
+		`+ group operators`,
+		`+   project /1.0/projects/default operator`,
+		`- group legacy`,
+		`~ identity provider group sales: viewers -> operators, viewers`,
+		`~ identity oidc/joe@example.com: legacy -> (none)`,
+	}
+
+	changes = authAccessModelDiff(current, desired, true)
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("Unexpected changes with pruning:\n%v\nexpected:\n%v", changes, expected)
+	}
+}
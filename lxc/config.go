@@ -24,7 +24,7 @@ type cmdConfig struct {
 }
 
 // Command creates a Cobra command for managing instance and server configurations,
-// including options for device, edit, get, metadata, profile, set, show, template, trust, and unset.
+// including options for device, edit, get, history, metadata, profile, rollback, set, show, template, trust, and unset.
 func (c *cmdConfig) Command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("config")
@@ -44,6 +44,10 @@ func (c *cmdConfig) Command() *cobra.Command {
 	configGetCmd := cmdConfigGet{global: c.global, config: c}
 	cmd.AddCommand(configGetCmd.Command())
 
+	// History
+	configHistoryCmd := cmdConfigHistory{global: c.global, entity: "instance"}
+	cmd.AddCommand(configHistoryCmd.Command())
+
 	// Metadata
 	configMetadataCmd := cmdConfigMetadata{global: c.global, config: c}
 	cmd.AddCommand(configMetadataCmd.Command())
@@ -55,6 +59,10 @@ func (c *cmdConfig) Command() *cobra.Command {
 	profileCmd.Deprecated = i18n.G("please use `lxc profile`")
 	cmd.AddCommand(profileCmd)
 
+	// Rollback
+	configRollbackCmd := cmdConfigRollback{global: c.global, entity: "instance"}
+	cmd.AddCommand(configRollbackCmd.Command())
+
 	// Set
 	configSetCmd := cmdConfigSet{global: c.global, config: c}
 	cmd.AddCommand(configSetCmd.Command())
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+// History.
+type cmdConfigHistory struct {
+	global *cmdGlobal
+
+	// entity is the type of entity whose history is shown: "instance", "profile" or "project".
+	entity string
+
+	flagFormat string
+}
+
+func (c *cmdConfigHistory) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+
+	switch c.entity {
+	case "profile":
+		cmd.Use = usage("history", i18n.G("[<remote>:]<profile>"))
+		cmd.Short = i18n.G("Show the configuration history of a profile")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Show the configuration history of a profile
+
+Each change to the profile is recorded as a revision that can be restored with "lxc profile rollback".`))
+	case "project":
+		cmd.Use = usage("history", i18n.G("[<remote>:]<project>"))
+		cmd.Short = i18n.G("Show the configuration history of a project")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Show the configuration history of a project
+
+Each change to the project is recorded as a revision that can be restored with "lxc project rollback".`))
+	default:
+		cmd.Use = usage("history", i18n.G("[<remote>:]<instance>"))
+		cmd.Short = i18n.G("Show the configuration history of an instance")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Show the configuration history of an instance
+
+Each change to the instance is recorded as a revision that can be restored with "lxc config rollback".`))
+	}
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdConfigHistory) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	var revisions []api.ConfigRevision
+	switch c.entity {
+	case "profile":
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing profile name"))
+		}
+
+		revisions, err = resource.server.GetProfileHistory(resource.name)
+	case "project":
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing project name"))
+		}
+
+		revisions, err = resource.server.GetProjectHistory(resource.name)
+	default:
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing instance name"))
+		}
+
+		revisions, err = resource.server.GetInstanceHistory(resource.name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(revisions))
+	for _, revision := range revisions {
+		requestor := ""
+		if revision.Requestor != nil {
+			requestor = revision.Requestor.Username
+			if requestor == "" {
+				requestor = revision.Requestor.Protocol
+			}
+		}
+
+		changes := make([]string, 0, len(revision.Changes))
+		for _, change := range revision.Changes {
+			changes = append(changes, configHistoryChangeString(change))
+		}
+
+		data = append(data, []string{
+			strconv.FormatInt(revision.Revision, 10),
+			revision.CreatedAt.Local().Format("2006/01/02 15:04 MST"),
+			requestor,
+			strings.Join(changes, "\n"),
+		})
+	}
+
+	header := []string{
+		i18n.G("REVISION"),
+		i18n.G("DATE"),
+		i18n.G("REQUESTOR"),
+		i18n.G("CHANGES"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, revisions)
+}
+
+// configHistoryChangeString returns a human readable representation of the change of a key.
+func configHistoryChangeString(change api.ConfigRevisionChange) string {
+	switch {
+	case change.OldValue == "":
+		return fmt.Sprintf("+ %s: %s", change.Key, change.NewValue)
+	case change.NewValue == "":
+		return fmt.Sprintf("- %s: %s", change.Key, change.OldValue)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", change.Key, change.OldValue, change.NewValue)
+	}
+}
+
+// Rollback.
+type cmdConfigRollback struct {
+	global *cmdGlobal
+
+	// entity is the type of entity to roll back: "instance", "profile" or "project".
+	entity string
+}
+
+func (c *cmdConfigRollback) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+
+	switch c.entity {
+	case "profile":
+		cmd.Use = usage("rollback", i18n.G("[<remote>:]<profile> <revision>"))
+		cmd.Short = i18n.G("Restore a previous configuration of a profile")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Restore a previous configuration of a profile
+
+The configuration, devices and description of the profile are restored as recorded by the revision.
+The rollback itself is recorded as a new revision.`))
+		cmd.Example = cli.FormatSection("", i18n.G(
+			`lxc profile rollback default 3
+    Restore the configuration of the default profile recorded by revision 3.`))
+	case "project":
+		cmd.Use = usage("rollback", i18n.G("[<remote>:]<project> <revision>"))
+		cmd.Short = i18n.G("Restore a previous configuration of a project")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Restore a previous configuration of a project
+
+The configuration and description of the project are restored as recorded by the revision.
+The rollback itself is recorded as a new revision.`))
+		cmd.Example = cli.FormatSection("", i18n.G(
+			`lxc project rollback p1 3
+    Restore the configuration of project p1 recorded by revision 3.`))
+	default:
+		cmd.Use = usage("rollback", i18n.G("[<remote>:]<instance> <revision>"))
+		cmd.Short = i18n.G("Restore a previous configuration of an instance")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Restore a previous configuration of an instance
+
+The configuration, devices, profiles and description of the instance are restored as recorded by the revision.
+Volatile configuration keys are left unchanged. The rollback itself is recorded as a new revision.`))
+		cmd.Example = cli.FormatSection("", i18n.G(
+			`lxc config rollback c1 3
+    Restore the configuration of instance c1 recorded by revision 3.`))
+	}
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdConfigRollback) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	revision, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid revision %q: %w"), args[1], err)
+	}
+
+	switch c.entity {
+	case "profile":
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing profile name"))
+		}
+
+		return resource.server.RollbackProfileConfig(resource.name, revision)
+	case "project":
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing project name"))
+		}
+
+		return resource.server.RollbackProjectConfig(resource.name, revision)
+	default:
+		if resource.name == "" {
+			return fmt.Errorf(i18n.G("Missing instance name"))
+		}
+
+		return resource.server.RollbackInstanceConfig(resource.name, revision)
+	}
+}
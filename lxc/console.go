@@ -27,6 +27,7 @@ type cmdConsole struct {
 
 	flagShowLog bool
 	flagType    string
+	flagDevice  string
 }
 
 func (c *cmdConsole) Command() *cobra.Command {
@@ -37,11 +38,18 @@ func (c *cmdConsole) Command() *cobra.Command {
 		`Attach to instance consoles
 
 This command allows you to interact with the boot console of an instance
-as well as retrieve past log entries from it.`))
+as well as retrieve past log entries from it.
+
+The --device flag attaches to a serial device of the instance instead
+of its boot console.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc console c1 --device serial0
+    Attach to the "serial0" serial device of instance c1.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the instance's console log"))
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
+	cmd.Flags().StringVar(&c.flagDevice, "device", "", i18n.G("Serial device to attach to instead of the boot console")+"``")
 
 	return cmd
 }
@@ -108,6 +116,14 @@ func (c *cmdConsole) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown output type %q"), c.flagType)
 	}
 
+	if c.flagDevice != "" && c.flagType != "console" {
+		return fmt.Errorf(i18n.G("The --device flag is only supported by the 'console' output type"))
+	}
+
+	if c.flagDevice != "" && c.flagShowLog {
+		return fmt.Errorf(i18n.G("The --device and --show-log flags can't be used together"))
+	}
+
 	// Connect to LXD
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -182,6 +198,7 @@ func (c *cmdConsole) console(d lxd.InstanceServer, name string) error {
 		Width:  width,
 		Height: height,
 		Type:   "console",
+		Device: c.flagDevice,
 	}
 
 	consoleDisconnect := make(chan bool)
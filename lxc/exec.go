@@ -30,6 +30,12 @@ type cmdExec struct {
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
+	flagSession             bool
+	flagDetach              bool
+	flagAttach              string
+	flagListSessions        bool
+	flagTerminate           string
+	flagFormat              string
 
 	interactive bool
 }
@@ -48,7 +54,18 @@ executable, passing the shell commands as arguments, for example:
 
   lxc exec <instance> -- sh -c "cd /tmp && pwd"
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+With --session or --detach, the command runs with a terminal in a persistent
+exec session which keeps running when the client disconnects. Sessions can be
+listed with --list-sessions, attached to again with --attach and killed with
+--terminate. To detach from a session, press <ctrl>+a q.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc exec c1 --detach -- make
+    Run "make" in a persistent exec session of instance c1 and print the session ID.
+
+lxc exec c1 --attach 6916c8a6-9b7d-4abd-90b3-aedfec7ec7da
+    Attach to an exec session of instance c1, replaying its recent output.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -59,6 +76,12 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
+	cmd.Flags().BoolVar(&c.flagSession, "session", false, i18n.G("Run the command in a persistent exec session and attach to it"))
+	cmd.Flags().BoolVar(&c.flagDetach, "detach", false, i18n.G("Run the command in a persistent exec session without attaching to it"))
+	cmd.Flags().StringVar(&c.flagAttach, "attach", "", i18n.G("Attach to a persistent exec session")+"``")
+	cmd.Flags().BoolVar(&c.flagListSessions, "list-sessions", false, i18n.G("List the persistent exec sessions of the instance"))
+	cmd.Flags().StringVar(&c.flagTerminate, "terminate", "", i18n.G("Kill the command of a persistent exec session and remove the session")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", cli.TableFormatTable, i18n.G(`Format (csv|json|table|yaml|compact), only used with --list-sessions`)+"``")
 
 	return cmd
 }
@@ -84,9 +107,28 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
-	if exit {
-		return err
+	sessionManagement := c.flagAttach != "" || c.flagListSessions || c.flagTerminate != ""
+	if sessionManagement {
+		exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+		if exit {
+			return err
+		}
+	} else {
+		exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+		if exit {
+			return err
+		}
+	}
+
+	sessionFlags := 0
+	for _, set := range []bool{c.flagSession, c.flagDetach, c.flagAttach != "", c.flagListSessions, c.flagTerminate != ""} {
+		if set {
+			sessionFlags++
+		}
+	}
+
+	if sessionFlags > 1 {
+		return fmt.Errorf(i18n.G("Only one of --session, --detach, --attach, --list-sessions and --terminate can be passed"))
 	}
 
 	if c.flagForceInteractive && c.flagForceNonInteractive {
@@ -108,6 +150,18 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagListSessions {
+		return c.listSessions(d, name)
+	}
+
+	if c.flagTerminate != "" {
+		return d.DeleteInstanceExecSession(name, c.flagTerminate)
+	}
+
+	if c.flagAttach != "" {
+		return c.attachSession(d, name, c.flagAttach)
+	}
+
 	// Set the environment
 	env := map[string]string{}
 	myTerm, ok := c.getTERM()
@@ -125,6 +179,29 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		env[pieces[0]] = value
 	}
 
+	if c.flagSession || c.flagDetach {
+		width, height, _ := termios.GetSize(getStdoutFd())
+		session, err := d.CreateInstanceExecSession(name, api.InstanceExecSessionsPost{
+			Command:     args[1:],
+			Environment: env,
+			Width:       width,
+			Height:      height,
+			User:        c.flagUser,
+			Group:       c.flagGroup,
+			Cwd:         c.flagCwd,
+		})
+		if err != nil {
+			return err
+		}
+
+		if c.flagDetach {
+			fmt.Println(session.ID)
+			return nil
+		}
+
+		return c.attachSession(d, name, session.ID)
+	}
+
 	// Configure the terminal
 	stdinFd := getStdinFd()
 	stdoutFd := getStdoutFd()
@@ -220,3 +297,126 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// listSessions lists the persistent exec sessions of the instance.
+func (c *cmdExec) listSessions(d lxd.InstanceServer, name string) error {
+	sessions, err := d.GetInstanceExecSessions(name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, session := range sessions {
+		status := session.Status
+		if session.Status == api.InstanceExecSessionStatusExited {
+			status = fmt.Sprintf("%s (%d)", session.Status, session.ExitCode)
+		}
+
+		data = append(data, []string{session.ID, strings.Join(session.Command, " "), status, strconv.FormatBool(session.Attached), session.CreatedAt.UTC().Format("2006/01/02 15:04 UTC")})
+	}
+
+	header := []string{
+		i18n.G("ID"),
+		i18n.G("COMMAND"),
+		i18n.G("STATUS"),
+		i18n.G("ATTACHED"),
+		i18n.G("CREATED AT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, sessions)
+}
+
+// attachSession attaches the terminal to the persistent exec session until the user detaches or the command exits.
+func (c *cmdExec) attachSession(d lxd.InstanceServer, name string, id string) error {
+	// Sessions always run with a terminal.
+	c.interactive = true
+
+	stdinFd := getStdinFd()
+	stdoutFd := getStdoutFd()
+
+	req := api.InstanceExecSessionAttachPost{}
+	if termios.IsTerminal(stdoutFd) {
+		width, height, err := termios.GetSize(stdoutFd)
+		if err != nil {
+			return err
+		}
+
+		req.Width = width
+		req.Height = height
+	}
+
+	if termios.IsTerminal(stdinFd) {
+		oldttystate, err := termios.MakeRaw(stdinFd)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = termios.Restore(stdinFd, oldttystate) }()
+
+		fmt.Printf(i18n.G("To detach from the session, press: <ctrl>+a q") + "\n\r")
+	}
+
+	execArgs := lxd.InstanceExecArgs{
+		Stdin:    &execDetachReader{r: os.Stdin},
+		Stdout:   getStdout(),
+		Control:  c.controlSocketHandler,
+		DataDone: make(chan bool),
+	}
+
+	op, err := d.AttachInstanceExecSession(name, id, req, &execArgs)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the operation to complete
+	err = op.Wait()
+	opAPI := op.Get()
+	if opAPI.Metadata != nil {
+		exitStatusRaw, ok := opAPI.Metadata["return"].(float64)
+		if ok {
+			c.global.ret = int(exitStatusRaw)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Wait for any remaining I/O to be flushed
+	<-execArgs.DataDone
+
+	return nil
+}
+
+// execDetachReader reads the terminal input, ending it once the <ctrl>+a q detach sequence is typed.
+type execDetachReader struct {
+	r           io.Reader
+	foundEscape bool
+	detached    bool
+}
+
+// The terminal has been switched to raw mode so that the input is read byte by byte.
+func (er *execDetachReader) Read(p []byte) (int, error) {
+	if er.detached {
+		return 0, io.EOF
+	}
+
+	n, err := er.r.Read(p)
+	if n != 1 {
+		er.foundEscape = false
+		return n, err
+	}
+
+	if p[0] == '\u0001' && !er.foundEscape {
+		er.foundEscape = true
+		return 0, err
+	}
+
+	if p[0] == 'q' && er.foundEscape {
+		er.detached = true
+		return 0, io.EOF
+	}
+
+	er.foundEscape = false
+	return n, err
+}
@@ -465,6 +465,14 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 
 	fmt.Printf(i18n.G("Status: %s")+"\n", strings.ToUpper(inst.Status))
 
+	if inst.State.Health != nil {
+		if inst.State.Health.Failures > 0 {
+			fmt.Printf(i18n.G("Health: %s (%d failed checks, last error: %s)")+"\n", strings.ToUpper(inst.State.Health.Status), inst.State.Health.Failures, inst.State.Health.LastError)
+		} else {
+			fmt.Printf(i18n.G("Health: %s")+"\n", strings.ToUpper(inst.State.Health.Status))
+		}
+	}
+
 	if inst.Type == "" {
 		inst.Type = "container"
 	}
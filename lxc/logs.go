@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdLogs struct {
+	global *cmdGlobal
+
+	flagFollow bool
+	flagSince  string
+	flagSource []string
+	flagFormat string
+}
+
+func (c *cmdLogs) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("logs", i18n.G("[<remote>:]<instance>"))
+	cmd.Short = i18n.G("Show instance logs")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show instance logs
+
+The logs are read from the console of containers (console), from the log
+files kept by LXD for the instance (lxd) and from the journal or syslog of
+the guest (guest). Reading the guest logs runs commands in the instance.
+
+The --since flag takes either a duration relative to now or an RFC 3339 time.
+The console output has no timestamps and is skipped when --since is set.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc logs c1 --follow
+    Show the logs of instance c1 and keep showing the new entries.
+
+lxc logs c1 --since 1h --source guest
+    Show the guest logs of instance c1 from the last hour.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVarP(&c.flagFollow, "follow", "f", false, i18n.G("Keep showing the new log entries"))
+	cmd.Flags().StringVar(&c.flagSince, "since", "", i18n.G("Only show the log entries from this time onwards")+"``")
+	cmd.Flags().StringArrayVar(&c.flagSource, "source", nil, i18n.G("Log source to show (console, lxd or guest)")+"``")
+	cmd.Flags().StringVar(&c.flagFormat, "format", "pretty", i18n.G("Format (json|pretty)")+"``")
+
+	return cmd
+}
+
+func (c *cmdLogs) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if !shared.ValueInSlice(c.flagFormat, []string{"json", "pretty"}) {
+		return fmt.Errorf(i18n.G("Invalid format: %s"), c.flagFormat)
+	}
+
+	for _, source := range c.flagSource {
+		if !shared.ValueInSlice(source, api.InstanceLogSources) {
+			return fmt.Errorf(i18n.G("Unknown log source %q"), source)
+		}
+	}
+
+	streamArgs := &lxd.InstanceLogStreamArgs{
+		Follow:  c.flagFollow,
+		Sources: c.flagSource,
+	}
+
+	if c.flagSince != "" {
+		duration, err := time.ParseDuration(c.flagSince)
+		if err == nil {
+			streamArgs.Since = time.Now().Add(-duration)
+		} else {
+			streamArgs.Since, err = time.Parse(time.RFC3339, c.flagSince)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Invalid time %q, expected a duration or an RFC 3339 time"), c.flagSince)
+			}
+		}
+	}
+
+	// Connect to LXD.
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	stream, err := d.GetInstanceLogStream(name, streamArgs)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if c.flagFormat == "json" {
+			fmt.Println(scanner.Text())
+			continue
+		}
+
+		entry := api.InstanceLogEntry{}
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return err
+		}
+
+		origin := entry.Source
+		if entry.Origin != "" {
+			origin = entry.Source + "/" + entry.Origin
+		}
+
+		fmt.Printf("%s [%s] %s\n", entry.Timestamp.Local().Format(time.RFC3339), origin, entry.Message)
+	}
+
+	return scanner.Err()
+}
@@ -114,6 +114,10 @@ For help with any of those, simply call them with --help.`))
 	aliasCmd := cmdAlias{global: &globalCmd}
 	app.AddCommand(aliasCmd.Command())
 
+	// apply sub-command
+	applyCmd := cmdApply{global: &globalCmd}
+	app.AddCommand(applyCmd.Command())
+
 	// cluster sub-command
 	clusterCmd := cmdCluster{global: &globalCmd}
 	app.AddCommand(clusterCmd.Command())
@@ -170,6 +174,10 @@ For help with any of those, simply call them with --help.`))
 	listCmd := cmdList{global: &globalCmd}
 	app.AddCommand(listCmd.Command())
 
+	// logs sub-command
+	logsCmd := cmdLogs{global: &globalCmd}
+	app.AddCommand(logsCmd.Command())
+
 	// manpage sub-command
 	manpageCmd := cmdManpage{global: &globalCmd}
 	app.AddCommand(manpageCmd.Command())
@@ -198,6 +206,14 @@ For help with any of those, simply call them with --help.`))
 	publishCmd := cmdPublish{global: &globalCmd}
 	app.AddCommand(publishCmd.Command())
 
+	// placement-group sub-command
+	placementGroupCmd := cmdPlacementGroup{global: &globalCmd}
+	app.AddCommand(placementGroupCmd.Command())
+
+	// port-forward sub-command
+	portForwardCmd := cmdPortForward{global: &globalCmd}
+	app.AddCommand(portForwardCmd.Command())
+
 	// profile sub-command
 	profileCmd := cmdProfile{global: &globalCmd}
 	app.AddCommand(profileCmd.Command())
@@ -230,6 +246,10 @@ For help with any of those, simply call them with --help.`))
 	restoreCmd := cmdRestore{global: &globalCmd}
 	app.AddCommand(restoreCmd.Command())
 
+	// secret sub-command
+	secretCmd := cmdSecret{global: &globalCmd}
+	app.AddCommand(secretCmd.Command())
+
 	// snapshot sub-command
 	snapshotCmd := cmdSnapshot{global: &globalCmd}
 	app.AddCommand(snapshotCmd.Command())
@@ -246,6 +266,10 @@ For help with any of those, simply call them with --help.`))
 	stopCmd := cmdStop{global: &globalCmd}
 	app.AddCommand(stopCmd.Command())
 
+	// top sub-command
+	topCmd := cmdTop{global: &globalCmd}
+	app.AddCommand(topCmd.Command())
+
 	// version sub-command
 	versionCmd := cmdVersion{global: &globalCmd}
 	app.AddCommand(versionCmd.Command())
@@ -0,0 +1,614 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdPlacementGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdPlacementGroup) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("placement-group")
+	cmd.Short = i18n.G("Manage placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage placement groups"))
+
+	// List.
+	placementGroupListCmd := cmdPlacementGroupList{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupListCmd.Command())
+
+	// Show.
+	placementGroupShowCmd := cmdPlacementGroupShow{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupShowCmd.Command())
+
+	// Get.
+	placementGroupGetCmd := cmdPlacementGroupGet{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupGetCmd.Command())
+
+	// Create.
+	placementGroupCreateCmd := cmdPlacementGroupCreate{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupCreateCmd.Command())
+
+	// Set.
+	placementGroupSetCmd := cmdPlacementGroupSet{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupSetCmd.Command())
+
+	// Unset.
+	placementGroupUnsetCmd := cmdPlacementGroupUnset{global: c.global, placementGroup: c, placementGroupSet: &placementGroupSetCmd}
+	cmd.AddCommand(placementGroupUnsetCmd.Command())
+
+	// Edit.
+	placementGroupEditCmd := cmdPlacementGroupEdit{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupEditCmd.Command())
+
+	// Delete.
+	placementGroupDeleteCmd := cmdPlacementGroupDelete{global: c.global, placementGroup: c}
+	cmd.AddCommand(placementGroupDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdPlacementGroupList struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagFormat string
+}
+
+func (c *cmdPlacementGroupList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available placement groups"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the placement groups.
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	groups, err := resource.server.GetPlacementGroups()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, group := range groups {
+		strUsedBy := fmt.Sprintf("%d", len(group.UsedBy))
+		details := []string{
+			group.Name,
+			group.Description,
+			strUsedBy,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("USED BY"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Show.
+type cmdPlacementGroupShow struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Show placement group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show placement group configurations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	// Show the placement group config.
+	group, _, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(group.UsedBy)
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdPlacementGroupGet struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Get values for placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for placement group configuration keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a placement group property"))
+	return cmd
+}
+
+func (c *cmdPlacementGroupGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	resp, _, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJsonTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the placement group %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range resp.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdPlacementGroupCreate struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group> [key=value...]"))
+	cmd.Short = i18n.G("Create new placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new placement groups"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var groupPut api.PlacementGroupPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the placement group.
+	group := api.PlacementGroupsPost{
+		Name:              resource.name,
+		PlacementGroupPut: groupPut,
+	}
+
+	if group.Config == nil {
+		group.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		group.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreatePlacementGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Placement group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdPlacementGroupSet struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<group> <key>=<value>..."))
+	cmd.Short = i18n.G("Set placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set placement group configuration keys"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a placement group property"))
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	// Get the placement group.
+	group, etag, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := group.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	return resource.server.UpdatePlacementGroup(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdPlacementGroupUnset struct {
+	global            *cmdGlobal
+	placementGroup    *cmdPlacementGroup
+	placementGroupSet *cmdPlacementGroupSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdPlacementGroupUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Unset placement group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset placement group configuration keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a placement group property"))
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.placementGroupSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.placementGroupSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdPlacementGroupEdit struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit placement group configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit placement group configurations as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the placement group.
+### Any line starting with a '# will be ignored.
+###
+### A placement group consists of a set of configuration items.
+###
+### An example would look like:
+### name: db-replicas
+### description: Database replicas
+### config:
+###  policy: spread
+###  rigor: strict
+###  scope: member
+`)
+}
+
+func (c *cmdPlacementGroupEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc placement-group show` command to be passed in here, but only take the contents
+		// of the PlacementGroupPut fields when updating the group. The other fields are silently discarded.
+		newdata := api.PlacementGroup{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdatePlacementGroup(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current config.
+	group, etag, err := resource.server.GetPlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.PlacementGroup{} // We show the full group info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdatePlacementGroup(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdPlacementGroupDelete struct {
+	global         *cmdGlobal
+	placementGroup *cmdPlacementGroup
+}
+
+func (c *cmdPlacementGroupDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete placement groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete placement groups"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdPlacementGroupDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing placement group name"))
+	}
+
+	// Delete the placement group.
+	err = resource.server.DeletePlacementGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Placement group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	lxd "github.com/canonical/lxd/client"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+type cmdPortForward struct {
+	global *cmdGlobal
+}
+
+func (c *cmdPortForward) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("port-forward", i18n.G("[<remote>:]<instance> [<listen address>:]<local port>:<instance port>|<port>..."))
+	cmd.Short = i18n.G("Forward local ports to an instance")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Forward local ports to an instance
+
+Connections to the local ports are tunnelled over the LXD API to the
+matching TCP ports on the loopback interface of the instance.
+The instance configuration isn't modified and the ports are only
+forwarded for as long as the command runs.
+
+Local ports listen on 127.0.0.1 unless a listen address is given.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc port-forward c1 8080:80
+    Forward local port 8080 to port 80 of instance c1.
+
+lxc port-forward c1 5432 0.0.0.0:2222:22
+    Forward local port 5432 to port 5432 and port 2222 on all addresses to port 22 of instance c1.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdPortForward) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	if !resource.server.HasExtension("instance_port_forward") {
+		return fmt.Errorf(i18n.G("The server doesn't support port forwarding"))
+	}
+
+	// Check instance exists.
+	_, _, err = resource.server.GetInstance(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set up the listeners before accepting any connection so that all of them are validated.
+	listeners := make([]net.Listener, 0, len(args)-1)
+	defer func() {
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+	}()
+
+	ports := make([]int, 0, len(args)-1)
+	for _, spec := range args[1:] {
+		listenAddr, port, err := parsePortForwardSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", listenAddr)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed to listen for connection: %w"), err)
+		}
+
+		listeners = append(listeners, listener)
+		ports = append(ports, port)
+	}
+
+	chErr := make(chan error, len(listeners))
+	for i, listener := range listeners {
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("Forwarding %s to port %d of %s")+"\n", listener.Addr(), ports[i], resource.name)
+		}
+
+		go func(listener net.Listener, port int) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					chErr <- fmt.Errorf(i18n.G("Failed to accept incoming connection: %w"), err)
+					return
+				}
+
+				go c.forward(resource.server, resource.name, conn, port)
+			}
+		}(listener, ports[i])
+	}
+
+	return <-chErr
+}
+
+// forward tunnels a local connection to the given port of the instance.
+func (c *cmdPortForward) forward(server lxd.InstanceServer, instName string, conn net.Conn, port int) {
+	defer func() { _ = conn.Close() }()
+
+	wsConn, err := server.GetInstancePortForward(instName, port)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.G("Failed forwarding connection from %s to port %d: %v")+"\n", conn.RemoteAddr(), port, err)
+		return
+	}
+
+	defer func() { _ = wsConn.Close() }()
+
+	chRead, chWrite := ws.Mirror(wsConn, conn)
+
+	// Once the instance is done sending, propagate the end of the stream to the local connection. If the
+	// tunnel went away, close the local connection so that the read mirror stops too.
+	err = <-chWrite
+	tcpConn, ok := conn.(*net.TCPConn)
+	if err == nil && ok {
+		_ = tcpConn.CloseWrite()
+	} else {
+		_ = conn.Close()
+	}
+
+	<-chRead
+}
+
+// parsePortForwardSpec parses a port forward in the form "[<listen address>:]<local port>:<instance port>" or
+// "<port>" and returns the local address to listen on and the port of the instance.
+func parsePortForwardSpec(spec string) (string, int, error) {
+	parsePort := func(value string) (int, error) {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 0 || port > 65535 {
+			return -1, fmt.Errorf(i18n.G("Invalid port %q in port forward %q"), value, spec)
+		}
+
+		return port, nil
+	}
+
+	listenHost := "127.0.0.1"
+	localPort := spec
+	instancePort := spec
+
+	sepIndex := strings.LastIndex(spec, ":")
+	if sepIndex >= 0 {
+		localPort = spec[:sepIndex]
+		instancePort = spec[sepIndex+1:]
+
+		sepIndex = strings.LastIndex(localPort, ":")
+		if sepIndex >= 0 {
+			listenHost = strings.TrimSuffix(strings.TrimPrefix(localPort[:sepIndex], "["), "]")
+			localPort = localPort[sepIndex+1:]
+		}
+	}
+
+	port, err := parsePort(instancePort)
+	if err != nil {
+		return "", -1, err
+	}
+
+	if port == 0 {
+		return "", -1, fmt.Errorf(i18n.G("Invalid port %q in port forward %q"), instancePort, spec)
+	}
+
+	_, err = parsePort(localPort)
+	if err != nil {
+		return "", -1, err
+	}
+
+	return net.JoinHostPort(listenHost, localPort), port, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParsePortForwardSpec(t *testing.T) {
+	tests := []struct {
+		spec       string
+		listenAddr string
+		port       int
+		wantErr    bool
+	}{
+		{spec: "80", listenAddr: "127.0.0.1:80", port: 80},
+		{spec: "8080:80", listenAddr: "127.0.0.1:8080", port: 80},
+		{spec: ":80", wantErr: true},
+		{spec: "0:80", listenAddr: "127.0.0.1:0", port: 80},
+		{spec: "0.0.0.0:2222:22", listenAddr: "0.0.0.0:2222", port: 22},
+		{spec: "[::1]:8080:80", listenAddr: "[::1]:8080", port: 80},
+		{spec: "8080:0", wantErr: true},
+		{spec: "8080:http", wantErr: true},
+		{spec: "70000:80", wantErr: true},
+	}
+
+	for _, test := range tests {
+		listenAddr, port, err := parsePortForwardSpec(test.spec)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Expected an error for %q, got %q and %d", test.spec, listenAddr, port)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.spec, err)
+			continue
+		}
+
+		if listenAddr != test.listenAddr || port != test.port {
+			t.Errorf("Unexpected result for %q: got %q and %d, expected %q and %d", test.spec, listenAddr, port, test.listenAddr, test.port)
+		}
+	}
+}
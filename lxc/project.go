@@ -69,6 +69,14 @@ func (c *cmdProject) Command() *cobra.Command {
 	projectGetInfo := cmdProjectInfo{global: c.global, project: c}
 	cmd.AddCommand(projectGetInfo.Command())
 
+	// History
+	projectHistoryCmd := cmdConfigHistory{global: c.global, entity: "project"}
+	cmd.AddCommand(projectHistoryCmd.Command())
+
+	// Rollback
+	projectRollbackCmd := cmdConfigRollback{global: c.global, entity: "project"}
+	cmd.AddCommand(projectRollbackCmd.Command())
+
 	// Set default
 	projectSwitchCmd := cmdProjectSwitch{global: c.global, project: c}
 	cmd.AddCommand(projectSwitchCmd.Command())
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdSecret struct {
+	global *cmdGlobal
+}
+
+func (c *cmdSecret) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("secret")
+	cmd.Short = i18n.G("Manage secrets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage secrets
+
+Secrets are encrypted by the server and their values can't be retrieved through the API.
+They can be exposed to instances as files through disk devices with "source=secrets:<secret>[,<secret>...]"
+or read from within an instance through /dev/lxd when listed in "security.devlxd.secrets".`))
+
+	// List.
+	secretListCmd := cmdSecretList{global: c.global, secret: c}
+	cmd.AddCommand(secretListCmd.Command())
+
+	// Show.
+	secretShowCmd := cmdSecretShow{global: c.global, secret: c}
+	cmd.AddCommand(secretShowCmd.Command())
+
+	// Create.
+	secretCreateCmd := cmdSecretCreate{global: c.global, secret: c}
+	cmd.AddCommand(secretCreateCmd.Command())
+
+	// Set.
+	secretSetCmd := cmdSecretSet{global: c.global, secret: c}
+	cmd.AddCommand(secretSetCmd.Command())
+
+	// Edit.
+	secretEditCmd := cmdSecretEdit{global: c.global, secret: c}
+	cmd.AddCommand(secretEditCmd.Command())
+
+	// Delete.
+	secretDeleteCmd := cmdSecretDelete{global: c.global, secret: c}
+	cmd.AddCommand(secretDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// secretValue reads the value of a secret from stdin, or prompts for it if stdin is a terminal.
+func secretValue() (string, error) {
+	if termios.IsTerminal(getStdinFd()) {
+		return cli.AskPassword(i18n.G("Secret value: ")), nil
+	}
+
+	contents, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+
+	if len(contents) == 0 {
+		return "", fmt.Errorf(i18n.G("Empty secret value"))
+	}
+
+	return string(contents), nil
+}
+
+// List.
+type cmdSecretList struct {
+	global *cmdGlobal
+	secret *cmdSecret
+
+	flagFormat string
+}
+
+func (c *cmdSecretList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available secrets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available secrets"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdSecretList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the secrets.
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	secrets, err := resource.server.GetSecrets()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, secret := range secrets {
+		details := []string{
+			secret.Name,
+			secret.Description,
+			secret.LastUpdated.Local().Format("2006/01/02 15:04 MST"),
+			fmt.Sprintf("%d", len(secret.UsedBy)),
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("LAST UPDATED"),
+		i18n.G("USED BY"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, secrets)
+}
+
+// Show.
+type cmdSecretShow struct {
+	global *cmdGlobal
+	secret *cmdSecret
+}
+
+func (c *cmdSecretShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<secret>"))
+	cmd.Short = i18n.G("Show secret details")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show secret details
+
+The value of the secret isn't shown as it can't be retrieved.`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdSecretShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing secret name"))
+	}
+
+	// Show the secret.
+	secret, _, err := resource.server.GetSecret(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(secret.UsedBy)
+
+	data, err := yaml.Marshal(&secret)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdSecretCreate struct {
+	global *cmdGlobal
+	secret *cmdSecret
+
+	flagDescription string
+}
+
+func (c *cmdSecretCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<secret>"))
+	cmd.Short = i18n.G("Create new secrets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create new secrets
+
+The value of the secret is read from stdin, or prompted for if stdin is a terminal.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc secret create db-password --description "Database password"
+    Create the db-password secret, prompting for its value.
+
+lxc secret create tls-key < server.key
+    Create the tls-key secret with the content of server.key.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Secret description")+"``")
+
+	return cmd
+}
+
+func (c *cmdSecretCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing secret name"))
+	}
+
+	value, err := secretValue()
+	if err != nil {
+		return err
+	}
+
+	// Create the secret.
+	secret := api.SecretsPost{
+		Name: resource.name,
+		SecretPut: api.SecretPut{
+			Description: c.flagDescription,
+			Value:       value,
+		},
+	}
+
+	err = resource.server.CreateSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Secret %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdSecretSet struct {
+	global *cmdGlobal
+	secret *cmdSecret
+}
+
+func (c *cmdSecretSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<secret>"))
+	cmd.Short = i18n.G("Set the value of secrets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set the value of secrets
+
+The new value of the secret is read from stdin, or prompted for if stdin is a terminal.
+Disk devices exposing the secret get the new value when the instance is restarted.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdSecretSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing secret name"))
+	}
+
+	// Get the secret.
+	secret, etag, err := resource.server.GetSecret(resource.name)
+	if err != nil {
+		return err
+	}
+
+	value, err := secretValue()
+	if err != nil {
+		return err
+	}
+
+	writable := secret.Writable()
+	writable.Value = value
+
+	return resource.server.UpdateSecret(resource.name, writable, etag)
+}
+
+// Edit.
+type cmdSecretEdit struct {
+	global *cmdGlobal
+	secret *cmdSecret
+}
+
+func (c *cmdSecretEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<secret>"))
+	cmd.Short = i18n.G("Edit secret details as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit secret details as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdSecretEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the secret.
+### Any line starting with a '# will be ignored.
+###
+### Only the description can be changed, use "lxc secret set" to change the value.
+###
+### An example would look like:
+### name: db-password
+### description: Database password
+`)
+}
+
+func (c *cmdSecretEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing secret name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc secret show` command to be passed in here, but only take the contents
+		// of the SecretPut fields when updating the secret. The other fields are silently discarded.
+		newdata := api.Secret{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateSecret(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current details.
+	secret, etag, err := resource.server.GetSecret(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&secret)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.Secret{} // We show the full secret info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateSecret(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdSecretDelete struct {
+	global *cmdGlobal
+	secret *cmdSecret
+}
+
+func (c *cmdSecretDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<secret>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete secrets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete secrets"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdSecretDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing secret name"))
+	}
+
+	// Delete the secret.
+	err = resource.server.DeleteSecret(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Secret %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
+)
+
+type cmdTop struct {
+	global *cmdGlobal
+
+	flagAllProjects bool
+	flagFormat      string
+	flagOnce        bool
+	flagRefresh     int
+	flagSort        string
+}
+
+// topInstance is the resource usage of an instance shown by lxc top.
+type topInstance struct {
+	Name        string  `json:"name" yaml:"name"`
+	Project     string  `json:"project" yaml:"project"`
+	Location    string  `json:"location" yaml:"location"`
+	Type        string  `json:"type" yaml:"type"`
+	CPUPercent  float64 `json:"cpu_percent" yaml:"cpu_percent"`
+	MemoryUsage int64   `json:"memory_usage" yaml:"memory_usage"`
+	Processes   int64   `json:"processes" yaml:"processes"`
+}
+
+// topSample is a sample of the CPU usage of an instance or a process, in nanoseconds of CPU time.
+type topSample struct {
+	time     time.Time
+	cpuUsage map[string]int64
+}
+
+// cpuPercent returns the CPU usage in percent of one CPU since the previous sample, or -1 if there is none.
+func (s *topSample) cpuPercent(previous *topSample, key string) float64 {
+	if previous == nil {
+		return -1
+	}
+
+	previousUsage, ok := previous.cpuUsage[key]
+	elapsed := s.time.Sub(previous.time)
+	if !ok || elapsed <= 0 || s.cpuUsage[key] < previousUsage {
+		return -1
+	}
+
+	return 100 * float64(s.cpuUsage[key]-previousUsage) / float64(elapsed)
+}
+
+func (c *cmdTop) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("top", i18n.G("[<remote>:][<instance>]"))
+	cmd.Short = i18n.G("Show the resource usage of instances and their processes")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the resource usage of instances and their processes
+
+Without an instance name, the CPU usage, memory usage and number of processes
+of the running instances of the remote are shown, including those running on
+the other members of a cluster.
+
+With an instance name, the processes running in the instance are shown with
+their user, CPU usage, resident memory and command line.
+
+The CPU usage is the percentage of one CPU used since the previous refresh.
+The display is refreshed until interrupted, unless --once is set in which case
+the usage is measured over one refresh interval and shown once.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc top
+    Show the resource usage of the running instances.
+
+lxc top c1 --sort memory
+    Show the processes of instance c1, ordered by resident memory.
+
+lxc top --once --format csv
+    Show the resource usage of the running instances once, in CSV format.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("Show instances from all projects"))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "compact", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().BoolVar(&c.flagOnce, "once", false, i18n.G("Show the resource usage once instead of refreshing it"))
+	cmd.Flags().IntVar(&c.flagRefresh, "refresh", 2, i18n.G("Refresh interval in seconds")+"``")
+	cmd.Flags().StringVar(&c.flagSort, "sort", "cpu", i18n.G("Sort order (cpu|memory|name)")+"``")
+
+	return cmd
+}
+
+func (c *cmdTop) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.global.flagProject != "" && c.flagAllProjects {
+		return fmt.Errorf(i18n.G("Can't specify --project with --all-projects"))
+	}
+
+	if c.flagRefresh < 1 {
+		return fmt.Errorf(i18n.G("Invalid refresh interval %d, it must be at least one second"), c.flagRefresh)
+	}
+
+	if !shared.ValueInSlice(c.flagSort, []string{"cpu", "memory", "name"}) {
+		return fmt.Errorf(i18n.G("Invalid sort order %q"), c.flagSort)
+	}
+
+	// Parse the remote.
+	remote := conf.DefaultRemote
+	name := ""
+	if len(args) > 0 {
+		remote, name, err = conf.ParseRemote(args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	if name != "" && c.flagAllProjects {
+		return fmt.Errorf(i18n.G("Can't specify an instance with --all-projects"))
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	render := c.renderInstances
+	if name != "" {
+		render = func(d lxd.InstanceServer, previous *topSample) (*topSample, error) {
+			return c.renderProcesses(d, name, previous)
+		}
+	}
+
+	// The screen is only cleared between refreshes when showing the usage interactively.
+	interactive := !c.flagOnce && termios.IsTerminal(getStdoutFd())
+
+	var previous *topSample
+	for {
+		if interactive {
+			fmt.Print("\033[H\033[2J")
+		}
+
+		sample, err := render(d, previous)
+		if err != nil {
+			return err
+		}
+
+		// With --once, the first sample only serves as the reference for the CPU usage.
+		if c.flagOnce && previous != nil {
+			return nil
+		}
+
+		previous = sample
+		time.Sleep(time.Duration(c.flagRefresh) * time.Second)
+	}
+}
+
+// renderInstances shows the resource usage of the running instances and returns the CPU usage sample. Nothing
+// is shown for the first sample with --once.
+func (c *cmdTop) renderInstances(d lxd.InstanceServer, previous *topSample) (*topSample, error) {
+	var instances []api.InstanceFull
+	var err error
+	if c.flagAllProjects {
+		instances, err = d.GetInstancesFullAllProjects(api.InstanceTypeAny)
+	} else {
+		instances, err = d.GetInstancesFull(api.InstanceTypeAny)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &topSample{time: time.Now(), cpuUsage: map[string]int64{}}
+	rows := []topInstance{}
+	for _, inst := range instances {
+		if inst.State == nil || inst.StatusCode != api.Running {
+			continue
+		}
+
+		key := inst.Project + "/" + inst.Name
+		sample.cpuUsage[key] = inst.State.CPU.Usage
+		rows = append(rows, topInstance{
+			Name:        inst.Name,
+			Project:     inst.Project,
+			Location:    inst.Location,
+			Type:        inst.Type,
+			CPUPercent:  sample.cpuPercent(previous, key),
+			MemoryUsage: inst.State.Memory.Usage,
+			Processes:   inst.State.Processes,
+		})
+	}
+
+	if c.flagOnce && previous == nil {
+		return sample, nil
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch c.flagSort {
+		case "cpu":
+			return rows[i].CPUPercent > rows[j].CPUPercent
+		case "memory":
+			return rows[i].MemoryUsage > rows[j].MemoryUsage
+		}
+
+		if rows[i].Project != rows[j].Project {
+			return rows[i].Project < rows[j].Project
+		}
+
+		return rows[i].Name < rows[j].Name
+	})
+
+	header := []string{i18n.G("NAME")}
+	if c.flagAllProjects {
+		header = append(header, i18n.G("PROJECT"))
+	}
+
+	header = append(header, i18n.G("TYPE"))
+	if d.IsClustered() {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	header = append(header, i18n.G("CPU%"), i18n.G("MEMORY"), i18n.G("PROCESSES"))
+
+	data := [][]string{}
+	for _, row := range rows {
+		line := []string{row.Name}
+		if c.flagAllProjects {
+			line = append(line, row.Project)
+		}
+
+		line = append(line, row.Type)
+		if d.IsClustered() {
+			line = append(line, row.Location)
+		}
+
+		line = append(line, topPercent(row.CPUPercent), units.GetByteSizeStringIEC(row.MemoryUsage, 2), strconv.FormatInt(row.Processes, 10))
+		data = append(data, line)
+	}
+
+	return sample, cli.RenderTable(c.flagFormat, header, data, rows)
+}
+
+// renderProcesses shows the resource usage of the processes of an instance and returns the CPU usage sample.
+// Nothing is shown for the first sample with --once.
+func (c *cmdTop) renderProcesses(d lxd.InstanceServer, name string, previous *topSample) (*topSample, error) {
+	processes, err := d.GetInstanceProcesses(name)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &topSample{time: time.Now(), cpuUsage: map[string]int64{}}
+	for i, process := range processes {
+		key := strconv.FormatInt(process.PID, 10)
+		sample.cpuUsage[key] = process.CPUUsage
+
+		// Keep the average usage since the start of the process until there is a previous sample.
+		cpuPercent := sample.cpuPercent(previous, key)
+		if cpuPercent >= 0 {
+			processes[i].CPUPercent = cpuPercent
+		}
+	}
+
+	if c.flagOnce && previous == nil {
+		return sample, nil
+	}
+
+	sort.SliceStable(processes, func(i, j int) bool {
+		switch c.flagSort {
+		case "cpu":
+			return processes[i].CPUPercent > processes[j].CPUPercent
+		case "memory":
+			return processes[i].MemoryRSS > processes[j].MemoryRSS
+		}
+
+		return processes[i].Command < processes[j].Command
+	})
+
+	header := []string{i18n.G("PID"), i18n.G("USER"), i18n.G("CPU%"), i18n.G("MEMORY"), i18n.G("COMMAND")}
+	data := [][]string{}
+	for _, process := range processes {
+		data = append(data, []string{
+			strconv.FormatInt(process.PID, 10),
+			process.User,
+			topPercent(process.CPUPercent),
+			units.GetByteSizeStringIEC(process.MemoryRSS, 2),
+			process.Command,
+		})
+	}
+
+	return sample, cli.RenderTable(c.flagFormat, header, data, processes)
+}
+
+// topPercent formats a CPU usage, which is unknown if negative.
+func topPercent(value float64) string {
+	if value < 0 {
+		return "-"
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
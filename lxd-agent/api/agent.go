@@ -18,3 +18,14 @@ type API10Put struct {
 	// Example: true
 	Devlxd bool `json:"devlxd" yaml:"devlxd"`
 }
+
+// FilesystemFreezePost is used to freeze or thaw the file systems of the guest.
+type FilesystemFreezePost struct {
+	// Action to take (freeze or thaw)
+	// Example: freeze
+	Action string `json:"action" yaml:"action"`
+
+	// Number of seconds after which frozen file systems are thawed
+	// Example: 60
+	Timeout int `json:"timeout" yaml:"timeout"`
+}
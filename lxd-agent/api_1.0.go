@@ -29,11 +29,14 @@ var api10 = []APIEndpoint{
 	api10Cmd,
 	execCmd,
 	eventsCmd,
+	fsfreezeCmd,
 	metricsCmd,
 	operationsCmd,
 	operationCmd,
 	operationWebsocket,
 	operationWait,
+	portForwardCmd,
+	processesCmd,
 	sftpCmd,
 	stateCmd,
 }
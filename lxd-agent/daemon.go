@@ -1,7 +1,9 @@
 package main
 
 import (
+	"os"
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/lxd/events"
 )
@@ -22,6 +24,12 @@ type Daemon struct {
 	devlxdRunning bool
 	devlxdMu      sync.Mutex
 	devlxdEnabled bool
+
+	// Frozen file systems, the timer that thaws them and whether it expired since the last freeze.
+	fsfreezeMu      sync.Mutex
+	fsfreezeFiles   []*os.File
+	fsfreezeTimer   *time.Timer
+	fsfreezeExpired bool
 }
 
 // newDaemon returns a new Daemon object with the given configuration.
@@ -56,7 +56,7 @@ func getVsockClient(d *Daemon) (lxd.InstanceServer, error) {
 	return server, nil
 }
 
-var devlxdConfigGet = devLxdHandler{"/1.0/config", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+var devlxdConfigHandler = devLxdHandler{"/1.0/config", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 	client, err := getVsockClient(d)
 	if err != nil {
 		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
@@ -64,6 +64,15 @@ var devlxdConfigGet = devLxdHandler{"/1.0/config", func(d *Daemon, w http.Respon
 
 	defer client.Disconnect()
 
+	if r.Method == "PATCH" {
+		_, _, err := client.RawQuery(r.Method, "/1.0/config", r.Body, "")
+		if err != nil {
+			return smartResponse(err)
+		}
+
+		return okResponse("", "raw")
+	}
+
 	resp, _, err := client.RawQuery("GET", "/1.0/config", nil, "")
 	if err != nil {
 		return smartResponse(err)
@@ -217,16 +226,184 @@ var devlxdDevicesGet = devLxdHandler{"/1.0/devices", func(d *Daemon, w http.Resp
 	return okResponse(devices, "json")
 }}
 
+var devlxdSecretsGet = devLxdHandler{"/1.0/secrets", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery("GET", "/1.0/secrets", nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var secrets []string
+
+	err = resp.MetadataAsStruct(&secrets)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+	}
+
+	return okResponse(secrets, "json")
+}}
+
+var devlxdSecretGet = devLxdHandler{"/1.0/secrets/{name}", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return &devLxdResponse{"bad request", http.StatusBadRequest, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery("GET", fmt.Sprintf("/1.0/secrets/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var value string
+
+	err = resp.MetadataAsStruct(&value)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+	}
+
+	return okResponse(value, "raw")
+}}
+
+var devlxdSnapshotsPost = devLxdHandler{"/1.0/snapshots", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	if r.Method != "POST" {
+		return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusMethodNotAllowed, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery(r.Method, "/1.0/snapshots", r.Body, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var name string
+
+	err = resp.MetadataAsStruct(&name)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+	}
+
+	return okResponse(name, "raw")
+}}
+
+var devlxdRestartPost = devLxdHandler{"/1.0/restart", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	if r.Method != "POST" {
+		return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusMethodNotAllowed, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	_, _, err = client.RawQuery(r.Method, "/1.0/restart", nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	return okResponse("", "raw")
+}}
+
+var devlxdDNSRecordsHandler = devLxdHandler{"/1.0/dns-records", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	if r.Method == "GET" {
+		resp, _, err := client.RawQuery(r.Method, "/1.0/dns-records", nil, "")
+		if err != nil {
+			return smartResponse(err)
+		}
+
+		var records []api.DevLXDDNSRecord
+
+		err = resp.MetadataAsStruct(&records)
+		if err != nil {
+			return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+		}
+
+		return okResponse(records, "json")
+	} else if r.Method == "POST" {
+		_, _, err := client.RawQuery(r.Method, "/1.0/dns-records", r.Body, "")
+		if err != nil {
+			return smartResponse(err)
+		}
+
+		return okResponse("", "raw")
+	}
+
+	return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusMethodNotAllowed, "raw"}
+}}
+
+var devlxdDNSRecordDelete = devLxdHandler{"/1.0/dns-records/{zone}/{name}", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	if r.Method != "DELETE" {
+		return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusMethodNotAllowed, "raw"}
+	}
+
+	zoneName, err := url.PathUnescape(mux.Vars(r)["zone"])
+	if err != nil {
+		return &devLxdResponse{"bad request", http.StatusBadRequest, "raw"}
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return &devLxdResponse{"bad request", http.StatusBadRequest, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	_, _, err = client.RawQuery(r.Method, fmt.Sprintf("/1.0/dns-records/%s/%s", url.PathEscape(zoneName), url.PathEscape(name)), nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	return okResponse("", "raw")
+}}
+
 var handlers = []devLxdHandler{
 	{"/", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 		return okResponse([]string{"/1.0"}, "json")
 	}},
 	devlxdAPIGet,
-	devlxdConfigGet,
+	devlxdConfigHandler,
 	devlxdConfigKeyGet,
 	devlxdMetadataGet,
 	devLxdEventsGet,
 	devlxdDevicesGet,
+	devlxdSecretsGet,
+	devlxdSecretGet,
+	devlxdSnapshotsPost,
+	devlxdRestartPost,
+	devlxdDNSRecordsHandler,
+	devlxdDNSRecordDelete,
 }
 
 func hoistReq(f func(*Daemon, http.ResponseWriter, *http.Request) *devLxdResponse, d *Daemon) func(http.ResponseWriter, *http.Request) {
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	agentAPI "github.com/canonical/lxd/lxd-agent/api"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// Ioctls used to freeze and thaw a file system (_IOWR('X', 119, int) and _IOWR('X', 120, int)).
+const (
+	ioctlFIFREEZE = 0xC0045877
+	ioctlFITHAW   = 0xC0045878
+)
+
+// fsfreezeHooksPath is the directory containing the executables run before freezing and after thawing the file
+// systems, with "freeze" or "thaw" as their argument.
+const fsfreezeHooksPath = "/etc/lxd-agent/fsfreeze.d"
+
+var fsfreezeCmd = APIEndpoint{
+	Name: "fsfreeze",
+	Path: "fsfreeze",
+
+	Post: APIEndpointAction{Handler: fsfreezePost},
+}
+
+func fsfreezePost(d *Daemon, r *http.Request) response.Response {
+	req := agentAPI.FilesystemFreezePost{}
+
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = json.Unmarshal(buf, &req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	switch req.Action {
+	case "freeze":
+		if req.Timeout <= 0 {
+			return response.BadRequest(fmt.Errorf("A timeout is required to freeze the file systems"))
+		}
+
+		err = d.filesystemsFreeze(time.Duration(req.Timeout) * time.Second)
+	case "thaw":
+		err = d.filesystemsThaw()
+	default:
+		return response.BadRequest(fmt.Errorf("Invalid action %q", req.Action))
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// filesystemsFreeze runs the freeze hooks and freezes the file systems of the guest.
+// The file systems are thawed automatically once the timeout expires.
+func (d *Daemon) filesystemsFreeze(timeout time.Duration) error {
+	d.fsfreezeMu.Lock()
+	defer d.fsfreezeMu.Unlock()
+
+	if d.fsfreezeTimer != nil {
+		return api.StatusErrorf(http.StatusConflict, "The file systems are already frozen")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = runFilesystemFreezeHooks("thaw") })
+
+	err := runFilesystemFreezeHooks("freeze")
+	if err != nil {
+		return err
+	}
+
+	mountInfo, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+
+	mountPaths, err := freezableFilesystems(mountInfo)
+	_ = mountInfo.Close()
+	if err != nil {
+		return err
+	}
+
+	// Log before freezing as the log may be written to one of the file systems.
+	logger.Info("Freezing file systems", logger.Ctx{"timeout": timeout})
+
+	var frozen []*os.File
+	revert.Add(func() { _ = thawFilesystems(frozen) })
+
+	// Freeze nested mounts before their parents.
+	for i := len(mountPaths) - 1; i >= 0; i-- {
+		f, err := os.Open(mountPaths[i])
+		if err != nil {
+			return fmt.Errorf("Failed opening %q: %w", mountPaths[i], err)
+		}
+
+		err = unix.IoctlSetInt(int(f.Fd()), ioctlFIFREEZE, 0)
+		if err != nil {
+			_ = f.Close()
+
+			// Skip file systems that can't be frozen.
+			if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOTTY) {
+				continue
+			}
+
+			return fmt.Errorf("Failed freezing %q: %w", mountPaths[i], err)
+		}
+
+		frozen = append(frozen, f)
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		d.fsfreezeMu.Lock()
+		defer d.fsfreezeMu.Unlock()
+
+		// Check the file systems weren't thawed and frozen again in the meantime.
+		if d.fsfreezeTimer != timer {
+			return
+		}
+
+		err := d.filesystemsThawLocked()
+		if err != nil {
+			logger.Error("Failed thawing file systems", logger.Ctx{"err": err})
+		}
+
+		// Report the expiry to the next thaw request as the file systems weren't frozen for the whole operation.
+		d.fsfreezeExpired = true
+
+		logger.Warn("Thawed file systems after timeout", logger.Ctx{"timeout": timeout})
+	})
+
+	d.fsfreezeFiles = frozen
+	d.fsfreezeTimer = timer
+	d.fsfreezeExpired = false
+
+	revert.Success()
+	return nil
+}
+
+// filesystemsThaw thaws the file systems of the guest and runs the thaw hooks.
+// It fails if the file systems were already thawed because the freeze timeout expired.
+func (d *Daemon) filesystemsThaw() error {
+	d.fsfreezeMu.Lock()
+	defer d.fsfreezeMu.Unlock()
+
+	if d.fsfreezeExpired {
+		d.fsfreezeExpired = false
+		return api.StatusErrorf(http.StatusRequestTimeout, "The file systems were thawed before the end of the operation as the freeze timeout expired")
+	}
+
+	return d.filesystemsThawLocked()
+}
+
+// filesystemsThawLocked thaws the file systems of the guest, the caller must hold fsfreezeMu.
+func (d *Daemon) filesystemsThawLocked() error {
+	if d.fsfreezeTimer == nil {
+		return nil
+	}
+
+	d.fsfreezeTimer.Stop()
+	d.fsfreezeTimer = nil
+
+	err := thawFilesystems(d.fsfreezeFiles)
+	d.fsfreezeFiles = nil
+
+	hookErr := runFilesystemFreezeHooks("thaw")
+	if err != nil {
+		return err
+	}
+
+	if hookErr != nil {
+		return hookErr
+	}
+
+	logger.Info("Thawed file systems")
+
+	return nil
+}
+
+// thawFilesystems thaws and closes the given frozen mount points. All of them are thawed even if some fail.
+func thawFilesystems(frozen []*os.File) error {
+	var thawErr error
+	for _, f := range frozen {
+		err := unix.IoctlSetInt(int(f.Fd()), ioctlFITHAW, 0)
+		if err != nil && thawErr == nil {
+			thawErr = fmt.Errorf("Failed thawing %q: %w", f.Name(), err)
+		}
+
+		_ = f.Close()
+	}
+
+	return thawErr
+}
+
+// freezableFilesystems returns the mount points of the writable block device backed file systems listed in the
+// given mountinfo, in mount order. Each file system is only returned once.
+func freezableFilesystems(mountInfo io.Reader) ([]string, error) {
+	devices := map[string]bool{}
+	mountPaths := []string{}
+
+	scanner := bufio.NewScanner(mountInfo)
+	for scanner.Scan() {
+		// Format: ID parentID major:minor root mountpoint options [optional fields...] - fstype source superoptions
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		var source string
+		for i := 6; i+2 < len(fields); i++ {
+			if fields[i] == "-" {
+				source = fields[i+2]
+				break
+			}
+		}
+
+		if !strings.HasPrefix(source, "/dev/") {
+			continue
+		}
+
+		if shared.ValueInSlice("ro", strings.Split(fields[5], ",")) {
+			continue
+		}
+
+		if devices[fields[2]] {
+			continue
+		}
+
+		devices[fields[2]] = true
+		mountPaths = append(mountPaths, unescapeMountPath(fields[4]))
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return mountPaths, nil
+}
+
+// unescapeMountPath replaces the octal escapes used by the kernel in mount paths.
+func unescapeMountPath(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
+
+// runFilesystemFreezeHooks runs the executables in the hooks directory with the action as their argument.
+// Freeze hooks are run in lexical order and stop at the first failure, thaw hooks are run in reverse order
+// and all of them are run even if some fail.
+func runFilesystemFreezeHooks(action string) error {
+	entries, err := os.ReadDir(fsfreezeHooksPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if action == "thaw" {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	var hookErr error
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		hookPath := filepath.Join(fsfreezeHooksPath, entry.Name())
+		_, err = shared.RunCommand(hookPath, action)
+		if err != nil {
+			err = fmt.Errorf("Failed running %q hook %q: %w", action, hookPath, err)
+			if action == "freeze" {
+				return err
+			}
+
+			logger.Error("Failed running file system thaw hook", logger.Ctx{"hook": hookPath, "err": err})
+			if hookErr == nil {
+				hookErr = err
+			}
+		}
+	}
+
+	return hookErr
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezableFilesystems(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountInfo string
+		want      []string
+	}{
+		{
+			name: "block devices in mount order",
+			mountInfo: `22 1 252:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:22 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw
+25 22 252:15 / /boot/efi rw,relatime shared:2 - vfat /dev/vda15 rw,fmask=0077
+26 22 0:23 / /run rw,nosuid,nodev,noexec,relatime shared:5 - tmpfs tmpfs rw,size=400000k
+`,
+			want: []string{"/", "/boot/efi"},
+		},
+		{
+			name: "read-only mounts are skipped",
+			mountInfo: `22 1 252:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+27 22 7:0 / /snap/core/1 ro,nodev,relatime shared:3 - squashfs /dev/loop0 ro
+`,
+			want: []string{"/"},
+		},
+		{
+			name: "bind mounts of the same device are only returned once",
+			mountInfo: `22 1 252:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+28 22 252:1 /srv /mnt/srv rw,relatime shared:1 - ext4 /dev/vda1 rw
+29 22 252:16 / /data rw,relatime shared:4 - xfs /dev/vdb rw
+`,
+			want: []string{"/", "/data"},
+		},
+		{
+			name: "optional fields and escaped mount paths",
+			mountInfo: `22 1 252:1 / / rw,relatime shared:1 master:2 - ext4 /dev/vda1 rw
+30 22 252:17 / /mnt/my\040data rw,relatime - ext4 /dev/vdc rw
+`,
+			want: []string{"/", "/mnt/my data"},
+		},
+		{
+			name: "malformed lines are ignored",
+			mountInfo: `22 1 252:1 / / rw,relatime
+23 22 252:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+`,
+			want: []string{"/"},
+		},
+		{
+			name:      "no block devices",
+			mountInfo: "23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw\n",
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mountPaths, err := freezableFilesystems(strings.NewReader(tt.mountInfo))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mountPaths)
+		})
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "/"},
+		{path: `/mnt/my\040data`, want: "/mnt/my data"},
+		{path: `/mnt/tab\011here`, want: "/mnt/tab\there"},
+		{path: `/mnt/new\012line`, want: "/mnt/new\nline"},
+		{path: `/mnt/back\134slash`, want: `/mnt/back\slash`},
+		{path: `/mnt/a\040b\040c`, want: "/mnt/a b c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, unescapeMountPath(tt.path))
+		})
+	}
+}
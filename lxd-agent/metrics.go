@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/pressure"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
@@ -61,6 +62,13 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.Network = netStats
 	}
 
+	pressureStats, err := getPressureMetrics(d)
+	if err != nil {
+		logger.Warn("Failed to get pressure metrics", logger.Ctx{"err": err})
+	} else {
+		out.Pressure = pressureStats
+	}
+
 	out.ProcessesTotal, err = getTotalProcesses(d)
 	if err != nil {
 		logger.Warn("Failed to get total processes", logger.Ctx{"err": err})
@@ -203,6 +211,36 @@ func getTotalProcesses(d *Daemon) (uint64, error) {
 	return pidCount, nil
 }
 
+func getPressureMetrics(d *Daemon) (map[string]metrics.PressureMetrics, error) {
+	out := map[string]metrics.PressureMetrics{}
+
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		pressurePath := filepath.Join("/proc/pressure", resource)
+
+		content, err := os.ReadFile(pressurePath)
+		if err != nil {
+			// Pressure stall information isn't available in all guest kernels.
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("Failed to read %q: %w", pressurePath, err)
+		}
+
+		stats, err := pressure.Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse %q: %w", pressurePath, err)
+		}
+
+		out[resource] = metrics.PressureMetrics{
+			WaitingSeconds: float64(stats.SomeTotal) / 1000000,
+			StalledSeconds: float64(stats.FullTotal) / 1000000,
+		}
+	}
+
+	return out, nil
+}
+
 func getDiskMetrics(d *Daemon) (map[string]metrics.DiskMetrics, error) {
 	diskStats, err := os.ReadFile("/proc/diskstats")
 	if err != nil {
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+)
+
+var portForwardCmd = APIEndpoint{
+	Name: "port-forward",
+	Path: "port-forward",
+
+	Get: APIEndpointAction{Handler: portForwardHandler},
+}
+
+func portForwardHandler(d *Daemon, r *http.Request) response.Response {
+	return &portForwardServe{d, r}
+}
+
+type portForwardServe struct {
+	d *Daemon
+	r *http.Request
+}
+
+func (r *portForwardServe) String() string {
+	return "port-forward handler"
+}
+
+func (r *portForwardServe) Render(w http.ResponseWriter) error {
+	// Upgrade to port-forward.
+	if r.r.Header.Get("Upgrade") != "port-forward" {
+		http.Error(w, "Missing or invalid upgrade header", http.StatusBadRequest)
+		return nil
+	}
+
+	port, err := strconv.Atoi(r.r.FormValue("port"))
+	if err != nil || port < 1 || port > 65535 {
+		http.Error(w, "Invalid port", http.StatusBadRequest)
+		return nil
+	}
+
+	// Connect to the port on the loopback interface before upgrading so that failures can be reported.
+	var target net.Conn
+	for _, host := range []string{"127.0.0.1", "::1"} {
+		target, err = net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), 5*time.Second)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed connecting to port %d: %v", port, err), http.StatusBadGateway)
+		return nil
+	}
+
+	defer func() { _ = target.Close() }()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Webserver doesn't support hijacking", http.StatusInternalServerError)
+
+		return nil
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Errorf("Failed to hijack connection: %w", err).Error(), http.StatusInternalServerError)
+
+		return nil
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	err = response.Upgrade(conn, "port-forward")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return nil
+	}
+
+	// Copy the traffic in both directions, propagating the end of each stream to the other side.
+	chDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(target, conn)
+		closeWrite(target)
+		close(chDone)
+	}()
+
+	_, _ = io.Copy(conn, target)
+	closeWrite(conn)
+	<-chDone
+
+	return nil
+}
+
+// closeWrite shuts down the writing side of the connection if supported, or closes it otherwise.
+func closeWrite(conn net.Conn) {
+	writeCloser, ok := conn.(interface{ CloseWrite() error })
+	if ok {
+		_ = writeCloser.CloseWrite()
+		return
+	}
+
+	_ = conn.Close()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/processes"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var processesCmd = APIEndpoint{
+	Path: "processes",
+
+	Get: APIEndpointAction{Handler: processesGet},
+}
+
+func processesGet(d *Daemon, r *http.Request) response.Response {
+	guestProcesses, err := processes.List("/proc")
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed listing processes: %w", err))
+	}
+
+	users, err := processes.Users("/etc/passwd")
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed reading users: %w", err))
+	}
+
+	result := []api.InstanceProcess{}
+	for _, p := range guestProcesses {
+		// Kernel threads are excluded to match the processes of containers.
+		if p.KernelThread {
+			continue
+		}
+
+		result = append(result, p.ToAPI(p.PID, processes.UserName(users, p.UID)))
+	}
+
+	return response.SyncResponse(true, result)
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
@@ -43,6 +44,7 @@ var api10 = []APIEndpoint{
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
+	clusterDatabaseBackupCmd,
 	clusterGroupCmd,
 	clusterGroupsCmd,
 	clusterNodeCmd,
@@ -55,16 +57,23 @@ var api10 = []APIEndpoint{
 	instanceCmd,
 	instanceConsoleCmd,
 	instanceExecCmd,
+	instanceExecSessionsCmd,
+	instanceExecSessionCmd,
+	instanceExecSessionAttachCmd,
 	instanceFileCmd,
 	instanceExecOutputCmd,
 	instanceExecOutputsCmd,
+	instanceLogStreamCmd,
 	instanceLogCmd,
 	instanceLogsCmd,
 	instanceMetadataCmd,
 	instanceMetadataTemplatesCmd,
+	instanceProcessesCmd,
 	instancesCmd,
 	instanceRebuildCmd,
 	instanceSFTPCmd,
+	instancePortForwardCmd,
+	instanceHistoryCmd,
 	instanceSnapshotCmd,
 	instanceSnapshotsCmd,
 	instanceStateCmd,
@@ -100,11 +109,17 @@ var api10 = []APIEndpoint{
 	operationsCmd,
 	operationWait,
 	operationWebsocket,
+	placementGroupCmd,
+	placementGroupsCmd,
 	profileCmd,
+	profileHistoryCmd,
 	profilesCmd,
 	projectCmd,
 	projectsCmd,
 	projectStateCmd,
+	projectHistoryCmd,
+	secretCmd,
+	secretsCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
@@ -127,8 +142,12 @@ var api10 = []APIEndpoint{
 	identitiesCmd,
 	identitiesByAuthenticationMethodCmd,
 	identityCmd,
+	identityTokensCmd,
+	identityTokenCmd,
+	identityEffectivePermissionsCmd,
 	authGroupsCmd,
 	authGroupCmd,
+	authAccessModelCmd,
 	identityProviderGroupsCmd,
 	identityProviderGroupCmd,
 	permissionsCmd,
@@ -796,6 +815,7 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	bgpChanged := false
 	dnsChanged := false
 	lokiChanged := false
+	webhooksChanged := false
 	acmeDomainChanged := false
 	acmeCAURLChanged := false
 	oidcChanged := false
@@ -856,6 +876,10 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		case "oidc.issuer", "oidc.client.id", "oidc.audience", "oidc.groups.claim":
 			oidcChanged = true
 		}
+
+		if strings.HasPrefix(key, "webhooks.") {
+			webhooksChanged = true
+		}
 	}
 
 	for key := range nodeChanged {
@@ -970,13 +994,17 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	if lokiChanged {
 		lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := clusterConfig.LokiServer()
 
-		if lokiURL == "" || lokiLoglevel == "" || len(lokiTypes) == 0 {
-			d.internalListener.RemoveHandler("loki")
-		} else {
-			err := d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes)
-			if err != nil {
-				return err
-			}
+		// Stop the existing client and only start a new one if Loki is still configured.
+		err := d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if webhooksChanged {
+		err := d.setupWebhooks(clusterConfig.Webhooks())
+		if err != nil {
+			return err
 		}
 	}
 
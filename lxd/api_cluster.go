@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"encoding/pem"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,6 +36,7 @@ import (
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/placement"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/scriptlet"
@@ -107,6 +110,12 @@ var clusterCertificateCmd = APIEndpoint{
 	Put: APIEndpointAction{Handler: clusterCertificatePut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var clusterDatabaseBackupCmd = APIEndpoint{
+	Path: "cluster/database-backup",
+
+	Get: APIEndpointAction{Handler: clusterDatabaseBackupGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var clusterGroupsCmd = APIEndpoint{
 	Path: "cluster/groups",
 
@@ -2982,85 +2991,88 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	if req.Action == "evacuate" {
-		stopFunc := func(inst instance.Instance) error {
-			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+		return evacuateClusterMember(s, d.gateway, r, req.Mode, evacuateClusterStopInstance, evacuateClusterMigrateInstance)
+	} else if req.Action == "restore" {
+		return restoreClusterMember(d, r)
+	}
 
-			// Get the shutdown timeout for the instance.
-			timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
-			val, err := strconv.Atoi(timeout)
-			if err != nil {
-				val = evacuateHostShutdownDefaultTimeout
-			}
+	return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
+}
 
-			// Start with a clean shutdown.
-			err = inst.Shutdown(time.Duration(val) * time.Second)
-			if err != nil {
-				l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
+// evacuateClusterStopInstance cleanly shuts down the instance, forcing it to stop on failure, and records it as
+// running so that its state can be restored.
+func evacuateClusterStopInstance(inst instance.Instance) error {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
-				// Fallback to forced stop.
-				err = inst.Stop(false)
-				if err != nil && !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
-					return fmt.Errorf("Failed to stop instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
-				}
-			}
+	// Get the shutdown timeout for the instance.
+	timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+	val, err := strconv.Atoi(timeout)
+	if err != nil {
+		val = evacuateHostShutdownDefaultTimeout
+	}
 
-			// Mark the instance as RUNNING in volatile so its state can be properly restored.
-			err = inst.VolatileSet(map[string]string{"volatile.last_state.power": instance.PowerStateRunning})
-			if err != nil {
-				l.Warn("Failed to set instance state to RUNNING", logger.Ctx{"err": err})
-			}
+	// Start with a clean shutdown.
+	err = inst.Shutdown(time.Duration(val) * time.Second)
+	if err != nil {
+		l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
 
-			return nil
+		// Fallback to forced stop.
+		err = inst.Stop(false)
+		if err != nil && !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+			return fmt.Errorf("Failed to stop instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
 		}
+	}
 
-		migrateFunc := func(s *state.State, r *http.Request, inst instance.Instance, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
-			// Migrate the instance.
-			req := api.InstancePost{
-				Name: inst.Name(),
-				Live: live,
-			}
+	// Mark the instance as RUNNING in volatile so its state can be properly restored.
+	err = inst.VolatileSet(map[string]string{"volatile.last_state.power": instance.PowerStateRunning})
+	if err != nil {
+		l.Warn("Failed to set instance state to RUNNING", logger.Ctx{"err": err})
+	}
 
-			err := migrateInstance(s, r, inst, targetMemberInfo.Name, req, op)
-			if err != nil {
-				return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
-			}
+	return nil
+}
 
-			if !startInstance || live {
-				return nil
-			}
+// evacuateClusterMigrateInstance migrates the instance to the target member and starts it there if requested.
+func evacuateClusterMigrateInstance(s *state.State, r *http.Request, inst instance.Instance, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
+	// Migrate the instance.
+	req := api.InstancePost{
+		Name: inst.Name(),
+		Live: live,
+	}
 
-			// Start it back up on target.
-			dest, err := cluster.Connect(targetMemberInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
-			if err != nil {
-				return fmt.Errorf("Failed to connect to destination %q for instance %q in project %q: %w", targetMemberInfo.Address, inst.Name(), inst.Project().Name, err)
-			}
+	err := migrateInstance(s, r, inst, targetMemberInfo.Name, req, op)
+	if err != nil {
+		return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
 
-			dest = dest.UseProject(inst.Project().Name)
+	if !startInstance || live {
+		return nil
+	}
 
-			if metadata != nil && op != nil {
-				metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
-				_ = op.UpdateMetadata(metadata)
-			}
+	// Start it back up on target.
+	dest, err := cluster.Connect(targetMemberInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to destination %q for instance %q in project %q: %w", targetMemberInfo.Address, inst.Name(), inst.Project().Name, err)
+	}
 
-			startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
-			if err != nil {
-				return err
-			}
+	dest = dest.UseProject(inst.Project().Name)
 
-			err = startOp.Wait()
-			if err != nil {
-				return err
-			}
+	if metadata != nil && op != nil {
+		metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
+		_ = op.UpdateMetadata(metadata)
+	}
 
-			return nil
-		}
+	startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
+	if err != nil {
+		return err
+	}
 
-		return evacuateClusterMember(s, d.gateway, r, req.Mode, stopFunc, migrateFunc)
-	} else if req.Action == "restore" {
-		return restoreClusterMember(d, r)
+	err = startOp.Wait()
+	if err != nil {
+		return err
 	}
 
-	return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
+	return nil
 }
 
 func internalClusterHeal(d *Daemon, r *http.Request) response.Response {
@@ -3252,7 +3264,8 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 
 	metadata := make(map[string]any)
 
-	for _, inst := range opts.instances {
+	// Stop or move the instances before the instances they depend on.
+	for _, inst := range instancesDependencyOrder(opts.instances, true) {
 		instProject := inst.Project()
 		l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
 
@@ -3273,6 +3286,26 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 			}
 		}
 
+		// Pick the target before stopping the instance so that it keeps running if its placement group can't
+		// be satisfied.
+		var targetMemberInfo *db.NodeInfo
+		if migrate {
+			// Get candidate cluster members to move instances to.
+			candidateMembers, err := evacuateClusterCandidateMembers(ctx, opts.s, inst)
+			if err != nil {
+				return err
+			}
+
+			targetMemberInfo, err = evacuateClusterSelectTarget(ctx, opts.s, opts.gateway, inst, candidateMembers)
+			if err != nil {
+				if !api.StatusErrorCheck(err, http.StatusNotFound) {
+					return err
+				}
+
+				targetMemberInfo = nil
+			}
+		}
+
 		// Stop the instance if needed.
 		isRunning := inst.IsRunning()
 		if opts.stopInstance != nil && isRunning && !(migrate && live) {
@@ -3290,32 +3323,10 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 			continue
 		}
 
-		// Get candidate cluster members to move instances to.
-		var candidateMembers []db.NodeInfo
-		err := opts.s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-			allMembers, err := tx.GetNodes(ctx)
-			if err != nil {
-				return fmt.Errorf("Failed getting cluster members: %w", err)
-			}
-
-			candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", nil, opts.s.GlobalConfig.OfflineThreshold())
-			if err != nil {
-				return err
-			}
-
-			return nil
-		})
-		if err != nil {
-			return err
-		}
-
-		targetMemberInfo, err := evacuateClusterSelectTarget(ctx, opts.s, opts.gateway, inst, candidateMembers)
-		if err != nil {
-			if api.StatusErrorCheck(err, http.StatusNotFound) {
-				// Skip migration if no target is available
-				l.Warn("No migration target available for instance")
-				continue
-			}
+		// Skip migration if no target is available.
+		if targetMemberInfo == nil {
+			l.Warn("No migration target available for instance")
+			continue
 		}
 
 		// Start migrating the instance.
@@ -3328,7 +3339,7 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 		}
 
 		start := isRunning || instanceShouldAutoStart(inst)
-		err = opts.migrateInstance(opts.s, opts.r, inst, targetMemberInfo, live, start, metadata, opts.op)
+		err := opts.migrateInstance(opts.s, opts.r, inst, targetMemberInfo, live, start, metadata, opts.op)
 		if err != nil {
 			return err
 		}
@@ -3337,6 +3348,38 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 	return nil
 }
 
+// evacuateClusterCandidateMembers returns the cluster members the instance can be moved to.
+func evacuateClusterCandidateMembers(ctx context.Context, s *state.State, inst instance.Instance) ([]db.NodeInfo, error) {
+	var candidateMembers []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", nil, s.GlobalConfig.OfflineThreshold())
+		if err != nil {
+			return err
+		}
+
+		// Only keep the members satisfying the placement group of the instance.
+		placementGroup := inst.ExpandedConfig()["placement.group"]
+		if placementGroup != "" {
+			candidateMembers, err = placement.FilterCandidates(ctx, tx, inst.Project().Name, placementGroup, inst.Name(), candidateMembers, allMembers)
+			if err != nil {
+				return fmt.Errorf("Failed placing instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candidateMembers, nil
+}
+
 func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
@@ -3382,6 +3425,12 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 		instances = append(instances, inst)
 	}
 
+	// Check that moving the instances back doesn't violate their placement groups.
+	err = restoreClusterMemberCheckPlacement(r.Context(), s, originName, instances)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	run := func(op *operations.Operation) error {
 		// Setup a reverter.
 		revert := revert.New()
@@ -3403,8 +3452,8 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 
 		metadata := make(map[string]any)
 
-		// Restart the local instances.
-		for _, inst := range localInstances {
+		// Restart the local instances, starting dependencies first.
+		for _, inst := range instancesDependencyOrder(localInstances, false) {
 			// Don't start instances which were stopped by the user.
 			if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
 				continue
@@ -4320,6 +4369,69 @@ func clusterGroupValidateName(name string) error {
 	return nil
 }
 
+// restoreClusterMemberCheckPlacement checks that the given evacuated instances can be moved back to the cluster
+// member being restored without violating their placement groups, assuming all of them are moved back.
+func restoreClusterMemberCheckPlacement(ctx context.Context, s *state.State, originName string, instances []instance.Instance) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var allMembers []db.NodeInfo
+		var origin db.NodeInfo
+
+		// Locations of the instances of each placement group, keyed by project and group name.
+		groupInstances := map[[2]string]map[string]string{}
+
+		for _, inst := range instances {
+			projectName := inst.Project().Name
+			groupName := inst.ExpandedConfig()["placement.group"]
+			if groupName == "" {
+				continue
+			}
+
+			if allMembers == nil {
+				var err error
+				allMembers, err = tx.GetNodes(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster members: %w", err)
+				}
+
+				origin, err = tx.GetNodeByName(ctx, originName)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster member %q: %w", originName, err)
+				}
+			}
+
+			_, group, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+			if err != nil {
+				return fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+			}
+
+			key := [2]string{projectName, groupName}
+			locations, ok := groupInstances[key]
+			if !ok {
+				locations, err = placement.Instances(ctx, tx, projectName, groupName)
+				if err != nil {
+					return fmt.Errorf("Failed loading instances of placement group %q: %w", groupName, err)
+				}
+
+				for _, other := range instances {
+					_, found := locations[other.Name()]
+					if found && other.Project().Name == projectName {
+						locations[other.Name()] = originName
+					}
+				}
+
+				groupInstances[key] = locations
+			}
+
+			_, err = placement.Filter(group, []db.NodeInfo{origin}, allMembers, placement.Usage(locations, inst.Name()))
+			if err != nil {
+				return fmt.Errorf("Failed restoring instance %q in project %q: %w", inst.Name(), projectName, err)
+			}
+		}
+
+		return nil
+	})
+}
+
 func evacuateClusterSelectTarget(ctx context.Context, s *state.State, gateway *cluster.Gateway, inst instance.Instance, candidateMembers []db.NodeInfo) (*db.NodeInfo, error) {
 	var targetMemberInfo *db.NodeInfo
 
@@ -4489,3 +4601,154 @@ func autoHealCluster(ctx context.Context, s *state.State, offlineMembers []db.No
 
 	return nil
 }
+
+// swagger:operation GET /1.0/cluster/database-backup cluster cluster_database_backup_get
+//
+//	Get a global database backup
+//
+//	Download a consistent snapshot of the global database as a tarball.
+//	The tarball can be restored with `lxd cluster restore-database`.
+//
+//	---
+//	produces:
+//	  - application/octet-stream
+//	responses:
+//	  "200":
+//	    description: Raw backup data
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseBackupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	var buf bytes.Buffer
+	var metadata *dbCluster.DatabaseBackupMetadata
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		metadata, err = dbCluster.WriteDatabaseBackup(ctx, tx.Tx(), &buf)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ent := response.FileResponseEntry{
+		Identifier:   "database-backup",
+		Filename:     clusterDatabaseBackupFilename(metadata.CreatedAt),
+		File:         bytes.NewReader(buf.Bytes()),
+		FileSize:     int64(buf.Len()),
+		FileModified: metadata.CreatedAt,
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ClusterDatabaseBackupRetrieved.Event("database-backup", request.CreateRequestor(r), nil))
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
+
+// clusterDatabaseBackupFilename returns the file name of a global database backup taken at the given time.
+func clusterDatabaseBackupFilename(createdAt time.Time) string {
+	return fmt.Sprintf("lxd-database-%s.tar.gz", createdAt.UTC().Format("20060102-150405"))
+}
+
+func autoClusterDatabaseBackupTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		schedule, retention := s.GlobalConfig.ClusterDatabaseBackup()
+		if schedule == "" || !snapshotIsScheduledNow(schedule, 0) {
+			return
+		}
+
+		// Only take a single backup per cluster, from the leader.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			return // Skip backup if not cluster leader.
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return autoClusterDatabaseBackup(ctx, s, retention)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterDatabaseBackup, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating global database backup operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Backing up global database")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting global database backup operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed backing up global database", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done backing up global database")
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// autoClusterDatabaseBackup writes a new global database backup to the local backups directory and removes
+// the oldest backups so that at most retention of them are kept.
+func autoClusterDatabaseBackup(ctx context.Context, s *state.State, retention int64) error {
+	backupsPath := shared.VarPath("backups", "database")
+	err := os.MkdirAll(backupsPath, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed creating database backups directory %q: %w", backupsPath, err)
+	}
+
+	var buf bytes.Buffer
+	var metadata *dbCluster.DatabaseBackupMetadata
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		metadata, err = dbCluster.WriteDatabaseBackup(ctx, tx.Tx(), &buf)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(backupsPath, clusterDatabaseBackupFilename(metadata.CreatedAt))
+	err = os.WriteFile(target, buf.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed writing database backup %q: %w", target, err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ClusterDatabaseBackupCreated.Event("database-backup", nil, map[string]any{"file": target}))
+
+	// Prune the oldest backups, relying on the file names sorting by creation time.
+	entries, err := os.ReadDir(backupsPath)
+	if err != nil {
+		return fmt.Errorf("Failed listing database backups: %w", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "lxd-database-") && strings.HasSuffix(entry.Name(), ".tar.gz") {
+			backups = append(backups, entry.Name())
+		}
+	}
+
+	sort.Strings(backups)
+	for len(backups) > int(retention) {
+		err = os.Remove(filepath.Join(backupsPath, backups[0]))
+		if err != nil {
+			return fmt.Errorf("Failed removing database backup %q: %w", backups[0], err)
+		}
+
+		backups = backups[1:]
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/instance"
 	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -22,10 +25,14 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
 )
 
 type metricsCacheEntry struct {
@@ -129,6 +136,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 		// Register internal metrics.
 		intMetrics = internalMetrics(ctx, s.StartTime, tx)
+		intMetrics.Merge(d.webhookClient.Metrics())
 		return nil
 	})
 	if err != nil {
@@ -167,6 +175,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
+		metricSet.Merge(storageMetricsForProjects(s, projectNames))
 		return getFilteredMetrics(s, r, compress, metricSet)
 	}
 
@@ -192,6 +201,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
+		metricSet.Merge(storageMetricsForProjects(s, projectNames))
 		return getFilteredMetrics(s, r, compress, metricSet)
 	}
 
@@ -264,6 +274,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 					// Add the metrics if available.
 					if instanceMetrics != nil {
 						newMetrics[projectName].Merge(instanceMetrics)
+						newMetrics[projectName].Merge(device.HTTPProxyMetrics(inst))
 					}
 
 					newMetricsLock.Unlock()
@@ -345,6 +356,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	metricsCacheLock.Unlock()
 
+	metricSet.Merge(storageMetricsForProjects(s, projectNames))
 	return getFilteredMetrics(s, r, compress, metricSet)
 }
 
@@ -386,6 +398,29 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 	} else {
 		// Total number of warnings
 		out.AddSamples(metrics.WarningsTotal, metrics.Sample{Value: float64(len(warnings))})
+
+		// Number of unresolved warnings by type
+		active := map[warningtype.Type]int{}
+		for _, warning := range warnings {
+			if warning.Status != warningtype.StatusResolved {
+				active[warning.TypeCode]++
+			}
+		}
+
+		typeCodes := make([]warningtype.Type, 0, len(active))
+		for typeCode := range active {
+			typeCodes = append(typeCodes, typeCode)
+		}
+
+		sort.Slice(typeCodes, func(i, j int) bool { return typeCodes[i] < typeCodes[j] })
+
+		for _, typeCode := range typeCodes {
+			count := active[typeCode]
+			out.AddSamples(metrics.WarningsActive, metrics.Sample{
+				Labels: map[string]string{"type": warningtype.TypeNames[typeCode], "severity": warningtype.Severities[typeCode.Severity()]},
+				Value:  float64(count),
+			})
+		}
 	}
 
 	operations, err := dbCluster.GetOperations(ctx, tx.Tx())
@@ -432,3 +467,276 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 
 	return out
 }
+
+// storageMetricsCache contains the storage metrics of the local member indexed by project name.
+// The metrics of the storage pools themselves use an empty project name.
+var storageMetricsCache map[string]*metrics.MetricSet
+var storageMetricsCacheExpiry time.Time
+var storageMetricsCacheLock sync.Mutex
+
+// storageMetricsTaskInterval is how often the storage metrics refresh interval is checked.
+const storageMetricsTaskInterval = 10 * time.Second
+
+// storageMetricsTask refreshes the storage metrics cache at the interval set in core.metrics_storage_interval.
+func storageMetricsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		interval := s.GlobalConfig.MetricsStorageInterval()
+		if interval <= 0 {
+			storageMetricsCacheLock.Lock()
+			storageMetricsCache = nil
+			storageMetricsCacheExpiry = time.Time{}
+			storageMetricsCacheLock.Unlock()
+
+			return
+		}
+
+		storageMetricsCacheLock.Lock()
+		expired := storageMetricsCache == nil || !storageMetricsCacheExpiry.After(time.Now())
+		storageMetricsCacheLock.Unlock()
+
+		if !expired {
+			return
+		}
+
+		// Compute the metrics outside of the lock so that the metrics handler never waits on the storage drivers.
+		cache := storageMetrics(ctx, s)
+
+		storageMetricsCacheLock.Lock()
+		storageMetricsCache = cache
+		storageMetricsCacheExpiry = time.Now().Add(interval)
+		storageMetricsCacheLock.Unlock()
+	}
+
+	return f, task.Every(storageMetricsTaskInterval)
+}
+
+// storageMetricsForProjects returns the cached storage metrics of the given projects.
+// The cache is refreshed by storageMetricsTask, so nothing is returned until it ran once.
+func storageMetricsForProjects(s *state.State, projectNames []string) *metrics.MetricSet {
+	if s.GlobalConfig.MetricsStorageInterval() <= 0 {
+		return nil
+	}
+
+	storageMetricsCacheLock.Lock()
+	defer storageMetricsCacheLock.Unlock()
+
+	if storageMetricsCache == nil {
+		return nil
+	}
+
+	out := metrics.NewMetricSet(nil)
+	for _, projectName := range projectNames {
+		if projectName == api.ProjectDefaultName {
+			out.Merge(storageMetricsCache[""])
+		}
+
+		out.Merge(storageMetricsCache[projectName])
+	}
+
+	return out
+}
+
+// storageMetricsReportRemote returns whether the local member reports the shared metrics of remote storage pools.
+// This is the online member with the lowest ID, so that a single member reports them even if the leader changes.
+func storageMetricsReportRemote(ctx context.Context, s *state.State, tx *db.ClusterTx) (bool, error) {
+	if !s.ServerClustered {
+		return true, nil
+	}
+
+	members, err := tx.GetNodes(ctx)
+	if err != nil {
+		return false, fmt.Errorf("Failed loading cluster members: %w", err)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	offlineThreshold := s.GlobalConfig.OfflineThreshold()
+
+	for _, member := range members {
+		if member.IsOffline(offlineThreshold) {
+			continue
+		}
+
+		return member.Name == s.ServerName, nil
+	}
+
+	return false, nil
+}
+
+// storageMetrics returns the metrics of the storage pools, volumes and buckets available on the local member,
+// indexed by project name.
+// Remote storage pools are shared by all members, so their totals, custom volumes and buckets are only reported by
+// the online member with the lowest ID, while their instance volumes are reported by the member running the instance.
+func storageMetrics(ctx context.Context, s *state.State) map[string]*metrics.MetricSet {
+	out := map[string]*metrics.MetricSet{"": metrics.NewMetricSet(nil)}
+
+	projectMetrics := func(projectName string) *metrics.MetricSet {
+		if out[projectName] == nil {
+			out[projectName] = metrics.NewMetricSet(nil)
+		}
+
+		return out[projectName]
+	}
+
+	var dbPools map[int64]api.StoragePool
+	var dbPoolMembers map[int64]map[int64]db.StoragePoolNode
+	dbVolumes := map[int64][]*db.StorageVolume{}
+	dbBuckets := map[int64][]*db.StorageBucket{}
+	reportRemote := false
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		reportRemote, err = storageMetricsReportRemote(ctx, s, tx)
+		if err != nil {
+			return err
+		}
+
+		poolState := db.StoragePoolCreated
+		dbPools, dbPoolMembers, err = tx.GetStoragePools(ctx, &poolState)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage pools: %w", err)
+		}
+
+		for poolID := range dbPools {
+			dbVolumes[poolID], err = tx.GetStoragePoolVolumes(ctx, poolID, true)
+			if err != nil {
+				return fmt.Errorf("Failed loading storage volumes: %w", err)
+			}
+
+			dbBuckets[poolID], err = tx.GetStoragePoolBuckets(ctx, true, db.StorageBucketFilter{PoolID: &poolID})
+			if err != nil {
+				return fmt.Errorf("Failed loading storage buckets: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed getting storage metrics", logger.Ctx{"err": err})
+		return out
+	}
+
+	for poolID, dbPool := range dbPools {
+		pool, err := storagePools.LoadByRecord(s, poolID, dbPool, dbPoolMembers[poolID])
+		if err != nil {
+			logger.Warn("Failed loading storage pool", logger.Ctx{"pool": dbPool.Name, "err": err})
+			continue
+		}
+
+		// Only one member reports what is shared by all members on remote pools.
+		reportShared := !pool.Driver().Info().Remote || reportRemote
+
+		// Pool usage.
+		if reportShared {
+			res, err := pool.GetResources()
+			if err != nil {
+				logger.Debug("Failed getting storage pool resources", logger.Ctx{"pool": dbPool.Name, "err": err})
+			} else {
+				labels := map[string]string{"pool": dbPool.Name, "driver": dbPool.Driver}
+
+				out[""].AddSamples(metrics.StoragePoolSizeBytes, metrics.Sample{Value: float64(res.Space.Total), Labels: labels})
+				out[""].AddSamples(metrics.StoragePoolUsedBytes, metrics.Sample{Value: float64(res.Space.Used), Labels: labels})
+
+				if res.Inodes.Total > 0 {
+					out[""].AddSamples(metrics.StoragePoolInodes, metrics.Sample{Value: float64(res.Inodes.Total), Labels: labels})
+					out[""].AddSamples(metrics.StoragePoolInodesUsed, metrics.Sample{Value: float64(res.Inodes.Used), Labels: labels})
+				}
+			}
+		}
+
+		// Count the snapshots of each volume.
+		snapshots := map[string]int{}
+		for _, vol := range dbVolumes[poolID] {
+			parentName, _, isSnapshot := api.GetParentAndSnapshotName(vol.Name)
+			if isSnapshot {
+				snapshots[vol.Project+"/"+vol.Type+"/"+parentName]++
+			}
+		}
+
+		// Volume usage.
+		for _, vol := range dbVolumes[poolID] {
+			if shared.IsSnapshot(vol.Name) || vol.Type == dbCluster.StoragePoolVolumeTypeNameImage {
+				continue
+			}
+
+			var usage *storagePools.VolumeUsage
+			switch vol.Type {
+			case dbCluster.StoragePoolVolumeTypeNameCustom:
+				if !reportShared {
+					continue
+				}
+
+				usage, err = pool.GetCustomVolumeUsage(vol.Project, vol.Name)
+			case dbCluster.StoragePoolVolumeTypeNameContainer, dbCluster.StoragePoolVolumeTypeNameVM:
+				var inst instance.Instance
+				inst, err = instance.LoadByProjectAndName(s, vol.Project, vol.Name)
+				if err != nil {
+					break
+				}
+
+				// Volumes on remote pools are reported by the member running the instance.
+				if inst.Location() != "" && inst.Location() != s.ServerName {
+					continue
+				}
+
+				usage, err = pool.GetInstanceUsage(inst)
+			default:
+				continue
+			}
+
+			labels := map[string]string{"project": vol.Project, "pool": dbPool.Name, "type": vol.Type, "name": vol.Name}
+			set := projectMetrics(vol.Project)
+
+			set.AddSamples(metrics.StorageVolumeSnapshots, metrics.Sample{Value: float64(snapshots[vol.Project+"/"+vol.Type+"/"+vol.Name]), Labels: labels})
+
+			if err != nil {
+				if !errors.Is(err, storageDrivers.ErrNotSupported) {
+					logger.Debug("Failed getting storage volume usage", logger.Ctx{"project": vol.Project, "pool": dbPool.Name, "volume": vol.Name, "err": err})
+				}
+
+				continue
+			}
+
+			if usage.Used >= 0 {
+				set.AddSamples(metrics.StorageVolumeUsedBytes, metrics.Sample{Value: float64(usage.Used), Labels: labels})
+			}
+
+			if usage.Total > 0 {
+				set.AddSamples(metrics.StorageVolumeQuotaBytes, metrics.Sample{Value: float64(usage.Total), Labels: labels})
+			}
+		}
+
+		// Bucket usage.
+		for _, bucket := range dbBuckets[poolID] {
+			if !reportShared {
+				break
+			}
+
+			labels := map[string]string{"project": bucket.Project, "pool": dbPool.Name, "name": bucket.Name}
+			set := projectMetrics(bucket.Project)
+
+			quota, err := units.ParseByteSizeString(bucket.Config["size"])
+			if err == nil && quota > 0 {
+				set.AddSamples(metrics.StorageBucketQuotaBytes, metrics.Sample{Value: float64(quota), Labels: labels})
+			}
+
+			usage, err := pool.GetBucketUsage(bucket.Project, bucket.Name)
+			if err != nil {
+				if !errors.Is(err, storageDrivers.ErrNotSupported) {
+					logger.Debug("Failed getting storage bucket usage", logger.Ctx{"project": bucket.Project, "pool": dbPool.Name, "bucket": bucket.Name, "err": err})
+				}
+
+				continue
+			}
+
+			if usage.Used >= 0 {
+				set.AddSamples(metrics.StorageBucketUsedBytes, metrics.Sample{Value: float64(usage.Used), Labels: labels})
+			}
+		}
+	}
+
+	return out
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -498,7 +499,7 @@ func projectPut(d *Daemon, r *http.Request) response.Response {
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(project.Name, lifecycle.ProjectUpdated.Event(project.Name, requestor, nil))
 
-	return projectChange(s, project, req)
+	return projectChange(s, r, project, req)
 }
 
 // swagger:operation PATCH /1.0/projects/{name} projects project_patch
@@ -614,11 +615,11 @@ func projectPatch(d *Daemon, r *http.Request) response.Response {
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(project.Name, lifecycle.ProjectUpdated.Event(project.Name, requestor, nil))
 
-	return projectChange(s, project, req)
+	return projectChange(s, r, project, req)
 }
 
 // Common logic between PUT and PATCH.
-func projectChange(s *state.State, project *api.Project, req api.ProjectPut) response.Response {
+func projectChange(s *state.State, r *http.Request, project *api.Project, req api.ProjectPut) response.Response {
 	// Make a list of config keys that have changed.
 	configChanged := []string{}
 	for key := range project.Config {
@@ -677,12 +678,18 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 	}
 
 	// Update the database entry.
+	var projectID int64
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		err := projecthelpers.AllowProjectUpdate(s.GlobalConfig, tx, project.Name, req.Config, configChanged)
 		if err != nil {
 			return err
 		}
 
+		projectID, err = cluster.GetProjectID(ctx, tx.Tx(), project.Name)
+		if err != nil {
+			return err
+		}
+
 		err = cluster.UpdateProject(ctx, tx.Tx(), project.Name, req)
 		if err != nil {
 			return fmt.Errorf("Persist profile changes: %w", err)
@@ -717,6 +724,8 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 		return response.SmartError(err)
 	}
 
+	configHistoryRecord(s, r, entity.TypeProject, int(projectID), project.Writable(), req)
+
 	return response.EmptySyncResponse
 }
 
@@ -1279,6 +1288,15 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent using devices of type `proxy`
 		"restricted.devices.proxy": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.serial)
+		// Possible values are `allow` or `block`.
+		// When allowed, serial devices can't use the `tcp` backend or the `listen` option, so that they only expose
+		// a Unix socket in the devices directory of the instance.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent using devices of type `serial`
+		"restricted.devices.serial": isEitherAllowOrBlock,
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.nic)
 		// Possible values are `allow`, `block`, or `managed`.
 		//
@@ -1330,6 +1348,19 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: string
 		//  shortdesc: Which host GID ranges are allowed in `raw.idmap`
 		"restricted.idmap.gid": validate.Optional(validate.IsListOf(validate.IsUint32Range)),
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.instance.max_ttl)
+		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+		// Instances are stopped and deleted once they are older than this, even if their {config:option}`instance-miscellaneous:instance.expiry` is later or not set.
+		//
+		// See {ref}`instances-expiry` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum lifetime of the instances in the project
+		"restricted.instance.max_ttl": func(value string) error {
+			// Validate expression
+			_, err := shared.GetExpiry(time.Time{}, value)
+			return err
+		},
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.networks.access)
 		// Specify a comma-delimited list of network names that are allowed for use in this project.
 		// If this option is not set, all networks are accessible.
@@ -37,6 +37,12 @@ type Authorizer interface {
 	GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, entityType entity.Type) (PermissionChecker, error)
 }
 
+// PermissionExplainer is implemented by authorization drivers that can determine which permissions grant an
+// entitlement. It is used to explain the effective permissions of an identity.
+type PermissionExplainer interface {
+	GrantingPermissions(ctx context.Context, authenticationMethod string, identifier string, entityURL *api.URL, entitlement Entitlement, permissions []api.Permission) ([]api.Permission, error)
+}
+
 // Opts is used as part of the LoadAuthorizer function so that only the relevant configuration fields are passed into a
 // particular driver.
 type Opts struct {
@@ -41,6 +41,8 @@ type requestDetails struct {
 	isPKI                bool
 	idpGroups            []string
 	forwardedIDPGroups   []string
+	tokenName            string
+	forwardedTokenName   string
 }
 
 func (r *requestDetails) isInternalOrUnix() bool {
@@ -79,6 +81,14 @@ func (r *requestDetails) identityProviderGroups() []string {
 	return r.idpGroups
 }
 
+func (r *requestDetails) identityToken() string {
+	if r.protocol == "cluster" {
+		return r.forwardedTokenName
+	}
+
+	return r.tokenName
+}
+
 func (c *commonAuthorizer) requestDetails(r *http.Request) (*requestDetails, error) {
 	if r == nil {
 		return nil, api.StatusErrorf(http.StatusInternalServerError, "Cannot inspect nil request")
@@ -125,6 +135,10 @@ func (c *commonAuthorizer) requestDetails(r *http.Request) (*requestDetails, err
 	d.idpGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroups)
 	d.forwardedIDPGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxForwardedIdentityProviderGroups)
 
+	// Check for identity bearer tokens.
+	d.tokenName, _ = request.GetCtxValue[string](r.Context(), request.CtxIdentityToken)
+	d.forwardedTokenName, _ = request.GetCtxValue[string](r.Context(), request.CtxForwardedIdentityToken)
+
 	// Check if the request is for all projects.
 	values, err := url.ParseQuery(r.URL.RawQuery)
 	if err != nil {
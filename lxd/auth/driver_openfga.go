@@ -134,6 +134,22 @@ func (e *embeddedOpenFGA) CheckPermission(ctx context.Context, r *http.Request,
 	logCtx["protocol"] = protocol
 	l := e.logger.AddContext(logCtx)
 
+	// Requests authenticated with an identity token must also be allowed by the permissions of the token.
+	err = e.checkIdentityTokenPermission(ctx, details, entityURL, entitlement)
+	if err != nil {
+		if !IsDeniedError(err) {
+			return err
+		}
+
+		responseCode := http.StatusForbidden
+		if r.Method == http.MethodGet {
+			responseCode = http.StatusNotFound
+		}
+
+		l.Info("Access denied by identity token", logger.Ctx{"http_code": responseCode, "token": details.identityToken()})
+		return api.StatusErrorf(responseCode, http.StatusText(responseCode))
+	}
+
 	// If the authentication method was TLS, use the TLS driver instead.
 	if protocol == api.AuthenticationMethodTLS {
 		return e.tlsAuthorizer.CheckPermission(ctx, r, entityURL, entitlement)
@@ -276,8 +292,195 @@ func (e *embeddedOpenFGA) CheckPermission(ctx context.Context, r *http.Request,
 	return nil
 }
 
-// GetPermissionChecker returns a PermissionChecker using the embedded OpenFGA server.
+// identityTokenTuples returns an OpenFGA user object representing the identity token used to authenticate the
+// request along with contextual tuples granting it the permissions of the token. An empty user object is returned if
+// the request wasn't authenticated with an identity token or if the token isn't restricted to specific permissions.
+func (e *embeddedOpenFGA) identityTokenTuples(details *requestDetails) (string, []*openfgav1.TupleKey, error) {
+	tokenName := details.identityToken()
+	if tokenName == "" {
+		return "", nil, nil
+	}
+
+	protocol := details.authenticationProtocol()
+	username := details.username()
+	token, err := e.identityCache.GetToken(protocol, username, tokenName)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed loading identity token %q for %q: %w", tokenName, username, err)
+	}
+
+	if len(token.Permissions) == 0 {
+		return "", nil, nil
+	}
+
+	// The token is represented by a separate user object so that only its own permissions are considered.
+	userObject := fmt.Sprintf("%s:%s", entity.TypeIdentity, entity.IdentityURL(protocol, username).WithQuery("token", tokenName).String())
+	tuples := make([]*openfgav1.TupleKey, 0, len(token.Permissions))
+	for _, permission := range token.Permissions {
+		tuples = append(tuples, &openfgav1.TupleKey{
+			User:     userObject,
+			Relation: permission.Entitlement,
+			Object:   fmt.Sprintf("%s:%s", permission.EntityType, permission.EntityReference),
+		})
+	}
+
+	return userObject, tuples, nil
+}
+
+// checkIdentityTokenPermission checks whether the identity token used to authenticate the request (if any) has the
+// given entitlement on the given entity. A denied error is returned if it doesn't.
+func (e *embeddedOpenFGA) checkIdentityTokenPermission(ctx context.Context, details *requestDetails, entityURL *api.URL, entitlement Entitlement) error {
+	userObject, tuples, err := e.identityTokenTuples(details)
+	if err != nil {
+		return err
+	}
+
+	if userObject == "" {
+		return nil
+	}
+
+	entityType, _, _, _, err := entity.ParseURL(entityURL.URL)
+	if err != nil {
+		return fmt.Errorf("Authorization driver failed to parse entity URL %q: %w", entityURL.String(), err)
+	}
+
+	resp, err := e.server.Check(ctx, &openfgav1.CheckRequest{
+		StoreId: dummyDatastoreULID,
+		TupleKey: &openfgav1.CheckRequestTupleKey{
+			User:     userObject,
+			Relation: string(entitlement),
+			Object:   fmt.Sprintf("%s:%s", entityType, entityURL.String()),
+		},
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: tuples},
+	})
+	if err != nil {
+		var openFGAInternalError openFGAErrors.InternalError
+		if errors.As(err, &openFGAInternalError) {
+			err = openFGAInternalError.Internal()
+		}
+
+		return fmt.Errorf("Failed to check OpenFGA relation for identity token: %w", err)
+	}
+
+	if !resp.GetAllowed() {
+		return api.StatusErrorf(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+	}
+
+	return nil
+}
+
+// GrantingPermissions returns the subset of the given permissions that grant the given entitlement on the given entity
+// to the identity. Each permission is checked on its own so that the result lists every permission that is
+// sufficient to grant the entitlement.
+func (e *embeddedOpenFGA) GrantingPermissions(ctx context.Context, authenticationMethod string, identifier string, entityURL *api.URL, entitlement Entitlement, permissions []api.Permission) ([]api.Permission, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	entityType, _, _, _, err := entity.ParseURL(entityURL.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Authorization driver failed to parse entity URL %q: %w", entityURL.String(), err)
+	}
+
+	// Each permission is given to a separate user object so that the permissions of the identity aren't considered.
+	userObject := fmt.Sprintf("%s:%s", entity.TypeIdentity, entity.IdentityURL(authenticationMethod, identifier).WithQuery("permissions", "explain").String())
+	entityObject := fmt.Sprintf("%s:%s", entityType, entityURL.String())
+
+	var granting []api.Permission
+	for _, permission := range permissions {
+		resp, err := e.server.Check(ctx, &openfgav1.CheckRequest{
+			StoreId: dummyDatastoreULID,
+			TupleKey: &openfgav1.CheckRequestTupleKey{
+				User:     userObject,
+				Relation: string(entitlement),
+				Object:   entityObject,
+			},
+			ContextualTuples: &openfgav1.ContextualTupleKeys{
+				TupleKeys: []*openfgav1.TupleKey{
+					{
+						User:     userObject,
+						Relation: permission.Entitlement,
+						Object:   fmt.Sprintf("%s:%s", permission.EntityType, permission.EntityReference),
+					},
+				},
+			},
+		})
+		if err != nil {
+			var openFGAInternalError openFGAErrors.InternalError
+			if errors.As(err, &openFGAInternalError) {
+				err = openFGAInternalError.Internal()
+			}
+
+			return nil, fmt.Errorf("Failed to check OpenFGA relation for permission %q on %q: %w", permission.Entitlement, permission.EntityReference, err)
+		}
+
+		if resp.GetAllowed() {
+			granting = append(granting, permission)
+		}
+	}
+
+	return granting, nil
+}
+
+// GetPermissionChecker returns a PermissionChecker using the embedded OpenFGA server. For requests authenticated
+// with an identity token restricted to specific permissions, the returned PermissionChecker only allows the entities
+// that both the identity and the token have the entitlement on.
 func (e *embeddedOpenFGA) GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, entityType entity.Type) (PermissionChecker, error) {
+	checker, err := e.getPermissionChecker(ctx, r, entitlement, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server entity is checked with CheckPermission which already accounts for identity tokens.
+	if entityType == entity.TypeServer {
+		return checker, nil
+	}
+
+	details, err := e.requestDetails(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to extract request details: %w", err)
+	}
+
+	if !details.trusted || details.isInternalOrUnix() {
+		return checker, nil
+	}
+
+	userObject, tuples, err := e.identityTokenTuples(details)
+	if err != nil {
+		return nil, err
+	}
+
+	if userObject == "" {
+		return checker, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := e.server.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+		StoreId:          dummyDatastoreULID,
+		Type:             entityType.String(),
+		Relation:         string(entitlement),
+		User:             userObject,
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: tuples},
+	})
+	if err != nil {
+		var openFGAInternalError openFGAErrors.InternalError
+		if errors.As(err, &openFGAInternalError) {
+			err = openFGAInternalError.Internal()
+		}
+
+		return nil, fmt.Errorf("Failed to list OpenFGA objects of type %q with entitlement %q for identity token: %w", entityType.String(), entitlement, err)
+	}
+
+	tokenObjects := resp.GetObjects()
+	return func(entityURL *api.URL) bool {
+		object := fmt.Sprintf("%s:%s", entityType, entityURL.String())
+		return shared.ValueInSlice(object, tokenObjects) && checker(entityURL)
+	}, nil
+}
+
+// getPermissionChecker returns a PermissionChecker for the identity that sent the request using the embedded OpenFGA
+// server.
+func (e *embeddedOpenFGA) getPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, entityType entity.Type) (PermissionChecker, error) {
 	logCtx := logger.Ctx{"entity_type": entityType, "entitlement": entitlement, "url": r.URL.String(), "method": r.Method}
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -10,13 +10,13 @@ import (
 type Entitlement string
 
 const (
-	// EntitlementCanView is the "can_view" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStorageVolume.
+	// EntitlementCanView is the "can_view" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypePlacementGroup, entity.TypeProfile, entity.TypeProject, entity.TypeSecret, entity.TypeStorageBucket, entity.TypeStorageVolume.
 	EntitlementCanView Entitlement = "can_view"
 
-	// EntitlementCanEdit is the "can_edit" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeServer, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
+	// EntitlementCanEdit is the "can_edit" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypePlacementGroup, entity.TypeProfile, entity.TypeProject, entity.TypeSecret, entity.TypeServer, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
 	EntitlementCanEdit Entitlement = "can_edit"
 
-	// EntitlementCanDelete is the "can_delete" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
+	// EntitlementCanDelete is the "can_delete" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypePlacementGroup, entity.TypeProfile, entity.TypeProject, entity.TypeSecret, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
 	EntitlementCanDelete Entitlement = "can_delete"
 
 	// EntitlementAdmin is the "admin" entitlement. It applies to the following entities: entity.TypeServer.
@@ -205,6 +205,21 @@ const (
 	// EntitlementCanDeleteNetworkZones is the "can_delete_network_zones" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementCanDeleteNetworkZones Entitlement = "can_delete_network_zones"
 
+	// EntitlementPlacementGroupManager is the "placement_group_manager" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementPlacementGroupManager Entitlement = "placement_group_manager"
+
+	// EntitlementCanCreatePlacementGroups is the "can_create_placement_groups" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanCreatePlacementGroups Entitlement = "can_create_placement_groups"
+
+	// EntitlementCanViewPlacementGroups is the "can_view_placement_groups" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanViewPlacementGroups Entitlement = "can_view_placement_groups"
+
+	// EntitlementCanEditPlacementGroups is the "can_edit_placement_groups" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanEditPlacementGroups Entitlement = "can_edit_placement_groups"
+
+	// EntitlementCanDeletePlacementGroups is the "can_delete_placement_groups" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanDeletePlacementGroups Entitlement = "can_delete_placement_groups"
+
 	// EntitlementProfileManager is the "profile_manager" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementProfileManager Entitlement = "profile_manager"
 
@@ -220,6 +235,21 @@ const (
 	// EntitlementCanDeleteProfiles is the "can_delete_profiles" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementCanDeleteProfiles Entitlement = "can_delete_profiles"
 
+	// EntitlementSecretManager is the "secret_manager" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementSecretManager Entitlement = "secret_manager"
+
+	// EntitlementCanCreateSecrets is the "can_create_secrets" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanCreateSecrets Entitlement = "can_create_secrets"
+
+	// EntitlementCanViewSecrets is the "can_view_secrets" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanViewSecrets Entitlement = "can_view_secrets"
+
+	// EntitlementCanEditSecrets is the "can_edit_secrets" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanEditSecrets Entitlement = "can_edit_secrets"
+
+	// EntitlementCanDeleteSecrets is the "can_delete_secrets" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanDeleteSecrets Entitlement = "can_delete_secrets"
+
 	// EntitlementStorageVolumeManager is the "storage_volume_manager" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementStorageVolumeManager Entitlement = "storage_volume_manager"
 
@@ -279,6 +309,9 @@ const (
 
 	// EntitlementCanExec is the "can_exec" entitlement. It applies to the following entities: entity.TypeInstance.
 	EntitlementCanExec Entitlement = "can_exec"
+
+	// EntitlementCanPortForward is the "can_port_forward" entitlement. It applies to the following entities: entity.TypeInstance.
+	EntitlementCanPortForward Entitlement = "can_port_forward"
 )
 
 var entityTypeToEntitlements = map[entity.Type][]Entitlement{
@@ -355,6 +388,8 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		EntitlementCanAccessConsole,
 		// Grants permission to start a terminal session.
 		EntitlementCanExec,
+		// Grants permission to forward ports from the client to the instance.
+		EntitlementCanPortForward,
 	},
 	entity.TypeNetwork: {
 		// Grants permission to edit the network.
@@ -380,6 +415,14 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		// Grants permission to view the network zone.
 		EntitlementCanView,
 	},
+	entity.TypePlacementGroup: {
+		// Grants permission to edit the placement group.
+		EntitlementCanEdit,
+		// Grants permission to delete the placement group.
+		EntitlementCanDelete,
+		// Grants permission to view the placement group.
+		EntitlementCanView,
+	},
 	entity.TypeProfile: {
 		// Grants permission to edit the profile.
 		EntitlementCanEdit,
@@ -461,6 +504,16 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		EntitlementCanEditNetworkZones,
 		// Grants permission to delete network zones.
 		EntitlementCanDeleteNetworkZones,
+		// Grants permission to create, view, edit, and delete all placement groups belonging to the project.
+		EntitlementPlacementGroupManager,
+		// Grants permission to create placement groups.
+		EntitlementCanCreatePlacementGroups,
+		// Grants permission to view placement groups.
+		EntitlementCanViewPlacementGroups,
+		// Grants permission to edit placement groups.
+		EntitlementCanEditPlacementGroups,
+		// Grants permission to delete placement groups.
+		EntitlementCanDeletePlacementGroups,
 		// Grants permission to create, view, edit, and delete all profiles belonging to the project.
 		EntitlementProfileManager,
 		// Grants permission to create profiles.
@@ -471,6 +524,16 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		EntitlementCanEditProfiles,
 		// Grants permission to delete profiles.
 		EntitlementCanDeleteProfiles,
+		// Grants permission to create, view, edit, and delete all secrets belonging to the project.
+		EntitlementSecretManager,
+		// Grants permission to create secrets.
+		EntitlementCanCreateSecrets,
+		// Grants permission to view secrets and to use them in instances. This isn't granted to project viewers.
+		EntitlementCanViewSecrets,
+		// Grants permission to edit secrets.
+		EntitlementCanEditSecrets,
+		// Grants permission to delete secrets.
+		EntitlementCanDeleteSecrets,
 		// Grants permission to create, view, edit, and delete all storage volumes belonging to the project.
 		EntitlementStorageVolumeManager,
 		// Grants permission to create storage volumes.
@@ -498,6 +561,14 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		// Grants permission to view project level metrics.
 		EntitlementCanViewMetrics,
 	},
+	entity.TypeSecret: {
+		// Grants permission to edit the secret.
+		EntitlementCanEdit,
+		// Grants permission to delete the secret.
+		EntitlementCanDelete,
+		// Grants permission to view the secret and to use it in instances.
+		EntitlementCanView,
+	},
 	entity.TypeServer: {
 		// Grants full access to LXD as if via Unix socket.
 		EntitlementAdmin,
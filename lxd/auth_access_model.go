@@ -0,0 +1,685 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var authAccessModelCmd = APIEndpoint{
+	Name: "auth_access_model",
+	Path: "auth/access-model",
+	Get: APIEndpointAction{
+		Handler:       getAuthAccessModel,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewPermissions),
+	},
+	Put: APIEndpointAction{
+		Handler:       updateAuthAccessModel,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementPermissionManager),
+	},
+}
+
+// authAccessModelChanges records the entries of the access model that were changed when applying it.
+type authAccessModelChanges struct {
+	groupsCreated     []string
+	groupsUpdated     []string
+	groupsDeleted     []string
+	idpGroupsCreated  []string
+	idpGroupsUpdated  []string
+	idpGroupsDeleted  []string
+	identitiesUpdated []api.AuthAccessModelIdentity
+}
+
+// swagger:operation GET /1.0/auth/access-model auth_access_model auth_access_model_get
+//
+//	Get the access model
+//
+//	Gets the authorization groups and their permissions, the identity provider group mappings and the group
+//	memberships of identities in a single document. Only the identities that the requestor can view are listed.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Access model
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/AuthAccessModel"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getAuthAccessModel(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	canViewIdentity, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var model *api.AuthAccessModel
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		model, err = loadAuthAccessModel(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	model = filterAuthAccessModel(model, canViewIdentity)
+
+	return response.SyncResponseETag(true, model, model)
+}
+
+// swagger:operation PUT /1.0/auth/access-model auth_access_model auth_access_model_put
+//
+//	Apply the access model
+//
+//	Applies the given access model in a single transaction. Groups, identity provider groups and identities present
+//	in the access model are created or updated to match it. If `prune` is set, groups and identity provider groups
+//	that are absent from the access model are deleted, and identities that are absent from it are removed from all
+//	groups. Changing the groups of an identity requires the `can_edit` entitlement on it.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: prune
+//	    description: Whether to remove entries that are absent from the access model
+//	    type: boolean
+//	    example: true
+//	  - in: body
+//	    name: model
+//	    description: Access model
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/AuthAccessModel"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func updateAuthAccessModel(d *Daemon, r *http.Request) response.Response {
+	var model api.AuthAccessModel
+	err := json.NewDecoder(r.Body).Decode(&model)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid request body: %w", err))
+	}
+
+	err = normalizeAuthAccessModel(&model)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	prune := shared.IsTrue(request.QueryParam(r, "prune"))
+
+	s := d.State()
+	canViewIdentity, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	canEditIdentity, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanEdit, entity.TypeIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var changes *authAccessModelChanges
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		current, err := loadAuthAccessModel(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		// The ETag is computed on the access model returned by GET, which only lists the visible identities.
+		err = util.EtagCheck(r, filterAuthAccessModel(current, canViewIdentity))
+		if err != nil {
+			return err
+		}
+
+		changes, err = applyAuthAccessModel(ctx, tx.Tx(), current, &model, prune, canEditIdentity)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.UpdateIdentityCache()
+
+	// Send a lifecycle event for each changed entry.
+	requestor := request.CreateRequestor(r)
+	for _, name := range changes.groupsCreated {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthGroupCreated.Event(name, requestor, nil))
+	}
+
+	for _, name := range changes.groupsUpdated {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthGroupUpdated.Event(name, requestor, nil))
+	}
+
+	for _, name := range changes.groupsDeleted {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthGroupDeleted.Event(name, requestor, nil))
+	}
+
+	for _, name := range changes.idpGroupsCreated {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.IdentityProviderGroupCreated.Event(name, requestor, nil))
+	}
+
+	for _, name := range changes.idpGroupsUpdated {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.IdentityProviderGroupUpdated.Event(name, requestor, nil))
+	}
+
+	for _, name := range changes.idpGroupsDeleted {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.IdentityProviderGroupDeleted.Event(name, requestor, nil))
+	}
+
+	for _, identity := range changes.identitiesUpdated {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.IdentityUpdated.Event(identity.AuthenticationMethod, identity.Identifier, requestor, nil))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// loadAuthAccessModel returns the access model stored in the database. All lists are sorted so that the result can be
+// used to compute an ETag.
+func loadAuthAccessModel(ctx context.Context, tx *sql.Tx) (*api.AuthAccessModel, error) {
+	groups, err := dbCluster.GetAuthGroups(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := dbCluster.GetPermissions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, entityURLs, err := dbCluster.GetPermissionEntityURLs(ctx, tx, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsByGroupID := make(map[int][]api.Permission, len(groups))
+	for _, permission := range permissions {
+		permissionsByGroupID[permission.GroupID] = append(permissionsByGroupID[permission.GroupID], api.Permission{
+			EntityType:      string(permission.EntityType),
+			EntityReference: entityURLs[entity.Type(permission.EntityType)][permission.EntityID].String(),
+			Entitlement:     string(permission.Entitlement),
+		})
+	}
+
+	identitiesByGroupID, err := dbCluster.GetAllIdentitiesByAuthGroupIDs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	idpGroupsByGroupID, err := dbCluster.GetAllIdentityProviderGroupsByGroupIDs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &api.AuthAccessModel{
+		Groups:                 make([]api.AuthGroupsPost, 0, len(groups)),
+		IdentityProviderGroups: []api.IdentityProviderGroup{},
+		Identities:             []api.AuthAccessModelIdentity{},
+	}
+
+	identityIndexes := map[string]int{}
+	for _, group := range groups {
+		groupPermissions := permissionsByGroupID[group.ID]
+		if groupPermissions == nil {
+			groupPermissions = []api.Permission{}
+		}
+
+		sortPermissions(groupPermissions)
+		model.Groups = append(model.Groups, api.AuthGroupsPost{
+			AuthGroupPost: api.AuthGroupPost{Name: group.Name},
+			AuthGroupPut: api.AuthGroupPut{
+				Description: group.Description,
+				Permissions: groupPermissions,
+			},
+		})
+
+		for _, identity := range identitiesByGroupID[group.ID] {
+			key := string(identity.AuthMethod) + "/" + identity.Identifier
+			i, ok := identityIndexes[key]
+			if !ok {
+				i = len(model.Identities)
+				identityIndexes[key] = i
+				model.Identities = append(model.Identities, api.AuthAccessModelIdentity{
+					AuthenticationMethod: string(identity.AuthMethod),
+					Identifier:           identity.Identifier,
+					Groups:               []string{},
+				})
+			}
+
+			model.Identities[i].Groups = append(model.Identities[i].Groups, group.Name)
+		}
+	}
+
+	// Identity provider groups that aren't mapped to any group are only returned by GetIdentityProviderGroups.
+	idpGroups, err := dbCluster.GetIdentityProviderGroups(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	idpGroupIndexes := make(map[int]int, len(idpGroups))
+	for _, idpGroup := range idpGroups {
+		idpGroupIndexes[idpGroup.ID] = len(model.IdentityProviderGroups)
+		model.IdentityProviderGroups = append(model.IdentityProviderGroups, api.IdentityProviderGroup{
+			Name:   idpGroup.Name,
+			Groups: []string{},
+		})
+	}
+
+	for _, group := range groups {
+		for _, idpGroup := range idpGroupsByGroupID[group.ID] {
+			i, ok := idpGroupIndexes[idpGroup.ID]
+			if !ok {
+				continue
+			}
+
+			model.IdentityProviderGroups[i].Groups = append(model.IdentityProviderGroups[i].Groups, group.Name)
+		}
+	}
+
+	sortAuthAccessModel(model)
+
+	return model, nil
+}
+
+// filterAuthAccessModel returns a copy of the access model that only lists the identities the requestor can view.
+func filterAuthAccessModel(model *api.AuthAccessModel, canViewIdentity auth.PermissionChecker) *api.AuthAccessModel {
+	filtered := *model
+	filtered.Identities = make([]api.AuthAccessModelIdentity, 0, len(model.Identities))
+	for _, identity := range model.Identities {
+		if canViewIdentity(entity.IdentityURL(identity.AuthenticationMethod, identity.Identifier)) {
+			filtered.Identities = append(filtered.Identities, identity)
+		}
+	}
+
+	return &filtered
+}
+
+// normalizeAuthAccessModel validates the access model, canonicalises the entity references of its permissions and
+// sorts it so that it can be compared with the access model stored in the database.
+func normalizeAuthAccessModel(model *api.AuthAccessModel) error {
+	if model.Groups == nil {
+		model.Groups = []api.AuthGroupsPost{}
+	}
+
+	if model.IdentityProviderGroups == nil {
+		model.IdentityProviderGroups = []api.IdentityProviderGroup{}
+	}
+
+	if model.Identities == nil {
+		model.Identities = []api.AuthAccessModelIdentity{}
+	}
+
+	groupNames := make(map[string]bool, len(model.Groups))
+	for i, group := range model.Groups {
+		err := validateGroupName(group.Name)
+		if err != nil {
+			return err
+		}
+
+		if groupNames[group.Name] {
+			return api.StatusErrorf(http.StatusBadRequest, "Group %q is defined more than once", group.Name)
+		}
+
+		groupNames[group.Name] = true
+
+		err = validatePermissions(group.Permissions)
+		if err != nil {
+			return err
+		}
+
+		permissions, err := canonicalPermissions(group.Permissions)
+		if err != nil {
+			return err
+		}
+
+		// Remove duplicate permissions.
+		model.Groups[i].Permissions = make([]api.Permission, 0, len(permissions))
+		for _, permission := range permissions {
+			if !shared.ValueInSlice(permission, model.Groups[i].Permissions) {
+				model.Groups[i].Permissions = append(model.Groups[i].Permissions, permission)
+			}
+		}
+	}
+
+	idpGroupNames := make(map[string]bool, len(model.IdentityProviderGroups))
+	for i, idpGroup := range model.IdentityProviderGroups {
+		if idpGroup.Name == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Identity provider group name cannot be empty")
+		}
+
+		if idpGroupNames[idpGroup.Name] {
+			return api.StatusErrorf(http.StatusBadRequest, "Identity provider group %q is defined more than once", idpGroup.Name)
+		}
+
+		idpGroupNames[idpGroup.Name] = true
+
+		if idpGroup.Groups == nil {
+			model.IdentityProviderGroups[i].Groups = []string{}
+		}
+	}
+
+	identities := make(map[string]bool, len(model.Identities))
+	for i, identity := range model.Identities {
+		err := auth.ValidateAuthenticationMethod(identity.AuthenticationMethod)
+		if err != nil {
+			return err
+		}
+
+		key := identity.AuthenticationMethod + "/" + identity.Identifier
+		if identities[key] {
+			return api.StatusErrorf(http.StatusBadRequest, "Identity %q is defined more than once", key)
+		}
+
+		identities[key] = true
+
+		if identity.AuthenticationMethod == api.AuthenticationMethodTLS && len(identity.Groups) > 0 {
+			return api.StatusErrorf(http.StatusNotImplemented, "Adding TLS identities to groups is currently not supported")
+		}
+
+		if identity.Groups == nil {
+			model.Identities[i].Groups = []string{}
+		}
+	}
+
+	sortAuthAccessModel(model)
+
+	return nil
+}
+
+// applyAuthAccessModel updates the database so that it matches the desired access model. Only the entries that differ
+// from the current access model are changed. The group memberships of an identity are only changed if the requestor
+// can edit it.
+func applyAuthAccessModel(ctx context.Context, tx *sql.Tx, current *api.AuthAccessModel, desired *api.AuthAccessModel, prune bool, canEditIdentity auth.PermissionChecker) (*authAccessModelChanges, error) {
+	changes := &authAccessModelChanges{}
+
+	currentGroups := make(map[string]api.AuthGroupsPost, len(current.Groups))
+	for _, group := range current.Groups {
+		currentGroups[group.Name] = group
+	}
+
+	desiredGroups := make(map[string]bool, len(desired.Groups))
+	for _, group := range desired.Groups {
+		desiredGroups[group.Name] = true
+	}
+
+	// Check that all referenced groups will exist.
+	groupExists := func(name string) bool {
+		_, ok := currentGroups[name]
+		return desiredGroups[name] || (ok && !prune)
+	}
+
+	for _, idpGroup := range desired.IdentityProviderGroups {
+		for _, groupName := range idpGroup.Groups {
+			if !groupExists(groupName) {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Identity provider group %q is mapped to group %q which doesn't exist", idpGroup.Name, groupName)
+			}
+		}
+	}
+
+	for _, identity := range desired.Identities {
+		for _, groupName := range identity.Groups {
+			if !groupExists(groupName) {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Identity %q is a member of group %q which doesn't exist", identity.AuthenticationMethod+"/"+identity.Identifier, groupName)
+			}
+		}
+	}
+
+	// Create and update groups.
+	for _, group := range desired.Groups {
+		currentGroup, ok := currentGroups[group.Name]
+		if ok && currentGroup.Description == group.Description && reflect.DeepEqual(currentGroup.Permissions, group.Permissions) {
+			continue
+		}
+
+		var groupID int64
+		var err error
+		if !ok {
+			groupID, err = dbCluster.CreateAuthGroup(ctx, tx, dbCluster.AuthGroup{
+				Name:        group.Name,
+				Description: group.Description,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			changes.groupsCreated = append(changes.groupsCreated, group.Name)
+		} else {
+			groupID, err = dbCluster.GetAuthGroupID(ctx, tx, group.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			err = dbCluster.UpdateAuthGroup(ctx, tx, group.Name, dbCluster.AuthGroup{
+				Name:        group.Name,
+				Description: group.Description,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			changes.groupsUpdated = append(changes.groupsUpdated, group.Name)
+		}
+
+		err = upsertPermissions(ctx, tx, int(groupID), group.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create and update identity provider groups.
+	currentIDPGroups := make(map[string]api.IdentityProviderGroup, len(current.IdentityProviderGroups))
+	for _, idpGroup := range current.IdentityProviderGroups {
+		currentIDPGroups[idpGroup.Name] = idpGroup
+	}
+
+	desiredIDPGroups := make(map[string]bool, len(desired.IdentityProviderGroups))
+	for _, idpGroup := range desired.IdentityProviderGroups {
+		desiredIDPGroups[idpGroup.Name] = true
+
+		currentIDPGroup, ok := currentIDPGroups[idpGroup.Name]
+		if ok && reflect.DeepEqual(currentIDPGroup.Groups, idpGroup.Groups) {
+			continue
+		}
+
+		var idpGroupID int64
+		var err error
+		if !ok {
+			idpGroupID, err = dbCluster.CreateIdentityProviderGroup(ctx, tx, dbCluster.IdentityProviderGroup{Name: idpGroup.Name})
+			if err != nil {
+				return nil, err
+			}
+
+			changes.idpGroupsCreated = append(changes.idpGroupsCreated, idpGroup.Name)
+		} else {
+			idpGroupID, err = dbCluster.GetIdentityProviderGroupID(ctx, tx, idpGroup.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			changes.idpGroupsUpdated = append(changes.idpGroupsUpdated, idpGroup.Name)
+		}
+
+		err = dbCluster.SetIdentityProviderGroupMapping(ctx, tx, int(idpGroupID), idpGroup.Groups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Update group memberships of identities.
+	currentIdentities := make(map[string]api.AuthAccessModelIdentity, len(current.Identities))
+	for _, identity := range current.Identities {
+		currentIdentities[identity.AuthenticationMethod+"/"+identity.Identifier] = identity
+	}
+
+	setIdentityGroups := func(identity api.AuthAccessModelIdentity) error {
+		if !canEditIdentity(entity.IdentityURL(identity.AuthenticationMethod, identity.Identifier)) {
+			return api.StatusErrorf(http.StatusForbidden, "Not authorized to edit identity %q", identity.AuthenticationMethod+"/"+identity.Identifier)
+		}
+
+		id, err := dbCluster.GetIdentity(ctx, tx, dbCluster.AuthMethod(identity.AuthenticationMethod), identity.Identifier)
+		if err != nil {
+			return fmt.Errorf("Failed to get identity %q: %w", identity.AuthenticationMethod+"/"+identity.Identifier, err)
+		}
+
+		err = dbCluster.SetIdentityAuthGroups(ctx, tx, id.ID, identity.Groups)
+		if err != nil {
+			return err
+		}
+
+		changes.identitiesUpdated = append(changes.identitiesUpdated, identity)
+		return nil
+	}
+
+	desiredIdentities := make(map[string]bool, len(desired.Identities))
+	for _, identity := range desired.Identities {
+		key := identity.AuthenticationMethod + "/" + identity.Identifier
+		desiredIdentities[key] = true
+
+		currentIdentity, ok := currentIdentities[key]
+		if (ok && reflect.DeepEqual(currentIdentity.Groups, identity.Groups)) || (!ok && len(identity.Groups) == 0) {
+			continue
+		}
+
+		err := setIdentityGroups(identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !prune {
+		return changes, nil
+	}
+
+	// Remove entries that are absent from the desired access model.
+	for _, identity := range current.Identities {
+		if desiredIdentities[identity.AuthenticationMethod+"/"+identity.Identifier] {
+			continue
+		}
+
+		identity.Groups = []string{}
+		err := setIdentityGroups(identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, idpGroup := range current.IdentityProviderGroups {
+		if desiredIDPGroups[idpGroup.Name] {
+			continue
+		}
+
+		err := dbCluster.DeleteIdentityProviderGroup(ctx, tx, idpGroup.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.idpGroupsDeleted = append(changes.idpGroupsDeleted, idpGroup.Name)
+	}
+
+	for _, group := range current.Groups {
+		if desiredGroups[group.Name] {
+			continue
+		}
+
+		err := dbCluster.DeleteAuthGroup(ctx, tx, group.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.groupsDeleted = append(changes.groupsDeleted, group.Name)
+	}
+
+	return changes, nil
+}
+
+// sortAuthAccessModel sorts all lists of the access model.
+func sortAuthAccessModel(model *api.AuthAccessModel) {
+	sort.Slice(model.Groups, func(i, j int) bool { return model.Groups[i].Name < model.Groups[j].Name })
+	for _, group := range model.Groups {
+		sortPermissions(group.Permissions)
+	}
+
+	sort.Slice(model.IdentityProviderGroups, func(i, j int) bool {
+		return model.IdentityProviderGroups[i].Name < model.IdentityProviderGroups[j].Name
+	})
+
+	for _, idpGroup := range model.IdentityProviderGroups {
+		sort.Strings(idpGroup.Groups)
+	}
+
+	sort.Slice(model.Identities, func(i, j int) bool {
+		if model.Identities[i].AuthenticationMethod != model.Identities[j].AuthenticationMethod {
+			return model.Identities[i].AuthenticationMethod < model.Identities[j].AuthenticationMethod
+		}
+
+		return model.Identities[i].Identifier < model.Identities[j].Identifier
+	})
+
+	for _, identity := range model.Identities {
+		sort.Strings(identity.Groups)
+	}
+}
+
+// sortPermissions sorts permissions by entity type, entity reference and entitlement.
+func sortPermissions(permissions []api.Permission) {
+	sort.Slice(permissions, func(i, j int) bool {
+		a := permissions[i]
+		b := permissions[j]
+		return strings.Join([]string{a.EntityType, a.EntityReference, a.Entitlement}, " ") < strings.Join([]string{b.EntityType, b.EntityReference, b.Entitlement}, " ")
+	})
+}
@@ -824,6 +824,36 @@ func validatePermissions(permissions []api.Permission) error {
 	return nil
 }
 
+// canonicalPermissions returns the given permissions with their entity references in canonical form, so that
+// they match the entity URLs used during authorization checks.
+func canonicalPermissions(permissions []api.Permission) ([]api.Permission, error) {
+	result := make([]api.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		u, err := url.Parse(permission.EntityReference)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Failed to parse permission entity reference %q: %w", permission.EntityReference, err)
+		}
+
+		entityType, projectName, location, pathArguments, err := entity.ParseURL(*u)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Failed to parse permission entity reference %q: %w", permission.EntityReference, err)
+		}
+
+		entityURL, err := entityType.URL(projectName, location, pathArguments...)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Failed to parse permission entity reference %q: %w", permission.EntityReference, err)
+		}
+
+		result = append(result, api.Permission{
+			EntityType:      string(entityType),
+			EntityReference: entityURL.String(),
+			Entitlement:     permission.Entitlement,
+		})
+	}
+
+	return result, nil
+}
+
 // upsertPermissions converts the given slice of api.Permission into a slice of cluster.Permission by resolving
 // the URLs of each permission to an entity ID. Then sets those permissions against the group with the given ID.
 func upsertPermissions(ctx context.Context, tx *sql.Tx, groupID int, permissions []api.Permission) error {
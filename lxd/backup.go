@@ -194,7 +194,14 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), nil)
+	// Freeze the guest file systems of running virtual machines if enabled while a temporary snapshot is taken,
+	// the backup is then exported from that snapshot.
+	var quiesce func() (func() error, error)
+	if sourceInst.Type() == instancetype.VM && shared.IsTrue(sourceInst.ExpandedConfig()["snapshots.quiesce"]) && sourceInst.IsRunning() {
+		quiesce = sourceInst.(instance.VM).Quiesce
+	}
+
+	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), quiesce, nil)
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
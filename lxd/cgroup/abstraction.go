@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/canonical/lxd/lxd/pressure"
 	"github.com/canonical/lxd/shared"
 )
 
@@ -1062,6 +1063,12 @@ func (cg *CGroup) GetIOStats() (map[string]*IOStats, error) {
 					ioStats.ReadsCompleted = statValue
 				case "wios":
 					ioStats.WritesCompleted = statValue
+				case "dbytes":
+					ioStats.DiscardedBytes = statValue
+				case "dios":
+					ioStats.DiscardsCompleted = statValue
+				case "cost.wait":
+					ioStats.ThrottledTime = &statValue
 				}
 			}
 
@@ -1073,3 +1080,23 @@ func (cg *CGroup) GetIOStats() (map[string]*IOStats, error) {
 
 	return nil, ErrUnknownVersion
 }
+
+// GetPressure returns the pressure stall information of the "cpu", "memory" or "io" resource.
+func (cg *CGroup) GetPressure(resource string) (*pressure.Stats, error) {
+	version := cgControllers["pressure"]
+	if version != V2 {
+		return nil, ErrControllerMissing
+	}
+
+	val, err := cg.rw.Get(version, resource, fmt.Sprintf("%s.pressure", resource))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting %s.pressure: %w", resource, err)
+	}
+
+	stats, err := pressure.Parse(val)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing %s.pressure: %w", resource, err)
+	}
+
+	return stats, nil
+}
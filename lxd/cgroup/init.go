@@ -117,6 +117,9 @@ const (
 
 	// Pids resource control.
 	Pids
+
+	// Pressure stall information.
+	Pressure
 )
 
 // SupportsVersion indicates whether or not a given cgroup resource is
@@ -228,6 +231,9 @@ func (info *Info) SupportsVersion(resource Resource) (Backend, bool) {
 		}
 
 		return Unavailable, false
+	case Pressure:
+		val, ok := cgControllers["pressure"]
+		return val, ok
 	}
 
 	return Unavailable, false
@@ -497,5 +503,10 @@ func Init() {
 
 		// With Cgroup2 freezer is built-in.
 		cgControllers["freezer"] = V2
+
+		// Pressure stall information is only available when enabled in the kernel.
+		if shared.PathExists("/proc/pressure/cpu") {
+			cgControllers["pressure"] = V2
+		}
 	}
 }
@@ -24,10 +24,16 @@ type ReadWriter interface {
 
 // IOStats represent IO stats.
 type IOStats struct {
-	ReadBytes       uint64
-	ReadsCompleted  uint64
-	WrittenBytes    uint64
-	WritesCompleted uint64
+	ReadBytes         uint64
+	ReadsCompleted    uint64
+	WrittenBytes      uint64
+	WritesCompleted   uint64
+	DiscardedBytes    uint64
+	DiscardsCompleted uint64
+
+	// Time in microseconds that IO was delayed by the IO cost controller. The cost.wait field of io.stat is only
+	// reported when the io.cost controller is enabled for the device, so this is nil otherwise.
+	ThrottledTime *uint64
 }
 
 // CPUStats represent CPU stats.
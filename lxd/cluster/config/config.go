@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/canonical/lxd/lxd/config"
 	"github.com/canonical/lxd/lxd/db"
 	scriptletLoad "github.com/canonical/lxd/lxd/scriptlet/load"
+	"github.com/canonical/lxd/lxd/webhooks"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/validate"
 )
@@ -53,11 +55,21 @@ func (c *Config) MetricsAuthentication() bool {
 	return c.m.GetBool("core.metrics_authentication")
 }
 
+// MetricsStorageInterval returns the refresh interval of the storage metrics.
+func (c *Config) MetricsStorageInterval() time.Duration {
+	return time.Duration(c.m.GetInt64("core.metrics_storage_interval")) * time.Second
+}
+
 // BGPASN returns the BGP ASN setting.
 func (c *Config) BGPASN() int64 {
 	return c.m.GetInt64("core.bgp_asn")
 }
 
+// ConfigHistoryRetention returns the number of configuration revisions to keep for each entity.
+func (c *Config) ConfigHistoryRetention() int64 {
+	return c.m.GetInt64("core.config_history_retention")
+}
+
 // HTTPSAllowedHeaders returns the relevant CORS setting.
 func (c *Config) HTTPSAllowedHeaders() string {
 	return c.m.GetString("core.https_allowed_headers")
@@ -256,6 +268,58 @@ func (c *Config) ClusterHealingThreshold() time.Duration {
 	return healingThreshold
 }
 
+// ClusterDatabaseBackup returns the schedule of the automatic global database backups
+// and the number of such backups to keep.
+func (c *Config) ClusterDatabaseBackup() (schedule string, retention int64) {
+	return c.m.GetString("cluster.database_backup.schedule"), c.m.GetInt64("cluster.database_backup.retention")
+}
+
+// WarningsStoragePoolUsageThreshold returns the percentage of used space above which a storage pool warning is
+// raised, 0 if disabled.
+func (c *Config) WarningsStoragePoolUsageThreshold() int64 {
+	return c.m.GetInt64("warnings.storage_pool_usage_threshold")
+}
+
+// WarningsProjectLimitsThreshold returns the percentage of a project limit above which a project warning is raised,
+// 0 if disabled.
+func (c *Config) WarningsProjectLimitsThreshold() int64 {
+	return c.m.GetInt64("warnings.project_limits_threshold")
+}
+
+// WarningsCertificateExpiryThreshold returns how long before its expiry a certificate warning is raised, 0 if
+// disabled.
+func (c *Config) WarningsCertificateExpiryThreshold() time.Duration {
+	return time.Duration(c.m.GetInt64("warnings.certificate_expiry_threshold")) * 24 * time.Hour
+}
+
+// WarningsImageRefreshThreshold returns how long an image can fail to refresh before a warning is raised, 0 if
+// disabled.
+func (c *Config) WarningsImageRefreshThreshold() time.Duration {
+	return time.Duration(c.m.GetInt64("warnings.image_refresh_threshold")) * 24 * time.Hour
+}
+
+// Webhooks returns the configuration of the enabled webhooks, indexed by name.
+func (c *Config) Webhooks() map[string]webhooks.Config {
+	configs := map[string]webhooks.Config{}
+
+	for _, name := range c.m.GetWildcardNames("webhooks.*.url") {
+		prefix := "webhooks." + name + "."
+
+		configs[name] = webhooks.Config{
+			URL:              c.m.GetString(prefix + "url"),
+			Types:            shared.SplitNTrimSpace(c.m.GetString(prefix+"types"), ",", -1, true),
+			Projects:         shared.SplitNTrimSpace(c.m.GetString(prefix+"projects"), ",", -1, true),
+			LifecycleActions: shared.SplitNTrimSpace(c.m.GetString(prefix+"lifecycle.actions"), ",", -1, true),
+			WarningSeverity:  c.m.GetString(prefix + "warnings.severity"),
+			Template:         c.m.GetString(prefix + "template"),
+			Secret:           c.m.GetString(prefix + "secret"),
+			Retries:          int(c.m.GetInt64(prefix + "retries")),
+		}
+	}
+
+	return configs
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -368,6 +432,26 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Threshold when to evacuate an offline cluster member
 	"cluster.healing_threshold": {Type: config.Int64, Default: "0"},
 
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.database_backup.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic backups.
+	// Backups are taken by the database leader and stored in its `backups/database` directory.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: empty
+	//  shortdesc: Schedule for automatic global database backups
+	"cluster.database_backup.schedule": {Validator: validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"}))},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.database_backup.retention)
+	// Specify the number of automatic global database backups to keep on a member.
+	// Older backups are deleted after a new one is taken.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `7`
+	//  shortdesc: Number of automatic global database backups to keep
+	"cluster.database_backup.retention": {Type: config.Int64, Default: "7", Validator: validate.Optional(validate.IsInRange(1, 1000))},
+
 	// lxdmeta:generate(entities=server; group=cluster; key=cluster.join_token_expiry)
 	//
 	// ---
@@ -406,6 +490,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.metrics_storage_interval)
+	// Specify the interval in seconds at which the storage pool, volume and bucket metrics are refreshed.
+	// Computing these metrics can be slow on some storage drivers, so they are cached in between.
+	// To disable the storage metrics, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `300`
+	//  shortdesc: Refresh interval of the storage metrics
+	"core.metrics_storage_interval": {Type: config.Int64, Default: "300", Validator: validate.IsUint32},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.bgp_asn)
 	//
 	// ---
@@ -414,6 +509,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: BGP Autonomous System Number for the local server
 	"core.bgp_asn": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 4294967294))},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.config_history_retention)
+	// Specify the number of configuration revisions to keep for each instance, profile and project.
+	// Older revisions are deleted when a new one is recorded.
+	// To disable recording configuration revisions, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `20`
+	//  shortdesc: Number of configuration revisions to keep
+	"core.config_history_retention": {Type: config.Int64, Default: "20", Validator: validate.IsUint32},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.https_allowed_headers)
 	//
 	// ---
@@ -751,6 +857,144 @@ var ConfigSchema = config.Schema{
 	//  defaultdesc: Content of `/etc/ovn/key_host` if present
 	//  shortdesc: OVN SSL client key
 	"network.ovn.client_key": {Default: ""},
+
+	// lxdmeta:generate(entities=server; group=warnings; key=warnings.storage_pool_usage_threshold)
+	// Specify the percentage of used space above which a warning is raised for a storage pool.
+	// To disable these warnings, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `90`
+	//  shortdesc: Storage pool usage that triggers a warning
+	"warnings.storage_pool_usage_threshold": {Type: config.Int64, Default: "90", Validator: validate.IsInRange(0, 100)},
+
+	// lxdmeta:generate(entities=server; group=warnings; key=warnings.project_limits_threshold)
+	// Specify the percentage of any of the `limits.*` options of a project above which a warning is raised for the project.
+	// To disable these warnings, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `90`
+	//  shortdesc: Project limit usage that triggers a warning
+	"warnings.project_limits_threshold": {Type: config.Int64, Default: "90", Validator: validate.IsInRange(0, 100)},
+
+	// lxdmeta:generate(entities=server; group=warnings; key=warnings.certificate_expiry_threshold)
+	// Specify the number of days before the expiry of the server certificate or of a trusted certificate at which a warning is raised.
+	// To disable these warnings, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `30`
+	//  shortdesc: Days before certificate expiry that trigger a warning
+	"warnings.certificate_expiry_threshold": {Type: config.Int64, Default: "30", Validator: validate.IsUint32},
+
+	// lxdmeta:generate(entities=server; group=warnings; key=warnings.image_refresh_threshold)
+	// Specify the number of days an image that is automatically updated can fail to refresh before a warning is raised.
+	// To disable these warnings, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `7`
+	//  shortdesc: Days of failed image refreshes that trigger a warning
+	"warnings.image_refresh_threshold": {Type: config.Int64, Default: "7", Validator: validate.IsUint32},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.url)
+	// LXD sends a `POST` request with the notification to this URL.
+	// The webhook is enabled once this option is set.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of the webhook
+	"webhooks.*.url": {Validator: validate.Optional(validate.IsRequestURL)},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.types)
+	// Specify a comma-separated list of notification types to send to the webhook.
+	// The types can be `lifecycle` (lifecycle events) and `warning` (new warnings).
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `lifecycle,warning`
+	//  shortdesc: Types of notifications to send to the webhook
+	"webhooks.*.types": {Default: "lifecycle,warning", Validator: validate.IsListOf(validate.IsOneOf(webhooks.TypeLifecycle, webhooks.TypeWarning))},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.projects)
+	// Specify a comma-separated list of projects.
+	// If set, only the notifications about these projects are sent to the webhook.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Projects whose notifications are sent to the webhook
+	"webhooks.*.projects": {Validator: validate.Optional(validate.IsListOf(validate.IsNotEmpty))},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.lifecycle.actions)
+	// Specify a comma-separated list of lifecycle actions, for example, `instance-started,instance-stopped`.
+	// The actions can contain shell patterns like `instance-*`.
+	// If set, only the lifecycle events with one of these actions are sent to the webhook.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Lifecycle actions that are sent to the webhook
+	"webhooks.*.lifecycle.actions": {Validator: validate.Optional(validate.IsListOf(webhookActionValidator))},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.warnings.severity)
+	// Only the warnings with this severity or a higher one are sent to the webhook.
+	// Possible values are `low`, `moderate` and `high`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `low`
+	//  shortdesc: Minimum severity of the warnings sent to the webhook
+	"webhooks.*.warnings.severity": {Default: "low", Validator: validate.IsOneOf(webhooks.Severities...)},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.template)
+	// Specify a Go template that renders the JSON payload sent to the webhook.
+	// By default, the notification itself is sent.
+	// See {ref}`webhooks-payload` for the available fields.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Template of the payload sent to the webhook
+	"webhooks.*.template": {Validator: webhookTemplateValidator},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.secret)
+	// If set, LXD signs the payload with HMAC-SHA256 using this secret and sends the signature in the `X-LXD-Signature-256` header.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Secret used to sign the payload sent to the webhook
+	"webhooks.*.secret": {Hidden: true},
+
+	// lxdmeta:generate(entities=server; group=webhooks; key=webhooks.<name>.retries)
+	// Specify how many times the delivery of a notification is retried, with an exponential back-off, when the webhook can't be reached or returns a server error.
+	// Notifications that can't be delivered are dropped and counted in the `lxd_webhook_dead_letters_total` metric.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `5`
+	//  shortdesc: Number of delivery retries
+	"webhooks.*.retries": {Type: config.Int64, Default: "5", Validator: validate.IsInRange(0, 20)},
+}
+
+func webhookActionValidator(value string) error {
+	_, err := path.Match(value, "")
+	if err != nil {
+		return fmt.Errorf("Invalid action pattern %q: %w", value, err)
+	}
+
+	return nil
+}
+
+func webhookTemplateValidator(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	_, err := webhooks.ParseTemplate(value)
+	if err != nil {
+		return fmt.Errorf("Invalid template: %w", err)
+	}
+
+	return nil
 }
 
 func expiryValidator(value string) error {
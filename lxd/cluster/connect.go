@@ -67,6 +67,11 @@ func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.Ce
 
 			req.Header.Add(request.HeaderForwardedAddress, r.RemoteAddr)
 
+			identityToken, hasIdentityToken := ctx.Value(request.CtxIdentityToken).(string)
+			if hasIdentityToken {
+				req.Header.Add(request.HeaderForwardedIdentityToken, identityToken)
+			}
+
 			identityProviderGroupsAny := ctx.Value(request.CtxIdentityProviderGroups)
 			if ok {
 				identityProviderGroups, ok := identityProviderGroupsAny.([]string)
@@ -44,7 +44,7 @@ func (m *Map) Change(changes map[string]any) (map[string]string, error) {
 
 	errors := ErrorList{}
 	for name, change := range changes {
-		key, ok := m.schema[name]
+		key, ok := m.schema.getKey(name)
 
 		// When a hidden value is set to "true" in the change set, it
 		// means "keep it unchanged", so we replace it with our current
@@ -74,12 +74,33 @@ func (m *Map) Change(changes map[string]any) (map[string]string, error) {
 
 	// Any key not explicitly set, is considered unset.
 	for name, key := range m.schema {
+		if isWildcardKey(name) {
+			continue
+		}
+
 		_, ok := values[name]
 		if !ok {
 			values[name] = key.Default
 		}
 	}
 
+	// Same for the keys matching a wildcard key.
+	for name := range m.values {
+		if shared.IsUserConfig(name) {
+			continue
+		}
+
+		_, ok := m.schema[name]
+		if ok {
+			continue
+		}
+
+		_, ok = values[name]
+		if !ok {
+			values[name] = ""
+		}
+	}
+
 	names, err := m.update(values)
 
 	changed := map[string]string{}
@@ -99,7 +120,7 @@ func (m *Map) Dump() map[string]any {
 	values := map[string]any{}
 
 	for name, value := range m.values {
-		key, ok := m.schema[name]
+		key, ok := m.schema.getKey(name)
 		if ok {
 			// Schema key
 			value := m.GetRaw(name)
@@ -135,6 +156,34 @@ func (m *Map) GetRaw(name string) string {
 	return value
 }
 
+// GetWildcardNames returns the values of the "*" segment of the keys that are set and match the given wildcard
+// key, sorted. For example, it returns the names of the configured webhooks for "webhooks.*.url".
+func (m *Map) GetWildcardNames(pattern string) []string {
+	names := []string{}
+	index := -1
+	for i, segment := range strings.Split(pattern, ".") {
+		if segment == "*" {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return names
+	}
+
+	for name := range m.values {
+		segments := strings.Split(name, ".")
+		if matchWildcardKey(pattern, segments) && !shared.ValueInSlice(segments[index], names) {
+			names = append(names, segments[index])
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 // GetString returns the value of the given key, which must be of type String.
 func (m *Map) GetString(name string) string {
 	if !shared.IsUserConfig(name) {
@@ -227,7 +276,7 @@ func (m *Map) set(name string, value string, initial bool) (bool, error) {
 		return true, nil
 	}
 
-	key, ok := m.schema[name]
+	key, ok := m.schema.getKey(name)
 	if !ok {
 		return false, fmt.Errorf("unknown key")
 	}
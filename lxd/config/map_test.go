@@ -332,6 +332,40 @@ func TestMap_GettersPanic(t *testing.T) {
 	assert.Panics(t, func() { m.GetInt64("foo") })
 }
 
+// Keys with a "*" segment match any name for that segment.
+func TestMap_WildcardKeys(t *testing.T) {
+	schema := config.Schema{
+		"foo":       {},
+		"egg.*.url": {},
+		"egg.*.num": {Type: config.Int64, Default: "3"},
+	}
+
+	values := map[string]string{
+		"egg.a.url": "http://a",
+		"egg.b.url": "http://b",
+		"egg.b.num": "5",
+	}
+
+	m, err := config.Load(schema, values)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, m.GetWildcardNames("egg.*.url"))
+	assert.Equal(t, "http://a", m.GetString("egg.a.url"))
+	assert.Equal(t, int64(3), m.GetInt64("egg.a.num"))
+	assert.Equal(t, int64(5), m.GetInt64("egg.b.num"))
+	assert.Equal(t, int64(3), m.GetInt64("egg.c.num"))
+	assert.Panics(t, func() { m.GetRaw("egg.a.b.url") })
+
+	// Keys matching a wildcard key that aren't part of a change are unset.
+	changed, err := m.Change(map[string]any{"foo": "x", "egg.a.url": "http://a", "egg.c.url": "http://c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "x", "egg.b.url": "", "egg.b.num": "3", "egg.c.url": "http://c"}, changed)
+	assert.Equal(t, []string{"a", "c"}, m.GetWildcardNames("egg.*.url"))
+
+	_, err = m.Change(map[string]any{"egg.*.url": "http://d"})
+	assert.EqualError(t, err, "cannot set 'egg.*.url' to 'http://d': unknown key")
+}
+
 // A Key setter that always fail.
 func failingSetter(string) (string, error) {
 	return "", fmt.Errorf("boom")
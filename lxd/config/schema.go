@@ -37,9 +37,29 @@ func (s Schema) Defaults() map[string]any {
 	return values
 }
 
+// Get the Key associated with the given name.
+//
+// A "*" segment in the name of a schema key matches any non-empty segment, so
+// that a key like "webhooks.*.url" defines the "url" option of any webhook.
+func (s Schema) getKey(name string) (Key, bool) {
+	key, ok := s[name]
+	if ok {
+		return key, !isWildcardKey(name)
+	}
+
+	segments := strings.Split(name, ".")
+	for pattern, key := range s {
+		if isWildcardKey(pattern) && matchWildcardKey(pattern, segments) {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
 // Get the Key associated with the given name, or panic.
 func (s Schema) mustGetKey(name string) Key {
-	key, ok := s[name]
+	key, ok := s.getKey(name)
 	if !ok {
 		panic(fmt.Sprintf("attempt to access unknown key '%s'", name))
 	}
@@ -47,6 +67,35 @@ func (s Schema) mustGetKey(name string) Key {
 	return key
 }
 
+// isWildcardKey returns whether the given schema key name has a "*" segment.
+func isWildcardKey(name string) bool {
+	return shared.ValueInSlice("*", strings.Split(name, "."))
+}
+
+// matchWildcardKey returns whether the segments of a key name match the given schema key name.
+func matchWildcardKey(pattern string, segments []string) bool {
+	patternSegments := strings.Split(pattern, ".")
+	if len(patternSegments) != len(segments) {
+		return false
+	}
+
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			if segments[i] == "" || segments[i] == "*" {
+				return false
+			}
+
+			continue
+		}
+
+		if segment != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Assert that the Key with the given name as the given type. Panic if no Key
 // with such name exists, or if it does not match the tiven type.
 func (s Schema) assertKeyType(name string, code Type) {
@@ -0,0 +1,786 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/placement"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+var instanceHistoryCmd = APIEndpoint{
+	Name: "instanceHistory",
+	Path: "instances/{name}/history",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerHistory", Path: "containers/{name}/history"},
+		{Name: "vmHistory", Path: "virtual-machines/{name}/history"},
+	},
+
+	Get:  APIEndpointAction{Handler: instanceHistoryGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceHistoryPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var profileHistoryCmd = APIEndpoint{
+	Path: "profiles/{name}/history",
+
+	Get:  APIEndpointAction{Handler: profileHistoryGet, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: profileHistoryPost, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanEdit, "name")},
+}
+
+var projectHistoryCmd = APIEndpoint{
+	Path: "projects/{name}/history",
+
+	Get:  APIEndpointAction{Handler: projectHistoryGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: projectHistoryPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit, "name")},
+}
+
+// configHistoryRecord records a configuration revision of an entity if its writable state changed.
+// If the entity has no revisions yet, its state before the change is first recorded as a baseline revision without
+// changes, so that the first change can be rolled back too.
+// Failures are only logged as the change itself was already applied.
+func configHistoryRecord(s *state.State, r *http.Request, entityType entity.Type, entityID int, before any, after any) {
+	retention := s.GlobalConfig.ConfigHistoryRetention()
+	if retention == 0 {
+		return
+	}
+
+	l := logger.AddContext(logger.Ctx{"entityType": entityType, "entityID": entityID})
+
+	allChanges, err := util.ConfigChanges(before, after)
+	if err != nil {
+		l.Warn("Failed computing configuration changes", logger.Ctx{"err": err})
+		return
+	}
+
+	// Volatile keys reflect the state of instances rather than their configuration.
+	changes := make([]api.ConfigRevisionChange, 0, len(allChanges))
+	for _, change := range allChanges {
+		if !strings.HasPrefix(change.Key, "config."+instancetype.ConfigVolatilePrefix) {
+			changes = append(changes, change)
+		}
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	requestor, err := json.Marshal(request.CreateRequestor(r))
+	if err != nil {
+		l.Warn("Failed encoding configuration revision requestor", logger.Ctx{"err": err})
+		return
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		l.Warn("Failed encoding configuration revision changes", logger.Ctx{"err": err})
+		return
+	}
+
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		l.Warn("Failed encoding configuration revision state", logger.Ctx{"err": err})
+		return
+	}
+
+	stateJSON, err := json.Marshal(after)
+	if err != nil {
+		l.Warn("Failed encoding configuration revision state", logger.Ctx{"err": err})
+		return
+	}
+
+	revision := cluster.ConfigRevision{
+		EntityType:   cluster.EntityType(entityType),
+		EntityID:     entityID,
+		CreationDate: time.Now().UTC(),
+		Requestor:    string(requestor),
+		Changes:      string(changesJSON),
+		State:        string(stateJSON),
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		existing, err := cluster.GetConfigRevisions(ctx, tx.Tx(), entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		if len(existing) == 0 {
+			baseline := cluster.ConfigRevision{
+				EntityType:   revision.EntityType,
+				EntityID:     revision.EntityID,
+				CreationDate: revision.CreationDate,
+				Changes:      "[]",
+				State:        string(beforeJSON),
+			}
+
+			_, err = cluster.CreateConfigRevision(ctx, tx.Tx(), baseline, retention)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = cluster.CreateConfigRevision(ctx, tx.Tx(), revision, retention)
+		return err
+	})
+	if err != nil {
+		l.Warn("Failed recording configuration revision", logger.Ctx{"err": err})
+	}
+}
+
+// configHistoryResponse returns the configuration revisions of an entity.
+func configHistoryResponse(s *state.State, r *http.Request, entityType entity.Type, entityID int) response.Response {
+	revisions := []api.ConfigRevision{}
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbRevisions, err := cluster.GetConfigRevisions(ctx, tx.Tx(), entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		for _, dbRevision := range dbRevisions {
+			revision, err := dbRevision.ToAPI()
+			if err != nil {
+				return err
+			}
+
+			revisions = append(revisions, *revision)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, revisions)
+}
+
+// configHistoryState decodes the writable state recorded by a configuration revision of an entity into state.
+func configHistoryState(ctx context.Context, s *state.State, entityType entity.Type, entityID int, revision int64, state any) error {
+	var dbRevision *cluster.ConfigRevision
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		dbRevision, err = cluster.GetConfigRevision(ctx, tx.Tx(), entityType, entityID, revision)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal([]byte(dbRevision.State), state)
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal state of configuration revision %d: %w", revision, err)
+	}
+
+	return nil
+}
+
+// instanceConfigState returns the writable state of an instance as recorded by its configuration revisions.
+func instanceConfigState(inst instance.Instance) api.InstancePut {
+	profiles := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		profiles = append(profiles, profile.Name)
+	}
+
+	architecture, _ := osarch.ArchitectureName(inst.Architecture())
+
+	return api.InstancePut{
+		Architecture: architecture,
+		Config:       util.CopyConfig(inst.LocalConfig()),
+		Devices:      inst.LocalDevices().CloneNative(),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     profiles,
+		Description:  inst.Description(),
+	}
+}
+
+// instanceUpdateWithHistory updates an instance and records the resulting configuration revision.
+func instanceUpdateWithHistory(s *state.State, r *http.Request, inst instance.Instance, args db.InstanceArgs) error {
+	before := instanceConfigState(inst)
+
+	err := inst.Update(args, true)
+	if err != nil {
+		return err
+	}
+
+	configHistoryRecord(s, r, entity.TypeInstance, inst.ID(), before, instanceConfigState(inst))
+
+	return nil
+}
+
+// swagger:operation GET /1.0/instances/{name}/history instances instance_history_get
+//
+//	Get the configuration history
+//
+//	Gets the recorded configuration revisions of the instance, oldest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	var instID int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		instID, err = cluster.GetInstanceID(ctx, tx.Tx(), projectName, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryResponse(s, r, entity.TypeInstance, int(instID))
+}
+
+// swagger:operation POST /1.0/instances/{name}/history instances instance_history_post
+//
+//	Roll back the configuration
+//
+//	Restores the configuration recorded by a configuration revision of the instance.
+//	Volatile configuration keys are left unchanged.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: revision
+//	    description: Revision to roll back to
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ConfigHistoryPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceHistoryPost(d *Daemon, r *http.Request) response.Response {
+	// Don't mess with instance while in setup mode.
+	<-d.waitReady.Done()
+
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	req := api.ConfigHistoryPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	unlock, err := instanceOperationLock(s.ShutdownCtx, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer unlock()
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	put := api.InstancePut{}
+	err = configHistoryState(r.Context(), s, entity.TypeInstance, inst.ID(), req.Revision, &put)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Keep the current volatile keys as they reflect the state of the instance rather than its configuration.
+	config := map[string]string{}
+	for key, value := range put.Config {
+		if !strings.HasPrefix(key, instancetype.ConfigVolatilePrefix) {
+			config[key] = value
+		}
+	}
+
+	for key, value := range inst.LocalConfig() {
+		if strings.HasPrefix(key, instancetype.ConfigVolatilePrefix) {
+			config[key] = value
+		}
+	}
+
+	put.Config = config
+
+	// Check access to the secrets that the revision references again.
+	err = secretsCheckAccess(s, r, projectName, secrets.AddedReferences(inst.LocalConfig(), inst.LocalDevices(), put.Config, deviceConfig.NewDevices(put.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	architecture, err := osarch.ArchitectureId(put.Architecture)
+	if err != nil {
+		architecture = inst.Architecture()
+	}
+
+	// Check project limits.
+	apiProfiles := make([]api.Profile, 0, len(put.Profiles))
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		profiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, put.Profiles)
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			apiProfiles = append(apiProfiles, *apiProfile)
+		}
+
+		err = placement.ValidateInstanceConfig(ctx, tx, projectName, instancetype.ExpandInstanceConfig(nil, put.Config, apiProfiles))
+		if err != nil {
+			return err
+		}
+
+		return project.AllowInstanceUpdate(s.GlobalConfig, tx, projectName, name, put, inst.LocalConfig())
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the requestor may forward the guest logs of the instance to Loki again.
+	err = instanceLogsLokiCheckGuest(s, r, projectName, name, inst.ExpandedConfig(), instancetype.ExpandInstanceConfig(nil, put.Config, apiProfiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	args := db.InstanceArgs{
+		Architecture: architecture,
+		Config:       put.Config,
+		Description:  put.Description,
+		Devices:      deviceConfig.NewDevices(put.Devices),
+		Ephemeral:    put.Ephemeral,
+		Profiles:     apiProfiles,
+		Project:      projectName,
+	}
+
+	err = instanceUpdateWithHistory(s, r, inst, args)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/profiles/{name}/history profiles profile_history_get
+//
+//	Get the configuration history
+//
+//	Gets the recorded configuration revisions of the profile, oldest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var profileID int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		profileID, err = cluster.GetProfileID(ctx, tx.Tx(), p.Name, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryResponse(s, r, entity.TypeProfile, int(profileID))
+}
+
+// swagger:operation POST /1.0/profiles/{name}/history profiles profile_history_post
+//
+//	Roll back the configuration
+//
+//	Restores the configuration recorded by a configuration revision of the profile.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: revision
+//	    description: Revision to roll back to
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ConfigHistoryPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileHistoryPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ConfigHistoryPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var id int64
+	var profile *api.Profile
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		current, err := cluster.GetProfile(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return fmt.Errorf("Failed to retrieve profile %q: %w", name, err)
+		}
+
+		profile, err = current.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		id = int64(current.ID)
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	put := api.ProfilePut{}
+	err = configHistoryState(r.Context(), s, entity.TypeProfile, int(id), req.Revision, &put)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check access to the secrets that the revision references again.
+	err = secretsCheckAccess(s, r, p.Name, secrets.AddedReferences(profile.Config, deviceConfig.NewDevices(profile.Devices), put.Config, deviceConfig.NewDevices(put.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the requestor may forward the guest logs of the instances using the profile to Loki again.
+	err = profileLogsLokiCheckGuest(s, r, p.Name, name, profile.Config, put.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = profileUpdate(s, r, *p, name, id, profile, put)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/projects/{name}/history projects project_history_get
+//
+//	Get the configuration history
+//
+//	Gets the recorded configuration revisions of the project, oldest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var projectID int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectID, err = cluster.GetProjectID(ctx, tx.Tx(), name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryResponse(s, r, entity.TypeProject, int(projectID))
+}
+
+// swagger:operation POST /1.0/projects/{name}/history projects project_history_post
+//
+//	Roll back the configuration
+//
+//	Restores the configuration recorded by a configuration revision of the project.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: revision
+//	    description: Revision to roll back to
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ConfigHistoryPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectHistoryPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ConfigHistoryPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var projectID int64
+	var apiProject *api.Project
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), name)
+		if err != nil {
+			return err
+		}
+
+		projectID = int64(dbProject.ID)
+
+		apiProject, err = dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		apiProject.UsedBy, err = projectUsedBy(ctx, tx, dbProject)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	put := api.ProjectPut{}
+	err = configHistoryState(r.Context(), s, entity.TypeProject, int(projectID), req.Revision, &put)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(apiProject.Name, lifecycle.ProjectUpdated.Event(apiProject.Name, requestor, nil))
+
+	return projectChange(s, r, apiProject, put)
+}
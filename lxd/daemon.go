@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	dqliteClient "github.com/canonical/go-dqlite/client"
@@ -67,6 +68,7 @@ import (
 	"github.com/canonical/lxd/lxd/ucred"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/lxd/webhooks"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
@@ -149,7 +151,10 @@ type Daemon struct {
 	// Server's UUID from file.
 	serverUUID string
 
-	lokiClient *loki.Client
+	// Loki client, read without locking by the instance log forwarders.
+	lokiClient atomic.Pointer[loki.Client]
+
+	webhookClient *webhooks.Client
 
 	// HTTP-01 challenge provider for ACME
 	http01Provider acme.HTTP01Provider
@@ -349,6 +354,25 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 		return false, "", "", nil, fmt.Errorf("Bad/missing TLS on network query")
 	}
 
+	// Validate identity bearer tokens.
+	secret, ok := identity.BearerToken(r)
+	if ok {
+		token, err := d.identityCache.GetTokenBySecret(secret)
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed identity token authentication: %w", err)
+		}
+
+		err = token.Validate(r.RemoteAddr, time.Now())
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed identity token authentication: %w", err)
+		}
+
+		d.handleIdentityTokenUse(token.SecretHash)
+		request.SetCtxValue(r, request.CtxIdentityToken, token.Name)
+
+		return true, token.Identifier, token.AuthenticationMethod, nil, nil
+	}
+
 	if d.oidcVerifier != nil && d.oidcVerifier.IsRequest(r) {
 		result, err := d.oidcVerifier.Auth(d.shutdownCtx, w, r)
 		if err != nil {
@@ -387,6 +411,24 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 	return false, "", "", nil, nil
 }
 
+// handleIdentityTokenUse records the last used date of the identity token with the given secret hash in the
+// database. To limit the number of database writes, this is done at most once per minute for each token.
+func (d *Daemon) handleIdentityTokenUse(secretHash string) {
+	now := time.Now().UTC()
+	if !d.identityCache.MarkTokenUsed(secretHash, now) {
+		return
+	}
+
+	go func() {
+		err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.UpdateIdentityTokenLastUsedDate(ctx, tx.Tx(), secretHash, now)
+		})
+		if err != nil {
+			logger.Warn("Failed recording identity token use", logger.Ctx{"err": err})
+		}
+	}()
+}
+
 // handleOIDCAuthenticationResult checks the identity cache for the OIDC identity by their email address. If no identity
 // is found, an identity is added with that email. If an identity is found but the OIDC subject is different to the
 // expected value, the identity is updated with the new subject.
@@ -606,6 +648,7 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 				ctx = context.WithValue(ctx, request.CtxForwardedAddress, r.Header.Get(request.HeaderForwardedAddress))
 				ctx = context.WithValue(ctx, request.CtxForwardedUsername, r.Header.Get(request.HeaderForwardedUsername))
 				ctx = context.WithValue(ctx, request.CtxForwardedProtocol, r.Header.Get(request.HeaderForwardedProtocol))
+				ctx = context.WithValue(ctx, request.CtxForwardedIdentityToken, r.Header.Get(request.HeaderForwardedIdentityToken))
 				forwardedIdentityProviderGroupsJSON := r.Header.Get(request.HeaderForwardedIdentityProviderGroups)
 				if forwardedIdentityProviderGroupsJSON != "" {
 					var forwardedIdentityProviderGroups []string
@@ -795,8 +838,10 @@ func (d *Daemon) Init() error {
 
 func (d *Daemon) setupLoki(URL string, cert string, key string, caCert string, instanceName string, logLevel string, labels []string, types []string) error {
 	// Stop any existing loki client.
-	if d.lokiClient != nil {
-		d.lokiClient.Stop()
+	lokiClient := d.lokiClient.Swap(nil)
+	if lokiClient != nil {
+		d.internalListener.RemoveHandler("loki")
+		lokiClient.Stop()
 	}
 
 	// Check basic requirements for starting a new client.
@@ -816,10 +861,11 @@ func (d *Daemon) setupLoki(URL string, cert string, key string, caCert string, i
 	}
 
 	// Start a new client.
-	d.lokiClient = loki.NewClient(d.shutdownCtx, u, cert, key, caCert, instanceName, logLevel, labels, types)
+	lokiClient = loki.NewClient(d.shutdownCtx, u, cert, key, caCert, instanceName, logLevel, labels, types)
+	d.lokiClient.Store(lokiClient)
 
 	// Attach the new client to the log handler.
-	d.internalListener.AddHandler("loki", d.lokiClient.HandleEvent)
+	d.internalListener.AddHandler("loki", lokiClient.HandleEvent)
 
 	return nil
 }
@@ -841,6 +887,9 @@ func (d *Daemon) init() error {
 	// Setup internal event listener
 	d.internalListener = events.NewInternalListener(d.shutdownCtx, d.events)
 
+	// Remove the exec sessions of deleted instances.
+	d.internalListener.AddHandler("exec-sessions", execSessionsHandleEvent(d))
+
 	// Lets check if there's an existing LXD running
 	err = endpoints.CheckAlreadyRunning(d.UnixSocket())
 	if err != nil {
@@ -1408,6 +1457,7 @@ func (d *Daemon) init() error {
 	maasAPIURL, maasAPIKey = d.globalConfig.MAASController()
 	d.gateway.HeartbeatOfflineThreshold = d.globalConfig.OfflineThreshold()
 	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := d.globalConfig.LokiServer()
+	webhookConfigs := d.globalConfig.Webhooks()
 	oidcIssuer, oidcClientID, oidcAudience, oidcGroupsClaim := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
@@ -1423,6 +1473,12 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup webhooks.
+	err = d.setupWebhooks(webhookConfigs)
+	if err != nil {
+		return err
+	}
+
 	if syslogSocketEnabled {
 		err = d.setupSyslogSocket(true)
 		if err != nil {
@@ -1680,6 +1736,9 @@ func (d *Daemon) init() error {
 		// Remove expired backups (hourly)
 		d.tasks.Add(pruneExpiredBackupsTask(d))
 
+		// Stop and delete expired instances (minutely)
+		d.tasks.Add(pruneExpiredInstancesTask(d))
+
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
@@ -1694,6 +1753,30 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Take global database backups (minutely check of configurable cron expression)
+		d.tasks.Add(autoClusterDatabaseBackupTask(d))
+
+		// Run instance health checks (every 10 seconds check of configurable interval)
+		d.tasks.Add(instanceHealthChecksTask(d))
+
+		// Start and stop instances on their power schedules (minutely check of configurable cron expression)
+		d.tasks.Add(instancePowerSchedulesTask(d))
+
+		// Raise warnings about capacity and expiry thresholds (hourly)
+		d.tasks.Add(thresholdWarningsTask(d))
+
+		// Notify webhooks of new warnings (every 30 seconds)
+		d.tasks.Add(webhookWarningsTask(d))
+
+		// Forward the logs of the running instances to Loki (minutely check of the instance settings)
+		d.tasks.Add(instanceLogForwardingTask(d))
+
+		// Remove expired exec sessions and those of deleted instances (hourly)
+		d.tasks.Add(pruneExecSessionsTask(d))
+
+		// Refresh the storage metrics (every 10 seconds check of configurable interval)
+		d.tasks.Add(storageMetricsTask(d))
 	}
 
 	// Start all background tasks
@@ -0,0 +1,141 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+// ConfigRevision is the database representation of a change to the configuration of an entity.
+type ConfigRevision struct {
+	ID           int
+	EntityType   EntityType
+	EntityID     int
+	Revision     int64
+	CreationDate time.Time
+
+	// Requestor is a JSON encoded api.EventLifecycleRequestor.
+	Requestor string
+
+	// Changes is a JSON encoded list of api.ConfigRevisionChange.
+	Changes string
+
+	// State is the JSON encoded writable state of the entity after the change, such as an api.InstancePut.
+	State string
+}
+
+// ToAPI converts the ConfigRevision to an api.ConfigRevision.
+func (r ConfigRevision) ToAPI() (*api.ConfigRevision, error) {
+	revision := api.ConfigRevision{
+		Revision:  r.Revision,
+		CreatedAt: r.CreationDate,
+		Changes:   []api.ConfigRevisionChange{},
+	}
+
+	if r.Requestor != "" {
+		revision.Requestor = &api.EventLifecycleRequestor{}
+		err := json.Unmarshal([]byte(r.Requestor), revision.Requestor)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal requestor of configuration revision %d: %w", r.Revision, err)
+		}
+	}
+
+	err := json.Unmarshal([]byte(r.Changes), &revision.Changes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal changes of configuration revision %d: %w", r.Revision, err)
+	}
+
+	return &revision, nil
+}
+
+const configRevisionColumns = `config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.revision,
+config_revisions.creation_date, config_revisions.requestor, config_revisions.changes, config_revisions.state`
+
+// getConfigRevisionsRaw runs the given query and returns the resulting configuration revisions.
+func getConfigRevisionsRaw(ctx context.Context, tx *sql.Tx, stmt string, args ...any) ([]ConfigRevision, error) {
+	var result []ConfigRevision
+	dest := func(scan func(dest ...any) error) error {
+		r := ConfigRevision{}
+		err := scan(&r.ID, &r.EntityType, &r.EntityID, &r.Revision, &r.CreationDate, &r.Requestor, &r.Changes, &r.State)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, r)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetConfigRevisions returns the configuration revisions of the entity with the given type and ID, oldest first.
+func GetConfigRevisions(ctx context.Context, tx *sql.Tx, entityType entity.Type, entityID int) ([]ConfigRevision, error) {
+	stmt := `SELECT ` + configRevisionColumns + ` FROM config_revisions WHERE config_revisions.entity_type = ? AND config_revisions.entity_id = ? ORDER BY config_revisions.revision`
+
+	revisions, err := getConfigRevisionsRaw(ctx, tx, stmt, EntityType(entityType), entityID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get configuration revisions of %s with ID `%d`: %w", entityType, entityID, err)
+	}
+
+	return revisions, nil
+}
+
+// GetConfigRevision returns the given configuration revision of the entity with the given type and ID.
+func GetConfigRevision(ctx context.Context, tx *sql.Tx, entityType entity.Type, entityID int, revision int64) (*ConfigRevision, error) {
+	stmt := `SELECT ` + configRevisionColumns + ` FROM config_revisions WHERE config_revisions.entity_type = ? AND config_revisions.entity_id = ? AND config_revisions.revision = ?`
+
+	revisions, err := getConfigRevisionsRaw(ctx, tx, stmt, EntityType(entityType), entityID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get configuration revision %d of %s with ID `%d`: %w", revision, entityType, entityID, err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Configuration revision not found")
+	}
+
+	return &revisions[0], nil
+}
+
+// CreateConfigRevision records a new configuration revision of an entity, numbered after its latest revision, and
+// deletes its oldest revisions so that at most retention revisions are kept.
+func CreateConfigRevision(ctx context.Context, tx *sql.Tx, r ConfigRevision, retention int64) (int64, error) {
+	row := tx.QueryRowContext(ctx, `SELECT IFNULL(MAX(revision), 0) FROM config_revisions WHERE entity_type = ? AND entity_id = ?`, r.EntityType, r.EntityID)
+
+	var latest int64
+	err := row.Scan(&latest)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get latest configuration revision: %w", err)
+	}
+
+	r.Revision = latest + 1
+
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO config_revisions (entity_type, entity_id, revision, creation_date, requestor, changes, state)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.EntityType, r.EntityID, r.Revision, r.CreationDate, r.Requestor, r.Changes, r.State)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create configuration revision: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM config_revisions WHERE entity_type = ? AND entity_id = ? AND revision <= ?`, r.EntityType, r.EntityID, r.Revision-retention)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to delete old configuration revisions: %w", err)
+	}
+
+	return r.Revision, nil
+}
@@ -0,0 +1,254 @@
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Names of the files stored inside a global database backup tarball.
+const (
+	databaseBackupMetadataFile = "backup.yaml"
+	databaseBackupDumpFile     = "database.sql"
+)
+
+// DatabaseBackupMember describes a cluster member recorded in a global database backup.
+type DatabaseBackupMember struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+}
+
+// DatabaseBackupMetadata describes the content of a global database backup.
+type DatabaseBackupMetadata struct {
+	CreatedAt     time.Time              `yaml:"created_at"`
+	ServerVersion string                 `yaml:"server_version"`
+	SchemaVersion int                    `yaml:"schema_version"`
+	APIExtensions int                    `yaml:"api_extensions"`
+	Members       []DatabaseBackupMember `yaml:"members"`
+}
+
+// WriteDatabaseBackup writes a gzip compressed tarball containing a consistent SQL dump of the global
+// database, as seen by the given transaction, along with a metadata file describing it.
+func WriteDatabaseBackup(ctx context.Context, tx *sql.Tx, w io.Writer) (*DatabaseBackupMetadata, error) {
+	dump, err := query.Dump(ctx, tx, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed dumping global database: %w", err)
+	}
+
+	metadata := DatabaseBackupMetadata{
+		CreatedAt:     time.Now().UTC(),
+		ServerVersion: version.Version,
+		SchemaVersion: SchemaVersion,
+		APIExtensions: version.APIExtensionsCount(),
+		Members:       []DatabaseBackupMember{},
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT name, address FROM nodes ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching cluster members: %w", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		member := DatabaseBackupMember{}
+		err := rows.Scan(&member.Name, &member.Address)
+		if err != nil {
+			return nil, fmt.Errorf("Failed scanning cluster member: %w", err)
+		}
+
+		metadata.Members = append(metadata.Members, member)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching cluster members: %w", err)
+	}
+
+	metadataYAML, err := yaml.Marshal(&metadata)
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling database backup metadata: %w", err)
+	}
+
+	gzWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{name: databaseBackupMetadataFile, content: metadataYAML},
+		{name: databaseBackupDumpFile, content: []byte(dump)},
+	}
+
+	for _, file := range files {
+		hdr := &tar.Header{
+			Name:    file.name,
+			Mode:    0600,
+			Size:    int64(len(file.content)),
+			ModTime: metadata.CreatedAt,
+		}
+
+		err = tarWriter.WriteHeader(hdr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed writing %q header: %w", file.name, err)
+		}
+
+		_, err = tarWriter.Write(file.content)
+		if err != nil {
+			return nil, fmt.Errorf("Failed writing %q: %w", file.name, err)
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = gzWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// ReadDatabaseBackup parses a tarball produced by WriteDatabaseBackup and returns its metadata and SQL dump.
+func ReadDatabaseBackup(r io.Reader) (*DatabaseBackupMetadata, string, error) {
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("Invalid database backup: %w", err)
+	}
+
+	defer func() { _ = gzReader.Close() }()
+
+	var metadata *DatabaseBackupMetadata
+	var dump *string
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		hdr, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, "", fmt.Errorf("Invalid database backup: %w", err)
+		}
+
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, tarReader)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed reading %q from database backup: %w", hdr.Name, err)
+		}
+
+		switch hdr.Name {
+		case databaseBackupMetadataFile:
+			metadata = &DatabaseBackupMetadata{}
+			err = yaml.Unmarshal(buf.Bytes(), metadata)
+			if err != nil {
+				return nil, "", fmt.Errorf("Failed parsing database backup metadata: %w", err)
+			}
+
+		case databaseBackupDumpFile:
+			content := buf.String()
+			dump = &content
+		}
+	}
+
+	if metadata == nil {
+		return nil, "", fmt.Errorf("Invalid database backup: Missing %q", databaseBackupMetadataFile)
+	}
+
+	if dump == nil {
+		return nil, "", fmt.Errorf("Invalid database backup: Missing %q", databaseBackupDumpFile)
+	}
+
+	return metadata, *dump, nil
+}
+
+// databaseObjectRegexp matches the creation statement of tables and views in a SQL dump or schema.
+var databaseObjectRegexp = regexp.MustCompile(`(?m)^CREATE (TABLE|VIEW)(?: IF NOT EXISTS)? "?([A-Za-z0-9_]+)"?`)
+
+// DatabaseRestorePatch returns the queries that replace the content of the global database with the
+// content of the given backup.
+//
+// The result is meant to be written to the "patch.global.sql" file, which is executed in a transaction
+// at the next daemon start before any schema update is applied. This means that backups taken with an
+// older schema version are upgraded as part of the restore.
+//
+// The member argument is the name of the member being restored and address is its current cluster
+// address, which replaces the one recorded in the backup.
+func DatabaseRestorePatch(metadata DatabaseBackupMetadata, dump string, member string, address string) (string, error) {
+	if metadata.SchemaVersion > SchemaVersion {
+		return "", fmt.Errorf("Database backup has schema version %d, newer than the supported version %d", metadata.SchemaVersion, SchemaVersion)
+	}
+
+	found := false
+	for _, m := range metadata.Members {
+		if m.Name == member {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("Cluster member %q not found in database backup", member)
+	}
+
+	var builder strings.Builder
+
+	// Foreign keys can't be disabled inside a transaction, so defer their check until commit instead.
+	builder.WriteString("PRAGMA defer_foreign_keys=ON;\n")
+
+	// Drop all tables and views known to either the backup or the current schema, so that the
+	// backup can be restored on top of a database at any schema version.
+	dropped := map[string]bool{}
+	for _, source := range []string{dump, FreshSchema()} {
+		for _, match := range databaseObjectRegexp.FindAllStringSubmatch(source, -1) {
+			kind, name := match[1], match[2]
+			if dropped[name] {
+				continue
+			}
+
+			dropped[name] = true
+			builder.WriteString(fmt.Sprintf("DROP %s IF EXISTS %q;\n", kind, name))
+		}
+	}
+
+	// Strip the transaction handling statements from the dump, as the patch file is already
+	// executed inside a transaction.
+	for _, line := range strings.SplitAfter(dump, "\n") {
+		switch strings.TrimSpace(line) {
+		case "PRAGMA foreign_keys=OFF;", "BEGIN TRANSACTION;", "COMMIT;", "":
+			continue
+		}
+
+		builder.WriteString(line)
+	}
+
+	// Record the new address of the member being restored and mark all members as being at the
+	// current version, since the members recorded in the backup are not expected to come back.
+	builder.WriteString(fmt.Sprintf("UPDATE nodes SET address=%s WHERE name=%s;\n", sqlQuote(address), sqlQuote(member)))
+	builder.WriteString(fmt.Sprintf("UPDATE nodes SET schema=%d, api_extensions=%d;\n", SchemaVersion, version.APIExtensionsCount()))
+
+	return builder.String(), nil
+}
+
+// sqlQuote returns the given value as a SQL string literal.
+func sqlQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,69 @@
+package cluster_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// A database backup can be restored on top of a database with different content.
+func TestDatabaseBackupRestore(t *testing.T) {
+	source := newDB(t)
+	addNode(t, source, "10.0.0.1:8443", cluster.SchemaVersion, len(version.APIExtensions))
+	addNode(t, source, "10.0.0.2:8443", cluster.SchemaVersion, len(version.APIExtensions))
+
+	var buf bytes.Buffer
+	err := query.Transaction(context.TODO(), source, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.WriteDatabaseBackup(ctx, tx, &buf)
+		return err
+	})
+	require.NoError(t, err)
+
+	metadata, dump, err := cluster.ReadDatabaseBackup(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.SchemaVersion, metadata.SchemaVersion)
+	require.Len(t, metadata.Members, 2)
+	assert.Equal(t, "node at 10.0.0.1:8443", metadata.Members[0].Name)
+
+	_, err = cluster.DatabaseRestorePatch(*metadata, dump, "missing", "10.0.0.3:8443")
+	assert.EqualError(t, err, `Cluster member "missing" not found in database backup`)
+
+	patch, err := cluster.DatabaseRestorePatch(*metadata, dump, "node at 10.0.0.1:8443", "10.0.0.3:8443")
+	require.NoError(t, err)
+
+	target := newDB(t)
+	addNode(t, target, "192.0.2.1:8443", cluster.SchemaVersion-1, len(version.APIExtensions)-1)
+
+	err = query.Transaction(context.TODO(), target, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.Exec(patch)
+		return err
+	})
+	require.NoError(t, err)
+
+	err = query.Transaction(context.TODO(), target, func(ctx context.Context, tx *sql.Tx) error {
+		n, err := query.Count(ctx, tx, "nodes", "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assertNode(t, target, "10.0.0.3:8443", cluster.SchemaVersion, version.APIExtensionsCount())
+	assertNode(t, target, "10.0.0.2:8443", cluster.SchemaVersion, version.APIExtensionsCount())
+}
+
+// A database backup taken with a newer schema is rejected.
+func TestDatabaseRestorePatch_NewerSchema(t *testing.T) {
+	metadata := cluster.DatabaseBackupMetadata{SchemaVersion: cluster.SchemaVersion + 1}
+
+	_, err := cluster.DatabaseRestorePatch(metadata, "", "node", "10.0.0.1:8443")
+	assert.ErrorContains(t, err, "newer than the supported version")
+}
@@ -51,6 +51,8 @@ const (
 	entityTypeAuthGroup             int64 = 22
 	entityTypeIdentityProviderGroup int64 = 23
 	entityTypeIdentity              int64 = 24
+	entityTypePlacementGroup        int64 = 25
+	entityTypeSecret                int64 = 26
 )
 
 // Scan implements sql.Scanner for EntityType. This converts the integer value back into the correct entity.Type
@@ -124,6 +126,10 @@ func (e *EntityType) Scan(value any) error {
 		*e = EntityType(entity.TypeIdentityProviderGroup)
 	case entityTypeIdentity:
 		*e = EntityType(entity.TypeIdentity)
+	case entityTypePlacementGroup:
+		*e = EntityType(entity.TypePlacementGroup)
+	case entityTypeSecret:
+		*e = EntityType(entity.TypeSecret)
 	default:
 		return fmt.Errorf("Unknown entity type %d", entityTypeInt)
 	}
@@ -186,6 +192,10 @@ func (e EntityType) Value() (driver.Value, error) {
 		return entityTypeIdentityProviderGroup, nil
 	case EntityType(entity.TypeIdentity):
 		return entityTypeIdentity, nil
+	case EntityType(entity.TypePlacementGroup):
+		return entityTypePlacementGroup, nil
+	case EntityType(entity.TypeSecret):
+		return entityTypeSecret, nil
 	default:
 		return nil, fmt.Errorf("Unknown entity type %q", e)
 	}
@@ -504,6 +514,24 @@ FROM identities
 // identityEntityByID gets the entity of type entity.TypeIdentity with a particular ID.
 var identityEntityByID = fmt.Sprintf(`%s WHERE identities.id = ?`, identityEntities)
 
+// placementGroupEntities returns all entities of type entity.TypePlacementGroup.
+var placementGroupEntities = fmt.Sprintf(`SELECT %d, placement_groups.id, projects.name, '', json_array(placement_groups.name) FROM placement_groups JOIN projects ON placement_groups.project_id = projects.id`, entityTypePlacementGroup)
+
+// placementGroupEntityByID gets the entity of type entity.TypePlacementGroup with a particular ID.
+var placementGroupEntityByID = fmt.Sprintf(`%s WHERE placement_groups.id = ?`, placementGroupEntities)
+
+// placementGroupEntitiesByProjectName returns all entities of type entity.TypePlacementGroup in a particular project.
+var placementGroupEntitiesByProjectName = fmt.Sprintf(`%s WHERE projects.name = ?`, placementGroupEntities)
+
+// secretEntities returns all entities of type entity.TypeSecret.
+var secretEntities = fmt.Sprintf(`SELECT %d, secrets.id, projects.name, '', json_array(secrets.name) FROM secrets JOIN projects ON secrets.project_id = projects.id`, entityTypeSecret)
+
+// secretEntityByID gets the entity of type entity.TypeSecret with a particular ID.
+var secretEntityByID = fmt.Sprintf(`%s WHERE secrets.id = ?`, secretEntities)
+
+// secretEntitiesByProjectName returns all entities of type entity.TypeSecret in a particular project.
+var secretEntitiesByProjectName = fmt.Sprintf(`%s WHERE projects.name = ?`, secretEntities)
+
 // entityStatementsAll is a map of entity type to the statement which queries for all URL information for entities of that type.
 var entityStatementsAll = map[entity.Type]string{
 	entity.TypeContainer:             containerEntities,
@@ -530,6 +558,8 @@ var entityStatementsAll = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupEntities,
 	entity.TypeIdentityProviderGroup: identityProviderGroupEntities,
 	entity.TypeIdentity:              identityEntities,
+	entity.TypePlacementGroup:        placementGroupEntities,
+	entity.TypeSecret:                secretEntities,
 }
 
 // entityStatementsByID is a map of entity type to the statement which queries for all URL information for a single entity of that type with a given ID.
@@ -558,6 +588,8 @@ var entityStatementsByID = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupEntityByID,
 	entity.TypeIdentityProviderGroup: identityProviderGroupEntityByID,
 	entity.TypeIdentity:              identityEntityByID,
+	entity.TypePlacementGroup:        placementGroupEntityByID,
+	entity.TypeSecret:                secretEntityByID,
 }
 
 // entityStatementsByProjectName is a map of entity type to the statement which queries for all URL information for all entities of that type within a given project.
@@ -578,6 +610,8 @@ var entityStatementsByProjectName = map[entity.Type]string{
 	entity.TypeStorageBucket:         storageBucketEntitiesByProjectName,
 	entity.TypeImageAlias:            imageAliasEntitiesByProjectName,
 	entity.TypeNetworkZone:           networkZoneEntitiesByProjectName,
+	entity.TypePlacementGroup:        placementGroupEntitiesByProjectName,
+	entity.TypeSecret:                secretEntitiesByProjectName,
 }
 
 // EntityRef represents the expected format of entity URL queries.
@@ -1012,6 +1046,24 @@ WHERE '' = ?
 `, authMethodTLS, api.AuthenticationMethodTLS,
 	authMethodOIDC, api.AuthenticationMethodOIDC)
 
+// placementGroupIDFromURL gets the ID of a placement group from its URL.
+var placementGroupIDFromURL = `
+SELECT ?, placement_groups.id 
+FROM placement_groups 
+JOIN projects ON placement_groups.project_id = projects.id 
+WHERE projects.name = ? 
+	AND '' = ? 
+	AND placement_groups.name = ?`
+
+// secretIDFromURL gets the ID of a secret from its URL.
+var secretIDFromURL = `
+SELECT ?, secrets.id
+FROM secrets
+JOIN projects ON secrets.project_id = projects.id
+WHERE projects.name = ?
+	AND '' = ?
+	AND secrets.name = ?`
+
 // identityIDFromURLStatements is a map of entity.Type to a statement that can be used to get the ID of the entity from its URL.
 var entityIDFromURLStatements = map[entity.Type]string{
 	entity.TypeContainer:             containerIDFromURL,
@@ -1038,6 +1090,8 @@ var entityIDFromURLStatements = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupIDFromURL,
 	entity.TypeIdentityProviderGroup: identityProviderGroupIDFromURL,
 	entity.TypeIdentity:              identityIDFromURL,
+	entity.TypePlacementGroup:        placementGroupIDFromURL,
+	entity.TypeSecret:                secretIDFromURL,
 }
 
 // PopulateEntityReferencesFromURLs populates the values in the given map with entity references corresponding to the api.URL keys.
@@ -1216,6 +1270,8 @@ var entityDeletionTriggers = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupDeletionTrigger,
 	entity.TypeIdentityProviderGroup: identityProviderGroupDeletionTrigger,
 	entity.TypeIdentity:              identityDeletionTrigger,
+	entity.TypePlacementGroup:        placementGroupDeletionTrigger,
+	entity.TypeSecret:                secretDeletionTrigger,
 }
 
 // imageDeletionTrigger deletes any permissions or warnings associated with an image when it is deleted.
@@ -1233,7 +1289,7 @@ CREATE TRIGGER on_image_delete
 	END
 `, entityTypeImage, entityTypeImage)
 
-// profileDeletionTrigger deletes any permissions or warnings associated with a profile when it is deleted.
+// profileDeletionTrigger deletes any permissions, warnings or configuration revisions associated with a profile when it is deleted.
 var profileDeletionTrigger = fmt.Sprintf(`
 DROP TRIGGER IF EXISTS on_profile_delete;
 CREATE TRIGGER on_profile_delete
@@ -1245,10 +1301,13 @@ CREATE TRIGGER on_profile_delete
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
+	DELETE FROM config_revisions
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	END
-`, entityTypeProfile, entityTypeProfile)
+`, entityTypeProfile, entityTypeProfile, entityTypeProfile)
 
-// projectDeletionTrigger deletes any permissions or warnings associated with a project when it is deleted.
+// projectDeletionTrigger deletes any permissions, warnings or configuration revisions associated with a project when it is deleted.
 var projectDeletionTrigger = fmt.Sprintf(`
 DROP TRIGGER IF EXISTS on_project_delete;
 CREATE TRIGGER on_project_delete
@@ -1260,10 +1319,13 @@ CREATE TRIGGER on_project_delete
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
+	DELETE FROM config_revisions
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	END
-`, entityTypeProject, entityTypeProject)
+`, entityTypeProject, entityTypeProject, entityTypeProject)
 
-// instanceDeletionTrigger deletes any permissions or warnings associated with an instance when it is deleted.
+// instanceDeletionTrigger deletes any permissions, warnings or configuration revisions associated with an instance when it is deleted.
 var instanceDeletionTrigger = fmt.Sprintf(`
 DROP TRIGGER IF EXISTS on_instance_delete;
 CREATE TRIGGER on_instance_delete
@@ -1275,8 +1337,11 @@ CREATE TRIGGER on_instance_delete
 	DELETE FROM warnings
 		WHERE entity_type_code = %d
 		AND entity_id = OLD.id;
+	DELETE FROM config_revisions
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
 	END
-`, entityTypeInstance, entityTypeInstance)
+`, entityTypeInstance, entityTypeInstance, entityTypeInstance)
 
 // instanceBackupDeletionTrigger deletes any permissions or warnings associated with an instance backup when it is deleted.
 var instanceBackupDeletionTrigger = fmt.Sprintf(`
@@ -1542,3 +1607,33 @@ CREATE TRIGGER on_identity_delete
 		AND entity_id = OLD.id;
 	END
 `, entityTypeIdentity, entityTypeIdentity)
+
+// placementGroupDeletionTrigger deletes any permissions or warnings associated with a placement group when it is deleted.
+var placementGroupDeletionTrigger = fmt.Sprintf(`
+DROP TRIGGER IF EXISTS on_placement_group_delete;
+CREATE TRIGGER on_placement_group_delete
+	AFTER DELETE ON placement_groups
+	BEGIN
+	DELETE FROM auth_groups_permissions 
+		WHERE entity_type = %d 
+		AND entity_id = OLD.id;
+	DELETE FROM warnings
+		WHERE entity_type_code = %d
+		AND entity_id = OLD.id;
+	END
+`, entityTypePlacementGroup, entityTypePlacementGroup)
+
+// secretDeletionTrigger deletes any permissions or warnings associated with a secret when it is deleted.
+var secretDeletionTrigger = fmt.Sprintf(`
+DROP TRIGGER IF EXISTS on_secret_delete;
+CREATE TRIGGER on_secret_delete
+	AFTER DELETE ON secrets
+	BEGIN
+	DELETE FROM auth_groups_permissions
+		WHERE entity_type = %d
+		AND entity_id = OLD.id;
+	DELETE FROM warnings
+		WHERE entity_type_code = %d
+		AND entity_id = OLD.id;
+	END
+`, entityTypeSecret, entityTypeSecret)
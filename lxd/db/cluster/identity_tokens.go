@@ -0,0 +1,196 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// IdentityToken is the database representation of an identity bearer token.
+type IdentityToken struct {
+	ID           int
+	IdentityID   int
+	Name         string
+	Description  string
+	SecretHash   string
+	CreationDate time.Time
+	ExpiryDate   sql.NullTime
+	LastUsedDate sql.NullTime
+
+	// AllowedAddresses is a JSON encoded list of addresses or subnets.
+	AllowedAddresses string
+
+	// Permissions is a JSON encoded list of api.Permission.
+	Permissions string
+}
+
+// ToAPI converts the IdentityToken to an api.IdentityToken.
+func (t IdentityToken) ToAPI() (*api.IdentityToken, error) {
+	token := api.IdentityToken{
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   t.CreationDate,
+	}
+
+	if t.ExpiryDate.Valid {
+		token.ExpiresAt = t.ExpiryDate.Time
+	}
+
+	if t.LastUsedDate.Valid {
+		token.LastUsedAt = t.LastUsedDate.Time
+	}
+
+	var err error
+	token.AllowedAddresses, token.Permissions, err = t.Restrictions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Restrictions decodes the allowed addresses and permissions of the token.
+func (t IdentityToken) Restrictions() ([]string, []api.Permission, error) {
+	allowedAddresses := []string{}
+	err := json.Unmarshal([]byte(t.AllowedAddresses), &allowedAddresses)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to unmarshal allowed addresses of identity token %q: %w", t.Name, err)
+	}
+
+	permissions := []api.Permission{}
+	err = json.Unmarshal([]byte(t.Permissions), &permissions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to unmarshal permissions of identity token %q: %w", t.Name, err)
+	}
+
+	return allowedAddresses, permissions, nil
+}
+
+const identityTokenColumns = `identities_tokens.id, identities_tokens.identity_id, identities_tokens.name, identities_tokens.description,
+identities_tokens.secret_hash, identities_tokens.creation_date, identities_tokens.expiry_date, identities_tokens.last_used_date,
+identities_tokens.allowed_addresses, identities_tokens.permissions`
+
+// getIdentityTokensRaw runs the given query and returns the resulting identity tokens.
+func getIdentityTokensRaw(ctx context.Context, tx *sql.Tx, stmt string, args ...any) ([]IdentityToken, error) {
+	var result []IdentityToken
+	dest := func(scan func(dest ...any) error) error {
+		t := IdentityToken{}
+		err := scan(&t.ID, &t.IdentityID, &t.Name, &t.Description, &t.SecretHash, &t.CreationDate, &t.ExpiryDate, &t.LastUsedDate, &t.AllowedAddresses, &t.Permissions)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, t)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetIdentityTokens returns all identity tokens.
+func GetIdentityTokens(ctx context.Context, tx *sql.Tx) ([]IdentityToken, error) {
+	stmt := `SELECT ` + identityTokenColumns + ` FROM identities_tokens ORDER BY identities_tokens.id`
+
+	tokens, err := getIdentityTokensRaw(ctx, tx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get identity tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// GetIdentityTokensByIdentityID returns the tokens of the identity with the given ID.
+func GetIdentityTokensByIdentityID(ctx context.Context, tx *sql.Tx, identityID int) ([]IdentityToken, error) {
+	stmt := `SELECT ` + identityTokenColumns + ` FROM identities_tokens WHERE identities_tokens.identity_id = ? ORDER BY identities_tokens.name`
+
+	tokens, err := getIdentityTokensRaw(ctx, tx, stmt, identityID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tokens for identity with ID `%d`: %w", identityID, err)
+	}
+
+	return tokens, nil
+}
+
+// GetIdentityToken returns the token with the given name of the identity with the given ID.
+func GetIdentityToken(ctx context.Context, tx *sql.Tx, identityID int, name string) (*IdentityToken, error) {
+	stmt := `SELECT ` + identityTokenColumns + ` FROM identities_tokens WHERE identities_tokens.identity_id = ? AND identities_tokens.name = ?`
+
+	tokens, err := getIdentityTokensRaw(ctx, tx, stmt, identityID, name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get token %q for identity with ID `%d`: %w", name, identityID, err)
+	}
+
+	if len(tokens) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Identity token not found")
+	}
+
+	return &tokens[0], nil
+}
+
+// CreateIdentityToken adds a new identity token to the database.
+func CreateIdentityToken(ctx context.Context, tx *sql.Tx, token IdentityToken) (int64, error) {
+	_, err := GetIdentityToken(ctx, tx, token.IdentityID, token.Name)
+	if err == nil {
+		return -1, api.StatusErrorf(http.StatusConflict, "This identity token already exists")
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return -1, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+INSERT INTO identities_tokens (identity_id, name, description, secret_hash, creation_date, expiry_date, allowed_addresses, permissions)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.IdentityID, token.Name, token.Description, token.SecretHash, token.CreationDate, token.ExpiryDate, token.AllowedAddresses, token.Permissions)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create identity token %q: %w", token.Name, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Failed to fetch ID of identity token %q: %w", token.Name, err)
+	}
+
+	return id, nil
+}
+
+// DeleteIdentityToken deletes the token with the given name of the identity with the given ID.
+func DeleteIdentityToken(ctx context.Context, tx *sql.Tx, identityID int, name string) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM identities_tokens WHERE identity_id = ? AND name = ?`, identityID, name)
+	if err != nil {
+		return fmt.Errorf("Failed to delete identity token %q: %w", name, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Failed to check number of deleted identity tokens: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Identity token not found")
+	}
+
+	return nil
+}
+
+// UpdateIdentityTokenLastUsedDate records the last time the token with the given secret hash was used.
+func UpdateIdentityTokenLastUsedDate(ctx context.Context, tx *sql.Tx, secretHash string, lastUsed time.Time) error {
+	_, err := tx.ExecContext(ctx, `UPDATE identities_tokens SET last_used_date = ? WHERE secret_hash = ?`, lastUsed, secretHash)
+	if err != nil {
+		return fmt.Errorf("Failed to update last used date of identity token: %w", err)
+	}
+
+	return nil
+}
@@ -41,6 +41,17 @@ CREATE TABLE config (
     value TEXT,
     UNIQUE (key)
 );
+CREATE TABLE config_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    creation_date DATETIME NOT NULL,
+    requestor TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    state TEXT NOT NULL,
+    UNIQUE (entity_type, entity_id, revision)
+);
 CREATE TABLE identities (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     auth_method INTEGER NOT NULL,
@@ -70,6 +81,21 @@ CREATE TABLE identities_projects (
     FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
     UNIQUE (identity_id, project_id)
 );
+CREATE TABLE identities_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    identity_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    creation_date DATETIME NOT NULL,
+    expiry_date DATETIME,
+    last_used_date DATETIME,
+    allowed_addresses TEXT NOT NULL,
+    permissions TEXT NOT NULL,
+    UNIQUE (identity_id, name),
+    UNIQUE (secret_hash),
+    FOREIGN KEY (identity_id) REFERENCES identities (id) ON DELETE CASCADE
+);
 CREATE TABLE identity_provider_groups (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -128,6 +154,12 @@ CREATE TABLE "images_properties" (
     value TEXT,
     FOREIGN KEY (image_id) REFERENCES "images" (id) ON DELETE CASCADE
 );
+CREATE TABLE images_refreshes (
+    image_id INTEGER PRIMARY KEY NOT NULL,
+    last_success_date DATETIME,
+    last_failure_date DATETIME,
+    FOREIGN KEY (image_id) REFERENCES "images" (id) ON DELETE CASCADE
+);
 CREATE TABLE "images_source" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     image_id INTEGER NOT NULL,
@@ -434,6 +466,22 @@ CREATE TABLE "operations" (
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE TABLE placement_groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE placement_groups_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    placement_group_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (placement_group_id, key),
+    FOREIGN KEY (placement_group_id) REFERENCES placement_groups (id) ON DELETE CASCADE
+);
 CREATE TABLE "profiles" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -481,6 +529,20 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE secrets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    value BLOB NOT NULL,
+    last_updated DATETIME NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE secrets_key (
+    id INTEGER PRIMARY KEY NOT NULL,
+    key BLOB NOT NULL
+);
 CREATE TABLE "storage_buckets" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	name TEXT NOT NULL,
@@ -662,5 +724,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (79, strftime("%s"))
 `
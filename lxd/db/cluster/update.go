@@ -110,6 +110,135 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+}
+
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE images_refreshes (
+    image_id INTEGER PRIMARY KEY NOT NULL,
+    last_success_date DATETIME,
+    last_failure_date DATETIME,
+    FOREIGN KEY (image_id) REFERENCES "images" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE secrets_key (
+    id INTEGER PRIMARY KEY NOT NULL,
+    key BLOB NOT NULL
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE secrets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    value BLOB NOT NULL,
+    last_updated DATETIME NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE config_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    creation_date DATETIME NOT NULL,
+    requestor TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    state TEXT NOT NULL,
+    UNIQUE (entity_type, entity_id, revision)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE identities_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    identity_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    creation_date DATETIME NOT NULL,
+    expiry_date DATETIME,
+    last_used_date DATETIME,
+    allowed_addresses TEXT NOT NULL,
+    permissions TEXT NOT NULL,
+    UNIQUE (identity_id, name),
+    UNIQUE (secret_hash),
+    FOREIGN KEY (identity_id) REFERENCES identities (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE placement_groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE TABLE placement_groups_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    placement_group_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (placement_group_id, key),
+    FOREIGN KEY (placement_group_id) REFERENCES placement_groups (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func updateFromV72(ctx context.Context, tx *sql.Tx) error {
@@ -773,6 +773,62 @@ func (c *ClusterTx) SetImageCachedAndLastUseDate(ctx context.Context, projectNam
 	return err
 }
 
+// ImageRefresh holds the dates of the last successful and failed automatic refreshes of an image. The dates are
+// zero if the image was never refreshed with that outcome.
+type ImageRefresh struct {
+	LastSuccessDate time.Time
+	LastFailureDate time.Time
+}
+
+// SetImageRefreshResult records the date of the last successful or failed automatic refresh of the image with
+// the given ID.
+func (c *ClusterTx) SetImageRefreshResult(ctx context.Context, imageID int, date time.Time, success bool) error {
+	column := "last_failure_date"
+	if success {
+		column = "last_success_date"
+	}
+
+	stmt := fmt.Sprintf(`
+INSERT INTO images_refreshes (image_id, %[1]s) VALUES (?, ?)
+  ON CONFLICT (image_id) DO UPDATE SET %[1]s=excluded.%[1]s
+`, column)
+
+	_, err := c.tx.ExecContext(ctx, stmt, imageID, date.UTC())
+
+	return err
+}
+
+// GetImageRefreshes returns the dates of the last automatic refreshes of the images, indexed by image ID. Images
+// that were never refreshed automatically are omitted.
+func (c *ClusterTx) GetImageRefreshes(ctx context.Context) (map[int]ImageRefresh, error) {
+	refreshes := map[int]ImageRefresh{}
+
+	q := `SELECT image_id, last_success_date, last_failure_date FROM images_refreshes`
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var imageID int
+		var lastSuccessDate sql.NullTime
+		var lastFailureDate sql.NullTime
+
+		err := scan(&imageID, &lastSuccessDate, &lastFailureDate)
+		if err != nil {
+			return err
+		}
+
+		refreshes[imageID] = ImageRefresh{
+			LastSuccessDate: lastSuccessDate.Time,
+			LastFailureDate: lastFailureDate.Time,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshes, nil
+}
+
 // UnsetImageCached unsets the cached field of the image with the given fingerprint.
 func (c *ClusterTx) UnsetImageCached(ctx context.Context, projectName string, fingerprint string) error {
 	stmt := `UPDATE images SET cached=0 WHERE fingerprint=? AND project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)`
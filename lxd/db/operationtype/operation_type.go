@@ -76,6 +76,11 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	ClusterDatabaseBackup
+	CommandExecAttach
+	InstanceHealthRemediate
+	InstancePowerSchedule
+	InstancesExpire
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +204,16 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case ClusterDatabaseBackup:
+		return "Backing up cluster database"
+	case CommandExecAttach:
+		return "Attaching to exec session"
+	case InstanceHealthRemediate:
+		return "Remediating unhealthy instance"
+	case InstancePowerSchedule:
+		return "Applying instance power schedules"
+	case InstancesExpire:
+		return "Cleaning up expired instances"
 	default:
 		return "Executing operation"
 	}
@@ -227,8 +242,12 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeInstance, auth.EntitlementCanUpdateState
 	case InstanceRestart:
 		return entity.TypeInstance, auth.EntitlementCanUpdateState
+	case InstanceHealthRemediate:
+		return entity.TypeInstance, auth.EntitlementCanUpdateState
 	case CommandExec:
 		return entity.TypeInstance, auth.EntitlementCanExec
+	case CommandExecAttach:
+		return entity.TypeInstance, auth.EntitlementCanExec
 	case SnapshotCreate:
 		return entity.TypeInstance, auth.EntitlementCanManageSnapshots
 	case SnapshotRename:
@@ -0,0 +1,170 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetPlacementGroupsByProject returns the names of existing placement groups in the given project.
+func (c *ClusterTx) GetPlacementGroupsByProject(ctx context.Context, project string) ([]string, error) {
+	q := `SELECT name FROM placement_groups
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)
+		ORDER BY id
+	`
+
+	var groupNames []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var groupName string
+
+		err := scan(&groupName)
+		if err != nil {
+			return err
+		}
+
+		groupNames = append(groupNames, groupName)
+
+		return nil
+	}, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupNames, nil
+}
+
+// GetPlacementGroup returns the placement group with the given name in the given project.
+func (c *ClusterTx) GetPlacementGroup(ctx context.Context, projectName string, name string) (int64, *api.PlacementGroup, error) {
+	var id = int64(-1)
+
+	group := api.PlacementGroup{
+		Name: name,
+	}
+
+	q := `
+		SELECT id, description
+		FROM placement_groups
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &group.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Placement group not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = placementGroupConfig(ctx, c, id, &group)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &group, nil
+}
+
+// placementGroupConfig populates the config map of the placement group with the given ID.
+func placementGroupConfig(ctx context.Context, tx *ClusterTx, id int64, group *api.PlacementGroup) error {
+	q := `
+		SELECT key, value
+		FROM placement_groups_config
+		WHERE placement_group_id=?
+	`
+
+	group.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := group.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for placement group ID %d", key, id)
+		}
+
+		group.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreatePlacementGroup creates a new placement group in the given project.
+func (c *ClusterTx) CreatePlacementGroup(ctx context.Context, projectName string, info *api.PlacementGroupsPost) (int64, error) {
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO placement_groups (project_id, name, description)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?)
+		`, projectName, info.Name, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = placementGroupConfigAdd(ctx, c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// placementGroupConfigAdd inserts placement group config keys.
+func placementGroupConfigAdd(ctx context.Context, tx *sql.Tx, id int64, config map[string]string) error {
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO placement_groups_config (placement_group_id, key, value) VALUES(?, ?, ?)")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.ExecContext(ctx, id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdatePlacementGroup updates the placement group with the given ID.
+func (c *ClusterTx) UpdatePlacementGroup(ctx context.Context, id int64, config *api.PlacementGroupPut) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE placement_groups SET description=? WHERE id=?", config.Description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM placement_groups_config WHERE placement_group_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return placementGroupConfigAdd(ctx, c.tx, id, config.Config)
+}
+
+// DeletePlacementGroup deletes the placement group with the given ID.
+func (c *ClusterTx) DeletePlacementGroup(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM placement_groups WHERE id=?", id)
+
+	return err
+}
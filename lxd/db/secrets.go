@@ -0,0 +1,190 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetSecretsKey returns the key encrypting the values of the secrets, creating it with the given function on
+// first use. As the key is stored in the global database, it's shared by all cluster members and is included in
+// the database backups.
+func (c *ClusterTx) GetSecretsKey(ctx context.Context, newKey func() ([]byte, error)) ([]byte, error) {
+	var key []byte
+
+	err := c.tx.QueryRowContext(ctx, "SELECT key FROM secrets_key WHERE id=1").Scan(&key)
+	if err == nil {
+		return key, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	key, err = newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "INSERT INTO secrets_key (id, key) VALUES (1, ?)", key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// GetSecretNames returns the names of the secrets in the given project.
+func (c *ClusterTx) GetSecretNames(ctx context.Context, projectName string) ([]string, error) {
+	q := `SELECT name FROM secrets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)
+		ORDER BY name
+	`
+
+	var names []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var name string
+
+		err := scan(&name)
+		if err != nil {
+			return err
+		}
+
+		names = append(names, name)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetSecret returns the secret with the given name in the given project, without its value.
+func (c *ClusterTx) GetSecret(ctx context.Context, projectName string, name string) (int64, *api.Secret, error) {
+	var id = int64(-1)
+
+	secret := api.Secret{
+		Name: name,
+	}
+
+	q := `
+		SELECT id, description, last_updated
+		FROM secrets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &secret.Description, &secret.LastUpdated)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Secret not found")
+		}
+
+		return -1, nil, err
+	}
+
+	return id, &secret, nil
+}
+
+// GetSecretValue returns the encrypted value of the secret with the given name in the given project.
+func (c *ClusterTx) GetSecretValue(ctx context.Context, projectName string, name string) ([]byte, error) {
+	var value []byte
+
+	q := `
+		SELECT value
+		FROM secrets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Secret %q not found", name)
+		}
+
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetAllSecretValues returns the encrypted values of all secrets keyed by secret ID.
+func (c *ClusterTx) GetAllSecretValues(ctx context.Context) (map[int64][]byte, error) {
+	values := map[int64][]byte{}
+
+	err := query.Scan(ctx, c.tx, "SELECT id, value FROM secrets", func(scan func(dest ...any) error) error {
+		var id int64
+		var value []byte
+
+		err := scan(&id, &value)
+		if err != nil {
+			return err
+		}
+
+		values[id] = value
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// CreateSecret creates a new secret with the given encrypted value in the given project.
+func (c *ClusterTx) CreateSecret(ctx context.Context, projectName string, name string, description string, value []byte) (int64, error) {
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO secrets (project_id, name, description, value, last_updated)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?, ?)
+		`, projectName, name, description, value, time.Now().UTC())
+	if err != nil {
+		return -1, err
+	}
+
+	return result.LastInsertId()
+}
+
+// UpdateSecret updates the description of the secret with the given ID, and its value if not nil.
+func (c *ClusterTx) UpdateSecret(ctx context.Context, id int64, description string, value []byte) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE secrets SET description=? WHERE id=?", description, id)
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return c.UpdateSecretValue(ctx, id, value, true)
+}
+
+// UpdateSecretValue replaces the encrypted value of the secret with the given ID. The last updated date is only
+// changed if touch is true, so that re-encrypting a value doesn't appear as a change of the secret.
+func (c *ClusterTx) UpdateSecretValue(ctx context.Context, id int64, value []byte, touch bool) error {
+	if !touch {
+		_, err := c.tx.ExecContext(ctx, "UPDATE secrets SET value=? WHERE id=?", value, id)
+		return err
+	}
+
+	_, err := c.tx.ExecContext(ctx, "UPDATE secrets SET value=?, last_updated=? WHERE id=?", value, time.Now().UTC(), id)
+	return err
+}
+
+// DeleteSecret deletes the secret with the given ID.
+func (c *ClusterTx) DeleteSecret(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM secrets WHERE id=?", id)
+
+	return err
+}
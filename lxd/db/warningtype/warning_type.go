@@ -58,6 +58,18 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// InstanceExpiring represents an instance that is about to be deleted because it expires.
+	InstanceExpiring
+	// InstanceWatchdogTriggered represents the expiry of the watchdog device of an instance.
+	InstanceWatchdogTriggered
+	// StoragePoolNearlyFull represents a storage pool whose usage exceeds the configured threshold.
+	StoragePoolNearlyFull
+	// ProjectLimitsNearlyReached represents a project whose usage is close to its limits.
+	ProjectLimitsNearlyReached
+	// CertificateExpiring represents a server or trusted certificate that is about to expire.
+	CertificateExpiring
+	// ImageNotRefreshed represents an image that couldn't be refreshed for a while.
+	ImageNotRefreshed
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +100,12 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	InstanceExpiring:                       "Instance is about to expire",
+	InstanceWatchdogTriggered:              "Instance watchdog triggered",
+	StoragePoolNearlyFull:                  "Storage pool is nearly full",
+	ProjectLimitsNearlyReached:             "Project is close to its limits",
+	CertificateExpiring:                    "Certificate is about to expire",
+	ImageNotRefreshed:                      "Image hasn't been refreshed",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +163,18 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case InstanceExpiring:
+		return SeverityModerate
+	case InstanceWatchdogTriggered:
+		return SeverityModerate
+	case StoragePoolNearlyFull:
+		return SeverityModerate
+	case ProjectLimitsNearlyReached:
+		return SeverityModerate
+	case CertificateExpiring:
+		return SeverityModerate
+	case ImageNotRefreshed:
+		return SeverityLow
 	}
 
 	return SeverityLow
@@ -64,6 +64,8 @@ type RunConfig struct {
 	USBDevice        []USBDeviceItem  // USB device configuration settings.
 	TPMDevice        []RunConfigItem  // TPM device configuration settings.
 	PCIDevice        []RunConfigItem  // PCI device configuration settings.
+	WatchdogDevice   []RunConfigItem  // Watchdog device configuration settings.
+	SerialDevice     []RunConfigItem  // Serial device configuration settings.
 	Revert           revert.Hook      // Revert setup of device on post-setup error.
 }
 
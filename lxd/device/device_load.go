@@ -81,6 +81,10 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 		dev = &tpm{}
 	case "pci":
 		dev = &pci{}
+	case "watchdog":
+		dev = &watchdog{}
+	case "serial":
+		dev = &serial{}
 	}
 
 	// Check a valid device type has been found.
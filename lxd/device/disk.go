@@ -23,6 +23,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/secrets"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
 	"github.com/canonical/lxd/lxd/storage/filesystem"
@@ -112,6 +113,11 @@ func (d *disk) sourceIsCeph() bool {
 	return strings.HasPrefix(d.config["source"], "ceph:")
 }
 
+// sourceIsSecrets returns true if the disks source config setting is a list of project secrets.
+func (d *disk) sourceIsSecrets() bool {
+	return strings.HasPrefix(d.config["source"], secrets.SourcePrefix)
+}
+
 // CanHotPlug returns whether the device can be managed whilst the instance is running.
 func (d *disk) CanHotPlug() bool {
 	// Containers support hot-plugging all disk types.
@@ -140,7 +146,8 @@ func (d *disk) isRequired(devConfig deviceConfig.Device) bool {
 }
 
 // sourceIsLocalPath returns true if the source supplied should be considered a local path on the host.
-// It returns false if the disk source is empty, a VM cloud-init config drive, or a remote ceph/cephfs path.
+// It returns false if the disk source is empty, a VM cloud-init config drive, a remote ceph/cephfs path or a list
+// of project secrets.
 func (d *disk) sourceIsLocalPath(source string) bool {
 	if source == "" {
 		return false
@@ -150,7 +157,7 @@ func (d *disk) sourceIsLocalPath(source string) bool {
 		return false
 	}
 
-	if d.sourceIsCeph() || d.sourceIsCephFs() {
+	if d.sourceIsCeph() || d.sourceIsCephFs() || d.sourceIsSecrets() {
 		return false
 	}
 
@@ -390,6 +397,26 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Invalid options ceph.cluster_name/ceph.user_name for source %q", d.config["source"])
 	}
 
+	// Check secrets disks reference valid secrets and are mounted at a path.
+	if d.sourceIsSecrets() {
+		err := secrets.ValidateSource(d.config["source"])
+		if err != nil {
+			return err
+		}
+
+		if d.config["pool"] != "" {
+			return fmt.Errorf(`The "pool" property cannot be used with secrets disks`)
+		}
+
+		if d.config["path"] == "" {
+			return fmt.Errorf(`Secrets disks require a "path" to be defined`)
+		}
+
+		if shared.IsTrue(d.config["shift"]) || d.config["raw.mount.options"] != "" || d.config["propagation"] != "" {
+			return fmt.Errorf(`The "shift", "raw.mount.options" and "propagation" properties cannot be used with secrets disks`)
+		}
+	}
+
 	// Check no other devices also have the same path as us. Use LocalDevices for this check so
 	// that we can check before the config is expanded or when a profile is being checked.
 	// Don't take into account the device names, only count active devices that point to the
@@ -695,7 +722,7 @@ func (d *disk) Start() (*deviceConfig.RunConfig, error) {
 // startContainer starts the disk device for a container instance.
 func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 	runConf := deviceConfig.RunConfig{}
-	isReadOnly := shared.IsTrue(d.config["readonly"])
+	isReadOnly := shared.IsTrue(d.config["readonly"]) || d.sourceIsSecrets()
 
 	// Apply cgroups only after all the mounts have been processed.
 	runConf.PostHooks = append(runConf.PostHooks, func() error {
@@ -1069,14 +1096,14 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 				mount.Opts = append(mount.Opts, d.detectVMPoolMountOpts()...)
 			}
 
-			if shared.IsTrue(d.config["readonly"]) {
+			if shared.IsTrue(d.config["readonly"]) || d.sourceIsSecrets() {
 				mount.Opts = append(mount.Opts, "ro")
 			}
 
-			// If the source being added is a directory or cephfs share, then we will use the lxd-agent
-			// directory sharing feature to mount the directory inside the VM, and as such we need to
-			// indicate to the VM the target path to mount to.
-			if shared.IsDir(mount.DevPath) || d.sourceIsCephFs() {
+			// If the source being added is a directory, a cephfs share or a list of secrets, then we will
+			// use the lxd-agent directory sharing feature to mount the directory inside the VM, and as such
+			// we need to indicate to the VM the target path to mount to.
+			if shared.IsDir(mount.DevPath) || d.sourceIsCephFs() || d.sourceIsSecrets() {
 				if d.config["path"] == "" {
 					return nil, fmt.Errorf(`Missing mount "path" setting`)
 				}
@@ -1575,6 +1602,11 @@ func (d *disk) mountPoolVolume() (func(), string, *storagePools.MountInfo, error
 // The srcPath argument is the source of the disk device on the host.
 // Returns the created device path, and whether the path is a file or not.
 func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
+	if d.sourceIsSecrets() {
+		revertFunc, devPath, err := d.createSecretsDevice()
+		return revertFunc, devPath, false, err
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -1711,6 +1743,100 @@ func (d *disk) createDevice(srcPath string) (func(), string, bool, error) {
 	return cleanup, devPath, isFile, err
 }
 
+// createSecretsDevice mounts a tmpfs in the instance devices directory containing one read-only file per secret
+// referenced by the disk source, named after the secret and owned by the root user of the instance.
+func (d *disk) createSecretsDevice() (func(), string, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	names, _ := secrets.ParseSource(d.config["source"])
+	values, err := secrets.Values(context.TODO(), d.state, d.inst.Project().Name, names)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed loading secrets: %w", err)
+	}
+
+	// Get the host IDs of the root user of containers.
+	var uid, gid int64
+	if d.inst.Type() == instancetype.Container {
+		c, ok := d.inst.(instance.Container)
+		if !ok {
+			return nil, "", fmt.Errorf("Failed to cast instance %q to container", d.inst.Name())
+		}
+
+		var idmapSet *idmap.IdmapSet
+		if c.IsRunning() {
+			idmapSet, err = c.CurrentIdmap()
+		} else {
+			idmapSet, err = c.NextIdmap()
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		if idmapSet != nil {
+			uid, gid = idmapSet.ShiftIntoNs(0, 0)
+		}
+	}
+
+	devPath := d.getDevicePath(d.name, d.config)
+
+	// Create the devices directory if missing.
+	if !shared.PathExists(d.inst.DevicesPath()) {
+		err := os.Mkdir(d.inst.DevicesPath(), 0711)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Clean any existing entry.
+	if shared.PathExists(devPath) {
+		err := os.Remove(devPath)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	err = os.Mkdir(devPath, 0700)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Keep the secrets in memory only.
+	flags := uintptr(unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC)
+	size := (len(values) + 1) * secrets.MaxValueSize
+	err = unix.Mount("tmpfs", devPath, "tmpfs", flags, fmt.Sprintf("size=%d,mode=0500,uid=%d,gid=%d", size, uid, gid))
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed mounting secrets tmpfs at %q: %w", devPath, err)
+	}
+
+	revert.Add(func() { _ = DiskMountClear(devPath) })
+
+	for name, value := range values {
+		secretPath := filepath.Join(devPath, name)
+
+		err = os.WriteFile(secretPath, value, 0400)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed writing secret %q: %w", name, err)
+		}
+
+		err = os.Chown(secretPath, int(uid), int(gid))
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed setting ownership of secret %q: %w", name, err)
+		}
+	}
+
+	// Prevent the secrets from being modified.
+	err = unix.Mount("", devPath, "", flags|unix.MS_REMOUNT|unix.MS_RDONLY, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed remounting secrets tmpfs at %q read-only: %w", devPath, err)
+	}
+
+	cleanup := revert.Clone().Fail // Clone before calling revert.Success() so we can return the Fail func.
+	revert.Success()
+	return cleanup, devPath, nil
+}
+
 // localSourceOpen opens a local disk source path and returns a file handle to it.
 // If d.restrictedParentSourcePath has been set during validation, then the openat2 syscall is used to ensure that
 // the srcPath opened doesn't resolve above the allowed parent source path.
@@ -25,6 +25,7 @@ import (
 	"github.com/canonical/lxd/lxd/linux"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/secrets"
 	"github.com/canonical/lxd/lxd/subprocess"
 	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
@@ -159,6 +160,55 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: Whether to use the HAProxy PROXY protocol
 		"proxy_protocol": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=http)
+		// In HTTP mode, LXD handles the HTTP requests on the listen address and forwards them to the instance with an `X-Forwarded-For` header.
+		// Proxy devices of several instances can share the same listen address and route requests by host name (see `http.hostnames`).
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  required: no
+		//  shortdesc: Whether to proxy HTTP requests
+		"http": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=http.hostnames)
+		// Comma-separated list of host names, matched against the TLS server name (SNI) or the `Host` header of the requests.
+		// If empty, the device receives the requests that don't match the host names of the other proxy devices on the same listen address.
+		// ---
+		//  type: string
+		//  required: no
+		//  condition: HTTP mode
+		//  shortdesc: Host names routed to the instance
+		"http.hostnames": validate.Optional(validate.IsListOf(validate.IsHostname)),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=http.tls)
+		// The TLS connections are terminated with the certificate set in `http.tls.certificate`.
+		// All proxy devices on the same listen address must use the same value.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  required: no
+		//  condition: HTTP mode
+		//  shortdesc: Whether to terminate TLS on the listen address
+		"http.tls": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=http.tls.certificate)
+		// Set to `acme` to use the server certificate issued through ACME for {config:option}`server-acme:acme.domain`,
+		// or to `secrets:<name>` to use the certificate and private key stored in PEM format in a secret of the project.
+		// The certificate is used for the TLS connections to the host names of the device, and for those without a matching host name if the device has no host names.
+		// ---
+		//  type: string
+		//  required: yes
+		//  condition: HTTP mode with TLS
+		//  shortdesc: Source of the certificate of the host names
+		"http.tls.certificate": validate.Optional(func(value string) error {
+			names, ok := secrets.ParseSource(value)
+			if !ok {
+				return validate.IsOneOf("acme")(value)
+			}
+
+			if len(names) != 1 {
+				return fmt.Errorf("A single secret must be referenced")
+			}
+
+			return secrets.ValidName(names[0])
+		}),
 	}
 
 	err := d.config.Validate(rules)
@@ -166,8 +216,8 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
-	if instConf.Type() == instancetype.VM && shared.IsFalseOrEmpty(d.config["nat"]) {
-		return fmt.Errorf("Only NAT mode is supported for proxies on VM instances")
+	if instConf.Type() == instancetype.VM && shared.IsFalseOrEmpty(d.config["nat"]) && shared.IsFalseOrEmpty(d.config["http"]) {
+		return fmt.Errorf("Only NAT and HTTP modes are supported for proxies on VM instances")
 	}
 
 	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
@@ -199,6 +249,23 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Only proxy devices for non-abstract unix sockets can carry uid, gid, or mode properties")
 	}
 
+	if shared.IsTrue(d.config["http"]) {
+		err = d.validateHTTP(listenAddr, connectAddr)
+		if err != nil {
+			return err
+		}
+	} else if d.config["http.hostnames"] != "" || d.config["http.tls"] != "" || d.config["http.tls.certificate"] != "" {
+		return fmt.Errorf(`The "http.hostnames", "http.tls" and "http.tls.certificate" options require HTTP mode`)
+	}
+
+	if d.config["http.tls.certificate"] != "" && shared.IsFalseOrEmpty(d.config["http.tls"]) {
+		return fmt.Errorf(`The "http.tls.certificate" option requires "http.tls" to be enabled`)
+	}
+
+	if d.config["http.tls.certificate"] == "" && shared.IsTrue(d.config["http.tls"]) {
+		return fmt.Errorf(`The "http.tls" option requires "http.tls.certificate" to be set`)
+	}
+
 	if shared.IsTrue(d.config["nat"]) {
 		if d.inst != nil {
 			// Default project always has networks feature so don't bother loading the project config
@@ -248,6 +315,28 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 	return nil
 }
 
+// validateHTTP checks the listen and connect addresses of a proxy in HTTP mode.
+func (d *proxy) validateHTTP(listenAddr *deviceConfig.ProxyAddress, connectAddr *deviceConfig.ProxyAddress) error {
+	if shared.IsTrue(d.config["nat"]) || shared.IsTrue(d.config["proxy_protocol"]) {
+		return fmt.Errorf("HTTP mode cannot be combined with NAT or the PROXY protocol")
+	}
+
+	if d.config["bind"] != "" && d.config["bind"] != "host" {
+		return fmt.Errorf("Only host-bound proxies can use HTTP mode")
+	}
+
+	if listenAddr.ConnType != "tcp" || len(listenAddr.Ports) != 1 {
+		return fmt.Errorf("HTTP mode requires a single TCP listen port")
+	}
+
+	// Requests are forwarded to the loopback interface of the instance.
+	if connectAddr.ConnType != "tcp" || len(connectAddr.Ports) != 1 || !net.ParseIP(connectAddr.Address).IsLoopback() {
+		return fmt.Errorf("HTTP mode requires a single TCP connect port on a loopback address of the instance")
+	}
+
+	return nil
+}
+
 // validateEnvironment checks the runtime environment for correctness.
 func (d *proxy) validateEnvironment() error {
 	if d.name == "" {
@@ -292,6 +381,15 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 	runConf := deviceConfig.RunConfig{}
 	runConf.PostHooks = []func() error{
 		func() error {
+			if shared.IsTrue(d.config["http"]) {
+				err = d.startHTTP()
+				if err != nil {
+					return fmt.Errorf("Failed to start device %q: %w", d.name, err)
+				}
+
+				return nil // HTTP proxies are handled by LXD.
+			}
+
 			if shared.IsTrue(d.config["nat"]) {
 				err = d.setupNAT()
 				if err != nil {
@@ -416,8 +514,21 @@ func (d *proxy) checkProcStarted(logPath string) (bool, error) {
 	return false, nil
 }
 
+// Register is run after the LXD daemon restarts to set up the HTTP proxy again, as it runs within the daemon.
+func (d *proxy) Register() error {
+	if shared.IsFalseOrEmpty(d.config["http"]) {
+		return nil
+	}
+
+	return d.startHTTP()
+}
+
 // Stop is run when the device is removed from the instance.
 func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
+	if shared.IsTrue(d.config["http"]) {
+		return nil, d.stopHTTP()
+	}
+
 	// Remove possible iptables entries
 	err := d.state.Firewall.InstanceClearProxyNAT(d.inst.Project().Name, d.inst.Name(), d.name)
 	if err != nil {
@@ -0,0 +1,432 @@
+package device
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/secrets"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// httpProxyFrontends are the listeners shared by the proxy devices in HTTP mode, indexed by listen address.
+// A listen address is only shared by the proxy devices of a single project.
+var httpProxyFrontends = map[string]*httpProxyFrontend{}
+var httpProxyFrontendsMu sync.Mutex
+
+// httpProxyFrontend is a listener of the HTTP proxy which routes the requests to the proxy devices by host name.
+type httpProxyFrontend struct {
+	address string
+	project string
+	tls     bool
+	server  *http.Server
+
+	// Routes indexed by host name, the default route uses an empty host name.
+	routesMu sync.RWMutex
+	routes   map[string]*httpProxyRoute
+}
+
+// httpProxyRoute forwards the requests for some host names to a port of an instance.
+type httpProxyRoute struct {
+	inst      instance.Instance
+	devName   string
+	hostnames []string
+	proxy     *httputil.ReverseProxy
+	transport *http.Transport
+
+	// Returns the certificate presented for the host names of the route.
+	certificate func() (*tls.Certificate, error)
+
+	// Number of requests indexed by host name and status code.
+	requestsMu sync.Mutex
+	requests   map[string]map[int]uint64
+}
+
+// httpProxyStatusRecorder records the status code of the response.
+type httpProxyStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it to the response.
+func (w *httpProxyStatusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the implicit status code and writes the data to the response.
+func (w *httpProxyStatusRecorder) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap returns the original response writer, which allows upgraded connections to be hijacked.
+func (w *httpProxyStatusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// startHTTP adds the routes of the device to the HTTP proxy listening on its listen address.
+func (d *proxy) startHTTP() error {
+	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
+	if err != nil {
+		return err
+	}
+
+	connectAddr, err := network.ProxyParseAddr(d.config["connect"])
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(listenAddr.Address, strconv.FormatUint(listenAddr.Ports[0], 10))
+	port := int(connectAddr.Ports[0])
+
+	route := &httpProxyRoute{
+		inst:      d.inst,
+		devName:   d.name,
+		hostnames: shared.SplitNTrimSpace(strings.ToLower(d.config["http.hostnames"]), ",", -1, true),
+		requests:  map[string]map[int]uint64{},
+	}
+
+	if len(route.hostnames) == 0 {
+		route.hostnames = []string{""}
+	}
+
+	if shared.IsTrue(d.config["http.tls"]) {
+		route.certificate, err = d.httpCertificate(d.config["http.tls.certificate"])
+		if err != nil {
+			return err
+		}
+	}
+
+	// Connections to the backend are made to the loopback interface of the instance.
+	route.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			return route.inst.PortForwardConn(port)
+		},
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	route.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(&url.URL{Scheme: "http", Host: r.In.Host})
+			r.Out.Host = r.In.Host
+			r.SetXForwarded()
+		},
+		Transport: route.transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Debug("Failed proxying HTTP request", logger.Ctx{"project": route.inst.Project().Name, "instance": route.inst.Name(), "device": route.devName, "err": err})
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	httpProxyFrontendsMu.Lock()
+	defer httpProxyFrontendsMu.Unlock()
+
+	frontend, ok := httpProxyFrontends[address]
+	if !ok {
+		frontend, err = d.newHTTPProxyFrontend(address, shared.IsTrue(d.config["http.tls"]))
+		if err != nil {
+			return err
+		}
+
+		httpProxyFrontends[address] = frontend
+	} else if frontend.project != d.inst.Project().Name {
+		return fmt.Errorf("Listen address %q is already used by HTTP proxies of another project", address)
+	} else if frontend.tls != shared.IsTrue(d.config["http.tls"]) {
+		return fmt.Errorf("Listen address %q is already used by HTTP proxies with a different TLS setting", address)
+	}
+
+	frontend.routesMu.Lock()
+	defer frontend.routesMu.Unlock()
+
+	for _, hostname := range route.hostnames {
+		existing, ok := frontend.routes[hostname]
+		if ok && !existing.sameDevice(route) {
+			if hostname == "" {
+				return fmt.Errorf("Listen address %q already has a default route from device %q of instance %q", address, existing.devName, existing.inst.Name())
+			}
+
+			return fmt.Errorf("Host name %q on listen address %q is already routed to device %q of instance %q", hostname, address, existing.devName, existing.inst.Name())
+		}
+	}
+
+	// Replace the routes of the device if it's registered again.
+	frontend.removeRoutesLocked(route)
+
+	for _, hostname := range route.hostnames {
+		frontend.routes[hostname] = route
+	}
+
+	return nil
+}
+
+// stopHTTP removes the routes of the device from the HTTP proxy and stops it if it has no routes left.
+func (d *proxy) stopHTTP() error {
+	httpProxyFrontendsMu.Lock()
+	defer httpProxyFrontendsMu.Unlock()
+
+	for address, frontend := range httpProxyFrontends {
+		frontend.routesMu.Lock()
+		frontend.removeRoutesLocked(&httpProxyRoute{inst: d.inst, devName: d.name})
+		remaining := len(frontend.routes)
+		frontend.routesMu.Unlock()
+
+		if remaining > 0 {
+			continue
+		}
+
+		delete(httpProxyFrontends, address)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := frontend.server.Shutdown(ctx)
+		cancel()
+		if err != nil {
+			_ = frontend.server.Close()
+		}
+	}
+
+	return nil
+}
+
+// newHTTPProxyFrontend starts an HTTP proxy listening on the address.
+func (d *proxy) newHTTPProxyFrontend(address string, useTLS bool) (*httpProxyFrontend, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listening on %q: %w", address, err)
+	}
+
+	frontend := &httpProxyFrontend{
+		address: address,
+		project: d.inst.Project().Name,
+		tls:     useTLS,
+		routes:  map[string]*httpProxyRoute{},
+	}
+
+	frontend.server = &http.Server{
+		Handler:           frontend,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	if useTLS {
+		// The certificate is looked up for each connection so that renewed certificates are used.
+		frontend.server.TLSConfig = shared.InitTLSConfig()
+		frontend.server.TLSConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			route := frontend.route(hello.ServerName)
+			if route == nil {
+				return nil, fmt.Errorf("No route for host %q", hello.ServerName)
+			}
+
+			return route.certificate()
+		}
+
+		listener = tls.NewListener(listener, frontend.server.TLSConfig)
+	}
+
+	go func() {
+		err := frontend.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP proxy stopped", logger.Ctx{"address": address, "err": err})
+		}
+	}()
+
+	return frontend, nil
+}
+
+// httpProxyHostname returns the normalized host name of a Host header or TLS server name.
+func httpProxyHostname(hostname string) string {
+	host, _, err := net.SplitHostPort(hostname)
+	if err == nil {
+		hostname = host
+	}
+
+	return strings.ToLower(strings.TrimSuffix(hostname, "."))
+}
+
+// route returns the route of the host name, or the default route if no route matches it.
+func (f *httpProxyFrontend) route(hostname string) *httpProxyRoute {
+	f.routesMu.RLock()
+	defer f.routesMu.RUnlock()
+
+	route, ok := f.routes[httpProxyHostname(hostname)]
+	if !ok {
+		route = f.routes[""]
+	}
+
+	return route
+}
+
+// ServeHTTP routes the request to the instance serving its host name, or to the default route.
+// Requests whose Host header doesn't match the TLS server name are rejected, as the connection was established
+// with the certificate of another host name.
+func (f *httpProxyFrontend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hostname := httpProxyHostname(r.Host)
+	if r.TLS != nil && r.TLS.ServerName != "" && httpProxyHostname(r.TLS.ServerName) != hostname {
+		http.Error(w, "Host doesn't match the TLS server name", http.StatusMisdirectedRequest)
+		return
+	}
+
+	f.routesMu.RLock()
+	route, ok := f.routes[hostname]
+	if !ok {
+		hostname = ""
+		route, ok = f.routes[hostname]
+	}
+
+	f.routesMu.RUnlock()
+
+	if !ok {
+		http.Error(w, "No route for host", http.StatusNotFound)
+		return
+	}
+
+	recorder := &httpProxyStatusRecorder{ResponseWriter: w}
+	route.proxy.ServeHTTP(recorder, r)
+
+	if recorder.status == 0 {
+		// Upgraded connections are hijacked before a status is recorded.
+		recorder.status = http.StatusSwitchingProtocols
+	}
+
+	route.requestsMu.Lock()
+	if route.requests[hostname] == nil {
+		route.requests[hostname] = map[int]uint64{}
+	}
+
+	route.requests[hostname][recorder.status]++
+	route.requestsMu.Unlock()
+}
+
+// httpCertificate returns a function returning the certificate of the given source for each TLS connection.
+// Certificates stored in secrets are loaded when the device is started.
+func (d *proxy) httpCertificate(source string) (func() (*tls.Certificate, error), error) {
+	switch source {
+	case "acme":
+		// The server certificate is only used once it has been issued through ACME, so that the self-signed
+		// certificate of the LXD API is never presented for the traffic of the instances.
+		acmeIssued := func() bool {
+			domain, _, _, _ := d.state.GlobalConfig.ACME()
+			if domain == "" {
+				return false
+			}
+
+			// The self-signed certificate generated by LXD signs itself.
+			cert, err := d.state.Endpoints.NetworkCert().PublicKeyX509()
+			return err == nil && cert.VerifyHostname(domain) == nil && cert.CheckSignatureFrom(cert) != nil
+		}
+
+		if !acmeIssued() {
+			return nil, fmt.Errorf(`The "acme" certificate requires a server certificate issued through ACME for "acme.domain"`)
+		}
+
+		return func() (*tls.Certificate, error) {
+			if !acmeIssued() {
+				return nil, errors.New("No certificate issued through ACME")
+			}
+
+			keyPair := d.state.Endpoints.NetworkCert().KeyPair()
+			return &keyPair, nil
+		}, nil
+	}
+
+	names, ok := secrets.ParseSource(source)
+	if !ok || len(names) != 1 {
+		return nil, fmt.Errorf("Unknown certificate source %q", source)
+	}
+
+	values, err := secrets.Values(context.TODO(), d.state, d.inst.Project().Name, names)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading certificate secret %q: %w", names[0], err)
+	}
+
+	// The key pair parser skips the blocks it doesn't expect, so that the secret holds both in a single value.
+	certificate, err := tls.X509KeyPair(values[names[0]], values[names[0]])
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing certificate secret %q: %w", names[0], err)
+	}
+
+	return func() (*tls.Certificate, error) {
+		return &certificate, nil
+	}, nil
+}
+
+// removeRoutesLocked removes the routes of the device of the given route, the caller must hold routesMu.
+func (f *httpProxyFrontend) removeRoutesLocked(route *httpProxyRoute) {
+	for hostname, existing := range f.routes {
+		if existing.sameDevice(route) {
+			delete(f.routes, hostname)
+			existing.transport.CloseIdleConnections()
+		}
+	}
+}
+
+// sameDevice returns whether both routes belong to the same proxy device.
+func (r *httpProxyRoute) sameDevice(other *httpProxyRoute) bool {
+	return r.inst.ID() == other.inst.ID() && r.devName == other.devName
+}
+
+// HTTPProxyMetrics returns the request counters of the proxy devices in HTTP mode of the instance.
+func HTTPProxyMetrics(inst instance.Instance) *metrics.MetricSet {
+	set := metrics.NewMetricSet(map[string]string{"project": inst.Project().Name, "name": inst.Name(), "type": inst.Type().String()})
+
+	httpProxyFrontendsMu.Lock()
+	defer httpProxyFrontendsMu.Unlock()
+
+	// Sort the addresses so that the output is stable.
+	addresses := make([]string, 0, len(httpProxyFrontends))
+	for address := range httpProxyFrontends {
+		addresses = append(addresses, address)
+	}
+
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		frontend := httpProxyFrontends[address]
+
+		frontend.routesMu.RLock()
+		seen := map[*httpProxyRoute]bool{}
+		for _, route := range frontend.routes {
+			if seen[route] || route.inst.ID() != inst.ID() {
+				continue
+			}
+
+			seen[route] = true
+
+			route.requestsMu.Lock()
+			for hostname, codes := range route.requests {
+				for code, count := range codes {
+					set.AddSamples(metrics.ProxyHTTPRequestsTotal, metrics.Sample{
+						Labels: map[string]string{"device": route.devName, "host": hostname, "code": strconv.Itoa(code)},
+						Value:  float64(count),
+					})
+				}
+			}
+
+			route.requestsMu.Unlock()
+		}
+
+		frontend.routesMu.RUnlock()
+	}
+
+	return set
+}
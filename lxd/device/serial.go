@@ -0,0 +1,417 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+type serial struct {
+	deviceCommon
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running.
+func (d *serial) CanHotPlug() bool {
+	return true
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *serial) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	if instConf.Type() == instancetype.VM && instConf.Architecture() == osarch.ARCH_64BIT_S390_BIG_ENDIAN {
+		return fmt.Errorf("Serial devices aren't supported on s390x")
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-serial; group=device-conf; key=backend)
+		// Possible values are:
+		//
+		// - `pty`: Connect the serial port to a terminal that is accessed with `lxc console --device`
+		// - `unix`: Expose the serial port as a Unix socket on the host
+		// - `tcp`: Expose the serial port on a TCP address of the host
+		// - `file`: Write the output of the serial port to a `serial_<device_name>.log` file in the log directory of the instance
+		// ---
+		//  type: string
+		//  defaultdesc: `pty`
+		//  required: no
+		//  shortdesc: Where the host side of the serial port is connected
+		"backend": validate.Optional(validate.IsOneOf("pty", "unix", "tcp", "file")),
+
+		// lxdmeta:generate(entities=device-serial; group=device-conf; key=listen)
+		// For the `unix` backend, specify the absolute path of the socket on the host, for example, `/run/serial-c1.sock`.
+		// For the `tcp` backend, specify the address and port to listen on, for example, `127.0.0.1:4555`.
+		// Neither a custom socket path nor the `tcp` backend can be used in restricted projects (see {config:option}`project-restricted:restricted.devices.serial`).
+		// ---
+		//  type: string
+		//  defaultdesc: socket in the devices directory of the instance for `unix`
+		//  required: for `tcp`
+		//  condition: `unix` or `tcp` backend
+		//  shortdesc: Host address to listen on
+		"listen": validate.IsAny,
+
+		// lxdmeta:generate(entities=device-serial; group=device-conf; key=path)
+		// For example: `/dev/ttyS1`
+		// ---
+		//  type: string
+		//  required: for containers
+		//  condition: containers
+		//  shortdesc: Path of the serial port inside the container
+		"path": validate.IsAny,
+	}
+
+	switch d.config["backend"] {
+	case "unix":
+		rules["listen"] = validate.Optional(validate.IsAbsFilePath)
+	case "tcp":
+		rules["listen"] = validate.IsListenAddress(false, true, true)
+	}
+
+	if instConf.Type() == instancetype.Container {
+		rules["path"] = validate.IsAbsFilePath
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	if d.config["listen"] != "" && !shared.ValueInSlice(d.config["backend"], []string{"unix", "tcp"}) {
+		return fmt.Errorf(`The "listen" option is only supported by the "unix" and "tcp" backends`)
+	}
+
+	if instConf.Type() == instancetype.VM && d.config["path"] != "" {
+		return fmt.Errorf(`The "path" option is only supported by containers`)
+	}
+
+	return nil
+}
+
+// Start is run when the device is added to the instance.
+func (d *serial) Start() (*deviceConfig.RunConfig, error) {
+	backend, address := serialBackend(d.inst, d.name, d.config)
+
+	if d.inst.Type() == instancetype.VM {
+		runConf := deviceConfig.RunConfig{
+			SerialDevice: []deviceConfig.RunConfigItem{
+				{Key: "devName", Value: d.name},
+				{Key: "backend", Value: backend},
+				{Key: "address", Value: address},
+			},
+		}
+
+		return &runConf, nil
+	}
+
+	return d.startContainer(backend, address)
+}
+
+// startContainer creates a pseudo terminal for the container and starts the process that connects it to the
+// backend.
+func (d *serial) startContainer(backend string, address string) (*deviceConfig.RunConfig, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// The ownership of the terminal is shifted when it's mounted into the container.
+	ptx, pty, err := shared.OpenPty(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating terminal: %w", err)
+	}
+
+	defer func() {
+		_ = ptx.Close()
+		_ = pty.Close()
+	}()
+
+	files := []*os.File{ptx, pty}
+
+	// The listener is created by LXD so that it can keep track of the sockets it creates.
+	if backend != "file" {
+		listener, err := SerialListen(d.inst, d.name, backend, address)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = serialRemoveSocket(d.inst, d.name) })
+
+		listenerFile, err := serialListenerFile(listener)
+		_ = listener.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = listenerFile.Close() }()
+
+		files = append(files, listenerFile)
+	}
+
+	logPath := filepath.Join(d.inst.LogPath(), fmt.Sprintf("forkserial.%s.log", d.name))
+	proc, err := subprocess.NewProcess(d.state.OS.ExecPath, []string{"forkserial", backend, address}, logPath, logPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The process keeps both sides of the terminal open so that it persists while the container isn't using it.
+	err = proc.StartWithFiles(context.Background(), files)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start forkserial for device %q: %w", d.name, err)
+	}
+
+	revert.Add(func() { _ = proc.Stop() })
+
+	err = proc.Save(d.pidPath())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to save forkserial state for device %q: %w", d.name, err)
+	}
+
+	runConf := deviceConfig.RunConfig{}
+	runConf.Mounts = append(runConf.Mounts, deviceConfig.MountEntryItem{
+		DevPath:    pty.Name(),
+		TargetPath: d.config["path"],
+		FSType:     "none",
+		Opts:       []string{"bind", "create=file"},
+		OwnerShift: deviceConfig.MountOwnerShiftStatic,
+	})
+
+	revert.Success()
+
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *serial) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.postStop},
+	}
+
+	if d.inst.Type() == instancetype.Container {
+		runConf.Mounts = append(runConf.Mounts, deviceConfig.MountEntryItem{
+			TargetPath: d.config["path"],
+		})
+	}
+
+	return &runConf, nil
+}
+
+// postStop is run after the device is removed from the instance.
+func (d *serial) postStop() error {
+	pidPath := d.pidPath()
+	if shared.PathExists(pidPath) {
+		proc, err := subprocess.ImportProcess(pidPath)
+		if err != nil {
+			return fmt.Errorf("Failed to import process %q: %w", pidPath, err)
+		}
+
+		err = proc.Stop()
+		if err != nil && err != subprocess.ErrNotRunning {
+			return fmt.Errorf("Failed to stop forkserial for device %q: %w", d.name, err)
+		}
+
+		_ = os.Remove(pidPath)
+	}
+
+	return serialRemoveSocket(d.inst, d.name)
+}
+
+// pidPath returns the path of the PID file of the forkserial process of the device.
+func (d *serial) pidPath() string {
+	return filepath.Join(d.inst.DevicesPath(), fmt.Sprintf("%s.pid", d.name))
+}
+
+// serialBackend returns the backend of a serial device and the host address it's connected to, which is the path
+// of a Unix socket for the "pty" and "unix" backends, a TCP address for the "tcp" backend and the path of the log
+// file for the "file" backend.
+func serialBackend(inst instance.Instance, devName string, devConfig deviceConfig.Device) (backend string, address string) {
+	backend = devConfig["backend"]
+	if backend == "" {
+		backend = "pty"
+	}
+
+	switch backend {
+	case "tcp":
+		return backend, devConfig["listen"]
+	case "file":
+		return backend, filepath.Join(inst.LogPath(), fmt.Sprintf("serial_%s.log", devName))
+	case "unix":
+		if devConfig["listen"] != "" {
+			return backend, devConfig["listen"]
+		}
+	}
+
+	return backend, filepath.Join(inst.DevicesPath(), fmt.Sprintf("serial.%s.sock", devName))
+}
+
+// serialSocketRecordPath returns the path of the file recording the Unix socket created by a serial device.
+func serialSocketRecordPath(inst instance.Instance, devName string) string {
+	return filepath.Join(inst.DevicesPath(), fmt.Sprintf("%s.socket", devName))
+}
+
+// SerialListen creates the listener of a serial device with the "pty", "unix" or "tcp" backend.
+// Unix sockets are recorded so that only the sockets created by the device are ever removed, an existing file at
+// the address makes it fail.
+func SerialListen(inst instance.Instance, devName string, backend string, address string) (net.Listener, error) {
+	if backend == "tcp" {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listening on %q: %w", address, err)
+		}
+
+		return listener, nil
+	}
+
+	// Remove the socket left behind by a previous start of the device.
+	err := serialRemoveSocket(inst, devName)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listening on %q: %w", address, err)
+	}
+
+	// The socket is left in place once LXD's copy of the listener is closed.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	reverter.Add(func() {
+		_ = listener.Close()
+		_ = os.Remove(address)
+	})
+
+	err = os.Chmod(address, 0660)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(address)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, ok := info.Sys().(*unix.Stat_t)
+	if !ok {
+		return nil, fmt.Errorf("Failed getting inode of %q", address)
+	}
+
+	err = os.WriteFile(serialSocketRecordPath(inst, devName), []byte(fmt.Sprintf("%s\n%d:%d\n", address, stat.Dev, stat.Ino)), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed recording the socket of serial device %q: %w", devName, err)
+	}
+
+	reverter.Success()
+
+	return listener, nil
+}
+
+// serialRemoveSocket removes the Unix socket recorded by SerialListen for a serial device, as long as it's still the
+// socket that was created.
+func serialRemoveSocket(inst instance.Instance, devName string) error {
+	recordPath := serialSocketRecordPath(inst, devName)
+
+	content, err := os.ReadFile(recordPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 2 {
+		info, err := os.Lstat(fields[0])
+		if err == nil {
+			stat, ok := info.Sys().(*unix.Stat_t)
+			if ok && info.Mode()&os.ModeSocket != 0 && fmt.Sprintf("%d:%d", stat.Dev, stat.Ino) == fields[1] {
+				err = os.Remove(fields[0])
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.Remove(recordPath)
+}
+
+// serialListenerFile returns a duplicate of the file descriptor of the listener of a serial device.
+func serialListenerFile(listener net.Listener) (*os.File, error) {
+	var f *os.File
+	var err error
+	switch l := listener.(type) {
+	case *net.UnixListener:
+		f, err = l.File()
+	case *net.TCPListener:
+		f, err = l.File()
+	default:
+		return nil, fmt.Errorf("Unsupported listener type %T", listener)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting listener file: %w", err)
+	}
+
+	return f, nil
+}
+
+// SerialConsole connects to the host side of the serial device of a running instance.
+func SerialConsole(inst instance.Instance, devName string) (*os.File, error) {
+	devConfig, ok := inst.ExpandedDevices()[devName]
+	if !ok || devConfig["type"] != "serial" {
+		return nil, fmt.Errorf("Serial device %q not found", devName)
+	}
+
+	backend, address := serialBackend(inst, devName, devConfig)
+
+	var conn net.Conn
+	var err error
+	switch backend {
+	case "pty", "unix":
+		conn, err = net.Dial("unix", address)
+	case "tcp":
+		conn, err = net.Dial("tcp", address)
+	default:
+		return nil, fmt.Errorf("Serial devices with the %q backend don't support console access", backend)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to serial device %q: %w", devName, err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	var file *os.File
+	switch c := conn.(type) {
+	case *net.UnixConn:
+		file, err = c.File()
+	case *net.TCPConn:
+		file, err = c.File()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting socket file: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,120 @@
+package device
+
+import (
+	"fmt"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+type watchdog struct {
+	deviceCommon
+}
+
+// CanMigrate returns whether the device can be migrated to any other cluster member.
+func (d *watchdog) CanMigrate() bool {
+	return true
+}
+
+// UpdatableFields returns a list of fields that can be updated without triggering a device remove & add.
+func (d *watchdog) UpdatableFields(oldDevice Type) []string {
+	// Check old and new device types match.
+	_, match := oldDevice.(*watchdog)
+	if !match {
+		return []string{}
+	}
+
+	// The action is looked up when the watchdog expires.
+	return []string{"action"}
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *watchdog) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-watchdog; group=device-conf; key=model)
+		// Possible values are `i6300esb` (a PCI watchdog) and `itco` (the watchdog of the emulated chipset, only available on `x86_64`).
+		// ---
+		//  type: string
+		//  defaultdesc: `i6300esb`
+		//  required: no
+		//  shortdesc: Watchdog model exposed to the guest
+		"model": validate.Optional(validate.IsOneOf("i6300esb", "itco")),
+
+		// lxdmeta:generate(entities=device-watchdog; group=device-conf; key=action)
+		// Possible values are:
+		//
+		// - `reset`: Forcefully restart the instance
+		// - `poweroff`: Forcefully stop the instance
+		// - `pause`: Pause the instance so that it can be investigated
+		// - `dump-memory`: Write the memory of the instance to a `watchdog_<device_name>.dump` file, then forcefully restart it
+		// ---
+		//  type: string
+		//  defaultdesc: `reset`
+		//  required: no
+		//  shortdesc: Action to take when the watchdog expires
+		"action": validate.Optional(validate.IsOneOf("reset", "poweroff", "pause", "dump-memory")),
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	// Only check the architecture of instances, profiles can be used on any architecture.
+	if instConf.Type() == instancetype.VM {
+		switch d.config["model"] {
+		case "itco":
+			if instConf.Architecture() != osarch.ARCH_64BIT_INTEL_X86 {
+				return fmt.Errorf("The %q watchdog model is only supported on x86_64", "itco")
+			}
+
+		default:
+			if instConf.Architecture() == osarch.ARCH_64BIT_S390_BIG_ENDIAN {
+				return fmt.Errorf("The %q watchdog model isn't supported on s390x", "i6300esb")
+			}
+		}
+	}
+
+	// A VM can only have a single watchdog.
+	for devName, devConfig := range instConf.ExpandedDevices() {
+		if devConfig["type"] == "watchdog" && devName != d.name {
+			return fmt.Errorf("Only one watchdog device is supported, found %q", devName)
+		}
+	}
+
+	return nil
+}
+
+// Start is run when the device is added to the instance.
+func (d *watchdog) Start() (*deviceConfig.RunConfig, error) {
+	model := d.config["model"]
+	if model == "" {
+		model = "i6300esb"
+	}
+
+	runConf := deviceConfig.RunConfig{
+		WatchdogDevice: []deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "model", Value: model},
+		},
+	}
+
+	return &runConf, nil
+}
+
+// Update applies configuration changes to a started device.
+func (d *watchdog) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
+	return nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *watchdog) Stop() (*deviceConfig.RunConfig, error) {
+	return &deviceConfig.RunConfig{}, nil
+}
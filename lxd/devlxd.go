@@ -1,31 +1,47 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/miekg/dns"
 	"golang.org/x/sys/unix"
 
+	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/events"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/zone"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/ucred"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
 	"github.com/canonical/lxd/shared/version"
 	"github.com/canonical/lxd/shared/ws"
 )
@@ -54,11 +70,15 @@ type devLxdHandler struct {
 	f func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response
 }
 
-var devlxdConfigGet = devLxdHandler{"/1.0/config", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+var devlxdConfigHandler = devLxdHandler{"/1.0/config", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
 	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) {
 		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
 	}
 
+	if r.Method == "PATCH" {
+		return devlxdConfigPatch(d, c, r)
+	}
+
 	filtered := []string{}
 	for k := range c.ExpandedConfig() {
 		if strings.HasPrefix(k, "user.") || strings.HasPrefix(k, "cloud-init.") {
@@ -258,17 +278,508 @@ var devlxdDevicesGet = devLxdHandler{"/1.0/devices", func(d *Daemon, c instance.
 	return response.DevLxdResponse(http.StatusOK, c.ExpandedDevices(), "json", c.Type() == instancetype.VM)
 }}
 
+var devlxdSecretsGet = devLxdHandler{"/1.0/secrets", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	filtered := []string{}
+	for _, name := range secrets.DevLXDNames(c.ExpandedConfig()) {
+		filtered = append(filtered, fmt.Sprintf("/1.0/secrets/%s", name))
+	}
+
+	return response.DevLxdResponse(http.StatusOK, filtered, "json", c.Type() == instancetype.VM)
+}}
+
+var devlxdSecretGet = devLxdHandler{"/1.0/secrets/{name}", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "bad request"), c.Type() == instancetype.VM)
+	}
+
+	// Only the secrets granted to the instance can be read.
+	if !shared.ValueInSlice(name, secrets.DevLXDNames(c.ExpandedConfig())) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	values, err := secrets.Values(r.Context(), d.State(), c.Project().Name, []string{name})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusNotFound, "not found"), c.Type() == instancetype.VM)
+		}
+
+		logger.Warn("Failed loading secret for devlxd", logger.Ctx{"instance": c.Name(), "project": c.Project().Name, "secret": name, "err": err})
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, string(values[name]), "raw", c.Type() == instancetype.VM)
+}}
+
+// devlxdSetRequestor records the instance as the requestor of a guest-initiated action so that the action is
+// attributed to it in lifecycle events, operations and configuration history.
+func devlxdSetRequestor(r *http.Request, c instance.Instance) {
+	request.SetCtxValue(r, request.CtxUsername, c.Name())
+	request.SetCtxValue(r, request.CtxProtocol, "devlxd")
+}
+
+// devlxdConfigPatch applies the user keys sent by the instance to its local config. Empty values unset the keys.
+// devlxdConfigMaxRequestSize is the maximum size of a configuration change requested by an instance.
+const devlxdConfigMaxRequestSize = 64 * 1024
+
+// devlxdConfigMaxValueSize is the maximum size of a configuration value set by an instance.
+const devlxdConfigMaxValueSize = 4 * 1024
+
+// devlxdConfigChangesPerInterval is the maximum number of configuration changes requested by the same instance
+// within devlxdConfigChangesInterval.
+const devlxdConfigChangesPerInterval = 10
+
+// devlxdConfigChangesInterval is the interval over which the configuration changes requested by an instance are
+// limited.
+const devlxdConfigChangesInterval = time.Minute
+
+var devlxdConfigChangesMu sync.Mutex
+var devlxdConfigChanges = map[int][]time.Time{}
+
+func devlxdConfigPatch(d *Daemon, c instance.Instance, r *http.Request) response.Response {
+	if !instancetype.DevLXDActionAllowed(c.ExpandedConfig(), instancetype.DevLXDActionConfig) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	req := map[string]string{}
+
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, devlxdConfigMaxRequestSize)).Decode(&req)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	config := util.CopyConfig(c.LocalConfig())
+	for key, value := range req {
+		if !instancetype.DevLXDConfigKeyAllowed(c.ExpandedConfig(), key) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "Key %q can't be changed", key), c.Type() == instancetype.VM)
+		}
+
+		if len(value) > devlxdConfigMaxValueSize {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "Value of key %q is larger than %d bytes", key, devlxdConfigMaxValueSize), c.Type() == instancetype.VM)
+		}
+
+		if value == "" {
+			delete(config, key)
+		} else {
+			config[key] = value
+		}
+	}
+
+	// Each change is stored in the database along with a configuration revision, so limit how often an instance
+	// can change its configuration.
+	devlxdConfigChangesMu.Lock()
+	recent := make([]time.Time, 0, devlxdConfigChangesPerInterval)
+	for _, changed := range devlxdConfigChanges[c.ID()] {
+		if time.Since(changed) < devlxdConfigChangesInterval {
+			recent = append(recent, changed)
+		}
+	}
+
+	if len(recent) >= devlxdConfigChangesPerInterval {
+		devlxdConfigChanges[c.ID()] = recent
+		devlxdConfigChangesMu.Unlock()
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusTooManyRequests, "The configuration can only be changed %d times every %s", devlxdConfigChangesPerInterval, devlxdConfigChangesInterval), c.Type() == instancetype.VM)
+	}
+
+	devlxdConfigChanges[c.ID()] = append(recent, time.Now())
+	devlxdConfigChangesMu.Unlock()
+
+	devlxdSetRequestor(r, c)
+
+	args := db.InstanceArgs{
+		Architecture: c.Architecture(),
+		Config:       config,
+		Description:  c.Description(),
+		Devices:      c.LocalDevices(),
+		Ephemeral:    c.IsEphemeral(),
+		Profiles:     c.Profiles(),
+		Project:      c.Project().Name,
+		ExpiryDate:   c.ExpiryDate(),
+	}
+
+	err = instanceUpdateWithHistory(d.State(), r, c, args)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, "", "raw", c.Type() == instancetype.VM)
+}
+
+// devlxdSnapshotInterval is the minimum time between two snapshots requested by the same instance.
+const devlxdSnapshotInterval = time.Minute
+
+var devlxdSnapshotsMu sync.Mutex
+var devlxdSnapshotsLast = map[int]time.Time{}
+
+var devlxdSnapshotsPost = devLxdHandler{"/1.0/snapshots", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if !instancetype.DevLXDActionAllowed(c.ExpandedConfig(), instancetype.DevLXDActionSnapshot) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	if r.Method != "POST" {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), c.Type() == instancetype.VM)
+	}
+
+	s := d.State()
+
+	req := api.DevLXDSnapshotsPost{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), c.Project().Name)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return project.AllowSnapshotCreation(p)
+	})
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	if req.Name == "" {
+		req.Name, err = instanceAutoSnapshotName(s, c)
+		if err != nil {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+		}
+	}
+
+	err = validate.IsURLSegmentSafe(req.Name)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "Invalid snapshot name: %v", err), c.Type() == instancetype.VM)
+	}
+
+	expiry, err := shared.GetExpiry(time.Now(), c.ExpandedConfig()["snapshots.expiry"])
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	// Only one snapshot can be requested at a time and at most once per devlxdSnapshotInterval.
+	devlxdSnapshotsMu.Lock()
+	last, found := devlxdSnapshotsLast[c.ID()]
+	if found && time.Since(last) < devlxdSnapshotInterval {
+		devlxdSnapshotsMu.Unlock()
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusTooManyRequests, "Snapshots can only be created once every %s", devlxdSnapshotInterval), c.Type() == instancetype.VM)
+	}
+
+	devlxdSnapshotsLast[c.ID()] = time.Now()
+	devlxdSnapshotsMu.Unlock()
+
+	devlxdSetRequestor(r, c)
+
+	snapshot := func(op *operations.Operation) error {
+		c.SetOperation(op)
+
+		return c.Snapshot(req.Name, expiry, false)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", c.Name())}
+	resources["instances_snapshots"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", c.Name(), "snapshots", req.Name)}
+
+	op, err := operations.OperationCreate(s, c.Project().Name, operations.OperationClassTask, operationtype.SnapshotCreate, resources, nil, snapshot, nil, nil, r)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	err = op.Start()
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	// The snapshot name is only returned once it exists.
+	err = op.Wait(r.Context())
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, req.Name, "raw", c.Type() == instancetype.VM)
+}}
+
+var devlxdRestartPost = devLxdHandler{"/1.0/restart", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if !instancetype.DevLXDActionAllowed(c.ExpandedConfig(), instancetype.DevLXDActionRestart) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	if r.Method != "POST" {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), c.Type() == instancetype.VM)
+	}
+
+	devlxdSetRequestor(r, c)
+
+	// The restart runs in the background so that the instance gets a response before shutting down.
+	do := func(op *operations.Operation) error {
+		c.SetOperation(op)
+
+		return doInstanceStatePut(c, api.InstanceStatePut{Action: string(instancetype.Restart), Timeout: -1})
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", c.Name())}
+
+	op, err := operations.OperationCreate(d.State(), c.Project().Name, operations.OperationClassTask, operationtype.InstanceRestart, resources, nil, do, nil, nil, r)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	err = op.Start()
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, "", "raw", c.Type() == instancetype.VM)
+}}
+
+// devlxdDNSZones returns the forward DNS zones of the managed networks the instance is connected to.
+func devlxdDNSZones(s *state.State, c instance.Instance) ([]string, error) {
+	networkProjectName, _, err := project.NetworkProject(s.DB.Cluster, c.Project().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := []string{}
+	for _, devConfig := range c.ExpandedDevices() {
+		if devConfig["type"] != "nic" || devConfig["network"] == "" {
+			continue
+		}
+
+		n, err := network.LoadByName(s, networkProjectName, devConfig["network"])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, zoneName := range shared.SplitNTrimSpace(n.Config()["dns.zone.forward"], ",", -1, true) {
+			if !shared.ValueInSlice(zoneName, zones) {
+				zones = append(zones, zoneName)
+			}
+		}
+	}
+
+	sort.Strings(zones)
+
+	return zones, nil
+}
+
+// devlxdDNSRecordAllowed returns whether the instance can manage the DNS record with the given name.
+// Instances can only manage records named after themselves or ending with their name.
+func devlxdDNSRecordAllowed(c instance.Instance, name string) bool {
+	return name == c.Name() || strings.HasSuffix(name, "."+c.Name())
+}
+
+// devlxdDNSRecordOwnerKey is the record config key marking the records created by an instance through devlxd.
+// Its value is the project and name of the instance that owns the record.
+const devlxdDNSRecordOwnerKey = "user.devlxd.instance"
+
+// devlxdDNSRecordOwner returns the owner marker value for records created by the instance.
+func devlxdDNSRecordOwner(c instance.Instance) string {
+	return c.Project().Name + "/" + c.Name()
+}
+
+var devlxdDNSRecordsHandler = devLxdHandler{"/1.0/dns-records", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if !instancetype.DevLXDActionAllowed(c.ExpandedConfig(), instancetype.DevLXDActionDNS) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	s := d.State()
+
+	zones, err := devlxdDNSZones(s, c)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	if r.Method == "GET" {
+		records := []api.DevLXDDNSRecord{}
+		for _, zoneName := range zones {
+			netzone, err := zone.LoadByName(s, zoneName)
+			if err != nil {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+			}
+
+			zoneRecords, err := netzone.GetRecords()
+			if err != nil {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+			}
+
+			for _, record := range zoneRecords {
+				if devlxdDNSRecordAllowed(c, record.Name) && record.Config[devlxdDNSRecordOwnerKey] == devlxdDNSRecordOwner(c) {
+					records = append(records, api.DevLXDDNSRecord{Zone: zoneName, Name: record.Name, Entries: record.Entries})
+				}
+			}
+		}
+
+		return response.DevLxdResponse(http.StatusOK, records, "json", c.Type() == instancetype.VM)
+	} else if r.Method == "POST" {
+		req := api.DevLXDDNSRecord{}
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+		}
+
+		if req.Zone == "" && len(zones) == 1 {
+			req.Zone = zones[0]
+		}
+
+		if !shared.ValueInSlice(req.Zone, zones) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "Zone %q isn't available to the instance", req.Zone), c.Type() == instancetype.VM)
+		}
+
+		_, isDomainName := dns.IsDomainName(req.Name)
+		if !isDomainName || !devlxdDNSRecordAllowed(c, req.Name) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "Record name must be %q or end with %q", c.Name(), "."+c.Name()), c.Type() == instancetype.VM)
+		}
+
+		// Delegations and other zone level records are reserved to the zone owner.
+		for _, entry := range req.Entries {
+			if !shared.ValueInSlice(entry.Type, []string{"A", "AAAA", "CNAME", "SRV", "TXT"}) {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "Record type %q isn't allowed", entry.Type), c.Type() == instancetype.VM)
+			}
+		}
+
+		netzone, err := zone.LoadByName(s, req.Zone)
+		if err != nil {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+		}
+
+		devlxdSetRequestor(r, c)
+
+		recordPut := api.NetworkZoneRecordPut{
+			Description: fmt.Sprintf("Managed by instance %q", c.Name()),
+			Config:      map[string]string{devlxdDNSRecordOwnerKey: devlxdDNSRecordOwner(c)},
+			Entries:     req.Entries,
+		}
+
+		// Records are replaced as a whole if they already exist and were created by the instance.
+		record, err := netzone.GetRecord(req.Name)
+		if err == nil {
+			if record.Config[devlxdDNSRecordOwnerKey] != devlxdDNSRecordOwner(c) {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusConflict, "Record %q already exists and isn't managed by the instance", req.Name), c.Type() == instancetype.VM)
+			}
+
+			for key, value := range record.Config {
+				_, found := recordPut.Config[key]
+				if !found {
+					recordPut.Config[key] = value
+				}
+			}
+
+			err = netzone.UpdateRecord(req.Name, recordPut, clusterRequest.ClientTypeNormal)
+			if err != nil {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+			}
+
+			s.Events.SendLifecycle(netzone.Project(), lifecycle.NetworkZoneRecordUpdated.Event(netzone, req.Name, request.CreateRequestor(r), nil))
+		} else if api.StatusErrorCheck(err, http.StatusNotFound) {
+			err = netzone.AddRecord(api.NetworkZoneRecordsPost{NetworkZoneRecordPut: recordPut, Name: req.Name})
+			if err != nil {
+				return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), c.Type() == instancetype.VM)
+			}
+
+			s.Events.SendLifecycle(netzone.Project(), lifecycle.NetworkZoneRecordCreated.Event(netzone, req.Name, request.CreateRequestor(r), nil))
+		} else {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+		}
+
+		return response.DevLxdResponse(http.StatusOK, "", "raw", c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), c.Type() == instancetype.VM)
+}}
+
+var devlxdDNSRecordDelete = devLxdHandler{"/1.0/dns-records/{zone}/{name}", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if !instancetype.DevLXDActionAllowed(c.ExpandedConfig(), instancetype.DevLXDActionDNS) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	if r.Method != "DELETE" {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), c.Type() == instancetype.VM)
+	}
+
+	s := d.State()
+
+	zoneName, err := url.PathUnescape(mux.Vars(r)["zone"])
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "bad request"), c.Type() == instancetype.VM)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "bad request"), c.Type() == instancetype.VM)
+	}
+
+	zones, err := devlxdDNSZones(s, c)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	if !shared.ValueInSlice(zoneName, zones) || !devlxdDNSRecordAllowed(c, name) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	netzone, err := zone.LoadByName(s, zoneName)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	record, err := netzone.GetRecord(name)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusNotFound, "not found"), c.Type() == instancetype.VM)
+		}
+
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	// Only records created by the instance itself can be removed.
+	if record.Config[devlxdDNSRecordOwnerKey] != devlxdDNSRecordOwner(c) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	err = netzone.DeleteRecord(name)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, err.Error()), c.Type() == instancetype.VM)
+	}
+
+	devlxdSetRequestor(r, c)
+	s.Events.SendLifecycle(netzone.Project(), lifecycle.NetworkZoneRecordDeleted.Event(netzone, name, request.CreateRequestor(r), nil))
+
+	return response.DevLxdResponse(http.StatusOK, "", "raw", c.Type() == instancetype.VM)
+}}
+
 var handlers = []devLxdHandler{
 	{"/", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
 		return response.DevLxdResponse(http.StatusOK, []string{"/1.0"}, "json", c.Type() == instancetype.VM)
 	}},
 	devlxdAPIHandler,
-	devlxdConfigGet,
+	devlxdConfigHandler,
 	devlxdConfigKeyGet,
 	devlxdMetadataGet,
 	devlxdEventsGet,
 	devlxdImageExport,
 	devlxdDevicesGet,
+	devlxdSecretsGet,
+	devlxdSecretGet,
+	devlxdSnapshotsPost,
+	devlxdRestartPost,
+	devlxdDNSRecordsHandler,
+	devlxdDNSRecordDelete,
 }
 
 func hoistReq(f func(*Daemon, instance.Instance, http.ResponseWriter, *http.Request) response.Response, d *Daemon) func(http.ResponseWriter, *http.Request) {
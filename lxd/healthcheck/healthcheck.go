@@ -0,0 +1,277 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Default values of the health check configuration.
+const (
+	// DefaultInterval is the default number of seconds between health checks.
+	DefaultInterval = 30
+
+	// DefaultTimeout is the default number of seconds after which a health check fails.
+	DefaultTimeout = 5
+
+	// DefaultRetries is the default number of failed health checks before an instance is unhealthy.
+	DefaultRetries = 3
+)
+
+// Config is the health check configuration of an instance.
+type Config struct {
+	// Check is the type of health check (exec, tcp or http).
+	Check string
+
+	// Command is the command to run for exec checks.
+	Command string
+
+	// Port is the port to connect to for tcp and http checks.
+	Port int
+
+	// Path is the path to request for http checks.
+	Path string
+
+	// Interval is the time between health checks.
+	Interval time.Duration
+
+	// Timeout is the time after which a health check fails.
+	Timeout time.Duration
+
+	// Retries is the number of failed health checks in a row after which the instance is unhealthy.
+	Retries int
+
+	// Action is what to do when the instance becomes unhealthy (none, restart or evacuate).
+	Action string
+}
+
+// ParseConfig returns the health check configuration from the expanded config of an instance.
+// It returns nil if no health check is configured.
+func ParseConfig(config map[string]string) *Config {
+	if config["health.check"] == "" {
+		return nil
+	}
+
+	intValue := func(key string, defaultValue int) int {
+		value, err := strconv.Atoi(config[key])
+		if err != nil || value <= 0 {
+			return defaultValue
+		}
+
+		return value
+	}
+
+	c := &Config{
+		Check:    config["health.check"],
+		Command:  config["health.check.command"],
+		Port:     intValue("health.check.port", 0),
+		Path:     config["health.check.path"],
+		Interval: time.Duration(intValue("health.check.interval", DefaultInterval)) * time.Second,
+		Timeout:  time.Duration(intValue("health.check.timeout", DefaultTimeout)) * time.Second,
+		Retries:  intValue("health.check.retries", DefaultRetries),
+		Action:   config["health.action"],
+	}
+
+	if c.Path == "" {
+		c.Path = "/"
+	}
+
+	if c.Action == "" {
+		c.Action = "none"
+	}
+
+	return c
+}
+
+// Address returns the address used to reach the instance for tcp and http checks. This is the first global
+// address of the instance, preferring IPv4 addresses.
+func Address(network map[string]api.InstanceStateNetwork) (string, error) {
+	var ipv6 string
+
+	for _, nic := range network {
+		if nic.Type == "loopback" {
+			continue
+		}
+
+		for _, addr := range nic.Addresses {
+			if addr.Scope != "global" {
+				continue
+			}
+
+			if addr.Family == "inet" {
+				return addr.Address, nil
+			}
+
+			if ipv6 == "" && addr.Family == "inet6" {
+				ipv6 = addr.Address
+			}
+		}
+	}
+
+	if ipv6 == "" {
+		return "", fmt.Errorf("The instance has no global address")
+	}
+
+	return ipv6, nil
+}
+
+// CheckTCP checks that a TCP connection can be established to the port of the address.
+func CheckTCP(ctx context.Context, address string, port int) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// CheckHTTP checks that a GET request for the path on the port of the address gets a 2xx or 3xx response.
+func CheckHTTP(ctx context.Context, address string, port int, path string) error {
+	u := url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(address, strconv.Itoa(port)),
+		Path:   path,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             nil,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("Unexpected HTTP status %q", resp.Status)
+	}
+
+	return nil
+}
+
+// Tracker keeps track of the health of the instances running on this member.
+type Tracker struct {
+	mu     sync.Mutex
+	states map[int]*state
+}
+
+type state struct {
+	health  api.InstanceStateHealth
+	pid     int
+	running bool
+}
+
+// NewTracker returns a new health tracker.
+func NewTracker() *Tracker {
+	return &Tracker{states: map[int]*state{}}
+}
+
+// Get returns the health of the instance, or nil if it isn't tracked.
+func (t *Tracker) Get(instID int) *api.InstanceStateHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[instID]
+	if !ok {
+		return nil
+	}
+
+	health := s.health
+
+	return &health
+}
+
+// Due returns whether a health check of the instance should be started. The tracking of the instance is reset
+// when its PID changes, meaning that it was restarted.
+func (t *Tracker) Due(instID int, pid int, interval time.Duration, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[instID]
+	if !ok || s.pid != pid {
+		t.states[instID] = &state{
+			health: api.InstanceStateHealth{Status: api.InstanceHealthStatusStarting},
+			pid:    pid,
+		}
+
+		s = t.states[instID]
+	}
+
+	if s.running {
+		return false
+	}
+
+	if !s.health.LastCheckedAt.IsZero() && now.Sub(s.health.LastCheckedAt) < interval {
+		return false
+	}
+
+	s.running = true
+
+	return true
+}
+
+// Record records the result of a health check of the instance started with the given PID. It returns the previous
+// and the new health status of the instance.
+func (t *Tracker) Record(instID int, pid int, checkErr error, retries int, now time.Time) (string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[instID]
+	if !ok || s.pid != pid {
+		// The instance was restarted or stopped while being checked.
+		return "", ""
+	}
+
+	s.running = false
+	previous := s.health.Status
+	s.health.LastCheckedAt = now
+
+	if checkErr == nil {
+		s.health.Failures = 0
+		s.health.LastError = ""
+		s.health.Status = api.InstanceHealthStatusHealthy
+
+		return previous, s.health.Status
+	}
+
+	s.health.Failures++
+	s.health.LastError = checkErr.Error()
+	if s.health.Failures >= retries {
+		s.health.Status = api.InstanceHealthStatusUnhealthy
+	}
+
+	return previous, s.health.Status
+}
+
+// Forget stops tracking the health of all instances but the given ones.
+func (t *Tracker) Forget(keep map[int]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for instID := range t.states {
+		if !keep[instID] {
+			delete(t.states, instID)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestParseConfig(t *testing.T) {
+	assert.Nil(t, ParseConfig(map[string]string{"health.check.port": "80"}))
+
+	config := ParseConfig(map[string]string{
+		"health.check":      "http",
+		"health.check.port": "8080",
+	})
+
+	require.NotNil(t, config)
+	assert.Equal(t, "http", config.Check)
+	assert.Equal(t, 8080, config.Port)
+	assert.Equal(t, "/", config.Path)
+	assert.Equal(t, DefaultInterval*time.Second, config.Interval)
+	assert.Equal(t, DefaultTimeout*time.Second, config.Timeout)
+	assert.Equal(t, DefaultRetries, config.Retries)
+	assert.Equal(t, "none", config.Action)
+}
+
+func TestAddress(t *testing.T) {
+	network := map[string]api.InstanceStateNetwork{
+		"lo": {
+			Type:      "loopback",
+			Addresses: []api.InstanceStateNetworkAddress{{Family: "inet", Address: "127.0.0.1", Scope: "global"}},
+		},
+		"eth0": {
+			Type: "broadcast",
+			Addresses: []api.InstanceStateNetworkAddress{
+				{Family: "inet6", Address: "fe80::1", Scope: "link"},
+				{Family: "inet6", Address: "fd42::1", Scope: "global"},
+				{Family: "inet", Address: "10.0.0.2", Scope: "global"},
+			},
+		},
+	}
+
+	address, err := Address(network)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", address)
+
+	eth0 := network["eth0"]
+	eth0.Addresses = eth0.Addresses[:2]
+	network["eth0"] = eth0
+
+	address, err = Address(network)
+	require.NoError(t, err)
+	assert.Equal(t, "fd42::1", address)
+
+	delete(network, "eth0")
+
+	_, err = Address(network)
+	assert.Error(t, err)
+}
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	assert.NoError(t, CheckHTTP(context.Background(), host, port, "/healthz"))
+	assert.Error(t, CheckHTTP(context.Background(), host, port, "/"))
+	assert.NoError(t, CheckTCP(context.Background(), host, port))
+
+	server.Close()
+	assert.Error(t, CheckTCP(context.Background(), host, port))
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Now()
+	checkErr := errors.New("Connection refused")
+
+	assert.Nil(t, tracker.Get(1))
+
+	// The first check is due immediately and no other check starts while it runs.
+	assert.True(t, tracker.Due(1, 100, time.Minute, now))
+	assert.False(t, tracker.Due(1, 100, time.Minute, now))
+	assert.Equal(t, api.InstanceHealthStatusStarting, tracker.Get(1).Status)
+
+	previous, status := tracker.Record(1, 100, nil, 2, now)
+	assert.Equal(t, api.InstanceHealthStatusStarting, previous)
+	assert.Equal(t, api.InstanceHealthStatusHealthy, status)
+
+	// The next check is due after the interval.
+	assert.False(t, tracker.Due(1, 100, time.Minute, now.Add(30*time.Second)))
+	assert.True(t, tracker.Due(1, 100, time.Minute, now.Add(time.Minute)))
+
+	// A single failure doesn't make the instance unhealthy.
+	previous, status = tracker.Record(1, 100, checkErr, 2, now.Add(time.Minute))
+	assert.Equal(t, api.InstanceHealthStatusHealthy, previous)
+	assert.Equal(t, api.InstanceHealthStatusHealthy, status)
+	assert.Equal(t, 1, tracker.Get(1).Failures)
+	assert.Equal(t, "Connection refused", tracker.Get(1).LastError)
+
+	assert.True(t, tracker.Due(1, 100, time.Minute, now.Add(2*time.Minute)))
+	previous, status = tracker.Record(1, 100, checkErr, 2, now.Add(2*time.Minute))
+	assert.Equal(t, api.InstanceHealthStatusHealthy, previous)
+	assert.Equal(t, api.InstanceHealthStatusUnhealthy, status)
+
+	// A restart of the instance resets its health.
+	assert.True(t, tracker.Due(1, 200, time.Minute, now.Add(2*time.Minute)))
+	assert.Equal(t, api.InstanceHealthStatusStarting, tracker.Get(1).Status)
+	assert.Equal(t, 0, tracker.Get(1).Failures)
+
+	// Results of checks started before the restart are ignored.
+	previous, status = tracker.Record(1, 100, nil, 2, now.Add(2*time.Minute))
+	assert.Equal(t, "", previous)
+	assert.Equal(t, "", status)
+
+	tracker.Forget(map[int]bool{})
+	assert.Nil(t, tracker.Get(1))
+}
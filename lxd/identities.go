@@ -768,6 +768,7 @@ func updateIdentityCache(d *Daemon) {
 	projects := make(map[int][]string)
 	groups := make(map[int][]string)
 	idpGroupMapping := make(map[string][]string)
+	var tokens []dbCluster.IdentityToken
 	var err error
 	err = s.DB.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		identities, err = dbCluster.GetIdentitys(ctx, tx.Tx())
@@ -775,6 +776,11 @@ func updateIdentityCache(d *Daemon) {
 			return err
 		}
 
+		tokens, err = dbCluster.GetIdentityTokens(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
 		for _, identity := range identities {
 			identityProjects, err := dbCluster.GetIdentityProjects(ctx, tx.Tx(), identity.ID)
 			if err != nil {
@@ -874,6 +880,46 @@ func updateIdentityCache(d *Daemon) {
 	if err != nil {
 		logger.Warn("Failed to update identity cache", logger.Ctx{"error": err})
 	}
+
+	identitiesByID := make(map[int]dbCluster.Identity, len(identities))
+	for _, id := range identities {
+		identitiesByID[id.ID] = id
+	}
+
+	tokenCacheEntries := make([]identity.TokenCacheEntry, 0, len(tokens))
+	for _, token := range tokens {
+		id, ok := identitiesByID[token.IdentityID]
+		if !ok {
+			continue
+		}
+
+		allowedAddresses, permissions, err := token.Restrictions()
+		if err != nil {
+			logger.Warn("Failed to load identity token restrictions", logger.Ctx{"error": err})
+			continue
+		}
+
+		tokenCacheEntry := identity.TokenCacheEntry{
+			Name:                 token.Name,
+			SecretHash:           token.SecretHash,
+			AuthenticationMethod: string(id.AuthMethod),
+			Identifier:           id.Identifier,
+			AllowedAddresses:     allowedAddresses,
+			Permissions:          permissions,
+		}
+
+		if token.ExpiryDate.Valid {
+			tokenCacheEntry.ExpiresAt = token.ExpiryDate.Time
+		}
+
+		if token.LastUsedDate.Valid {
+			tokenCacheEntry.LastUsedAt = token.LastUsedDate.Time
+		}
+
+		tokenCacheEntries = append(tokenCacheEntries, tokenCacheEntry)
+	}
+
+	d.identityCache.ReplaceTokens(tokenCacheEntries)
 }
 
 // updateIdentityCacheFromLocal loads trusted server certificates from local database into the identity cache.
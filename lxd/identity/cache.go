@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -18,7 +19,10 @@ type Cache struct {
 
 	// identityProviderGroups is a map of identity provider group name to slice of LXD group names.
 	identityProviderGroups map[string]*[]string
-	mu                     sync.RWMutex
+
+	// tokens is a map of secret hash to identity bearer token.
+	tokens map[string]*TokenCacheEntry
+	mu     sync.RWMutex
 }
 
 // CacheEntry represents an identity.
@@ -204,3 +208,62 @@ func (c *Cache) GetIdentityProviderGroupMapping(idpGroup string) ([]string, erro
 
 	return *lxdGroups, nil
 }
+
+// ReplaceTokens deletes all identity bearer tokens from the cache and replaces them with the given values.
+func (c *Cache) ReplaceTokens(tokens []TokenCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = make(map[string]*TokenCacheEntry, len(tokens))
+	for _, token := range tokens {
+		t := token
+		c.tokens[token.SecretHash] = &t
+	}
+}
+
+// GetTokenBySecret returns the identity bearer token with the given secret or returns an api.StatusError with
+// http.StatusNotFound.
+func (c *Cache) GetTokenBySecret(secret string) (*TokenCacheEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	token, ok := c.tokens[TokenSecretHash(secret)]
+	if !ok || token == nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Identity token not found")
+	}
+
+	tokenCopy := *token
+	return &tokenCopy, nil
+}
+
+// GetToken returns the identity bearer token with the given name belonging to the identity with the given
+// authentication method and identifier or returns an api.StatusError with http.StatusNotFound.
+func (c *Cache) GetToken(authenticationMethod string, identifier string, name string) (*TokenCacheEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, token := range c.tokens {
+		if token != nil && token.AuthenticationMethod == authenticationMethod && token.Identifier == identifier && token.Name == name {
+			tokenCopy := *token
+			return &tokenCopy, nil
+		}
+	}
+
+	return nil, api.StatusErrorf(http.StatusNotFound, "Identity token %q of identity %q (%s) not found", name, identifier, authenticationMethod)
+}
+
+// MarkTokenUsed records that the identity bearer token with the given secret hash was used at the given time.
+// It returns true if the last used date of the token should be written to the database, which is at most once
+// per minute for each token.
+func (c *Cache) MarkTokenUsed(secretHash string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.tokens[secretHash]
+	if !ok || token == nil || now.Sub(token.LastUsedAt) < tokenLastUsedInterval {
+		return false
+	}
+
+	token.LastUsedAt = now
+	return true
+}
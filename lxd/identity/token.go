@@ -0,0 +1,109 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// TokenPrefix is the prefix of identity bearer token secrets. It distinguishes them from OIDC access tokens.
+const TokenPrefix = "lxd-token-"
+
+// tokenLastUsedInterval is the minimum interval between two updates of the last used date of a token.
+const tokenLastUsedInterval = time.Minute
+
+// TokenCacheEntry represents an identity bearer token.
+type TokenCacheEntry struct {
+	Name                 string
+	SecretHash           string
+	AuthenticationMethod string
+	Identifier           string
+	ExpiresAt            time.Time
+	LastUsedAt           time.Time
+	AllowedAddresses     []string
+	Permissions          []api.Permission
+}
+
+// NewTokenSecret generates a new token secret and returns it along with its hash.
+func NewTokenSecret() (secret string, secretHash string, err error) {
+	randomString, err := shared.RandomCryptoString()
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to generate token secret: %w", err)
+	}
+
+	secret = TokenPrefix + randomString
+
+	return secret, TokenSecretHash(secret), nil
+}
+
+// TokenSecretHash returns the hash of a token secret as stored in the database.
+func TokenSecretHash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// BearerToken returns the identity token secret sent in the Authorization header of the request, if any.
+func BearerToken(r *http.Request) (string, bool) {
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(secret, TokenPrefix) {
+		return "", false
+	}
+
+	return secret, true
+}
+
+// ValidateTokenAllowedAddress validates an entry of the allowed addresses of a token (an IP address or subnet).
+func ValidateTokenAllowedAddress(value string) error {
+	if net.ParseIP(value) != nil {
+		return nil
+	}
+
+	_, _, err := net.ParseCIDR(value)
+	if err != nil {
+		return fmt.Errorf("Invalid address or subnet %q", value)
+	}
+
+	return nil
+}
+
+// Validate checks the token hasn't expired and can be used from the given remote address.
+func (t TokenCacheEntry) Validate(remoteAddr string, now time.Time) error {
+	if !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt) {
+		return fmt.Errorf("Identity token %q has expired", t.Name)
+	}
+
+	if len(t.AllowedAddresses) == 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("Failed to parse remote address %q", remoteAddr)
+	}
+
+	for _, allowed := range t.AllowedAddresses {
+		allowedIP := net.ParseIP(allowed)
+		if allowedIP != nil && allowedIP.Equal(ip) {
+			return nil
+		}
+
+		_, subnet, err := net.ParseCIDR(allowed)
+		if err == nil && subnet.Contains(ip) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Identity token %q can't be used from address %q", t.Name, ip.String())
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var identityEffectivePermissionsCmd = APIEndpoint{
+	Name: "identity_effective_permissions",
+	Path: "auth/identities/{authenticationMethod}/{nameOrIdentifier}/effective-permissions",
+	Get: APIEndpointAction{
+		Handler:       getIdentityEffectivePermissions,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanView),
+	},
+}
+
+// effectivePermissionGroup is a group of which an identity is an effective member, along with its permissions.
+type effectivePermissionGroup struct {
+	name        string
+	idpGroups   []string
+	permissions []api.Permission
+}
+
+// swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/effective-permissions identities identity_effective_permissions_get
+//
+//	Get the effective permissions of the identity
+//
+//	Evaluates whether the identity has an entitlement on an entity and returns the group permissions that grant it.
+//	If no entitlement is given, all entitlements that apply to the entity are evaluated.
+//	Evaluating the permissions of another identity requires the `can_view_permissions` entitlement on the server.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: entity
+//	    description: URL of the entity
+//	    type: string
+//	    required: true
+//	    example: /1.0/instances/c1?project=default
+//	  - in: query
+//	    name: entitlement
+//	    description: Entitlement to evaluate
+//	    type: string
+//	    example: can_edit
+//	  - in: query
+//	    name: identity-provider-groups
+//	    description: Comma separated list of identity provider groups to evaluate the permissions with (requires `can_view_permissions` on the server)
+//	    type: string
+//	    example: sales,support
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of evaluated entitlements
+//	          items:
+//	            $ref: "#/definitions/IdentityEffectivePermission"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityEffectivePermissions(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s := d.State()
+
+	// Identities can always evaluate their own permissions with their current identity provider groups. Evaluating
+	// the permissions of another identity, or with other identity provider groups, reveals the permissions of groups
+	// the requestor isn't a member of.
+	requestor, _ := request.GetCtxValue[string](r.Context(), request.CtxUsername)
+	protocol, _ := request.GetCtxValue[string](r.Context(), request.CtxProtocol)
+	isSelf := requestor == id.Identifier && protocol == string(id.AuthMethod)
+	idpGroupsParam := request.QueryParam(r, "identity-provider-groups")
+	if !isSelf || idpGroupsParam != "" {
+		err = s.Authorizer.CheckPermission(r.Context(), r, entity.ServerURL(), auth.EntitlementCanViewPermissions)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	entityURL, entityType, err := effectivePermissionEntityURL(request.QueryParam(r, "entity"))
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	entitlements := auth.EntitlementsByEntityType(entityType)
+	if len(entitlements) == 0 {
+		return response.BadRequest(fmt.Errorf("No entitlements can be granted against entities of type %q", entityType))
+	}
+
+	entitlementName := request.QueryParam(r, "entitlement")
+	if entitlementName != "" {
+		err = auth.ValidateEntitlement(entityType, auth.Entitlement(entitlementName))
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		entitlements = []auth.Entitlement{auth.Entitlement(entitlementName)}
+	}
+
+	// Identity provider groups are only known while an OIDC identity is authenticated. Default to the groups of the
+	// requestor when evaluating its own permissions.
+	var idpGroups []string
+	if idpGroupsParam != "" {
+		idpGroups = shared.SplitNTrimSpace(idpGroupsParam, ",", -1, true)
+	} else if isSelf {
+		idpGroups, _ = request.GetCtxValue[[]string](r.Context(), request.CtxIdentityProviderGroups)
+	}
+
+	cacheEntry, err := d.identityCache.Get(string(id.AuthMethod), id.Identifier)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading identity %q: %w", id.Identifier, err))
+	}
+
+	isRestricted, err := identity.IsRestrictedIdentityType(cacheEntry.IdentityType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Only group based authorization can be explained with permissions.
+	explainer, canExplain := s.Authorizer.(auth.PermissionExplainer)
+	isGroupBased := canExplain && isRestricted && string(id.AuthMethod) != api.AuthenticationMethodTLS
+
+	var groups []effectivePermissionGroup
+	if isGroupBased {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			groups, err = effectivePermissionGroups(ctx, tx, id, idpGroups)
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	result := make([]api.IdentityEffectivePermission, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		allowed, err := identityHasEntitlement(r.Context(), s.Authorizer, string(id.AuthMethod), id.Identifier, idpGroups, entityURL, entitlement)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		permission := api.IdentityEffectivePermission{
+			EntityURL:   entityURL.String(),
+			Entitlement: string(entitlement),
+			Allowed:     allowed,
+			Grants:      []api.IdentityPermissionGrant{},
+		}
+
+		if isGroupBased {
+			for _, group := range groups {
+				granting, err := explainer.GrantingPermissions(r.Context(), string(id.AuthMethod), id.Identifier, entityURL, entitlement, group.permissions)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				for _, grantingPermission := range granting {
+					permission.Grants = append(permission.Grants, api.IdentityPermissionGrant{
+						Group:                  group.name,
+						IdentityProviderGroups: group.idpGroups,
+						Permission:             grantingPermission,
+					})
+				}
+			}
+		}
+
+		switch {
+		case !isRestricted:
+			permission.Reason = fmt.Sprintf("Identity type %q is not restricted", cacheEntry.IdentityType)
+		case string(id.AuthMethod) == api.AuthenticationMethodTLS:
+			permission.Reason = "Determined by the project restrictions of the identity"
+		case !canExplain:
+			permission.Reason = fmt.Sprintf("Determined by the %q authorization driver", s.Authorizer.Driver())
+		case allowed && len(permission.Grants) > 0:
+			permission.Reason = "Granted by the permissions of the groups of the identity"
+		case allowed:
+			permission.Reason = "Granted to all identities"
+		default:
+			permission.Reason = "Not granted by any permission of the groups of the identity"
+		}
+
+		result = append(result, permission)
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// effectivePermissionEntityURL parses and canonicalises the URL of the entity whose permissions are evaluated.
+func effectivePermissionEntityURL(entityReference string) (*api.URL, entity.Type, error) {
+	if entityReference == "" {
+		return nil, "", fmt.Errorf("The entity query parameter is required")
+	}
+
+	u, err := url.Parse(entityReference)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to parse entity URL %q: %w", entityReference, err)
+	}
+
+	entityType, projectName, location, pathArguments, err := entity.ParseURL(*u)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to parse entity URL %q: %w", entityReference, err)
+	}
+
+	entityURL, err := entityType.URL(projectName, location, pathArguments...)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to parse entity URL %q: %w", entityReference, err)
+	}
+
+	return entityURL, entityType, nil
+}
+
+// effectivePermissionGroups returns the groups of which the identity is a direct member, or a member via the given
+// identity provider groups, along with their permissions.
+func effectivePermissionGroups(ctx context.Context, tx *db.ClusterTx, id *dbCluster.Identity, idpGroups []string) ([]effectivePermissionGroup, error) {
+	apiIdentity, err := id.ToAPI(ctx, tx.Tx(), func(entityURL *api.URL) bool { return true })
+	if err != nil {
+		return nil, fmt.Errorf("Failed to populate LXD groups: %w", err)
+	}
+
+	groups := make([]effectivePermissionGroup, 0, len(apiIdentity.Groups))
+	groupIndexes := make(map[string]int, len(apiIdentity.Groups))
+	for _, groupName := range apiIdentity.Groups {
+		groupIndexes[groupName] = len(groups)
+		groups = append(groups, effectivePermissionGroup{name: groupName, idpGroups: []string{}})
+	}
+
+	for _, idpGroup := range idpGroups {
+		mappedGroups, err := dbCluster.GetDistinctAuthGroupNamesFromIDPGroupNames(ctx, tx.Tx(), []string{idpGroup})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get groups mapped to identity provider group %q: %w", idpGroup, err)
+		}
+
+		for _, groupName := range mappedGroups {
+			i, ok := groupIndexes[groupName]
+			if !ok {
+				i = len(groups)
+				groupIndexes[groupName] = i
+				groups = append(groups, effectivePermissionGroup{name: groupName, idpGroups: []string{}})
+			}
+
+			// Groups the identity is a direct member of are granted without identity provider groups.
+			if shared.ValueInSlice(groupName, apiIdentity.Groups) {
+				continue
+			}
+
+			groups[i].idpGroups = append(groups[i].idpGroups, idpGroup)
+		}
+	}
+
+	for i, group := range groups {
+		permissions, err := dbCluster.GetDistinctPermissionsByGroupNames(ctx, tx.Tx(), []string{group.name})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get permissions of group %q: %w", group.name, err)
+		}
+
+		permissions, entityURLs, err := dbCluster.GetPermissionEntityURLs(ctx, tx.Tx(), permissions)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get entity URLs for permissions of group %q: %w", group.name, err)
+		}
+
+		groups[i].permissions = make([]api.Permission, 0, len(permissions))
+		for _, permission := range permissions {
+			groups[i].permissions = append(groups[i].permissions, api.Permission{
+				EntityType:      string(permission.EntityType),
+				EntityReference: entityURLs[entity.Type(permission.EntityType)][permission.EntityID].String(),
+				Entitlement:     string(permission.Entitlement),
+			})
+		}
+	}
+
+	return groups, nil
+}
+
+// identityHasEntitlement evaluates whether the identity has the entitlement on the entity. The authorizer is given a
+// request as if it was sent by the identity so that the decision is the same as for its own requests.
+func identityHasEntitlement(ctx context.Context, authorizer auth.Authorizer, authenticationMethod string, identifier string, idpGroups []string, entityURL *api.URL, entitlement auth.Entitlement) (bool, error) {
+	// The check request starts from an empty context so that nothing from the caller's request (forwarded
+	// details, groups, identity token or effective project) leaks into the permissions of the target identity.
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, entityURL.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("Failed to create permission check request: %w", err)
+	}
+
+	if idpGroups == nil {
+		idpGroups = []string{}
+	}
+
+	request.SetCtxValue(r, request.CtxTrusted, true)
+	request.SetCtxValue(r, request.CtxProtocol, authenticationMethod)
+	request.SetCtxValue(r, request.CtxUsername, identifier)
+	request.SetCtxValue(r, request.CtxIdentityProviderGroups, idpGroups)
+	request.SetCtxValue(r, request.CtxIdentityToken, "")
+	request.SetCtxValue(r, request.CtxForwardedProtocol, "")
+	request.SetCtxValue(r, request.CtxForwardedUsername, "")
+	request.SetCtxValue(r, request.CtxForwardedIdentityToken, "")
+
+	err = authorizer.CheckPermission(ctx, r, entityURL, entitlement)
+	if err != nil {
+		if auth.IsDeniedError(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
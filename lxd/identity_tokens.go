@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var identityTokensCmd = APIEndpoint{
+	Name: "identity_tokens",
+	Path: "auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens",
+	Get: APIEndpointAction{
+		Handler:       getIdentityTokens,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanView),
+	},
+	Post: APIEndpointAction{
+		Handler:       createIdentityToken,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
+	},
+}
+
+var identityTokenCmd = APIEndpoint{
+	Name: "identity_token",
+	Path: "auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens/{tokenName}",
+	Get: APIEndpointAction{
+		Handler:       getIdentityToken,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanView),
+	},
+	Delete: APIEndpointAction{
+		Handler:       deleteIdentityToken,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
+	},
+}
+
+// identityTokenURL returns the URL of the identity token with the given name.
+func identityTokenURL(id *dbCluster.Identity, tokenName string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "auth", "identities", string(id.AuthMethod), id.Identifier, "tokens", tokenName)
+}
+
+// swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens identities identity_tokens_get
+//
+//	Get the identity tokens
+//
+//	Returns a list of bearer tokens of the identity (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/auth/identities/tls/e1e06266e36f67431c996d5678e66d732dfd12fe5073c161e62e6360619fc226/tokens/ci-runner"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens?recursion=1 identities identity_tokens_get_recursion1
+//
+//	Get the identity tokens
+//
+//	Returns a list of bearer tokens of the identity.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of identity tokens
+//	          items:
+//	            $ref: "#/definitions/IdentityToken"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityTokens(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var tokens []dbCluster.IdentityToken
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		tokens, err = dbCluster.GetIdentityTokensByIdentityID(ctx, tx.Tx(), id.ID)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		apiTokens := make([]*api.IdentityToken, 0, len(tokens))
+		for _, token := range tokens {
+			apiToken, err := token.ToAPI()
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			apiTokens = append(apiTokens, apiToken)
+		}
+
+		return response.SyncResponse(true, apiTokens)
+	}
+
+	tokenURLs := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		tokenURLs = append(tokenURLs, identityTokenURL(id, token.Name).String())
+	}
+
+	return response.SyncResponse(true, tokenURLs)
+}
+
+// swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens/{tokenName} identities identity_token_get
+//
+//	Get the identity token
+//
+//	Gets a specific bearer token of the identity. The secret of the token is never returned.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/IdentityToken"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityToken(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	tokenName, err := url.PathUnescape(mux.Vars(r)["tokenName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var token *dbCluster.IdentityToken
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		token, err = dbCluster.GetIdentityToken(ctx, tx.Tx(), id.ID, tokenName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	apiToken, err := token.ToAPI()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, apiToken)
+}
+
+// swagger:operation POST /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens identities identity_tokens_post
+//
+//	Add an identity token
+//
+//	Creates a new bearer token for the identity. The secret of the token is only returned in this response.
+//	Requests authenticated with the token get the permissions of the identity, optionally narrowed down to the
+//	permissions listed in the token.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: token
+//	    description: Identity token
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/IdentityTokensPost"
+//	responses:
+//	  "201":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/IdentityTokenSecret"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func createIdentityToken(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Allowing tokens to create other tokens would allow escaping their expiry and restrictions.
+	requestTokenName, _ := request.GetCtxValue[string](r.Context(), request.CtxIdentityToken)
+	if requestTokenName != "" {
+		return response.Forbidden(fmt.Errorf("Identity tokens cannot be created using an identity token"))
+	}
+
+	var req api.IdentityTokensPost
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	err = validate.IsHostname(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid token name %q: %w", req.Name, err))
+	}
+
+	if !shared.ValueInSlice(string(id.Type), []string{api.IdentityTypeCertificateClientRestricted, api.IdentityTypeCertificateClientUnrestricted, api.IdentityTypeOIDCClient}) {
+		return response.BadRequest(fmt.Errorf("Identity tokens can only be created for client identities"))
+	}
+
+	if !req.ExpiresAt.IsZero() && req.ExpiresAt.Before(time.Now()) {
+		return response.BadRequest(fmt.Errorf("Token expiry date must be in the future"))
+	}
+
+	if req.AllowedAddresses == nil {
+		req.AllowedAddresses = []string{}
+	}
+
+	for _, allowedAddress := range req.AllowedAddresses {
+		err = identity.ValidateTokenAllowedAddress(allowedAddress)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	err = validatePermissions(req.Permissions)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	permissions, err := canonicalPermissions(req.Permissions)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	allowedAddressesJSON, err := json.Marshal(req.AllowedAddresses)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	permissionsJSON, err := json.Marshal(permissions)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	secret, secretHash, err := identity.NewTokenSecret()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	token := dbCluster.IdentityToken{
+		IdentityID:       id.ID,
+		Name:             req.Name,
+		Description:      req.Description,
+		SecretHash:       secretHash,
+		CreationDate:     time.Now().UTC(),
+		ExpiryDate:       sql.NullTime{Time: req.ExpiresAt.UTC(), Valid: !req.ExpiresAt.IsZero()},
+		AllowedAddresses: string(allowedAddressesJSON),
+		Permissions:      string(permissionsJSON),
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.CreateIdentityToken(ctx, tx.Tx(), token)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.UpdateIdentityCache()
+
+	lc := lifecycle.IdentityTokenCreated.Event(string(id.AuthMethod), id.Identifier, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, api.IdentityTokenSecret{Name: req.Name, Secret: secret, ExpiresAt: req.ExpiresAt}, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/tokens/{tokenName} identities identity_token_delete
+//
+//	Delete the identity token
+//
+//	Revokes a bearer token of the identity.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func deleteIdentityToken(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	tokenName, err := url.PathUnescape(mux.Vars(r)["tokenName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteIdentityToken(ctx, tx.Tx(), id.ID, tokenName)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyIdentityCacheRefresh(s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.UpdateIdentityCache()
+
+	lc := lifecycle.IdentityTokenDeleted.Event(string(id.AuthMethod), id.Identifier, tokenName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.EmptySyncResponse
+}
+
+// notifyIdentityCacheRefresh notifies other cluster members to update their identity cache.
+func notifyIdentityCacheRefresh(s *state.State) error {
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+}
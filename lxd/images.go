@@ -1817,6 +1817,8 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 				deleteIDs = append(deleteIDs, image.ID)
 			}
 
+			imageRefreshRecord(ctx, s, image.ID, err)
+
 			// newInfo will have the same content for each image in the list.
 			// Therefore, we just pick the first.
 			if newImage == nil {
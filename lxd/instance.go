@@ -478,6 +478,12 @@ func instanceLoadNodeProjectAll(ctx context.Context, s *state.State, project str
 	return instances, nil
 }
 
+// instanceAutoSnapshotName returns the name of the next snapshot of the instance that isn't named by the user.
+// It follows snapshots.pattern and defaults to "snap%d".
+func instanceAutoSnapshotName(s *state.State, inst instance.Instance) (string, error) {
+	return instance.NextSnapshotName(s, inst, "snap%d")
+}
+
 func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances []instance.Instance) error {
 	// Make the snapshots.
 	for _, inst := range instances {
@@ -488,7 +494,7 @@ func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances
 
 		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
-		snapshotName, err := instance.NextSnapshotName(s, inst, "snap%d")
+		snapshotName, err := instanceAutoSnapshotName(s, inst)
 		if err != nil {
 			l.Error("Error retrieving next snapshot name", logger.Ctx{"err": err})
 			return err
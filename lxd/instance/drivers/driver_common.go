@@ -385,6 +385,13 @@ func (d *common) LogPath() string {
 	return shared.LogPath(name)
 }
 
+// DumpPath returns the path of the instance's memory dumps.
+// The dumps contain the memory of the guest, so they're kept apart from the logs that only require view access.
+func (d *common) DumpPath() string {
+	name := project.Instance(d.project.Name, d.name)
+	return shared.VarPath("dumps", name)
+}
+
 // Path returns the instance's path.
 func (d *common) Path() string {
 	return storagePools.InstancePath(d.dbType, d.project.Name, d.name, d.isSnapshot)
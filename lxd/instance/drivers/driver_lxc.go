@@ -55,6 +55,7 @@ import (
 	"github.com/canonical/lxd/lxd/migration"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/processes"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/rsync"
@@ -6984,6 +6985,62 @@ func (d *lxc) FileSFTP() (*sftp.Client, error) {
 	return client, nil
 }
 
+// PortForwardConn returns a TCP connection to the given port on the loopback interface of the container.
+// The connection is made by a forked process attached to the network namespace of the container, which passes the
+// socket back over a socket pair, so that the daemon's own threads never change namespace.
+func (d *lxc) PortForwardConn(port int) (net.Conn, error) {
+	if !d.IsRunning() {
+		return nil, fmt.Errorf("Instance is not running")
+	}
+
+	pid := d.InitPID()
+	pidFdNr, pidFd := d.inheritInitPidFd()
+	if pidFdNr >= 0 {
+		defer func() { _ = pidFd.Close() }()
+	}
+
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating socket pair: %w", err)
+	}
+
+	local := os.NewFile(uintptr(fds[0]), "port-forward")
+	defer func() { _ = local.Close() }()
+
+	remote := os.NewFile(uintptr(fds[1]), "port-forward")
+	defer func() { _ = remote.Close() }()
+
+	// The socket pair is inherited as the file descriptor following the pidfd.
+	_, _, err = shared.RunCommandSplit(
+		context.TODO(),
+		nil,
+		[]*os.File{pidFd, remote},
+		d.state.OS.ExecPath,
+		"forknet",
+		"connect",
+		"--",
+		fmt.Sprintf("%d", pid),
+		fmt.Sprintf("%d", pidFdNr),
+		fmt.Sprintf("%d", port),
+		"4")
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to port %d in instance: %w", port, err)
+	}
+
+	connFile, err := netutils.AbstractUnixReceiveFd(int(local.Fd()), netutils.UnixFdsAcceptExact)
+	if err != nil {
+		return nil, fmt.Errorf("Failed receiving connection to port %d in instance: %w", port, err)
+	}
+
+	if connFile == nil {
+		return nil, fmt.Errorf("Failed receiving connection to port %d in instance", port)
+	}
+
+	defer func() { _ = connFile.Close() }()
+
+	return net.FileConn(connFile)
+}
+
 // stopForkFile attempts to send SIGTERM (if force is true) or SIGINT to forkfile then waits for it to exit.
 func (d *lxc) stopForkfile(force bool) {
 	// Make sure that when the function exits, no forkfile is running by acquiring the lock (which indicates
@@ -7469,6 +7526,65 @@ func (d *lxc) processesState(pid int) (int64, error) {
 	return int64(len(pids)), nil
 }
 
+// Processes returns the processes running in the container, read from the host. Only the processes of the cgroup
+// of the container that are in its PID namespace are returned.
+func (d *lxc) Processes() ([]api.InstanceProcess, error) {
+	pid := d.InitPID()
+	if pid < 1 {
+		return nil, ErrInstanceIsStopped
+	}
+
+	cgroupPath, err := processes.CgroupPath("/proc", "/sys/fs/cgroup", int64(pid))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting cgroup of the container: %w", err)
+	}
+
+	pids, err := processes.CgroupPIDs(cgroupPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing processes of the container: %w", err)
+	}
+
+	hostProcesses, err := processes.ListPIDs("/proc", pids)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing processes: %w", err)
+	}
+
+	pidNamespace, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", pid))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting PID namespace of the container: %w", err)
+	}
+
+	idmapset, err := d.CurrentIdmap()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := processes.Users(fmt.Sprintf("/proc/%d/root/etc/passwd", pid))
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading users of the container: %w", err)
+	}
+
+	result := []api.InstanceProcess{}
+	for _, p := range hostProcesses {
+		if p.PIDNamespace != pidNamespace {
+			continue
+		}
+
+		// Show the host IDs which aren't mapped into the container as the overflow user like the kernel does.
+		uid := p.UID
+		if idmapset != nil {
+			uid, _ = idmapset.ShiftFromNs(p.UID, -1)
+			if uid < 0 {
+				uid = 65534
+			}
+		}
+
+		result = append(result, p.ToAPI(p.NSPID, processes.UserName(users, uid)))
+	}
+
+	return result, nil
+}
+
 // getStorageType returns the storage type of the instance's storage pool.
 func (d *lxc) getStorageType() (string, error) {
 	pool, err := d.getStoragePool()
@@ -8442,6 +8558,31 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 			out.AddSamples(metrics.DiskReadsCompletedTotal, metrics.Sample{Value: float64(stats.ReadsCompleted), Labels: labels})
 			out.AddSamples(metrics.DiskWrittenBytesTotal, metrics.Sample{Value: float64(stats.WrittenBytes), Labels: labels})
 			out.AddSamples(metrics.DiskWritesCompletedTotal, metrics.Sample{Value: float64(stats.WritesCompleted), Labels: labels})
+			out.AddSamples(metrics.DiskDiscardedBytesTotal, metrics.Sample{Value: float64(stats.DiscardedBytes), Labels: labels})
+			out.AddSamples(metrics.DiskDiscardsCompletedTotal, metrics.Sample{Value: float64(stats.DiscardsCompleted), Labels: labels})
+			if stats.ThrottledTime != nil {
+				out.AddSamples(metrics.DiskThrottledSecondsTotal, metrics.Sample{Value: float64(*stats.ThrottledTime) / 1000000, Labels: labels})
+			}
+		}
+	}
+
+	// Get pressure stall information.
+	if d.state.OS.CGInfo.Supports(cgroup.Pressure, cg) {
+		pressureMetrics := map[string][2]metrics.MetricType{
+			"cpu":    {metrics.CPUPressureWaitingSecondsTotal, metrics.CPUPressureStalledSecondsTotal},
+			"memory": {metrics.MemoryPressureWaitingSecondsTotal, metrics.MemoryPressureStalledSecondsTotal},
+			"io":     {metrics.IOPressureWaitingSecondsTotal, metrics.IOPressureStalledSecondsTotal},
+		}
+
+		for resource, metricTypes := range pressureMetrics {
+			stats, err := cg.GetPressure(resource)
+			if err != nil {
+				d.logger.Warn("Failed to get pressure stall information", logger.Ctx{"resource": resource, "err": err})
+				continue
+			}
+
+			out.AddSamples(metricTypes[0], metrics.Sample{Value: float64(stats.SomeTotal) / 1000000})
+			out.AddSamples(metricTypes[1], metrics.Sample{Value: float64(stats.FullTotal) / 1000000})
 		}
 	}
 
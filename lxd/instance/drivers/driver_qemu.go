@@ -405,7 +405,7 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 	state := d.state
 
 	return func(event string, data map[string]any) {
-		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted}) {
+		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted, qmp.EventWatchdog}) {
 			return // Don't bother loading the instance from DB if we aren't going to handle the event.
 		}
 
@@ -460,10 +460,118 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Error("Failed to cleanly stop instance", logger.Ctx{"err": err})
 				return
 			}
+		} else if event == qmp.EventWatchdog {
+			d.onWatchdog()
 		}
 	}
 }
 
+// onWatchdog applies the action of the watchdog device of the instance when it expires.
+func (d *qemu) onWatchdog() {
+	var devName, action string
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] == "watchdog" {
+			devName = dev.Name
+			action = dev.Config["action"]
+			break
+		}
+	}
+
+	if action == "" {
+		action = "reset"
+	}
+
+	d.logger.Warn("Instance watchdog expired", logger.Ctx{"device": devName, "action": action})
+
+	// Record each expiry as an occurrence of the warning.
+	_ = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarning(ctx, d.node, d.project.Name, entity.TypeInstance, d.ID(), warningtype.InstanceWatchdogTriggered, fmt.Sprintf("Watchdog device %q expired, applying action %q", devName, action))
+	})
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceWatchdogTriggered.Event(d, map[string]any{"device": devName, "action": action}))
+
+	var err error
+	switch action {
+	case "poweroff":
+		err = d.Stop(false)
+	case "pause":
+		err = d.Freeze()
+	case "dump-memory":
+		err = d.dumpMemory(fmt.Sprintf("watchdog_%s.dump", devName))
+		if err != nil {
+			d.logger.Error("Failed dumping instance memory", logger.Ctx{"err": err})
+		}
+
+		err = d.Restart(0)
+	default:
+		err = d.Restart(0)
+	}
+
+	if err != nil {
+		d.logger.Error("Failed applying watchdog action", logger.Ctx{"action": action, "err": err})
+	}
+}
+
+// dumpMemory writes the memory of the running instance in ELF format to the file with the given name in the
+// instance's dump directory. The previous dump with the same name is only replaced once the new one is complete.
+func (d *qemu) dumpMemory(fileName string) error {
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(d.DumpPath(), 0700)
+	if err != nil {
+		return err
+	}
+
+	// The dump is roughly as large as the memory of the instance, so don't fill up the host file system.
+	memSize := d.expandedConfig["limits.memory"]
+	if memSize == "" {
+		memSize = QEMUDefaultMemSize
+	}
+
+	memSizeBytes, err := units.ParseByteSizeString(memSize)
+	if err == nil {
+		var stat unix.Statfs_t
+		err = unix.Statfs(d.DumpPath(), &stat)
+		if err != nil {
+			return err
+		}
+
+		available := int64(stat.Bavail) * int64(stat.Bsize)
+		if available < memSizeBytes {
+			return fmt.Errorf("Not enough space to dump the instance memory (%d bytes available, %d bytes needed)", available, memSizeBytes)
+		}
+	}
+
+	path := filepath.Join(d.DumpPath(), fileName)
+	tmpPath := path + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+	}()
+
+	err = monitor.DumpGuestMemory(f)
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
 // mount the instance's config volume if needed.
 func (d *qemu) mount() (*storagePools.MountInfo, error) {
 	var pool storagePools.Pool
@@ -1737,6 +1845,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		"shutdown": "poweroff",
 		"reboot":   "shutdown", // Don't reset on reboot. Let LXD handle reboots.
 		"panic":    "pause",    // Pause on panics to allow investigation.
+		"watchdog": "none",     // Let LXD apply the action of the watchdog device.
 	}
 
 	err = monitor.SetAction(actions)
@@ -2148,6 +2257,13 @@ func (d *qemu) deviceStart(dev device.Device, instanceRunning bool) (*deviceConf
 				}
 			}
 
+			if len(runConf.SerialDevice) > 0 {
+				err = d.deviceAttachSerial(dev.Name(), runConf.SerialDevice)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// If running, run post start hooks now (if not running LXD will run them
 			// once the instance is started).
 			err = d.runHooks(runConf.PostHooks)
@@ -2455,6 +2571,14 @@ func (d *qemu) deviceStop(dev device.Device, instanceRunning bool, _ string) err
 			}
 		}
 
+		// Detach serial device from running instance.
+		if configCopy["type"] == "serial" {
+			err = d.deviceDetachSerial(dev.Name())
+			if err != nil {
+				return err
+			}
+		}
+
 		// Detach disk from running instance.
 		if configCopy["type"] == "disk" {
 			if configCopy["path"] != "" {
@@ -3495,6 +3619,28 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 			monHooks = append(monHooks, monHook)
 		}
 
+		// Add serial device.
+		if len(runConf.SerialDevice) > 0 {
+			qemuDev := make(map[string]string)
+
+			// Allocate a PCI(e) port and write it to the config file so QMP can "hotplug" the serial
+			// device into it later.
+			devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
+			qemuDev["bus"] = devBus
+			qemuDev["addr"] = devAddr
+
+			if multi {
+				qemuDev["multifunction"] = "on"
+			}
+
+			monHook, err := d.addSerialDeviceConfig(qemuDev, runConf.SerialDevice)
+			if err != nil {
+				return "", nil, err
+			}
+
+			monHooks = append(monHooks, monHook)
+		}
+
 		// Add TPM device.
 		if len(runConf.TPMDevice) > 0 {
 			err = d.addTPMDeviceConfig(&cfg, runConf.TPMDevice)
@@ -3502,6 +3648,14 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 				return "", nil, err
 			}
 		}
+
+		// Add watchdog device.
+		if len(runConf.WatchdogDevice) > 0 {
+			err = d.addWatchdogDeviceConfig(&cfg, bus, runConf.WatchdogDevice)
+			if err != nil {
+				return "", nil, err
+			}
+		}
 	}
 
 	// VM generation ID is only available on x86.
@@ -4691,6 +4845,122 @@ func (d *qemu) addUSBDeviceConfig(usbDev deviceConfig.USBDeviceItem) (monitorHoo
 	return monHook, nil
 }
 
+// addSerialDeviceConfig returns a monitor hook to add a serial device to the instance.
+// The host side of the device is opened by LXD and passed to QEMU as it doesn't have access to it.
+func (d *qemu) addSerialDeviceConfig(qemuDev map[string]string, serialConfig []deviceConfig.RunConfigItem) (monitorHook, error) {
+	var devName, backend, address string
+	for _, serialItem := range serialConfig {
+		switch serialItem.Key {
+		case "devName":
+			devName = serialItem.Value
+		case "backend":
+			backend = serialItem.Value
+		case "address":
+			address = serialItem.Value
+		}
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(devName)
+	chardevID := fmt.Sprintf("lxd_%s", escapedDeviceName)
+
+	qemuDev["driver"] = "pci-serial"
+	qemuDev["id"] = fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
+	qemuDev["chardev"] = chardevID
+
+	monHook := func(m *qmp.Monitor) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		var chardevBackend map[string]any
+
+		if backend == "file" {
+			f, err := os.OpenFile(address, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return fmt.Errorf("Failed opening serial log file: %w", err)
+			}
+
+			defer func() { _ = f.Close() }()
+
+			info, err := m.SendFileWithFDSet(chardevID, f, false)
+			if err != nil {
+				return fmt.Errorf("Failed to send file descriptor: %w", err)
+			}
+
+			revert.Add(func() { _ = m.RemoveFDFromFDSet(chardevID) })
+
+			chardevBackend = map[string]any{
+				"type": "file",
+				"data": map[string]any{
+					"out":    fmt.Sprintf("/dev/fdset/%d", info.ID),
+					"append": true,
+				},
+			}
+		} else {
+			listener, err := device.SerialListen(d, devName, backend, address)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = listener.Close() }()
+
+			var f *os.File
+			switch l := listener.(type) {
+			case *net.UnixListener:
+				f, err = l.File()
+			case *net.TCPListener:
+				f, err = l.File()
+			}
+
+			if err != nil {
+				return fmt.Errorf("Failed getting listener file: %w", err)
+			}
+
+			defer func() { _ = f.Close() }()
+
+			err = m.SendFile(chardevID, f)
+			if err != nil {
+				return fmt.Errorf("Failed to send file descriptor: %w", err)
+			}
+
+			revert.Add(func() { _ = m.CloseFile(chardevID) })
+
+			chardevBackend = map[string]any{
+				"type": "socket",
+				"data": map[string]any{
+					"addr": map[string]any{
+						"type": "fd",
+						"data": map[string]any{
+							"str": chardevID,
+						},
+					},
+					"server": true,
+					"wait":   false,
+				},
+			}
+		}
+
+		err := m.AddCharDevice(map[string]any{
+			"id":      chardevID,
+			"backend": chardevBackend,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to add the character device: %w", err)
+		}
+
+		revert.Add(func() { _ = m.RemoveCharDevice(chardevID) })
+
+		err = m.AddDevice(qemuDev)
+		if err != nil {
+			return fmt.Errorf("Failed to add the serial device: %w", err)
+		}
+
+		revert.Success()
+		return nil
+	}
+
+	return monHook, nil
+}
+
 func (d *qemu) addTPMDeviceConfig(cfg *[]cfgSection, tpmConfig []deviceConfig.RunConfigItem) error {
 	var devName, socketPath string
 
@@ -4711,6 +4981,38 @@ func (d *qemu) addTPMDeviceConfig(cfg *[]cfgSection, tpmConfig []deviceConfig.Ru
 	return nil
 }
 
+// addWatchdogDeviceConfig adds the qemu config required for adding a watchdog device.
+func (d *qemu) addWatchdogDeviceConfig(cfg *[]cfgSection, bus *qemuBus, watchdogConfig []deviceConfig.RunConfigItem) error {
+	var devName, model string
+	for _, watchdogItem := range watchdogConfig {
+		if watchdogItem.Key == "devName" {
+			devName = watchdogItem.Value
+		} else if watchdogItem.Key == "model" {
+			model = watchdogItem.Value
+		}
+	}
+
+	watchdogOpts := qemuWatchdogOpts{
+		devName: devName,
+		model:   model,
+	}
+
+	// The i6300esb watchdog is a PCI device.
+	if model != "itco" {
+		devBus, devAddr, multi := bus.allocate(fmt.Sprintf("lxd_%s", devName))
+		watchdogOpts.dev = qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		}
+	}
+
+	*cfg = append(*cfg, qemuWatchdog(&watchdogOpts)...)
+
+	return nil
+}
+
 func (d *qemu) addVmgenDeviceConfig(cfg *[]cfgSection, guid string) error {
 	vmgenIDOpts := qemuVmgenIDOpts{
 		guid: guid,
@@ -4973,12 +5275,32 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		}
 	}
 
+	// Freeze the guest file systems, the state of stateful snapshots is already consistent.
+	thaw := func() error { return nil }
+	if !stateful {
+		thaw, err = d.Quiesce()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the snapshot.
 	err = d.snapshotCommon(d, name, expiry, stateful)
+	thawErr := thaw()
 	if err != nil {
 		return err
 	}
 
+	// The file systems may have been thawed before the snapshot was complete, so its content can't be relied on.
+	if thawErr != nil {
+		snap, err := instance.LoadByProjectAndName(d.state, d.project.Name, d.name+shared.SnapshotDelimiter+name)
+		if err == nil {
+			_ = snap.Delete(true)
+		}
+
+		return thawErr
+	}
+
 	// Resume the VM once the disk state has been saved.
 	if stateful {
 		// Remove the state from the main volume.
@@ -5242,6 +5564,17 @@ func (d *qemu) Rename(newName string, applyTemplateTrigger bool) error {
 		}
 	}
 
+	// Rename the memory dumps path.
+	newDumpPath := shared.VarPath("dumps", project.Instance(d.Project().Name, newName))
+	_ = os.RemoveAll(newDumpPath)
+	if shared.PathExists(d.DumpPath()) {
+		err := os.Rename(d.DumpPath(), newDumpPath)
+		if err != nil {
+			d.logger.Error("Failed renaming instance", ctxMap)
+			return err
+		}
+	}
+
 	// Rename the MAAS entry.
 	if !d.IsSnapshot() {
 		err = d.maasRename(d, newName)
@@ -6010,6 +6343,9 @@ func (d *qemu) cleanup() {
 
 	// Remove the shmounts path
 	_ = os.RemoveAll(d.ShmountsPath())
+
+	// Remove the memory dumps
+	_ = os.RemoveAll(d.DumpPath())
 }
 
 // cleanupDevices performs any needed device cleanup steps when instance is stopped.
@@ -7502,6 +7838,21 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 		return nil, fmt.Errorf("Instance is not running")
 	}
 
+	return d.agentUpgradeConn("/1.0/sftp", "sftp")
+}
+
+// PortForwardConn returns a connection to the given TCP port on the loopback interface of the VM through the
+// agent.
+func (d *qemu) PortForwardConn(port int) (net.Conn, error) {
+	if !d.IsRunning() {
+		return nil, fmt.Errorf("Instance is not running")
+	}
+
+	return d.agentUpgradeConn(fmt.Sprintf("/1.0/port-forward?port=%d", port), "port-forward")
+}
+
+// agentUpgradeConn connects to the agent endpoint at path and upgrades the connection to the given protocol.
+func (d *qemu) agentUpgradeConn(path string, protocol string) (net.Conn, error) {
 	// Connect to the agent.
 	client, err := d.getAgentClient()
 	if err != nil {
@@ -7511,11 +7862,11 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 	// Get the HTTP transport.
 	httpTransport, ok := client.Transport.(*http.Transport)
 	if !ok {
-		return nil, fmt.Errorf("FileSFTP transport is an invalid HTTP transport")
+		return nil, fmt.Errorf("Agent transport is an invalid HTTP transport")
 	}
 
 	// Send the upgrade request.
-	u, err := url.Parse("https://custom.socket/1.0/sftp")
+	u, err := url.Parse("https://custom.socket" + path)
 	if err != nil {
 		return nil, err
 	}
@@ -7530,7 +7881,7 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 		Host:       u.Host,
 	}
 
-	req.Header["Upgrade"] = []string{"sftp"}
+	req.Header["Upgrade"] = []string{protocol}
 	req.Header["Connection"] = []string{"Upgrade"}
 
 	conn, err := httpTransport.DialContext(context.Background(), "tcp", "8443")
@@ -7558,7 +7909,7 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 		return nil, fmt.Errorf("Dialing failed: expected status code 101 got %d", resp.StatusCode)
 	}
 
-	if resp.Header.Get("Upgrade") != "sftp" {
+	if resp.Header.Get("Upgrade") != protocol {
 		return nil, fmt.Errorf("Missing or unexpected Upgrade header in response")
 	}
 
@@ -7949,6 +8300,27 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 	return status, nil
 }
 
+// Processes returns the processes running in the virtual machine, as reported by the lxd-agent.
+func (d *qemu) Processes() ([]api.InstanceProcess, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	return agent.GetInstanceProcesses("")
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -8490,6 +8862,64 @@ func (d *qemu) devlxdEventSend(eventType string, eventMessage map[string]any) er
 	return nil
 }
 
+// Quiesce freezes the file systems of the running instance through the lxd-agent when snapshots.quiesce is
+// enabled and returns a function to thaw them. The lxd-agent also thaws them once snapshots.quiesce.timeout
+// expires.
+func (d *qemu) Quiesce() (func() error, error) {
+	if shared.IsFalseOrEmpty(d.expandedConfig["snapshots.quiesce"]) || !d.IsRunning() || d.IsFrozen() {
+		return func() error { return nil }, nil
+	}
+
+	timeout := 60
+	if d.expandedConfig["snapshots.quiesce.timeout"] != "" {
+		timeout, _ = strconv.Atoi(d.expandedConfig["snapshots.quiesce.timeout"])
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed to connect to lxd-agent", logger.Ctx{"err": err})
+		return nil, fmt.Errorf("Failed to connect to lxd-agent")
+	}
+
+	req := agentAPI.FilesystemFreezePost{
+		Action:  "freeze",
+		Timeout: timeout,
+	}
+
+	_, _, err = agent.RawQuery("POST", "/1.0/fsfreeze", req, "")
+	if err != nil {
+		agent.Disconnect()
+		return nil, fmt.Errorf("Failed freezing the file systems of the instance: %w", err)
+	}
+
+	d.logger.Debug("Froze the file systems of the instance", logger.Ctx{"timeout": timeout})
+
+	thaw := func() error {
+		defer agent.Disconnect()
+
+		req := agentAPI.FilesystemFreezePost{
+			Action: "thaw",
+		}
+
+		_, _, err := agent.RawQuery("POST", "/1.0/fsfreeze", req, "")
+		if err != nil {
+			d.logger.Warn("Failed thawing the file systems of the instance", logger.Ctx{"err": err})
+			return fmt.Errorf("Failed thawing the file systems of the instance: %w", err)
+		}
+
+		d.logger.Debug("Thawed the file systems of the instance")
+
+		return nil
+	}
+
+	return thaw, nil
+}
+
 // Info returns "qemu" and the currently loaded qemu version.
 func (d *qemu) Info() instance.Info {
 	data := instance.Info{
@@ -8892,6 +9322,85 @@ func (d *qemu) deviceDetachUSB(usbDev deviceConfig.USBDeviceItem) error {
 	return nil
 }
 
+// deviceAttachSerial live attaches a serial device to a running instance.
+func (d *qemu) deviceAttachSerial(deviceName string, serialConfig []deviceConfig.RunConfigItem) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	// Figure out a hotplug slot.
+	pciDevID := qemuPCIDeviceIDStart
+
+	// Iterate through all the instance devices in the same sorted order as is used when allocating the
+	// boot time devices in order to find the PCI bus slot device we would have used at boot time.
+	// Then attempt to use that same device, assuming it is available.
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Name == deviceName {
+			break // Found our device.
+		}
+
+		pciDevID++
+	}
+
+	pciDeviceName := fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID)
+	d.logger.Debug("Using PCI bus device to hotplug serial device into", logger.Ctx{"device": deviceName, "port": pciDeviceName})
+
+	qemuDev := map[string]string{
+		"bus":  pciDeviceName,
+		"addr": "00.0",
+	}
+
+	monHook, err := d.addSerialDeviceConfig(qemuDev, serialConfig)
+	if err != nil {
+		return err
+	}
+
+	return monHook(monitor)
+}
+
+// deviceDetachSerial detaches a serial device from a running instance.
+func (d *qemu) deviceDetachSerial(deviceName string) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(deviceName)
+	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
+	chardevID := fmt.Sprintf("lxd_%s", escapedDeviceName)
+
+	err = monitor.RemoveDevice(deviceID)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return fmt.Errorf("Failed removing serial device: %w", err)
+	}
+
+	// Wait until the device is actually removed (or we timeout waiting) as the character device can't be
+	// removed while it's in use.
+	waitDuration := time.Duration(time.Second * time.Duration(10))
+	waitUntil := time.Now().Add(waitDuration)
+	for {
+		err = monitor.RemoveCharDevice(chardevID)
+		if err == nil {
+			break
+		}
+
+		if time.Now().After(waitUntil) {
+			return fmt.Errorf("Failed to detach serial device after %v: %w", waitDuration, err)
+		}
+
+		d.logger.Debug("Waiting for serial device to be detached", logger.Ctx{"device": deviceName})
+		time.Sleep(time.Second * time.Duration(2))
+	}
+
+	// The file backend uses a file descriptor set which only exists for that backend.
+	_ = monitor.RemoveFDFromFDSet(chardevID)
+
+	return nil
+}
+
 // Block node names may only be up to 31 characters long, so use a hash if longer.
 func (d *qemu) blockNodeName(name string) string {
 	if len(name) > 27 {
@@ -1081,6 +1081,37 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_watchdog", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuWatchdogOpts
+			expected string
+		}{{
+			qemuWatchdogOpts{
+				dev:     qemuDevOpts{"pcie", "qemu_pcie1", "00.0", false},
+				devName: "wd0",
+				model:   "i6300esb",
+			},
+			`# Watchdog ("wd0" device)
+			[device "dev-lxd_wd0"]
+			driver = "i6300esb"
+			bus = "qemu_pcie1"
+			addr = "00.0"`,
+		}, {
+			qemuWatchdogOpts{
+				devName: "wd0",
+				model:   "itco",
+			},
+			`# Watchdog ("wd0" device)
+			[global]
+			driver = "ICH9-LPC"
+			property = "noreboot"
+			value = "off"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuWatchdog(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_raw_cfg_override", func(t *testing.T) {
 		cfg := []cfgSection{{
 			name: "global",
@@ -889,6 +889,38 @@ func qemuTPM(opts *qemuTPMOpts) []cfgSection {
 	}}
 }
 
+type qemuWatchdogOpts struct {
+	dev     qemuDevOpts
+	devName string
+	model   string
+}
+
+func qemuWatchdog(opts *qemuWatchdogOpts) []cfgSection {
+	// The iTCO watchdog is part of the ICH9 chipset, it only needs to be allowed to fire.
+	if opts.model == "itco" {
+		return []cfgSection{{
+			name:    "global",
+			comment: fmt.Sprintf(`Watchdog ("%s" device)`, opts.devName),
+			entries: []cfgEntry{
+				{key: "driver", value: "ICH9-LPC"},
+				{key: "property", value: "noreboot"},
+				{key: "value", value: "off"},
+			},
+		}}
+	}
+
+	deviceOpts := qemuDevEntriesOpts{
+		dev:     opts.dev,
+		pciName: "i6300esb",
+	}
+
+	return []cfgSection{{
+		name:    fmt.Sprintf(`device "dev-lxd_%s"`, opts.devName),
+		comment: fmt.Sprintf(`Watchdog ("%s" device)`, opts.devName),
+		entries: qemuDeviceEntries(&deviceOpts),
+	}}
+}
+
 type qemuVmgenIDOpts struct {
 	guid string
 }
@@ -653,6 +653,28 @@ func (m *Monitor) Reset() error {
 	return nil
 }
 
+// DumpGuestMemory writes the memory of the VM to the given file in ELF format.
+// The VM is paused while its memory is written.
+func (m *Monitor) DumpGuestMemory(file *os.File) error {
+	err := m.SendFile("memory-dump", file)
+	if err != nil {
+		return err
+	}
+
+	args := map[string]any{
+		"paging":   false,
+		"protocol": "fd:memory-dump",
+		"format":   "elf",
+	}
+
+	err = m.run("dump-guest-memory", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed dumping guest memory: %w", err)
+	}
+
+	return nil
+}
+
 // PCIClassInfo info about a device's class.
 type PCIClassInfo struct {
 	Class       int    `json:"class"`
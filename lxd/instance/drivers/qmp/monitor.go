@@ -25,6 +25,9 @@ var EventAgentStarted = "LXD-AGENT-STARTED"
 // EventVMShutdown is the event sent when VM guest shuts down.
 var EventVMShutdown = "SHUTDOWN"
 
+// EventWatchdog is the event sent when the watchdog device of the VM expires.
+var EventWatchdog = "WATCHDOG"
+
 // EventVMShutdownReasonDisconnect is used as the reason when the shutdown event is triggered by a QMP disconnect.
 var EventVMShutdownReasonDisconnect = "disconnect"
 
@@ -108,6 +108,9 @@ type Instance interface {
 	FileSFTPConn() (net.Conn, error)
 	FileSFTP() (*sftp.Client, error)
 
+	// Port forwarding.
+	PortForwardConn(port int) (net.Conn, error)
+
 	// Console - Allocate and run a console tty or a spice Unix socket.
 	Console(protocol string) (*os.File, chan error, error)
 	Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (Cmd, error)
@@ -116,6 +119,7 @@ type Instance interface {
 	Render(options ...func(response any) error) (any, any, error)
 	RenderFull(hostInterfaces []net.Interface) (*api.InstanceFull, any, error)
 	RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error)
+	Processes() ([]api.InstanceProcess, error)
 	IsRunning() bool
 	IsFrozen() bool
 	IsEphemeral() bool
@@ -151,6 +155,7 @@ type Instance interface {
 	LogFilePath() string
 	ConsoleBufferLogPath() string
 	LogPath() string
+	DumpPath() string
 	DevicesPath() string
 
 	// Storage.
@@ -189,6 +194,9 @@ type VM interface {
 
 	AgentCertificate() *x509.Certificate
 
+	// Quiesce freezes the guest file systems if enabled and returns a function to thaw them.
+	Quiesce() (func() error, error)
+
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
@@ -112,6 +112,20 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
 	}
 
+	if expanded {
+		switch config["health.check"] {
+		case "exec":
+			if config["health.check.command"] == "" {
+				return fmt.Errorf("health.check.command is required when health.check is %q", config["health.check"])
+			}
+
+		case "tcp", "http":
+			if config["health.check.port"] == "" {
+				return fmt.Errorf("health.check.port is required when health.check is %q", config["health.check"])
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -7,7 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kballard/go-shellquote"
+
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/validate"
 )
@@ -24,6 +27,14 @@ const (
 	Unfreeze InstanceAction = "unfreeze"
 )
 
+// Actions that can be requested through devlxd.
+const (
+	DevLXDActionSnapshot = "snapshot"
+	DevLXDActionConfig   = "config"
+	DevLXDActionDNS      = "dns"
+	DevLXDActionRestart  = "restart"
+)
+
 // ConfigVolatilePrefix indicates the prefix used for volatile config keys.
 const ConfigVolatilePrefix = "volatile."
 
@@ -102,6 +113,26 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on)
+	// Comma-separated list of instances in the same project that must be started before this instance and stopped after it.
+	// When LXD starts the instances, it waits for each of them to report that it is ready before starting this instance (see `boot.depends_on.timeout`).
+	// Dependencies take precedence over `boot.autostart.priority` and `boot.stop.priority`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Instances that this instance depends on
+	"boot.depends_on": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on.timeout)
+	// The number of seconds to wait for the instances listed in `boot.depends_on` to report that they are ready before starting this instance anyway.
+	// An instance reports that it is ready through the `/dev/lxd` API.
+	// ---
+	//  type: integer
+	//  defaultdesc: "60"
+	//  liveupdate: no
+	//  shortdesc: How long to wait for the dependencies to be ready
+	"boot.depends_on.timeout": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
 	// ---
@@ -120,6 +151,36 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: How long to wait for the instance to shut down
 	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.schedule.start)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or `@never` or an empty value to disable scheduled starts.
+	// The schedule is evaluated in the time zone set in `boot.schedule.timezone`.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for automatically starting the instance
+	"boot.schedule.start": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.schedule.stop)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or `@never` or an empty value to disable scheduled stops.
+	// The schedule is evaluated in the time zone set in `boot.schedule.timezone`.
+	// The instance is shut down cleanly and stopped forcefully if it doesn't shut down within `boot.host_shutdown_timeout`.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: yes
+	//  shortdesc: Schedule for automatically stopping the instance
+	"boot.schedule.stop": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.schedule.timezone)
+	// Name of a time zone from the IANA time zone database, for example, `Europe/London`.
+	// ---
+	//  type: string
+	//  defaultdesc: time zone of the LXD server
+	//  liveupdate: yes
+	//  shortdesc: Time zone of the start and stop schedules
+	"boot.schedule.timezone": validate.Optional(validate.IsTimezone),
+
 	// lxdmeta:generate(entities=instance; group=cloud-init; key=cloud-init.network-config)
 	// The content is used as seed value for `cloud-init`.
 	// ---
@@ -202,6 +263,148 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
 
+	// lxdmeta:generate(entities=instance; group=health; key=health.check)
+	// Possible values are `exec` (run `health.check.command` inside the instance), `tcp` (connect to
+	// `health.check.port` on the address of the instance) and `http` (send a `GET` request for
+	// `health.check.path` to `health.check.port` on the address of the instance).
+	//
+	// See {ref}`instances-health-checks` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Type of health check of the instance
+	"health.check": validate.Optional(validate.IsOneOf("exec", "tcp", "http")),
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.command)
+	// The command is split on spaces (honouring shell quoting) and run directly, without a shell.
+	// The check succeeds if the command exits with status 0.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `health.check` is `exec`
+	//  shortdesc: Command to run for the health check
+	"health.check.command": func(value string) error {
+		_, err := shellquote.Split(value)
+		return err
+	},
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.port)
+	// The TCP port is reached on the first global address of the instance, preferring IPv4 addresses.
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  condition: `health.check` is `tcp` or `http`
+	//  shortdesc: Port to connect to for the health check
+	"health.check.port": validate.Optional(validate.IsNetworkPort),
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.path)
+	// The check succeeds if the response has a 2xx or 3xx status code.
+	// ---
+	//  type: string
+	//  defaultdesc: `/`
+	//  liveupdate: yes
+	//  condition: `health.check` is `http`
+	//  shortdesc: Path to request for the health check
+	"health.check.path": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.interval)
+	// Health checks are scheduled at a granularity of 10 seconds.
+	// ---
+	//  type: integer
+	//  defaultdesc: "30"
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds between health checks
+	"health.check.interval": validate.Optional(validate.IsInRange(10, 86400)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.timeout)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: "5"
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds after which a health check fails
+	"health.check.timeout": validate.Optional(validate.IsInRange(1, 3600)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.check.retries)
+	// The instance becomes unhealthy once this number of health checks failed in a row.
+	// ---
+	//  type: integer
+	//  defaultdesc: "3"
+	//  liveupdate: yes
+	//  shortdesc: Number of failed health checks before the instance is unhealthy
+	"health.check.retries": validate.Optional(validate.IsInRange(1, 100)),
+
+	// lxdmeta:generate(entities=instance; group=health; key=health.action)
+	// Possible values are `none` (only report the health status), `restart` (restart the instance) and
+	// `evacuate` (move the instance to another cluster member and start it there).
+	// The action is taken when the instance becomes unhealthy.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: yes
+	//  shortdesc: What to do when the instance becomes unhealthy
+	"health.action": validate.Optional(validate.IsOneOf("none", "restart", "evacuate")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=instance.expiry)
+	// Specify either an absolute time in RFC 3339 format (for example, `2025-06-30T18:00:00Z`) or an expression
+	// like `1M 2H 3d 4w 5m 6y` that is relative to the time set by `instance.expiry.since`.
+	// Expired instances are stopped and deleted.
+	//
+	// See {ref}`instances-expiry` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: When the instance is to be deleted
+	"instance.expiry": func(value string) error {
+		_, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return nil
+		}
+
+		_, err = shared.GetExpiry(time.Time{}, value)
+		if err != nil {
+			return fmt.Errorf("Expiry must be an RFC 3339 time or an expression like %q: %w", "1M 2H 3d 4w 5m 6y", err)
+		}
+
+		return nil
+	},
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=instance.expiry.since)
+	// Possible values are `created` (the creation time of the instance) and `last-used` (the last time the
+	// instance was started or used).
+	// ---
+	//  type: string
+	//  defaultdesc: `created`
+	//  liveupdate: yes
+	//  condition: `instance.expiry` is an expression
+	//  shortdesc: What a relative `instance.expiry` is relative to
+	"instance.expiry.since": validate.Optional(validate.IsOneOf("created", "last-used")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=logs.loki)
+	// Comma-separated list of log sources (`console`, `lxd` and `guest`) of the instance whose new entries are
+	// forwarded to the Loki server set in `loki.api.url` while the instance is running. The `console` source is
+	// only available for containers. Forwarding the `guest` source requires the `can_exec` permission on the
+	// instance.
+	//
+	// See {ref}`instances-logs` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Instance log sources to forward to Loki
+	"logs.loki": validate.Optional(validate.IsListOf(validate.IsOneOf(api.InstanceLogSources...))),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=placement.group)
+	// The placement group must exist in the instance's project. Its policy is enforced when the instance
+	// is placed automatically on a cluster member, either at creation or when evacuating or restoring a
+	// cluster member.
+	//
+	// See {ref}`clustering-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Placement group of the instance
+	"placement.group": validate.Optional(validate.IsHostname),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -302,6 +505,42 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether `/dev/lxd` is present in the instance
 	"security.devlxd": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.secrets)
+	// Specify a comma-separated list of secrets of the project that the instance can read through `/1.0/secrets` over `devlxd`.
+	// See {ref}`secrets` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Secrets readable through `devlxd`
+	"security.devlxd.secrets": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.actions)
+	// Specify a comma-separated list of actions that the instance can request through `devlxd`.
+	// Possible values are `snapshot` (create a snapshot of the instance), `config` (change the keys allowed by {config:option}`instance-security:security.devlxd.config_keys`),
+	// `dns` (manage records named after the instance in the forward DNS zones of its networks) and `restart` (gracefully restart the instance).
+	// See {ref}`dev-lxd` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Actions the instance can request through `devlxd`
+	"security.devlxd.actions": validate.Optional(validate.IsListOf(validate.IsOneOf(DevLXDActionSnapshot, DevLXDActionConfig, DevLXDActionDNS, DevLXDActionRestart))),
+
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.config_keys)
+	// Specify a comma-separated list of `user.*` configuration keys that the instance can change through `devlxd`.
+	// A trailing `*` matches all keys with the given prefix, for example `user.app.*`.
+	// This requires `config` to be listed in {config:option}`instance-security:security.devlxd.actions`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: User keys the instance can change through `devlxd`
+	"security.devlxd.config_keys": validate.Optional(validate.IsListOf(func(value string) error {
+		if !shared.IsUserConfig(value) {
+			return fmt.Errorf("Only user keys can be allowed")
+		}
+
+		return nil
+	})),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.protection.delete)
 	//
 	// ---
@@ -1033,6 +1272,30 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to use the name and MTU of the default network interfaces
 	"agent.nic_config": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.quiesce)
+	// When enabled, the file systems of the instance are frozen through the `lxd-agent` while snapshots and backups of the running instance are taken, so that their content is consistent.
+	// The snapshot or backup fails if the file systems can't be frozen.
+	//
+	// See {ref}`instances-snapshots-quiesce` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to freeze the guest file systems during snapshots and backups
+	"snapshots.quiesce": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.quiesce.timeout)
+	// The `lxd-agent` thaws the file systems of the instance after this number of seconds (at most 3600), even if the snapshot isn't complete yet.
+	// The snapshot or backup then fails.
+	// ---
+	//  type: integer
+	//  defaultdesc: `60`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Maximum time for which the guest file systems are frozen
+	"snapshots.quiesce.timeout": validate.Optional(validate.IsInRange(1, 3600)),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.apply_nvram)
 	//
 	// ---
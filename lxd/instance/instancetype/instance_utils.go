@@ -2,8 +2,11 @@ package instancetype
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
 
@@ -65,3 +68,140 @@ func ExpandInstanceDevices(devices deviceConfig.Devices, profiles []api.Profile)
 
 	return expandedDevices
 }
+
+// BootDependencies returns the names of the instances listed in the boot.depends_on key of the given config.
+func BootDependencies(config map[string]string) []string {
+	names := []string{}
+
+	for _, name := range strings.Split(config["boot.depends_on"], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// DependencyOrder returns the given names ordered so that each name comes after the names it depends on, otherwise
+// keeping their original order. Dependencies on names that aren't in the list are ignored.
+// The names that are part of a dependency cycle, or depend on one, are appended in their original order and are
+// also returned as the second value.
+func DependencyOrder(names []string, dependsOn map[string][]string) ([]string, []string) {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	ordered := make([]string, 0, len(names))
+	placed := make(map[string]bool, len(names))
+
+	for len(ordered) < len(names) {
+		progress := false
+
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+
+			// A name depending on itself is never ready.
+			ready := true
+			for _, dependency := range dependsOn[name] {
+				if known[dependency] && !placed[dependency] {
+					ready = false
+					break
+				}
+			}
+
+			if !ready {
+				continue
+			}
+
+			ordered = append(ordered, name)
+			placed[name] = true
+			progress = true
+
+			// Start again from the beginning so that the original order is kept as much as possible.
+			break
+		}
+
+		if !progress {
+			break
+		}
+	}
+
+	var cycle []string
+	for _, name := range names {
+		if !placed[name] {
+			cycle = append(cycle, name)
+			ordered = append(ordered, name)
+		}
+	}
+
+	return ordered, cycle
+}
+
+// DevLXDActionAllowed returns whether the action can be requested through devlxd according to the
+// security.devlxd and security.devlxd.actions keys of the given expanded config.
+func DevLXDActionAllowed(config map[string]string, action string) bool {
+	if shared.IsFalse(config["security.devlxd"]) {
+		return false
+	}
+
+	return shared.ValueInSlice(action, shared.SplitNTrimSpace(config["security.devlxd.actions"], ",", -1, true))
+}
+
+// DevLXDConfigKeyAllowed returns whether the key can be changed through devlxd according to the
+// security.devlxd.config_keys key of the given expanded config. A trailing "*" in the list acts as a prefix match.
+func DevLXDConfigKeyAllowed(config map[string]string, key string) bool {
+	if !shared.IsUserConfig(key) {
+		return false
+	}
+
+	for _, pattern := range shared.SplitNTrimSpace(config["security.devlxd.config_keys"], ",", -1, true) {
+		prefix, isPrefix := strings.CutSuffix(pattern, "*")
+		if pattern == key || (isPrefix && strings.HasPrefix(key, prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Expiry returns when an instance expires based on the instance.expiry and instance.expiry.since keys of its
+// config and on the restricted.instance.max_ttl limit of its project (which is ignored if empty).
+// The zero time is returned if the instance never expires.
+func Expiry(config map[string]string, maxTTL string, creationDate time.Time, lastUsedDate time.Time) (time.Time, error) {
+	var expiry time.Time
+
+	value := strings.TrimSpace(config["instance.expiry"])
+	if value != "" {
+		var err error
+
+		expiry, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			refDate := creationDate
+			if config["instance.expiry.since"] == "last-used" && lastUsedDate.After(creationDate) {
+				refDate = lastUsedDate
+			}
+
+			expiry, err = shared.GetExpiry(refDate, value)
+			if err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+
+	if maxTTL != "" {
+		maxExpiry, err := shared.GetExpiry(creationDate, maxTTL)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if expiry.IsZero() || maxExpiry.Before(expiry) {
+			expiry = maxExpiry
+		}
+	}
+
+	return expiry, nil
+}
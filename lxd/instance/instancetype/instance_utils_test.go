@@ -0,0 +1,110 @@
+package instancetype
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootDependencies(t *testing.T) {
+	assert.Equal(t, []string{}, BootDependencies(map[string]string{}))
+	assert.Equal(t, []string{"db", "cache"}, BootDependencies(map[string]string{"boot.depends_on": "db, cache,"}))
+}
+
+func TestDevLXDActionAllowed(t *testing.T) {
+	config := map[string]string{"security.devlxd.actions": "snapshot, dns"}
+
+	assert.True(t, DevLXDActionAllowed(config, DevLXDActionSnapshot))
+	assert.True(t, DevLXDActionAllowed(config, DevLXDActionDNS))
+	assert.False(t, DevLXDActionAllowed(config, DevLXDActionRestart))
+	assert.False(t, DevLXDActionAllowed(map[string]string{}, DevLXDActionSnapshot))
+
+	// Nothing is allowed when devlxd is disabled.
+	config["security.devlxd"] = "false"
+	assert.False(t, DevLXDActionAllowed(config, DevLXDActionSnapshot))
+}
+
+func TestDevLXDConfigKeyAllowed(t *testing.T) {
+	config := map[string]string{"security.devlxd.config_keys": "user.status, user.app.*"}
+
+	assert.True(t, DevLXDConfigKeyAllowed(config, "user.status"))
+	assert.True(t, DevLXDConfigKeyAllowed(config, "user.app.version"))
+	assert.False(t, DevLXDConfigKeyAllowed(config, "user.status.detail"))
+	assert.False(t, DevLXDConfigKeyAllowed(config, "user.other"))
+
+	// Only user keys can ever be changed.
+	config["security.devlxd.config_keys"] = "user.*"
+	assert.True(t, DevLXDConfigKeyAllowed(config, "user.other"))
+	assert.False(t, DevLXDConfigKeyAllowed(config, "limits.cpu"))
+}
+
+func TestDependencyOrder(t *testing.T) {
+	names := []string{"web", "db", "cache", "other"}
+
+	// Dependencies come first, otherwise the original order is kept.
+	ordered, cycle := DependencyOrder(names, map[string][]string{
+		"web":   {"cache", "db"},
+		"cache": {"db"},
+		"other": {"missing"},
+	})
+
+	assert.Equal(t, []string{"db", "cache", "web", "other"}, ordered)
+	assert.Empty(t, cycle)
+
+	// Names in a cycle and those depending on them are appended in their original order.
+	ordered, cycle = DependencyOrder(names, map[string][]string{
+		"web":   {"cache"},
+		"cache": {"db"},
+		"db":    {"cache"},
+	})
+
+	assert.Equal(t, []string{"other", "web", "db", "cache"}, ordered)
+	assert.Equal(t, []string{"web", "db", "cache"}, cycle)
+
+	// A name depending on itself is a cycle.
+	ordered, cycle = DependencyOrder([]string{"a", "b"}, map[string][]string{"a": {"a"}})
+	assert.Equal(t, []string{"b", "a"}, ordered)
+	assert.Equal(t, []string{"a"}, cycle)
+}
+
+func TestExpiry(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastUsed := created.Add(48 * time.Hour)
+
+	expiry, err := Expiry(map[string]string{}, "", created, lastUsed)
+	require.NoError(t, err)
+	assert.True(t, expiry.IsZero())
+
+	// Absolute time.
+	expiry, err = Expiry(map[string]string{"instance.expiry": "2024-02-01T12:00:00Z"}, "", created, lastUsed)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), expiry)
+
+	// Duration since creation or last use.
+	expiry, err = Expiry(map[string]string{"instance.expiry": "1w"}, "", created, lastUsed)
+	require.NoError(t, err)
+	assert.Equal(t, created.AddDate(0, 0, 7), expiry)
+
+	expiry, err = Expiry(map[string]string{"instance.expiry": "1w", "instance.expiry.since": "last-used"}, "", created, lastUsed)
+	require.NoError(t, err)
+	assert.Equal(t, lastUsed.AddDate(0, 0, 7), expiry)
+
+	// A never used instance expires relative to its creation.
+	expiry, err = Expiry(map[string]string{"instance.expiry": "1w", "instance.expiry.since": "last-used"}, "", created, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, created.AddDate(0, 0, 7), expiry)
+
+	// The project limit applies when it's earlier.
+	expiry, err = Expiry(map[string]string{"instance.expiry": "1w"}, "3d", created, lastUsed)
+	require.NoError(t, err)
+	assert.Equal(t, created.AddDate(0, 0, 3), expiry)
+
+	expiry, err = Expiry(map[string]string{}, "3d", created, lastUsed)
+	require.NoError(t, err)
+	assert.Equal(t, created.AddDate(0, 0, 3), expiry)
+
+	_, err = Expiry(map[string]string{"instance.expiry": "tomorrow"}, "", created, lastUsed)
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/operations"
@@ -61,6 +62,9 @@ type consoleWs struct {
 
 	// channel type (either console or vga)
 	protocol string
+
+	// name of the serial device to attach to instead of the main console
+	device string
 }
 
 // Metadata returns a map of metadata.
@@ -207,8 +211,19 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	defer logger.Debug("Console websocket finished")
 	<-s.allConnected
 
-	// Get console from instance.
-	console, consoleDisconnectCh, err := s.instance.Console(s.protocol)
+	var console *os.File
+	var consoleDisconnectCh chan error
+	var err error
+
+	if s.device != "" {
+		// Get console from serial device.
+		console, err = device.SerialConsole(s.instance, s.device)
+		consoleDisconnectCh = make(chan error, 1)
+	} else {
+		// Get console from instance.
+		console, consoleDisconnectCh, err = s.instance.Console(s.protocol)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -322,10 +337,13 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	// Write a reset escape sequence to the console to cancel any ongoing reads to the handle
 	// and then close it. This ordering is important, close the console before closing the
 	// websocket to ensure console doesn't get stuck reading.
-	_, err = console.Write([]byte("\x1bc"))
-	if err != nil {
-		_ = console.Close()
-		return err
+	// Serial devices are sockets, closing them is enough and the guest shouldn't receive the sequence.
+	if s.device == "" {
+		_, err = console.Write([]byte("\x1bc"))
+		if err != nil {
+			_ = console.Close()
+			return err
+		}
 	}
 
 	err = console.Close()
@@ -485,6 +503,17 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
+	if post.Device != "" {
+		if post.Type != instance.ConsoleTypeConsole {
+			return response.BadRequest(fmt.Errorf("Serial devices are only supported by the %q console type", instance.ConsoleTypeConsole))
+		}
+
+		devConfig, ok := inst.ExpandedDevices()[post.Device]
+		if !ok || devConfig["type"] != "serial" {
+			return response.BadRequest(fmt.Errorf("Serial device %q not found", post.Device))
+		}
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -512,6 +541,7 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	ws.width = post.Width
 	ws.height = post.Height
 	ws.protocol = post.Type
+	ws.device = post.Device
 
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -191,37 +191,11 @@ func (s *execWs) Do(op *operations.Operation) error {
 			ttys = make([]*os.File, 1)
 			ptys = make([]*os.File, 1)
 
-			var rootUID, rootGID int64
-			var devptsFd *os.File
-
-			c, ok := s.instance.(instance.Container)
-			if !ok {
-				return fmt.Errorf("Invalid instance type")
-			}
-
-			idmapset, err := c.CurrentIdmap()
+			ptys[0], ttys[0], err = execContainerPty(s.s, s.instance)
 			if err != nil {
 				return err
 			}
 
-			if idmapset != nil {
-				rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
-			}
-
-			devptsFd, _ = c.DevptsFd()
-
-			if devptsFd != nil && s.s.OS.NativeTerminals {
-				ptys[0], ttys[0], err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
-				_ = devptsFd.Close()
-				devptsFd = nil
-			} else {
-				ptys[0], ttys[0], err = shared.OpenPty(rootUID, rootGID)
-			}
-
-			if err != nil {
-				return fmt.Errorf("Unable to open the PTY device: %w", err)
-			}
-
 			stdin = ttys[0]
 			stdout = ttys[0]
 			stderr = ttys[0]
@@ -489,6 +463,41 @@ func (s *execWs) Do(op *operations.Operation) error {
 	return finisher(exitStatus, err)
 }
 
+// execContainerPty opens a PTY for running an interactive command in the container. The PTY is allocated in the
+// devpts instance of the container when supported.
+func execContainerPty(s *state.State, inst instance.Instance) (pty *os.File, tty *os.File, err error) {
+	var rootUID, rootGID int64
+
+	c, ok := inst.(instance.Container)
+	if !ok {
+		return nil, nil, fmt.Errorf("Invalid instance type")
+	}
+
+	idmapset, err := c.CurrentIdmap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if idmapset != nil {
+		rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
+	}
+
+	devptsFd, _ := c.DevptsFd()
+
+	if devptsFd != nil && s.OS.NativeTerminals {
+		pty, tty, err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
+		_ = devptsFd.Close()
+	} else {
+		pty, tty, err = shared.OpenPty(rootUID, rootGID)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to open the PTY device: %w", err)
+	}
+
+	return pty, tty, nil
+}
+
 // swagger:operation POST /1.0/instances/{name}/exec instances instance_exec_post
 //
 //	Run a command
@@ -599,66 +608,7 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Instance is frozen"))
 	}
 
-	// Process environment.
-	if post.Environment == nil {
-		post.Environment = map[string]string{}
-	}
-
-	// Override any environment variable settings from the instance if not manually specified in post.
-	for k, v := range inst.ExpandedConfig() {
-		if strings.HasPrefix(k, "environment.") {
-			envKey := strings.TrimPrefix(k, "environment.")
-			_, found := post.Environment[envKey]
-			if !found {
-				post.Environment[envKey] = v
-			}
-		}
-	}
-
-	// Set default value for PATH.
-	_, ok := post.Environment["PATH"]
-	if !ok {
-		post.Environment["PATH"] = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
-
-		if inst.Type() == instancetype.Container {
-			// Add some additional paths. This directly looks through /proc
-			// rather than use FileExists as none of those paths are expected to be
-			// symlinks and this is much faster than forking a sub-process and
-			// attaching to the instance.
-			extraPaths := map[string]string{
-				"/snap":      "/snap/bin",
-				"/etc/NIXOS": "/run/current-system/sw/bin",
-			}
-
-			instPID := inst.InitPID()
-			for k, v := range extraPaths {
-				if shared.PathExists(fmt.Sprintf("/proc/%d/root%s", instPID, k)) {
-					post.Environment["PATH"] = fmt.Sprintf("%s:%s", post.Environment["PATH"], v)
-				}
-			}
-		}
-	}
-
-	// If running as root, set some env variables.
-	if post.User == 0 {
-		// Set default value for HOME.
-		_, ok = post.Environment["HOME"]
-		if !ok {
-			post.Environment["HOME"] = "/root"
-		}
-
-		// Set default value for USER.
-		_, ok = post.Environment["USER"]
-		if !ok {
-			post.Environment["USER"] = "root"
-		}
-	}
-
-	// Set default value for LANG.
-	_, ok = post.Environment["LANG"]
-	if !ok {
-		post.Environment["LANG"] = "C.UTF-8"
-	}
+	post.Environment = instanceExecEnvironment(inst, post.Environment, post.User)
 
 	if post.WaitForWS {
 		ws := &execWs{}
@@ -776,3 +726,69 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 
 	return operations.OperationResponse(op)
 }
+
+// instanceExecEnvironment returns the environment of a command run in the instance as the given user. The
+// environment variables of the instance and defaults are added unless already set in env.
+func instanceExecEnvironment(inst instance.Instance, env map[string]string, user uint32) map[string]string {
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	// Override any environment variable settings from the instance if not manually specified in the request.
+	for k, v := range inst.ExpandedConfig() {
+		if strings.HasPrefix(k, "environment.") {
+			envKey := strings.TrimPrefix(k, "environment.")
+			_, found := env[envKey]
+			if !found {
+				env[envKey] = v
+			}
+		}
+	}
+
+	// Set default value for PATH.
+	_, ok := env["PATH"]
+	if !ok {
+		env["PATH"] = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+		if inst.Type() == instancetype.Container {
+			// Add some additional paths. This directly looks through /proc
+			// rather than use FileExists as none of those paths are expected to be
+			// symlinks and this is much faster than forking a sub-process and
+			// attaching to the instance.
+			extraPaths := map[string]string{
+				"/snap":      "/snap/bin",
+				"/etc/NIXOS": "/run/current-system/sw/bin",
+			}
+
+			instPID := inst.InitPID()
+			for k, v := range extraPaths {
+				if shared.PathExists(fmt.Sprintf("/proc/%d/root%s", instPID, k)) {
+					env["PATH"] = fmt.Sprintf("%s:%s", env["PATH"], v)
+				}
+			}
+		}
+	}
+
+	// If running as root, set some env variables.
+	if user == 0 {
+		// Set default value for HOME.
+		_, ok = env["HOME"]
+		if !ok {
+			env["HOME"] = "/root"
+		}
+
+		// Set default value for USER.
+		_, ok = env["USER"]
+		if !ok {
+			env["USER"] = "root"
+		}
+	}
+
+	// Set default value for LANG.
+	_, ok = env["LANG"]
+	if !ok {
+		env["LANG"] = "C.UTF-8"
+	}
+
+	return env
+}
@@ -0,0 +1,1231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// execSessionScrollbackSize is the amount of recent output of an exec session that is replayed to attaching clients.
+const execSessionScrollbackSize = 1024 * 1024
+
+// execSessionExitedRetention is how long an exec session is kept after its command exited.
+const execSessionExitedRetention = 24 * time.Hour
+
+// execSessionClientQueueSize is the number of output chunks queued for an attached client before it is detached.
+const execSessionClientQueueSize = 64
+
+// execSessionsMaxPerInstance is the maximum number of exec sessions of an instance.
+const execSessionsMaxPerInstance = 32
+
+// execSessionsMax is the maximum number of exec sessions on the member.
+const execSessionsMax = 256
+
+// execSessions holds the exec sessions of the instances running on this member.
+var execSessions = &execSessionRegistry{sessions: map[string]*execSession{}, starting: map[int]int{}}
+
+// execSessionRegistry keeps track of the exec sessions by ID.
+type execSessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*execSession
+
+	// Number of sessions being started by instance ID, which count towards the limits.
+	starting map[int]int
+}
+
+// reserve checks that a new exec session can be started for the instance without exceeding the per-instance and
+// member limits, and counts it towards them until the returned function is called. Sessions that exited longer
+// than execSessionExitedRetention ago are removed.
+func (r *execSessionRegistry) reserve(inst instance.Instance) (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+
+	total := 0
+	instTotal := r.starting[inst.ID()]
+	for _, count := range r.starting {
+		total += count
+	}
+
+	for _, session := range r.sessions {
+		total++
+		if session.instID == inst.ID() {
+			instTotal++
+		}
+	}
+
+	if instTotal >= execSessionsMaxPerInstance {
+		return nil, api.StatusErrorf(http.StatusTooManyRequests, "The instance already has %d exec sessions", execSessionsMaxPerInstance)
+	}
+
+	if total >= execSessionsMax {
+		return nil, api.StatusErrorf(http.StatusTooManyRequests, "The server already has %d exec sessions", execSessionsMax)
+	}
+
+	r.starting[inst.ID()]++
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.starting[inst.ID()]--
+		if r.starting[inst.ID()] <= 0 {
+			delete(r.starting, inst.ID())
+		}
+	}, nil
+}
+
+// add registers a new exec session.
+func (r *execSessionRegistry) add(session *execSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.id] = session
+}
+
+// remove unregisters an exec session.
+func (r *execSessionRegistry) remove(session *execSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, session.id)
+}
+
+// get returns the exec session with the given ID of the instance.
+func (r *execSessionRegistry) get(inst instance.Instance, id string) (*execSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.instID != inst.ID() || session.expired() {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Exec session not found")
+	}
+
+	return session, nil
+}
+
+// list returns the exec sessions of the instance sorted by creation date. Sessions that exited longer than
+// execSessionExitedRetention ago are removed.
+func (r *execSessionRegistry) list(inst instance.Instance) []*execSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+
+	sessions := []*execSession{}
+	for _, session := range r.sessions {
+		if session.instID == inst.ID() {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].createdAt.Before(sessions[j].createdAt)
+	})
+
+	return sessions
+}
+
+// prune removes the sessions that exited longer than execSessionExitedRetention ago, and those of the instances
+// whose ID isn't in the given list. The commands of the removed sessions that are still running are killed.
+func (r *execSessionRegistry) prune(instIDs []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+
+	for id, session := range r.sessions {
+		if shared.ValueInSlice(session.instID, instIDs) {
+			continue
+		}
+
+		if session.running() {
+			_ = session.cmd.Signal(unix.SIGKILL)
+		}
+
+		delete(r.sessions, id)
+	}
+}
+
+// pruneLocked removes the sessions that exited longer than execSessionExitedRetention ago, the caller must hold
+// the registry lock.
+func (r *execSessionRegistry) pruneLocked() {
+	for id, session := range r.sessions {
+		if session.expired() {
+			delete(r.sessions, id)
+		}
+	}
+}
+
+// pruneExecSessions removes the expired exec sessions and those of the instances that are no longer on this member.
+func pruneExecSessions(ctx context.Context, s *state.State) error {
+	var instIDs []int
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbInstances, err := dbCluster.GetInstances(ctx, tx.Tx(), dbCluster.InstanceFilter{Node: &s.ServerName})
+		if err != nil {
+			return err
+		}
+
+		for _, dbInst := range dbInstances {
+			instIDs = append(instIDs, dbInst.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting instances: %w", err)
+	}
+
+	execSessions.prune(instIDs)
+
+	return nil
+}
+
+func pruneExecSessionsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := pruneExecSessions(ctx, d.State())
+		if err != nil {
+			logger.Error("Failed pruning exec sessions", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Hour)
+}
+
+// execSessionsHandleEvent returns an event handler removing the exec sessions of the deleted instances.
+func execSessionsHandleEvent(d *Daemon) events.EventHandler {
+	return func(event api.Event) {
+		if event.Type != api.EventTypeLifecycle {
+			return
+		}
+
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil || lifecycleEvent.Action != api.EventLifecycleInstanceDeleted {
+			return
+		}
+
+		err = pruneExecSessions(d.shutdownCtx, d.State())
+		if err != nil {
+			logger.Error("Failed pruning exec sessions", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// execScrollback is a fixed size ring buffer holding the most recent output of an exec session.
+type execScrollback struct {
+	buf  []byte
+	pos  int
+	full bool
+}
+
+// Write appends to the scrollback, discarding the oldest data once the buffer is full.
+func (b *execScrollback) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= len(b.buf) {
+		copy(b.buf, p[n-len(b.buf):])
+		b.pos = 0
+		b.full = true
+		return n, nil
+	}
+
+	copied := copy(b.buf[b.pos:], p)
+	if copied < n {
+		copy(b.buf, p[copied:])
+		b.full = true
+	}
+
+	b.pos += n
+	if b.pos >= len(b.buf) {
+		b.pos -= len(b.buf)
+		b.full = true
+	}
+
+	return n, nil
+}
+
+// Bytes returns a copy of the content of the scrollback, oldest data first.
+func (b *execScrollback) Bytes() []byte {
+	if !b.full {
+		return append([]byte(nil), b.buf[:b.pos]...)
+	}
+
+	return append(append([]byte(nil), b.buf[b.pos:]...), b.buf[:b.pos]...)
+}
+
+// execSession is a command running in an instance whose lifetime isn't tied to the client connection.
+// The output of the command is kept in a scrollback buffer, and clients can attach and detach at any time.
+type execSession struct {
+	id        string
+	instID    int
+	command   []string
+	createdAt time.Time
+
+	cmd      instance.Cmd
+	input    io.Writer
+	resizeFd int
+
+	mu         sync.Mutex
+	scrollback execScrollback
+	attached   *execSessionClient
+	exited     bool
+	exitCode   int
+	exitedAt   time.Time
+	chExited   chan struct{}
+}
+
+// execSessionClient is a websocket attached to an exec session. The output of the command is queued for the
+// client and written to the websocket by a separate goroutine, so that a slow client doesn't hold the session lock.
+type execSessionClient struct {
+	conn       *websocket.Conn
+	chOutput   chan []byte
+	chDetached chan struct{}
+	chDone     chan struct{}
+}
+
+// expired returns whether the session exited longer than execSessionExitedRetention ago.
+func (s *execSession) expired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exited && time.Since(s.exitedAt) > execSessionExitedRetention
+}
+
+// running returns whether the command of the session is still running.
+func (s *execSession) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.exited
+}
+
+// render returns the API representation of the session.
+func (s *execSession) render() api.InstanceExecSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := api.InstanceExecSession{
+		ID:        s.id,
+		Command:   s.command,
+		Status:    api.InstanceExecSessionStatusRunning,
+		Attached:  s.attached != nil,
+		CreatedAt: s.createdAt,
+	}
+
+	if s.exited {
+		session.Status = api.InstanceExecSessionStatusExited
+		session.ExitCode = s.exitCode
+		session.ExitedAt = s.exitedAt
+	}
+
+	return session
+}
+
+// attach queues the scrollback for the websocket and then makes it receive the output of the command, replacing
+// any previously attached websocket.
+func (s *execSession) attach(conn *websocket.Conn) *execSessionClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.detachLocked()
+
+	client := &execSessionClient{
+		conn:       conn,
+		chOutput:   make(chan []byte, execSessionClientQueueSize),
+		chDetached: make(chan struct{}),
+		chDone:     make(chan struct{}),
+	}
+
+	client.chOutput <- s.scrollback.Bytes()
+	s.attached = client
+
+	go s.writeOutput(client)
+
+	return client
+}
+
+// writeOutput writes the output queued for the client to its websocket until the client is detached and the
+// queued output was written.
+func (s *execSession) writeOutput(client *execSessionClient) {
+	defer close(client.chDone)
+
+	failed := false
+	for data := range client.chOutput {
+		if failed {
+			continue
+		}
+
+		// Don't let an unresponsive client hold its writer forever.
+		_ = client.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		err := client.conn.WriteMessage(websocket.BinaryMessage, data)
+		if err != nil {
+			failed = true
+			s.detach(client)
+		}
+	}
+}
+
+// detach detaches the client if it is still attached.
+func (s *execSession) detach(client *execSessionClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attached == client {
+		s.detachLocked()
+	}
+}
+
+func (s *execSession) detachLocked() {
+	if s.attached == nil {
+		return
+	}
+
+	close(s.attached.chOutput)
+	close(s.attached.chDetached)
+	s.attached = nil
+}
+
+// pumpOutput copies the output of the command to the scrollback and queues it for the attached client until the
+// output is closed. Clients that don't keep up with the output are detached.
+func (s *execSession) pumpOutput(output io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := output.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			_, _ = s.scrollback.Write(buf[:n])
+
+			if s.attached != nil {
+				select {
+				case s.attached.chOutput <- append([]byte(nil), buf[:n]...):
+				default:
+					s.detachLocked()
+				}
+			}
+
+			s.mu.Unlock()
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// startExecSession starts the command in the instance in a new exec session.
+func startExecSession(s *state.State, inst instance.Instance, req api.InstanceExecSessionsPost) (*execSession, error) {
+	execReq := api.InstanceExecPost{
+		Command:     req.Command,
+		Interactive: true,
+		Environment: instanceExecEnvironment(inst, req.Environment, req.User),
+		Width:       req.Width,
+		Height:      req.Height,
+		User:        req.User,
+		Group:       req.Group,
+		Cwd:         req.Cwd,
+	}
+
+	release, err := execSessions.reserve(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	session := &execSession{
+		id:         uuid.New().String(),
+		instID:     inst.ID(),
+		command:    req.Command,
+		createdAt:  time.Now().UTC(),
+		scrollback: execScrollback{buf: make([]byte, execSessionScrollbackSize)},
+		chExited:   make(chan struct{}),
+	}
+
+	// Files handed to the command, which are closed once it exits.
+	var cmdFiles []*os.File
+
+	// Files used by LXD, which are closed once all the output was read.
+	var sessionFiles []*os.File
+
+	var stdin, stdout, stderr *os.File
+	var output io.Reader
+
+	if inst.Type() == instancetype.Container {
+		// For containers, we setup a PTY on the LXD server.
+		pty, tty, err := execContainerPty(s, inst)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() {
+			_ = pty.Close()
+			_ = tty.Close()
+		})
+
+		if req.Width > 0 && req.Height > 0 {
+			_ = shared.SetSize(int(pty.Fd()), req.Width, req.Height)
+		}
+
+		stdin = tty
+		stdout = tty
+		stderr = tty
+		cmdFiles = append(cmdFiles, tty)
+
+		session.input = pty
+		session.resizeFd = int(pty.Fd())
+		sessionFiles = append(sessionFiles, pty)
+		output = shared.NewExecWrapper(nil, pty)
+	} else {
+		// For VMs we rely on the lxd-agent PTY running inside the VM guest.
+		stdinRead, stdinWrite, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() {
+			_ = stdinRead.Close()
+			_ = stdinWrite.Close()
+		})
+
+		stdoutRead, stdoutWrite, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() {
+			_ = stdoutRead.Close()
+			_ = stdoutWrite.Close()
+		})
+
+		stdin = stdinRead
+		stdout = stdoutWrite
+		cmdFiles = append(cmdFiles, stdinRead, stdoutWrite)
+
+		session.input = stdinWrite
+		session.resizeFd = int(stdinRead.Fd())
+		sessionFiles = append(sessionFiles, stdinWrite, stdoutRead)
+		output = stdoutRead
+	}
+
+	cmd, err := inst.Exec(execReq, stdin, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	session.cmd = cmd
+	revert.Success()
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "PID": cmd.PID(), "session": session.id})
+	l.Debug("Exec session started")
+
+	chOutputDone := make(chan struct{})
+	go func() {
+		session.pumpOutput(output)
+		close(chOutputDone)
+	}()
+
+	go func() {
+		exitCode, err := cmd.Wait()
+		l.Debug("Exec session command exited", logger.Ctx{"err": err, "exitCode": exitCode})
+
+		// Closing the files of the command lets the output be drained before marking the session as exited.
+		for _, file := range cmdFiles {
+			_ = file.Close()
+		}
+
+		<-chOutputDone
+
+		for _, file := range sessionFiles {
+			_ = file.Close()
+		}
+
+		session.mu.Lock()
+		session.exited = true
+		session.exitCode = exitCode
+		session.exitedAt = time.Now().UTC()
+		session.detachLocked()
+		session.mu.Unlock()
+
+		close(session.chExited)
+	}()
+
+	execSessions.add(session)
+
+	return session, nil
+}
+
+// execSessionAttachWs handles the websockets of a client attached to an exec session.
+type execSessionAttachWs struct {
+	req     api.InstanceExecSessionAttachPost
+	session *execSession
+
+	conns         map[int]*websocket.Conn
+	connsLock     sync.Mutex
+	waitConnected *cancel.Canceller
+	fds           map[int]string
+}
+
+// Metadata returns a map of metadata.
+func (s *execSessionAttachWs) Metadata() any {
+	fds := shared.Jmap{}
+	for fd, secret := range s.fds {
+		if fd == execWSControl {
+			fds[api.SecretNameControl] = secret
+		} else {
+			fds[strconv.Itoa(fd)] = secret
+		}
+	}
+
+	return shared.Jmap{
+		"fds":     fds,
+		"session": s.session.id,
+	}
+}
+
+// Connect connects to the websocket.
+func (s *execSessionAttachWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
+	secret := r.FormValue("secret")
+	if secret == "" {
+		return fmt.Errorf("missing secret")
+	}
+
+	for fd, fdSecret := range s.fds {
+		if secret != fdSecret {
+			continue
+		}
+
+		s.connsLock.Lock()
+		defer s.connsLock.Unlock()
+
+		if s.conns[fd] != nil {
+			return fmt.Errorf("Websocket number already connected")
+		}
+
+		conn, err := ws.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return err
+		}
+
+		s.conns[fd] = conn
+
+		if fd == execWSStdin {
+			s.waitConnected.Cancel() // Data connection connected.
+		}
+
+		return nil
+	}
+
+	// If we didn't find the right secret, the user provided a bad one, which 403, not 404, since this operation
+	// actually exists.
+	return os.ErrPermission
+}
+
+// Do attaches the websockets to the exec session until the client disconnects or the command exits.
+func (s *execSessionAttachWs) Do(op *operations.Operation) error {
+	// Once this function ends ensure that any connected websockets are closed.
+	defer func() {
+		s.connsLock.Lock()
+		for i := range s.conns {
+			if s.conns[i] != nil {
+				_ = s.conns[i].Close()
+			}
+		}
+
+		s.connsLock.Unlock()
+	}()
+
+	select {
+	case <-s.waitConnected.Done():
+	case <-time.After(time.Second * 5):
+		return fmt.Errorf("Timed out waiting for websockets to connect")
+	}
+
+	s.connsLock.Lock()
+	conn := s.conns[execWSStdin]
+	s.connsLock.Unlock()
+
+	l := logger.AddContext(logger.Ctx{"session": s.session.id, "remote": conn.RemoteAddr()})
+
+	if s.req.Width > 0 && s.req.Height > 0 {
+		err := s.session.cmd.WindowResize(s.session.resizeFd, s.req.Width, s.req.Height)
+		if err != nil {
+			l.Debug("Failed to set window size", logger.Ctx{"err": err})
+		}
+	}
+
+	client := s.session.attach(conn)
+	defer s.session.detach(client)
+
+	l.Debug("Attached to exec session")
+	defer l.Debug("Detached from exec session")
+
+	// Forward the input of the client to the command until the client disconnects.
+	chClientGone := make(chan struct{})
+	go func() {
+		defer close(chClientGone)
+
+		for {
+			mt, r, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+
+			// The client closing its input doesn't end the session, so that it can be attached again.
+			if mt != websocket.BinaryMessage {
+				continue
+			}
+
+			_, err = io.Copy(s.session.input, r)
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Handle window resizes and signals sent over the control websocket.
+	go func() {
+		s.connsLock.Lock()
+		control := s.conns[execWSControl]
+		s.connsLock.Unlock()
+
+		if control == nil {
+			return
+		}
+
+		for {
+			_, buf, err := control.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			command := api.InstanceExecControl{}
+			err = json.Unmarshal(buf, &command)
+			if err != nil {
+				l.Debug("Failed to unmarshal control socket command", logger.Ctx{"err": err})
+				continue
+			}
+
+			if command.Command == "window-resize" {
+				width, errWidth := strconv.Atoi(command.Args["width"])
+				height, errHeight := strconv.Atoi(command.Args["height"])
+				if errWidth != nil || errHeight != nil {
+					continue
+				}
+
+				err = s.session.cmd.WindowResize(s.session.resizeFd, width, height)
+				if err != nil {
+					l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": width, "height": height})
+				}
+			} else if command.Command == "signal" {
+				err = s.session.cmd.Signal(unix.Signal(command.Signal))
+				if err != nil {
+					l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
+				}
+			}
+		}
+	}()
+
+	select {
+	case <-client.chDetached:
+	case <-chClientGone:
+		return nil
+	}
+
+	// The client is detached when the command exits, once all the output was written.
+	<-client.chDone
+
+	s.session.mu.Lock()
+	exited := s.session.exited
+	exitCode := s.session.exitCode
+	s.session.mu.Unlock()
+
+	if !exited {
+		return nil
+	}
+
+	// Let the client know that the output has ended.
+	_ = conn.WriteMessage(websocket.TextMessage, []byte{})
+
+	return op.ExtendMetadata(shared.Jmap{"return": exitCode})
+}
+
+// execSessionInstanceFromRequest loads the instance of the request, or returns the response forwarding the request
+// to the member running the instance.
+func execSessionInstanceFromRequest(d *Daemon, r *http.Request) (instance.Instance, response.Response) {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return nil, response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Exec sessions live on the member running the instance.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if resp != nil {
+		return nil, resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	return inst, nil
+}
+
+// execSessionURL returns the URL of an exec session.
+func execSessionURL(inst instance.Instance, id string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "exec-sessions", id).Project(inst.Project().Name)
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions instances instance_exec_sessions_get
+//
+//	Get the exec sessions
+//
+//	Returns a list of exec sessions of the instance (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/exec-sessions/6916c8a6-9b7d-4abd-90b3-aedfec7ec7da"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions?recursion=1 instances instance_exec_sessions_get_recursion1
+//
+//	Get the exec sessions
+//
+//	Returns a list of exec sessions of the instance (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of exec sessions
+//	          items:
+//	            $ref: "#/definitions/InstanceExecSession"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionsGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := execSessionInstanceFromRequest(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	sessions := execSessions.list(inst)
+
+	if !util.IsRecursionRequest(r) {
+		urls := make([]string, 0, len(sessions))
+		for _, session := range sessions {
+			urls = append(urls, execSessionURL(inst, session.id).String())
+		}
+
+		return response.SyncResponse(true, urls)
+	}
+
+	result := make([]api.InstanceExecSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, session.render())
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation POST /1.0/instances/{name}/exec-sessions instances instance_exec_sessions_post
+//
+//	Start an exec session
+//
+//	Starts a command inside the instance in a persistent session. The command runs with a terminal and keeps
+//	running when no client is attached to the session. The most recent output of the command is kept so that it
+//	can be replayed to clients attaching to the session.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: session
+//	    description: Exec session request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceExecSessionsPost"
+//	responses:
+//	  "200":
+//	    description: Exec session
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceExecSession"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionsPost(d *Daemon, r *http.Request) response.Response {
+	inst, resp := execSessionInstanceFromRequest(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	req := api.InstanceExecSessionsPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(req.Command) == 0 {
+		return response.BadRequest(fmt.Errorf("A command is required"))
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	if inst.IsFrozen() {
+		return response.BadRequest(fmt.Errorf("Instance is frozen"))
+	}
+
+	session, err := startExecSession(d.State(), inst, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseLocation(true, session.render(), execSessionURL(inst, session.id).String())
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions/{id} instances instance_exec_session_get
+//
+//	Get the exec session
+//
+//	Gets a specific exec session of the instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Exec session
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceExecSession"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := execSessionInstanceFromRequest(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessions.get(inst, mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, session.render())
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/exec-sessions/{id} instances instance_exec_session_delete
+//
+//	Terminate the exec session
+//
+//	Kills the command of the exec session if still running and removes the session.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionDelete(d *Daemon, r *http.Request) response.Response {
+	inst, resp := execSessionInstanceFromRequest(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessions.get(inst, mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	select {
+	case <-session.chExited:
+	default:
+		err = session.cmd.Signal(unix.SIGKILL)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed killing the command of the exec session: %w", err))
+		}
+	}
+
+	execSessions.remove(session)
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/instances/{name}/exec-sessions/{id}/attach instances instance_exec_session_attach_post
+//
+//	Attach to the exec session
+//
+//	Attaches to the exec session of the instance.
+//
+//	The returned operation metadata contains two websockets. The "0" websocket is bi-directional and carries the
+//	terminal input and output. It first receives the scrollback of the session. The "control" websocket can be
+//	used to send signals and window sizing information.
+//
+//	Closing the websockets detaches from the session without ending the command. The operation finishes with the
+//	exit code of the command in its "return" metadata if the command exits while attached.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: attach
+//	    description: Attach request
+//	    schema:
+//	      $ref: "#/definitions/InstanceExecSessionAttachPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceExecSessionAttachPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	inst, resp := execSessionInstanceFromRequest(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessions.get(inst, mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.InstanceExecSessionAttachPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		return response.BadRequest(err)
+	}
+
+	attachWs := &execSessionAttachWs{
+		req:           req,
+		session:       session,
+		conns:         map[int]*websocket.Conn{execWSControl: nil, execWSStdin: nil},
+		waitConnected: cancel.New(r.Context()),
+		fds:           map[int]string{},
+	}
+
+	for i := range attachWs.conns {
+		attachWs.fds[i], err = shared.RandomCryptoString()
+		if err != nil {
+			return response.InternalError(err)
+		}
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", inst.Name())}
+
+	if inst.Type() == instancetype.Container {
+		resources["containers"] = resources["instances"]
+	}
+
+	op, err := operations.OperationCreate(s, inst.Project().Name, operations.OperationClassWebsocket, operationtype.CommandExecAttach, resources, attachWs.Metadata(), attachWs.Do, nil, attachWs.Connect, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
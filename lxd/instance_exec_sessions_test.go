@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExecScrollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name:   "partial",
+			writes: []string{"ab", "c"},
+			want:   "abc",
+		},
+		{
+			name:   "exactly full",
+			writes: []string{"abc", "de"},
+			want:   "abcde",
+		},
+		{
+			name:   "wrapped",
+			writes: []string{"abcd", "efg"},
+			want:   "cdefg",
+		},
+		{
+			name:   "wrapped twice",
+			writes: []string{"abc", "def", "ghi", "j"},
+			want:   "fghij",
+		},
+		{
+			name:   "larger than buffer",
+			writes: []string{"ab", "cdefghi"},
+			want:   "efghi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := execScrollback{buf: make([]byte, 5)}
+			for _, write := range tt.writes {
+				n, err := b.Write([]byte(write))
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if n != len(write) {
+					t.Fatalf("Expected %d bytes written, got %d", len(write), n)
+				}
+			}
+
+			got := b.Bytes()
+			if !bytes.Equal(got, []byte(tt.want)) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
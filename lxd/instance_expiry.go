@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceExpiryWarningPeriod is how long before an instance expires a warning is raised for it.
+// Expired instances are only deleted once their warning was raised for at least that long.
+const instanceExpiryWarningPeriod = 24 * time.Hour
+
+func pruneExpiredInstancesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		now := time.Now()
+
+		var expiredInstances []instance.Instance
+		warningMessages := map[int]string{}
+		warningProjects := map[int]string{}
+
+		// Get the instances on the local member that expired or are about to expire.
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+				inst, err := instance.Load(s, dbInst, p)
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q (project %q) for expiry task: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				expiry, err := instancetype.Expiry(inst.ExpandedConfig(), project.InstanceMaxTTL(&p), inst.CreationDate(), inst.LastUsedDate())
+				if err != nil {
+					logger.Warn("Failed getting instance expiry", logger.Ctx{"project": dbInst.Project, "instance": dbInst.Name, "err": err})
+					return nil
+				}
+
+				if expiry.IsZero() || now.Add(instanceExpiryWarningPeriod).Before(expiry) {
+					return nil
+				}
+
+				warningProjects[inst.ID()] = p.Name
+
+				if now.Before(expiry) {
+					warningMessages[inst.ID()] = fmt.Sprintf("The instance expires at %s, it will then be stopped and deleted once this warning was raised for %d hours", expiry.UTC().Format(time.RFC3339), int(instanceExpiryWarningPeriod.Hours()))
+					return nil
+				}
+
+				// Leave instances protected from deletion alone.
+				if shared.IsTrue(inst.ExpandedConfig()["security.protection.delete"]) {
+					warningMessages[inst.ID()] = fmt.Sprintf("The instance expired at %s but is protected from deletion", expiry.UTC().Format(time.RFC3339))
+					return nil
+				}
+
+				warningMessages[inst.ID()] = fmt.Sprintf("The instance expired at %s, it will be stopped and deleted once this warning was raised for %d hours", expiry.UTC().Format(time.RFC3339), int(instanceExpiryWarningPeriod.Hours()))
+				expiredInstances = append(expiredInstances, inst)
+
+				return nil
+			}, filter)
+		})
+		if err != nil {
+			logger.Error("Failed getting instance expiry info", logger.Ctx{"err": err})
+			return
+		}
+
+		warnedSince, err := instancesExpiryWarnings(ctx, s, warningMessages, warningProjects)
+		if err != nil {
+			logger.Warn("Failed updating instance expiry warnings", logger.Ctx{"err": err})
+			return
+		}
+
+		// Only delete the expired instances whose warning was raised for long enough, this includes instances
+		// that were already past their expiry when first checked.
+		pruneInstances := make([]instance.Instance, 0, len(expiredInstances))
+		for _, inst := range expiredInstances {
+			since, found := warnedSince[inst.ID()]
+			if !found || now.Sub(since) < instanceExpiryWarningPeriod {
+				continue
+			}
+
+			logger.Debug("Scheduling instance expiry", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "warnedSince": since})
+			pruneInstances = append(pruneInstances, inst)
+		}
+
+		if len(pruneInstances) == 0 {
+			return
+		}
+
+		expiredInstances = pruneInstances
+
+		opRun := func(op *operations.Operation) error {
+			return pruneExpiredInstances(expiredInstances)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.InstancesExpire, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating expired instances operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Pruning expired instances")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting expired instances operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed pruning expired instances", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done pruning expired instances")
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// instancesExpiryWarnings raises a warning with the given message for each of the given instances, unless the same
+// warning is already raised, and resolves the warnings of the other instances.
+// Returns the time since which each of the given instances has had a warning.
+func instancesExpiryWarnings(ctx context.Context, s *state.State, messages map[int]string, projects map[int]string) (map[int]time.Time, error) {
+	typeCode := warningtype.InstanceExpiring
+	filter := dbCluster.WarningFilter{Node: &s.ServerName, TypeCode: &typeCode}
+
+	// Resolved warnings keep their first seen date when raised again, so remove those of the instances to warn
+	// about to count the time since which they are warned from now on.
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		warnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		for _, warning := range warnings {
+			_, warn := messages[warning.EntityID]
+			if !warn || warning.EntityType != dbCluster.EntityType(entity.TypeInstance) || warning.Status != warningtype.StatusResolved {
+				continue
+			}
+
+			err = dbCluster.DeleteWarning(ctx, tx.Tx(), warning.UUID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make(map[localWarningEntity]localWarning, len(messages))
+	for instID, message := range messages {
+		warnings[localWarningEntity{entityType: entity.TypeInstance, entityID: instID}] = localWarning{project: projects[instID], message: message}
+	}
+
+	err = syncLocalWarnings(ctx, s, typeCode, warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	warnedSince := make(map[int]time.Time, len(messages))
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		warnings, err := dbCluster.GetWarnings(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		for _, warning := range warnings {
+			if warning.EntityType == dbCluster.EntityType(entity.TypeInstance) && warning.Status != warningtype.StatusResolved {
+				warnedSince[warning.EntityID] = warning.FirstSeenDate
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return warnedSince, nil
+}
+
+// pruneExpiredInstances stops and deletes the given expired instances.
+func pruneExpiredInstances(instances []instance.Instance) error {
+	failures := 0
+
+	for _, inst := range instances {
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		if inst.IsRunning() {
+			timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+			if err != nil {
+				timeout = evacuateHostShutdownDefaultTimeout
+			}
+
+			err = inst.Shutdown(time.Duration(timeout) * time.Second)
+			if err != nil {
+				l.Warn("Failed shutting down expired instance, forcefully stopping", logger.Ctx{"err": err})
+
+				err = inst.Stop(false)
+				if err != nil && !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+					l.Error("Failed stopping expired instance", logger.Ctx{"err": err})
+					failures++
+					continue
+				}
+			}
+		}
+
+		err := inst.Delete(false)
+		if err != nil {
+			l.Error("Failed deleting expired instance", logger.Ctx{"err": err})
+			failures++
+			continue
+		}
+
+		l.Info("Deleted expired instance")
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("Failed pruning %d of %d expired instances", failures, len(instances))
+	}
+
+	return nil
+}
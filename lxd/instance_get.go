@@ -13,6 +13,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // swagger:operation GET /1.0/instances/{name} instances instance_get
@@ -145,7 +146,14 @@ func instanceGet(d *Daemon, r *http.Request) response.Response {
 		state, etag, err = c.Render()
 	} else {
 		hostInterfaces, _ := net.Interfaces()
-		state, etag, err = c.RenderFull(hostInterfaces)
+
+		var full *api.InstanceFull
+		full, etag, err = c.RenderFull(hostInterfaces)
+		if err == nil {
+			instanceHealthRender(c, full.State)
+		}
+
+		state = full
 	}
 
 	if err != nil {
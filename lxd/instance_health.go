@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/healthcheck"
+	"github.com/canonical/lxd/lxd/instance"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// instanceHealthCheckTaskInterval is the granularity at which the health checks of the instances are scheduled.
+const instanceHealthCheckTaskInterval = 10 * time.Second
+
+// instanceHealth tracks the health of the instances running on this member.
+var instanceHealth = healthcheck.NewTracker()
+
+// instanceHealthRender adds the health of the instance to its rendered state.
+func instanceHealthRender(inst instance.Instance, instState *api.InstanceState) {
+	if instState == nil || !inst.IsRunning() || healthcheck.ParseConfig(inst.ExpandedConfig()) == nil {
+		return
+	}
+
+	instState.Health = instanceHealth.Get(inst.ID())
+}
+
+func instanceHealthChecksTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances for health checks", logger.Ctx{"err": err})
+			return
+		}
+
+		now := time.Now().UTC()
+		tracked := map[int]bool{}
+
+		for _, inst := range instances {
+			config := healthcheck.ParseConfig(inst.ExpandedConfig())
+			if config == nil || !inst.IsRunning() {
+				continue
+			}
+
+			tracked[inst.ID()] = true
+
+			pid := inst.InitPID()
+			if !instanceHealth.Due(inst.ID(), pid, config.Interval, now) {
+				continue
+			}
+
+			go instanceHealthCheck(d, inst, pid, config)
+		}
+
+		// Stop tracking the instances that were stopped, deleted or had their health check removed.
+		instanceHealth.Forget(tracked)
+	}
+
+	return f, task.Every(instanceHealthCheckTaskInterval)
+}
+
+// instanceHealthCheck runs the health check of the instance and records its result.
+func instanceHealthCheck(d *Daemon, inst instance.Instance, pid int, config *healthcheck.Config) {
+	s := d.State()
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "check": config.Check})
+
+	ctx, cancel := context.WithTimeout(s.ShutdownCtx, config.Timeout)
+	defer cancel()
+
+	checkErr := instanceHealthRunCheck(ctx, s, inst, config)
+	if checkErr != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		checkErr = fmt.Errorf("Health check timed out after %s", config.Timeout)
+	}
+
+	previous, status := instanceHealth.Record(inst.ID(), pid, checkErr, config.Retries, time.Now().UTC())
+	if checkErr != nil {
+		l.Debug("Health check failed", logger.Ctx{"err": checkErr})
+	}
+
+	if previous == status {
+		return
+	}
+
+	l.Info("Instance health changed", logger.Ctx{"previous": previous, "status": status})
+
+	ctxMap := map[string]any{
+		"status":   status,
+		"previous": previous,
+	}
+
+	if checkErr != nil {
+		ctxMap["error"] = checkErr.Error()
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceHealthChanged.Event(inst, ctxMap))
+
+	if status != api.InstanceHealthStatusUnhealthy || config.Action == "none" {
+		return
+	}
+
+	err := instanceHealthRemediate(d, inst, config.Action)
+	if err != nil {
+		l.Error("Failed remediating unhealthy instance", logger.Ctx{"action": config.Action, "err": err})
+	}
+}
+
+// instanceHealthRunCheck runs the health check of the instance once.
+func instanceHealthRunCheck(ctx context.Context, s *state.State, inst instance.Instance, config *healthcheck.Config) error {
+	if config.Check == "exec" {
+		return instanceHealthRunExec(ctx, inst, config.Command)
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	instState, err := inst.RenderState(hostInterfaces)
+	if err != nil {
+		return err
+	}
+
+	address, err := healthcheck.Address(instState.Network)
+	if err != nil {
+		return err
+	}
+
+	if config.Check == "http" {
+		return healthcheck.CheckHTTP(ctx, address, config.Port, config.Path)
+	}
+
+	return healthcheck.CheckTCP(ctx, address, config.Port)
+}
+
+// instanceHealthRunExec runs the health check command inside the instance, killing it if the context ends first.
+func instanceHealthRunExec(ctx context.Context, inst instance.Instance, command string) error {
+	args, err := shellquote.Split(command)
+	if err != nil {
+		return err
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	req := api.InstanceExecPost{
+		Command:     args,
+		Environment: instanceExecEnvironment(inst, nil, 0),
+	}
+
+	cmd, err := inst.Exec(req, devNull, devNull, devNull)
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		exitCode int
+		err      error
+	}
+
+	chResult := make(chan result, 1)
+	go func() {
+		exitCode, err := cmd.Wait()
+		chResult <- result{exitCode: exitCode, err: err}
+	}()
+
+	var res result
+	select {
+	case res = <-chResult:
+	case <-ctx.Done():
+		_ = cmd.Signal(unix.SIGKILL)
+		<-chResult
+		return ctx.Err()
+	}
+
+	if res.err != nil {
+		return res.err
+	}
+
+	if res.exitCode != 0 {
+		return fmt.Errorf("Command exited with status %d", res.exitCode)
+	}
+
+	return nil
+}
+
+// instanceHealthRemediate runs the health action of the unhealthy instance in a background operation.
+func instanceHealthRemediate(d *Daemon, inst instance.Instance, action string) error {
+	s := d.State()
+
+	run := func(op *operations.Operation) error {
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "action": action})
+		l.Warn("Remediating unhealthy instance")
+
+		switch action {
+		case "restart":
+			return instanceHealthRestart(inst)
+		case "evacuate":
+			return instanceHealthEvacuate(d, inst, op)
+		}
+
+		return fmt.Errorf("Unknown health action %q", action)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", inst.Name())}
+
+	op, err := operations.OperationCreate(s, inst.Project().Name, operations.OperationClassTask, operationtype.InstanceHealthRemediate, resources, nil, run, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return op.Start()
+}
+
+// instanceHealthRestart restarts the instance, forcing it to stop if it doesn't shut down in time.
+func instanceHealthRestart(inst instance.Instance) error {
+	timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+	if err != nil {
+		timeout = evacuateHostShutdownDefaultTimeout
+	}
+
+	err = inst.Restart(time.Duration(timeout) * time.Second)
+	if err == nil {
+		return nil
+	}
+
+	logger.Warn("Failed restarting unhealthy instance, forcing stop", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+
+	err = inst.Stop(false)
+	if err != nil && !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+		return err
+	}
+
+	return inst.Start(false)
+}
+
+// instanceHealthEvacuate moves the instance to another cluster member and starts it there.
+func instanceHealthEvacuate(d *Daemon, inst instance.Instance, op *operations.Operation) error {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return fmt.Errorf("Unhealthy instances can only be evacuated in a cluster")
+	}
+
+	candidateMembers, err := evacuateClusterCandidateMembers(context.TODO(), s, inst)
+	if err != nil {
+		return err
+	}
+
+	// Exclude the member currently running the instance.
+	otherMembers := make([]db.NodeInfo, 0, len(candidateMembers))
+	for _, member := range candidateMembers {
+		if member.Name != inst.Location() {
+			otherMembers = append(otherMembers, member)
+		}
+	}
+
+	targetMemberInfo, err := evacuateClusterSelectTarget(context.TODO(), s, d.gateway, inst, otherMembers)
+	if err != nil {
+		return err
+	}
+
+	err = evacuateClusterStopInstance(inst)
+	if err != nil {
+		return err
+	}
+
+	return evacuateClusterMigrateInstance(s, nil, inst, targetMemberInfo, false, true, nil, op)
+}
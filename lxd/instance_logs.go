@@ -1,23 +1,27 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instancelog"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
@@ -46,6 +50,17 @@ var instanceLogsCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: instanceLogsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
 }
 
+var instanceLogStreamCmd = APIEndpoint{
+	Name: "instanceLogStream",
+	Path: "instances/{name}/logs/stream",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerLogStream", Path: "containers/{name}/logs/stream"},
+		{Name: "vmLogStream", Path: "virtual-machines/{name}/logs/stream"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceLogStreamGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
 var instanceExecOutputCmd = APIEndpoint{
 	Name: "instanceExecOutput",
 	Path: "instances/{name}/logs/exec-output/{file}",
@@ -253,6 +268,23 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Memory dumps contain the memory of the guest, so they require the same permission as running commands in it.
+	if validDumpFileName(file) {
+		err = s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		ent := response.FileResponseEntry{
+			Path:     filepath.Join(inst.DumpPath(), file),
+			Filename: file,
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.InstanceLogRetrieved.Event(file, inst, request.CreateRequestor(r), nil))
+
+		return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+	}
+
 	if !validLogFileName(file) {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
@@ -337,6 +369,18 @@ func instanceLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Memory dumps can be deleted to free up space.
+	if validDumpFileName(file) {
+		err = os.Remove(filepath.Join(inst.DumpPath(), file))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.InstanceLogDeleted.Event(file, inst, request.CreateRequestor(r), nil))
+
+		return response.EmptySyncResponse
+	}
+
 	if !validLogFileName(file) {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
@@ -681,6 +725,159 @@ func instanceExecOutputDelete(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// swagger:operation GET /1.0/instances/{name}/logs/stream instances instance_logs_stream_get
+//
+//	Get the log stream
+//
+//	Streams the log entries of the instance as a sequence of JSON objects separated by new lines.
+//	The entries are read from the console of containers (`console`), from the log files kept by LXD for
+//	the instance (`lxd`) and from the journal or syslog of the guest (`guest`). The existing entries are
+//	sent first, ordered by time.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: follow
+//	    description: Whether to keep sending the new entries
+//	    type: boolean
+//	  - in: query
+//	    name: since
+//	    description: Only send the entries from this time onwards (RFC 3339)
+//	    type: string
+//	    example: 2024-01-02T03:04:05Z
+//	  - in: query
+//	    name: source
+//	    description: Comma-separated list of log sources (console, lxd or guest), all the supported sources by default
+//	    type: string
+//	    example: lxd,guest
+//	responses:
+//	  "200":
+//	    description: Log entries
+//	    schema:
+//	      $ref: "#/definitions/InstanceLogEntry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceLogStreamGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	opts := instanceLogStreamOptions(inst)
+	opts.Follow = shared.IsTrue(request.QueryParam(r, "follow"))
+
+	since := request.QueryParam(r, "since")
+	if since != "" {
+		opts.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid time %q: %w", since, err))
+		}
+	}
+
+	supported := instancelog.SupportedSources(inst)
+	sources := request.QueryParam(r, "source")
+	if sources != "" {
+		opts.Sources = shared.SplitNTrimSpace(sources, ",", -1, true)
+		for _, source := range opts.Sources {
+			if !shared.ValueInSlice(source, supported) {
+				return response.BadRequest(fmt.Errorf("Log source %q isn't supported by the instance", source))
+			}
+		}
+	} else {
+		opts.Sources = supported
+	}
+
+	// Reading the guest logs runs commands in the instance. The guest logs are left out if they weren't
+	// explicitly requested by a client which isn't allowed to do that.
+	if shared.ValueInSlice(api.InstanceLogSourceGuest, opts.Sources) {
+		err := s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec)
+		if err != nil {
+			if sources != "" {
+				return response.SmartError(err)
+			}
+
+			opts.Sources = shared.RemoveElementsFromSlice(opts.Sources, api.InstanceLogSourceGuest)
+		}
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+
+		err := instancelog.Stream(r.Context(), inst, opts, func(entry api.InstanceLogEntry) error {
+			err := encoder.Encode(entry)
+			if err != nil {
+				return err
+			}
+
+			if opts.Follow && flusher != nil {
+				flusher.Flush()
+			}
+
+			return nil
+		})
+		if err != nil && r.Context().Err() == nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// instanceLogStreamOptions returns the options of the log stream of the instance which don't depend on the request.
+func instanceLogStreamOptions(inst instance.Instance) instancelog.Options {
+	return instancelog.Options{
+		// The console log file is read by the console source and the configuration files aren't logs.
+		LogFile: func(name string) bool {
+			return validLogFileName(name) && !strings.HasSuffix(name, ".conf")
+		},
+		Environment: instanceExecEnvironment(inst, nil, 0),
+	}
+}
+
 func validLogFileName(fname string) bool {
 	/* Let's just require that the paths be relative, so that we don't have
 	 * to deal with any escaping or whatever.
@@ -690,7 +887,13 @@ func validLogFileName(fname string) bool {
 		fname == "qemu.log" ||
 		fname == "qemu.conf" ||
 		strings.HasPrefix(fname, "migration_") ||
-		strings.HasPrefix(fname, "snapshot_")
+		strings.HasPrefix(fname, "snapshot_") ||
+		strings.HasPrefix(fname, "serial_")
+}
+
+// validDumpFileName checks that the file name is the one of a watchdog memory dump.
+func validDumpFileName(fName string) bool {
+	return strings.HasPrefix(fName, "watchdog_") && strings.HasSuffix(fName, ".dump") && !strings.Contains(fName, "/")
 }
 
 func validExecOutputFileName(fName string) bool {
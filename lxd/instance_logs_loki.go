@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instancelog"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceLogForwarderKey identifies a log source of an instance forwarded to Loki.
+type instanceLogForwarderKey struct {
+	instanceID int
+	source     string
+}
+
+// instanceLogForwarder forwards the entries of a log source of an instance to Loki.
+type instanceLogForwarder struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Time at which the forwarder stopped, used to resume the source without losing entries.
+	stoppedAt time.Time
+}
+
+// instanceLogForwarders are the forwarders of the instances running on this member.
+var instanceLogForwarders = map[instanceLogForwarderKey]*instanceLogForwarder{}
+var instanceLogForwardersMu sync.Mutex
+
+// instanceLogForwardingTask starts and stops the forwarding of the instance logs to Loki according to the
+// `logs.loki` setting of the running instances. Each source is forwarded separately so that a source which
+// stopped, like the guest logs of a virtual machine whose agent wasn't ready yet, is resumed on the next run.
+func instanceLogForwardingTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		wanted := map[instanceLogForwarderKey]instance.Instance{}
+		if d.lokiClient.Load() != nil {
+			instances, err := instance.LoadNodeAll(s, instancetype.Any)
+			if err != nil {
+				logger.Warn("Failed loading instances for log forwarding", logger.Ctx{"err": err})
+				return
+			}
+
+			for _, inst := range instances {
+				sources := shared.SplitNTrimSpace(inst.ExpandedConfig()["logs.loki"], ",", -1, true)
+				if len(sources) == 0 || !inst.IsRunning() {
+					continue
+				}
+
+				supported := instancelog.SupportedSources(inst)
+				for _, source := range sources {
+					if shared.ValueInSlice(source, supported) {
+						wanted[instanceLogForwarderKey{instanceID: inst.ID(), source: source}] = inst
+					}
+				}
+			}
+		}
+
+		instanceLogForwardersMu.Lock()
+		defer instanceLogForwardersMu.Unlock()
+
+		for key, forwarder := range instanceLogForwarders {
+			_, ok := wanted[key]
+			if ok {
+				continue
+			}
+
+			forwarder.cancel()
+			delete(instanceLogForwarders, key)
+		}
+
+		for key, inst := range wanted {
+			since := time.Now()
+
+			forwarder, ok := instanceLogForwarders[key]
+			if ok {
+				select {
+				case <-forwarder.done:
+					since = forwarder.stoppedAt
+				default:
+					continue
+				}
+			}
+
+			instanceLogForwarders[key] = instanceLogForward(d, inst, key.source, since)
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// instanceLogForward starts forwarding the entries of the log source of the instance from the given time.
+func instanceLogForward(d *Daemon, inst instance.Instance, source string, since time.Time) *instanceLogForwarder {
+	ctx, cancel := context.WithCancel(d.shutdownCtx)
+	forwarder := &instanceLogForwarder{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	opts := instanceLogStreamOptions(inst)
+	opts.Sources = []string{source}
+	opts.Since = since
+	opts.Follow = true
+
+	location := d.State().ServerName
+
+	go func() {
+		defer close(forwarder.done)
+
+		err := instancelog.Stream(ctx, inst, opts, func(entry api.InstanceLogEntry) error {
+			lokiClient := d.lokiClient.Load()
+			if lokiClient != nil {
+				lokiClient.HandleInstanceLog(inst.Project().Name, inst.Name(), location, entry)
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Warn("Failed forwarding instance logs to Loki", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "source": source, "err": err})
+		}
+
+		forwarder.stoppedAt = time.Now()
+	}()
+
+	return forwarder
+}
+
+// instanceLogsLokiForwardsGuest returns whether the given instance configuration forwards the guest logs to Loki.
+func instanceLogsLokiForwardsGuest(config map[string]string) bool {
+	return shared.ValueInSlice(api.InstanceLogSourceGuest, shared.SplitNTrimSpace(config["logs.loki"], ",", -1, true))
+}
+
+// instanceLogsLokiCheckGuest checks that the requestor is allowed to read the guest logs of the instance when its new
+// expanded configuration starts forwarding them to Loki. Reading the guest logs runs commands in the instance, so
+// this requires the same permission as reading them through the API. The old configuration is nil for new instances.
+func instanceLogsLokiCheckGuest(s *state.State, r *http.Request, projectName string, instanceName string, oldConfig map[string]string, newConfig map[string]string) error {
+	if !instanceLogsLokiForwardsGuest(newConfig) || instanceLogsLokiForwardsGuest(oldConfig) {
+		return nil
+	}
+
+	// The instance doesn't exist yet when it is created.
+	if oldConfig == nil {
+		return s.Authorizer.CheckPermission(r.Context(), r, entity.ProjectURL(projectName), auth.EntitlementCanOperateInstances)
+	}
+
+	return s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, instanceName), auth.EntitlementCanExec)
+}
+
+// profileLogsLokiCheckGuest checks that the requestor is allowed to read the guest logs of all the instances using
+// the profile when its new configuration starts forwarding them to Loki.
+func profileLogsLokiCheckGuest(s *state.State, r *http.Request, projectName string, profileName string, oldConfig map[string]string, newConfig map[string]string) error {
+	if !instanceLogsLokiForwardsGuest(newConfig) || instanceLogsLokiForwardsGuest(oldConfig) {
+		return nil
+	}
+
+	insts, _, err := getProfileInstancesInfo(s.DB.Cluster, projectName, profileName)
+	if err != nil {
+		return fmt.Errorf("Failed to query instances associated with profile %q: %w", profileName, err)
+	}
+
+	for _, inst := range insts {
+		err := s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(inst.Project, inst.Name), auth.EntitlementCanExec)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
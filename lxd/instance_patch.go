@@ -15,9 +15,12 @@ import (
 	"github.com/canonical/lxd/lxd/db/cluster"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/placement"
 	projecthelpers "github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -183,6 +186,12 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Check access to the secrets newly referenced by the instance.
+	err = secretsCheckAccess(s, r, projectName, secrets.AddedReferences(c.LocalConfig(), c.LocalDevices(), req.Config, deviceConfig.NewDevices(req.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Check project limits.
 	apiProfiles := make([]api.Profile, 0, len(req.Profiles))
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -200,12 +209,23 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 			apiProfiles = append(apiProfiles, *apiProfile)
 		}
 
+		err = placement.ValidateInstanceConfig(ctx, tx, projectName, instancetype.ExpandInstanceConfig(nil, req.Config, apiProfiles))
+		if err != nil {
+			return err
+		}
+
 		return projecthelpers.AllowInstanceUpdate(s.GlobalConfig, tx, projectName, name, req, c.LocalConfig())
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	// Check that the requestor may forward the guest logs of the instance to Loki.
+	err = instanceLogsLokiCheckGuest(s, r, projectName, name, c.ExpandedConfig(), instancetype.ExpandInstanceConfig(nil, req.Config, apiProfiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
@@ -217,7 +237,7 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		Project:      projectName,
 	}
 
-	err = c.Update(args, true)
+	err = instanceUpdateWithHistory(s, r, c, args)
 	if err != nil {
 		return response.SmartError(err)
 	}
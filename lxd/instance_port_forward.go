@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// swagger:operation GET /1.0/instances/{name}/port-forward instances instance_port_forward
+//
+//	Forward a port of the instance
+//
+//	Upgrades the request to a websocket tunnelling a TCP connection to the given port on the loopback
+//	interface of the instance. The data is exchanged as binary messages and an empty text message marks the
+//	end of the stream in either direction. The connection is torn down when the websocket is closed.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: port
+//	    description: TCP port of the instance
+//	    type: integer
+//	    example: 80
+//	    required: true
+//	responses:
+//	  "101":
+//	    description: Switching protocols to websocket
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePortForwardHandler(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	instName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(instName) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	port, err := strconv.Atoi(request.QueryParam(r, "port"))
+	if err != nil || port < 1 || port > 65535 {
+		return response.BadRequest(fmt.Errorf("Invalid port %q", request.QueryParam(r, "port")))
+	}
+
+	if !websocket.IsWebSocketUpgrade(r) {
+		return response.BadRequest(fmt.Errorf("Port forwarding requires a websocket connection"))
+	}
+
+	// Redirect to correct server if needed.
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resp := &portForwardResponse{
+		req:         r,
+		projectName: projectName,
+		instName:    instName,
+		port:        port,
+	}
+
+	// Forward the request if the instance is remote.
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, instName, r, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		resp.memberConn, err = client.GetInstancePortForward(instName, port)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, instName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	resp.instConn, err = inst.PortForwardConn(port)
+	if err != nil {
+		return response.SmartError(api.StatusErrorf(http.StatusInternalServerError, "Failed connecting to port %d of the instance: %w", port, err))
+	}
+
+	return resp
+}
+
+// portForwardResponse tunnels the TCP connection to the instance (or the websocket of the cluster member
+// running the instance) over the websocket of the client.
+type portForwardResponse struct {
+	req         *http.Request
+	projectName string
+	instName    string
+	port        int
+	instConn    net.Conn
+	memberConn  *websocket.Conn
+}
+
+func (r *portForwardResponse) String() string {
+	return "port forward handler"
+}
+
+// Render renders the server response.
+func (r *portForwardResponse) Render(w http.ResponseWriter) error {
+	if r.memberConn != nil {
+		defer func() { _ = r.memberConn.Close() }()
+	} else {
+		defer func() { _ = r.instConn.Close() }()
+	}
+
+	conn, err := ws.Upgrader.Upgrade(w, r.req, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	l := logger.AddContext(logger.Ctx{
+		"project":  r.projectName,
+		"instance": r.instName,
+		"port":     r.port,
+		"remote":   conn.RemoteAddr(),
+	})
+
+	l.Debug("Port forward started")
+	defer l.Debug("Port forward finished")
+
+	if r.memberConn != nil {
+		<-ws.Proxy(conn, r.memberConn)
+		return nil
+	}
+
+	chRead, chWrite := ws.Mirror(conn, r.instConn)
+
+	// Once the client is done sending, propagate the end of the stream to the instance. If the client went
+	// away, close the instance connection so that the read mirror stops too.
+	err = <-chWrite
+	if err != nil {
+		_ = r.instConn.Close()
+	} else {
+		closeWrite(r.instConn)
+	}
+
+	<-chRead
+
+	return nil
+}
+
+// closeWrite shuts down the writing side of the connection if supported, or closes it otherwise.
+func closeWrite(conn net.Conn) {
+	writeCloser, ok := conn.(interface{ CloseWrite() error })
+	if ok {
+		_ = writeCloser.CloseWrite()
+		return
+	}
+
+	_ = conn.Close()
+}
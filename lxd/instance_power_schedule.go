@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instancePowerScheduleAction is a scheduled start or stop of an instance.
+type instancePowerScheduleAction struct {
+	inst     instance.Instance
+	start    bool
+	schedule string
+}
+
+func instancePowerSchedulesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Error("Failed loading instances for power schedules", logger.Ctx{"err": err})
+			return
+		}
+
+		evacuated := s.DB.Cluster.LocalNodeIsEvacuated()
+
+		var actions []instancePowerScheduleAction
+		for _, inst := range instances {
+			action := instancePowerScheduleDue(inst, evacuated)
+			if action != nil {
+				actions = append(actions, *action)
+			}
+		}
+
+		if len(actions) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			instancePowerSchedulesApply(s, actions)
+			return nil
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.InstancePowerSchedule, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating instance power schedules operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Applying instance power schedules")
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting instance power schedules operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed applying instance power schedules", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done applying instance power schedules")
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// instancePowerScheduleDue returns the scheduled start or stop of the instance due now, if any.
+// Instances aren't started on schedule while the cluster member is evacuated.
+func instancePowerScheduleDue(inst instance.Instance, evacuated bool) *instancePowerScheduleAction {
+	config := inst.ExpandedConfig()
+	if config["boot.schedule.start"] == "" && config["boot.schedule.stop"] == "" {
+		return nil
+	}
+
+	loc := time.Local
+	if config["boot.schedule.timezone"] != "" {
+		var err error
+		loc, err = time.LoadLocation(config["boot.schedule.timezone"])
+		if err != nil {
+			logger.Warn("Failed loading power schedule time zone", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			return nil
+		}
+	}
+
+	if inst.IsRunning() {
+		schedule := config["boot.schedule.stop"]
+		if schedule != "" && !inst.IsFrozen() && scheduleIsNow(schedule, int64(inst.ID()), loc) {
+			return &instancePowerScheduleAction{inst: inst, start: false, schedule: schedule}
+		}
+
+		return nil
+	}
+
+	schedule := config["boot.schedule.start"]
+	if schedule != "" && !evacuated && scheduleIsNow(schedule, int64(inst.ID()), loc) {
+		return &instancePowerScheduleAction{inst: inst, start: true, schedule: schedule}
+	}
+
+	return nil
+}
+
+// instancePowerSchedulesApply starts and stops the instances concurrently and waits for all of them.
+func instancePowerSchedulesApply(s *state.State, actions []instancePowerScheduleAction) {
+	var wg sync.WaitGroup
+
+	for _, action := range actions {
+		wg.Add(1)
+		go func(action instancePowerScheduleAction) {
+			defer wg.Done()
+
+			inst := action.inst
+			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "schedule": action.schedule})
+
+			if action.start {
+				err := inst.Start(false)
+				if err != nil {
+					// The instance may have been started in the meantime.
+					if inst.IsRunning() {
+						l.Debug("Instance already running, skipping scheduled start")
+						return
+					}
+
+					l.Error("Failed starting instance on schedule", logger.Ctx{"err": err})
+					return
+				}
+
+				s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceScheduleStarted.Event(inst, map[string]any{"schedule": action.schedule}))
+				return
+			}
+
+			timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+			if err != nil {
+				timeout = evacuateHostShutdownDefaultTimeout
+			}
+
+			forced := false
+			err = inst.Shutdown(time.Duration(timeout) * time.Second)
+			if errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+				// The instance may have been stopped in the meantime.
+				l.Debug("Instance already stopped, skipping scheduled stop")
+				return
+			} else if err != nil {
+				l.Warn("Failed shutting down instance on schedule, forcefully stopping", logger.Ctx{"err": err})
+
+				forced = true
+				err = inst.Stop(false)
+				if errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+					l.Debug("Instance already stopped, skipping scheduled stop")
+					return
+				} else if err != nil {
+					l.Error("Failed stopping instance on schedule", logger.Ctx{"err": err})
+					return
+				}
+			}
+
+			s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceScheduleStopped.Event(inst, map[string]any{"schedule": action.schedule, "forced": forced}))
+		}(action)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var instanceProcessesCmd = APIEndpoint{
+	Name: "instanceProcesses",
+	Path: "instances/{name}/processes",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerProcesses", Path: "containers/{name}/processes"},
+		{Name: "vmProcesses", Path: "virtual-machines/{name}/processes"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceProcessesGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/processes instances instance_processes_get
+//
+//	Get the processes
+//
+//	Gets the processes running in the instance with their resource usage.
+//
+//	The processes of containers are read from the host, those of virtual
+//	machines are retrieved through the `lxd-agent`.
+//
+//	The command lines of the processes are only returned to requestors
+//	that are allowed to execute commands in the instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Processes
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of processes
+//	          items:
+//	            $ref: "#/definitions/InstanceProcess"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceProcessesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	processes, err := inst.Processes()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Command lines often contain credentials, so only reveal them to requestors who could read them anyway.
+	err = s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec)
+	if err != nil {
+		if !auth.IsDeniedError(err) {
+			return response.SmartError(err)
+		}
+
+		for i := range processes {
+			processes[i].Command = ""
+		}
+	}
+
+	return response.SyncResponse(true, processes)
+}
@@ -17,9 +17,11 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/placement"
 	projecthelpers "github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
@@ -131,6 +133,12 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 	var do func(*operations.Operation) error
 	var opType operationtype.Type
 	if configRaw.Restore == "" {
+		// Check access to the secrets newly referenced by the instance.
+		err = secretsCheckAccess(s, r, projectName, secrets.AddedReferences(inst.LocalConfig(), inst.LocalDevices(), configRaw.Config, deviceConfig.NewDevices(configRaw.Devices)))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Check project limits.
 		apiProfiles := make([]api.Profile, 0, len(configRaw.Profiles))
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -148,12 +156,23 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				apiProfiles = append(apiProfiles, *apiProfile)
 			}
 
+			err = placement.ValidateInstanceConfig(ctx, tx, projectName, instancetype.ExpandInstanceConfig(nil, configRaw.Config, apiProfiles))
+			if err != nil {
+				return err
+			}
+
 			return projecthelpers.AllowInstanceUpdate(s.GlobalConfig, tx, projectName, name, configRaw, inst.LocalConfig())
 		})
 		if err != nil {
 			return response.SmartError(err)
 		}
 
+		// Check that the requestor may forward the guest logs of the instance to Loki.
+		err = instanceLogsLokiCheckGuest(s, r, projectName, name, inst.ExpandedConfig(), instancetype.ExpandInstanceConfig(nil, configRaw.Config, apiProfiles))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
@@ -168,16 +187,33 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				Project:      projectName,
 			}
 
-			err = inst.Update(args, true)
-			if err != nil {
-				return err
-			}
-
-			return nil
+			return instanceUpdateWithHistory(s, r, inst, args)
 		}
 
 		opType = operationtype.InstanceUpdate
 	} else {
+		snapName := configRaw.Restore
+		if !shared.IsSnapshot(snapName) {
+			snapName = name + shared.SnapshotDelimiter + snapName
+		}
+
+		snap, err := instance.LoadByProjectAndName(s, projectName, snapName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Check access to the secrets that the snapshot references again.
+		err = secretsCheckAccess(s, r, projectName, secrets.AddedReferences(inst.LocalConfig(), inst.LocalDevices(), snap.LocalConfig(), snap.LocalDevices()))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Check that the requestor may forward the guest logs of the instance to Loki again.
+		err = instanceLogsLokiCheckGuest(s, r, projectName, name, inst.ExpandedConfig(), snap.ExpandedConfig())
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Snapshot Restore
 		do = func(op *operations.Operation) error {
 			defer unlock()
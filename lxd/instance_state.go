@@ -105,6 +105,8 @@ func instanceState(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
+	instanceHealthRender(c, state)
+
 	return response.SyncResponse(true, state)
 }
 
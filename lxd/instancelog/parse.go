@@ -0,0 +1,123 @@
+package instancelog
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// lxcLogTimeLayout is the layout of the UTC timestamps of the liblxc log lines, which look like
+// "lxc c1 20240101120000.123 ERROR start - start.c:123 - Message".
+const lxcLogTimeLayout = "20060102150405.000"
+
+// ParseTimestamp returns the timestamp at the start of a log line, recognising the liblxc log format, RFC 3339
+// timestamps (QEMU and modern syslog) and traditional syslog timestamps. It returns the zero time if the line
+// doesn't start with a timestamp.
+func ParseTimestamp(line string) time.Time {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+
+	if len(fields) >= 3 && fields[0] == "lxc" {
+		t, err := time.Parse(lxcLogTimeLayout, fields[2])
+		if err == nil {
+			return t
+		}
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSuffix(fields[0], ":"))
+	if err == nil {
+		return t
+	}
+
+	// Traditional syslog timestamps have no year, assume the entry is from the last twelve months.
+	if len(fields) >= 3 {
+		t, err := time.ParseInLocation(time.Stamp, strings.Join(fields[0:3], " "), time.Local)
+		if err == nil {
+			now := time.Now()
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// journalEntry is the subset of the fields of a journal entry in the JSON output of journalctl.
+type journalEntry struct {
+	Cursor           string          `json:"__CURSOR"`
+	RealtimeUsec     string          `json:"__REALTIME_TIMESTAMP"`
+	Message          json.RawMessage `json:"MESSAGE"`
+	SyslogIdentifier string          `json:"SYSLOG_IDENTIFIER"`
+	Comm             string          `json:"_COMM"`
+}
+
+// ParseJournalEntry parses a line of the JSON output of journalctl and returns the timestamp, the program, the
+// message and the cursor of the entry.
+func ParseJournalEntry(line []byte) (timestamp time.Time, program string, message string, cursor string, err error) {
+	entry := journalEntry{}
+	err = json.Unmarshal(line, &entry)
+	if err != nil {
+		return time.Time{}, "", "", "", err
+	}
+
+	usec, err := strconv.ParseInt(entry.RealtimeUsec, 10, 64)
+	if err == nil {
+		timestamp = time.UnixMicro(usec)
+	}
+
+	// Messages that aren't valid UTF-8 are encoded as an array of bytes.
+	var text string
+	err = json.Unmarshal(entry.Message, &text)
+	if err != nil {
+		var raw []byte
+		var values []int
+		if json.Unmarshal(entry.Message, &values) == nil {
+			for _, v := range values {
+				raw = append(raw, byte(v))
+			}
+		}
+
+		text = strings.ToValidUTF8(string(raw), string(utf8.RuneError))
+	}
+
+	program = entry.SyslogIdentifier
+	if program == "" {
+		program = entry.Comm
+	}
+
+	return timestamp, program, text, entry.Cursor, nil
+}
+
+// ConsoleTailSize is the length of the end of the previously read console buffer which is looked up in the
+// current buffer to find the new output.
+const ConsoleTailSize = 256
+
+// NewConsoleOutput returns the part of the current content of a console ring buffer that wasn't in its previous
+// content. The previous content is located by looking up its end in the current content as the start of the
+// buffer is lost when it wraps.
+func NewConsoleOutput(previous string, current string) string {
+	if previous == "" {
+		return current
+	}
+
+	if strings.HasPrefix(current, previous) {
+		return current[len(previous):]
+	}
+
+	tail := previous[max(0, len(previous)-ConsoleTailSize):]
+	pos := strings.LastIndex(current, tail)
+	if pos < 0 {
+		// The buffer was cleared or all of its content was replaced.
+		return current
+	}
+
+	return current[pos+len(tail):]
+}
@@ -0,0 +1,64 @@
+package instancelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	cases := []struct {
+		title    string
+		line     string
+		expected time.Time
+	}{
+		{"liblxc", "lxc c1 20240102030405.678 ERROR start - start.c:123 - Failed", time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)},
+		{"RFC 3339", "2024-01-02T03:04:05.123456Z qemu-system-x86_64: warning", time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)},
+		{"no timestamp", "Starting container", time.Time{}},
+		{"empty", "", time.Time{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			assert.True(t, c.expected.Equal(ParseTimestamp(c.line)))
+		})
+	}
+
+	// Traditional syslog timestamps are within the last year.
+	timestamp := ParseTimestamp(time.Now().Add(-time.Hour).Format(time.Stamp) + " c1 systemd[1]: Started")
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), timestamp, time.Second)
+}
+
+func TestParseJournalEntry(t *testing.T) {
+	timestamp, program, message, cursor, err := ParseJournalEntry([]byte(`{"__CURSOR":"s=1","__REALTIME_TIMESTAMP":"1704164645000001","MESSAGE":"Started","SYSLOG_IDENTIFIER":"systemd","_COMM":"init"}`))
+	require.NoError(t, err)
+	assert.True(t, time.UnixMicro(1704164645000001).Equal(timestamp))
+	assert.Equal(t, "systemd", program)
+	assert.Equal(t, "Started", message)
+	assert.Equal(t, "s=1", cursor)
+
+	// Binary messages are arrays of bytes.
+	_, program, message, _, err = ParseJournalEntry([]byte(`{"MESSAGE":[104,105],"_COMM":"init"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "init", program)
+	assert.Equal(t, "hi", message)
+
+	_, _, _, _, err = ParseJournalEntry([]byte("-- No entries --"))
+	assert.Error(t, err)
+}
+
+func TestNewConsoleOutput(t *testing.T) {
+	assert.Equal(t, "abc", NewConsoleOutput("", "abc"))
+	assert.Equal(t, "def", NewConsoleOutput("abc", "abcdef"))
+	assert.Equal(t, "", NewConsoleOutput("abc", "abc"))
+
+	// The start of the buffer was dropped when it wrapped.
+	previous := strings.Repeat("a", ConsoleTailSize) + strings.Repeat("b", ConsoleTailSize)
+	assert.Equal(t, "ghi", NewConsoleOutput(previous, previous[100:]+"ghi"))
+
+	// The buffer was cleared.
+	assert.Equal(t, "xyz", NewConsoleOutput("abcdef", "xyz"))
+}
@@ -0,0 +1,619 @@
+package instancelog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	liblxc "github.com/lxc/go-lxc"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// PollInterval is how often the log files and the console are checked for new content when following.
+var PollInterval = time.Second
+
+// guestSetupScript prints how the logs of the guest can be read: "journal" if it uses the systemd journal,
+// otherwise the path of its syslog file.
+const guestSetupScript = `if command -v journalctl >/dev/null 2>&1 && [ -d /run/systemd/journal ]; then
+	echo journal
+elif [ -f /var/log/syslog ]; then
+	echo /var/log/syslog
+elif [ -f /var/log/messages ]; then
+	echo /var/log/messages
+fi`
+
+// Options configures a log stream.
+type Options struct {
+	// Sources to read the log entries from, all the sources supported by the instance if empty.
+	Sources []string
+
+	// Only the entries from this time onwards are returned, if set.
+	Since time.Time
+
+	// Whether to keep sending the new entries once the existing ones are sent.
+	Follow bool
+
+	// Selects the files of the instance log directory that are read by the lxd source.
+	LogFile func(name string) bool
+
+	// Environment of the commands run in the instance by the guest source.
+	Environment map[string]string
+}
+
+// SupportedSources returns the log sources supported by the instance.
+func SupportedSources(inst instance.Instance) []string {
+	if inst.Type() == instancetype.Container {
+		return []string{api.InstanceLogSourceConsole, api.InstanceLogSourceLXD, api.InstanceLogSourceGuest}
+	}
+
+	// The console of virtual machines isn't buffered by LXD.
+	return []string{api.InstanceLogSourceLXD, api.InstanceLogSourceGuest}
+}
+
+// emitFunc sends an entry to the stream.
+type emitFunc func(entry api.InstanceLogEntry) error
+
+// Stream sends the log entries of the instance to the handler. The existing entries are sent first, ordered by
+// time, then the new entries are sent as they're read if following. It returns once the existing entries are
+// sent if not following, and otherwise when the context is cancelled or the handler fails.
+func Stream(ctx context.Context, inst instance.Instance, opts Options, handler func(entry api.InstanceLogEntry) error) error {
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources = SupportedSources(inst)
+	}
+
+	for _, source := range sources {
+		if !shared.ValueInSlice(source, api.InstanceLogSources) {
+			return fmt.Errorf("Unknown log source %q", source)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	entries := make(chan api.InstanceLogEntry, 64)
+	emit := func(entry api.InstanceLogEntry) error {
+		if !opts.Since.IsZero() && entry.Timestamp.Before(opts.Since) {
+			return nil
+		}
+
+		select {
+		case entries <- entry:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Each source reports when it has read its existing entries so that these can be sorted before being sent.
+	var sourcesWg sync.WaitGroup
+	var historyWg sync.WaitGroup
+	for _, source := range sources {
+		var read func(ctx context.Context, inst instance.Instance, opts Options, emit emitFunc, caughtUp func()) error
+
+		switch source {
+		case api.InstanceLogSourceConsole:
+			read = readConsole
+		case api.InstanceLogSourceLXD:
+			read = readLogFiles
+		case api.InstanceLogSourceGuest:
+			read = readGuest
+		}
+
+		sourcesWg.Add(1)
+		historyWg.Add(1)
+		go func(source string) {
+			defer sourcesWg.Done()
+
+			var once sync.Once
+			caughtUp := func() { once.Do(historyWg.Done) }
+			defer caughtUp()
+
+			err := read(ctx, inst, opts, emit, caughtUp)
+			if err != nil && ctx.Err() == nil {
+				logger.Warn("Failed reading instance logs", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "source": source, "err": err})
+			}
+		}(source)
+	}
+
+	go func() {
+		sourcesWg.Wait()
+		close(entries)
+	}()
+
+	historyDone := make(chan struct{})
+	go func() {
+		historyWg.Wait()
+		close(historyDone)
+	}()
+
+	var history []api.InstanceLogEntry
+	sendHistory := func() error {
+		sort.SliceStable(history, func(i, j int) bool { return history[i].Timestamp.Before(history[j].Timestamp) })
+
+		for _, entry := range history {
+			err := handler(entry)
+			if err != nil {
+				return err
+			}
+		}
+
+		history = nil
+
+		return nil
+	}
+
+	var err error
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				if historyDone != nil {
+					return sendHistory()
+				}
+
+				return nil
+			}
+
+			if historyDone != nil {
+				history = append(history, entry)
+				continue
+			}
+
+			err = handler(entry)
+		case <-historyDone:
+			historyDone = nil
+			err = sendHistory()
+			if err == nil && !opts.Follow {
+				return nil
+			}
+		}
+
+		if err != nil {
+			// Stop the sources and wait for them to finish.
+			cancel()
+			for range entries {
+				// Drop the entries sent before the sources stopped.
+			}
+
+			return err
+		}
+	}
+}
+
+// readConsole reads the console ring buffer of a running container, or the console log file of a stopped one.
+// The console output has no timestamps so its entries are timestamped when read and the existing output is
+// skipped if only the entries from a given time are requested.
+func readConsole(ctx context.Context, inst instance.Instance, opts Options, emit emitFunc, caughtUp func()) error {
+	c, ok := inst.(instance.Container)
+	if !ok {
+		return nil
+	}
+
+	read := func() (string, error) {
+		if !c.IsRunning() {
+			content, err := os.ReadFile(c.ConsoleBufferLogPath())
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+
+			return string(content), nil
+		}
+
+		content, err := c.ConsoleLog(liblxc.ConsoleLogOptions{ReadLog: true})
+		if err != nil && !errors.Is(err, unix.ENODATA) {
+			return "", err
+		}
+
+		return content, nil
+	}
+
+	var partial string
+	emitOutput := func(output string) error {
+		lines := strings.Split(partial+output, "\n")
+		partial = lines[len(lines)-1]
+
+		for _, line := range lines[:len(lines)-1] {
+			err := emit(api.InstanceLogEntry{
+				Timestamp: time.Now(),
+				Source:    api.InstanceLogSourceConsole,
+				Message:   strings.TrimRight(line, "\r"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	previous, err := read()
+	if err != nil {
+		return err
+	}
+
+	if opts.Since.IsZero() {
+		err = emitOutput(previous)
+		if err != nil {
+			return err
+		}
+	}
+
+	caughtUp()
+
+	if !opts.Follow {
+		// Send the last line even if it's incomplete.
+		if partial != "" {
+			return emitOutput("\n")
+		}
+
+		return nil
+	}
+
+	running := c.IsRunning()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(PollInterval):
+		}
+
+		// The console log file is only written when the container stops and only repeats the ring buffer.
+		if !c.IsRunning() {
+			running = false
+			continue
+		}
+
+		if !running {
+			previous = ""
+			running = true
+		}
+
+		current, err := read()
+		if err != nil {
+			return err
+		}
+
+		err = emitOutput(NewConsoleOutput(previous, current))
+		if err != nil {
+			return err
+		}
+
+		previous = current
+	}
+}
+
+// logFile is a log file of the instance being read.
+type logFile struct {
+	name      string
+	offset    int64
+	partial   string
+	timestamp time.Time
+}
+
+// readLogFiles reads the log files that LXD keeps for the instance, following the files that are created or
+// truncated while following.
+func readLogFiles(ctx context.Context, inst instance.Instance, opts Options, emit emitFunc, caughtUp func()) error {
+	files := map[string]*logFile{}
+
+	readNew := func(final bool) error {
+		dents, err := os.ReadDir(inst.LogPath())
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, dent := range dents {
+			if dent.IsDir() || (opts.LogFile != nil && !opts.LogFile(dent.Name())) {
+				continue
+			}
+
+			f, ok := files[dent.Name()]
+			if !ok {
+				f = &logFile{name: dent.Name()}
+				files[dent.Name()] = f
+			}
+
+			err := f.read(filepath.Join(inst.LogPath(), f.name), emit, final)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	err := readNew(!opts.Follow)
+	if err != nil {
+		return err
+	}
+
+	caughtUp()
+
+	if !opts.Follow {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(PollInterval):
+		}
+
+		err := readNew(false)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// read sends the lines added to the log file since the previous read. The lines without a timestamp get the
+// timestamp of the previous line, or the modification time of the file. An incomplete last line is kept for
+// the next read unless final is set.
+func (f *logFile) read(path string, emit emitFunc, final bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Start over if the file was truncated or replaced by a smaller one.
+	if info.Size() < f.offset {
+		f.offset = 0
+		f.partial = ""
+	}
+
+	if info.Size() == f.offset && (!final || f.partial == "") {
+		return nil
+	}
+
+	if f.timestamp.IsZero() {
+		f.timestamp = info.ModTime()
+	}
+
+	_, err = file.Seek(f.offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, info.Size()-f.offset))
+	if err != nil {
+		return err
+	}
+
+	f.offset += int64(len(content))
+
+	lines := strings.Split(f.partial+string(content), "\n")
+	f.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+
+	if final && f.partial != "" {
+		lines = append(lines, f.partial)
+		f.partial = ""
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		timestamp := ParseTimestamp(line)
+		if timestamp.IsZero() {
+			timestamp = f.timestamp
+		} else {
+			f.timestamp = timestamp
+		}
+
+		err := emit(api.InstanceLogEntry{
+			Timestamp: timestamp,
+			Source:    api.InstanceLogSourceLXD,
+			Origin:    f.name,
+			Message:   line,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readGuest reads the systemd journal of the guest, or its syslog file if it doesn't use the journal. The
+// journal is limited to the current boot unless only the entries from a given time are requested.
+func readGuest(ctx context.Context, inst instance.Instance, opts Options, emit emitFunc, caughtUp func()) error {
+	if !inst.IsRunning() {
+		return nil
+	}
+
+	var setup bytes.Buffer
+	err := guestExec(ctx, inst, opts, []string{"sh", "-c", guestSetupScript}, func(line []byte) error {
+		setup.Write(line)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	method := strings.TrimSpace(setup.String())
+	if method == "" {
+		return nil
+	}
+
+	if method == "journal" {
+		var cursor string
+		handleEntry := func(line []byte) error {
+			timestamp, program, message, entryCursor, err := ParseJournalEntry(line)
+			if err != nil {
+				return nil
+			}
+
+			cursor = entryCursor
+
+			return emit(api.InstanceLogEntry{
+				Timestamp: timestamp,
+				Source:    api.InstanceLogSourceGuest,
+				Origin:    program,
+				Message:   message,
+			})
+		}
+
+		command := []string{"journalctl", "--no-pager", "--output=json"}
+		if opts.Since.IsZero() {
+			command = append(command, "--boot")
+		} else {
+			command = append(command, "--since=@"+strconv.FormatInt(opts.Since.Unix(), 10))
+		}
+
+		err := guestExec(ctx, inst, opts, command, handleEntry)
+		if err != nil {
+			return err
+		}
+
+		caughtUp()
+
+		if !opts.Follow {
+			return nil
+		}
+
+		// Carry on from the last entry read.
+		command = []string{"journalctl", "--no-pager", "--output=json", "--follow"}
+		if cursor != "" {
+			command = append(command, "--after-cursor="+cursor)
+		} else {
+			command = append(command, "--lines=0")
+		}
+
+		return guestExec(ctx, inst, opts, command, handleEntry)
+	}
+
+	// Read the syslog file, timestamping the lines like the LXD log files.
+	f := &logFile{name: filepath.Base(method), timestamp: time.Now()}
+	handleLine := func(line []byte) error {
+		f.offset += int64(len(line)) + 1
+
+		timestamp := ParseTimestamp(string(line))
+		if timestamp.IsZero() {
+			timestamp = f.timestamp
+		} else {
+			f.timestamp = timestamp
+		}
+
+		return emit(api.InstanceLogEntry{
+			Timestamp: timestamp,
+			Source:    api.InstanceLogSourceGuest,
+			Origin:    f.name,
+			Message:   string(line),
+		})
+	}
+
+	err = guestExec(ctx, inst, opts, []string{"cat", method}, handleLine)
+	if err != nil {
+		return err
+	}
+
+	caughtUp()
+
+	if !opts.Follow {
+		return nil
+	}
+
+	// Carry on from the end of the content read.
+	return guestExec(ctx, inst, opts, []string{"tail", "-c", "+" + strconv.FormatInt(f.offset+1, 10), "-F", method}, handleLine)
+}
+
+// guestExec runs the command in the instance and passes each line of its output to the handler. The command is
+// killed when the context is cancelled.
+func guestExec(ctx context.Context, inst instance.Instance, opts Options, command []string, handler func(line []byte) error) error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	req := api.InstanceExecPost{
+		Command:     command,
+		Environment: opts.Environment,
+	}
+
+	cmd, err := inst.Exec(req, devNull, writer, devNull)
+	if err != nil {
+		_ = writer.Close()
+		return err
+	}
+
+	// Close the write end of the pipe once the command exits so that the output ends.
+	exited := make(chan struct{})
+	go func() {
+		_, _ = cmd.Wait()
+		_ = writer.Close()
+		close(exited)
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = cmd.Signal(unix.SIGKILL)
+		case <-exited:
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		err = handler(scanner.Bytes())
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = scanner.Err()
+	}
+
+	// Kill the command if the output wasn't read to the end.
+	if err != nil {
+		_ = cmd.Signal(unix.SIGKILL)
+	}
+
+	<-exited
+
+	return err
+}
@@ -99,6 +99,17 @@ var instanceSFTPCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: instanceSFTPHandler, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanConnectSFTP, "name")},
 }
 
+var instancePortForwardCmd = APIEndpoint{
+	Name: "instancePortForward",
+	Path: "instances/{name}/port-forward",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerPortForward", Path: "containers/{name}/port-forward"},
+		{Name: "vmPortForward", Path: "virtual-machines/{name}/port-forward"},
+	},
+
+	Get: APIEndpointAction{Handler: instancePortForwardHandler, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanPortForward, "name")},
+}
+
 var instanceFileCmd = APIEndpoint{
 	Name: "instanceFile",
 	Path: "instances/{name}/files",
@@ -164,6 +175,41 @@ var instanceExecCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: instanceExecPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
 }
 
+var instanceExecSessionsCmd = APIEndpoint{
+	Name: "instanceExecSessions",
+	Path: "instances/{name}/exec-sessions",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSessions", Path: "containers/{name}/exec-sessions"},
+		{Name: "vmExecSessions", Path: "virtual-machines/{name}/exec-sessions"},
+	},
+
+	Get:  APIEndpointAction{Handler: instanceExecSessionsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+	Post: APIEndpointAction{Handler: instanceExecSessionsPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceExecSessionCmd = APIEndpoint{
+	Name: "instanceExecSession",
+	Path: "instances/{name}/exec-sessions/{id}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSession", Path: "containers/{name}/exec-sessions/{id}"},
+		{Name: "vmExecSession", Path: "virtual-machines/{name}/exec-sessions/{id}"},
+	},
+
+	Get:    APIEndpointAction{Handler: instanceExecSessionGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+	Delete: APIEndpointAction{Handler: instanceExecSessionDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceExecSessionAttachCmd = APIEndpoint{
+	Name: "instanceExecSessionAttach",
+	Path: "instances/{name}/exec-sessions/{id}/attach",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSessionAttach", Path: "containers/{name}/exec-sessions/{id}/attach"},
+		{Name: "vmExecSessionAttach", Path: "virtual-machines/{name}/exec-sessions/{id}/attach"},
+	},
+
+	Post: APIEndpointAction{Handler: instanceExecSessionAttachPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
 var instanceMetadataCmd = APIEndpoint{
 	Name: "instanceMetadata",
 	Path: "instances/{name}/metadata",
@@ -251,6 +297,9 @@ func (slice instanceAutostartList) Swap(i, j int) {
 
 var instancesStartMu sync.Mutex
 
+// instanceDependsOnDefaultTimeout is the default number of seconds to wait for the dependencies of an instance to be ready.
+const instanceDependsOnDefaultTimeout = 60
+
 // instanceShouldAutoStart returns whether the instance should be auto-started.
 // Returns true if boot.autostart is enabled or boot.autostart is not set and instance was previously running.
 func instanceShouldAutoStart(inst instance.Instance) bool {
@@ -271,12 +320,16 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
+	// Sort based on instance boot priority, then start dependencies first.
 	sort.Sort(instanceAutostartList(instances))
+	instances = instancesDependencyOrder(instances, false)
 
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
 
+	// Keep track of the instances started so their dependents can wait for them to be ready.
+	started := map[string]bool{}
+
 	// Start the instances
 	for _, inst := range instances {
 		if !instanceShouldAutoStart(inst) {
@@ -294,6 +347,9 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Wait for the instances it depends on to be ready.
+		instanceStartWaitDependencies(s, inst, started)
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
@@ -325,6 +381,8 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 				continue
 			}
 
+			started[project.Instance(inst.Project().Name, inst.Name())] = true
+
 			// Resolve any previous warning.
 			warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.InstanceAutostartFailure, entity.TypeInstance, inst.ID())
 			if warnErr != nil {
@@ -342,6 +400,79 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	}
 }
 
+// instanceStartWaitDependencies waits for the instances that the instance depends on and that were started in the
+// current pass to report that they are ready, giving up after boot.depends_on.timeout.
+func instanceStartWaitDependencies(s *state.State, inst instance.Instance, started map[string]bool) {
+	dependencies := instancetype.BootDependencies(inst.ExpandedConfig())
+	if len(dependencies) == 0 {
+		return
+	}
+
+	timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.depends_on.timeout"])
+	if err != nil {
+		timeout = instanceDependsOnDefaultTimeout
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	projectName := inst.Project().Name
+
+	for _, dependency := range dependencies {
+		// The ready state of instances that were already running isn't known.
+		if !started[project.Instance(projectName, dependency)] {
+			continue
+		}
+
+		for {
+			dependencyInst, err := instance.LoadByProjectAndName(s, projectName, dependency)
+			if err != nil || !dependencyInst.IsRunning() || shared.IsTrue(dependencyInst.LocalConfig()["volatile.last_state.ready"]) {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				logger.Warn("Timed out waiting for instance dependency to be ready", logger.Ctx{"project": projectName, "instance": inst.Name(), "dependency": dependency, "timeout": timeout})
+				break
+			}
+
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// instancesDependencyOrder returns the instances ordered so that each instance comes after the instances listed in
+// its boot.depends_on key, or before them if reverse is true, otherwise keeping their current order.
+func instancesDependencyOrder(instances []instance.Instance, reverse bool) []instance.Instance {
+	names := make([]string, 0, len(instances))
+	instancesByName := make(map[string]instance.Instance, len(instances))
+	dependsOn := map[string][]string{}
+
+	for _, inst := range instances {
+		name := project.Instance(inst.Project().Name, inst.Name())
+		names = append(names, name)
+		instancesByName[name] = inst
+
+		for _, dependency := range instancetype.BootDependencies(inst.ExpandedConfig()) {
+			dependencyName := project.Instance(inst.Project().Name, dependency)
+			if reverse {
+				dependsOn[dependencyName] = append(dependsOn[dependencyName], name)
+			} else {
+				dependsOn[name] = append(dependsOn[name], dependencyName)
+			}
+		}
+	}
+
+	ordered, cycle := instancetype.DependencyOrder(names, dependsOn)
+	if len(cycle) > 0 {
+		logger.Warn("Ignoring instance dependencies because of a dependency cycle", logger.Ctx{"instances": cycle})
+	}
+
+	orderedInstances := make([]instance.Instance, 0, len(ordered))
+	for _, name := range ordered {
+		orderedInstances = append(orderedInstances, instancesByName[name])
+	}
+
+	return orderedInstances
+}
+
 type instanceStopList []instance.Instance
 
 func (slice instanceStopList) Len() int {
@@ -436,7 +567,9 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 }
 
 func instancesShutdown(s *state.State, instances []instance.Instance) {
+	// Sort based on instance stop priority, then stop dependents first.
 	sort.Sort(instanceStopList(instances))
+	instances = instancesDependencyOrder(instances, true)
 
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
@@ -479,6 +612,7 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	}
 
 	var currentBatchPriority int
+	currentBatchDependencies := map[string]bool{}
 	for i, inst := range instances {
 		// Skip stopped instances.
 		if !inst.IsRunning() {
@@ -488,14 +622,20 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 		priority, _ := strconv.Atoi(inst.ExpandedConfig()["boot.stop.priority"])
 
 		// Shutdown instances in priority batches, logging at the start of each batch.
-		if i == 0 || priority != currentBatchPriority {
+		// An instance that an instance of the current batch depends on is shut down in the next batch.
+		if i == 0 || priority != currentBatchPriority || currentBatchDependencies[project.Instance(inst.Project().Name, inst.Name())] {
 			currentBatchPriority = priority
+			currentBatchDependencies = map[string]bool{}
 
 			// Wait for instances with higher priority to finish before starting next batch.
 			wg.Wait()
 			logger.Info("Stopping instances", logger.Ctx{"stopPriority": currentBatchPriority})
 		}
 
+		for _, dependency := range instancetype.BootDependencies(inst.ExpandedConfig()) {
+			currentBatchDependencies[project.Instance(inst.Project().Name, dependency)] = true
+		}
+
 		wg.Add(1)
 		instShutdownCh <- inst
 	}
@@ -476,6 +476,7 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 						if err != nil {
 							resultErrListAppend(dbInst, err)
 						} else {
+							instanceHealthRender(inst, c.State)
 							resultFullListAppend(c)
 						}
 					}
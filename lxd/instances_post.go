@@ -25,10 +25,12 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/placement"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/scriptlet"
+	"github.com/canonical/lxd/lxd/secrets"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
@@ -404,6 +406,35 @@ func createFromMigration(s *state.State, r *http.Request, projectName string, pr
 	return operations.OperationResponse(op)
 }
 
+// instanceCopyConfig returns the local configuration of a copy of the source instance with the given overrides.
+func instanceCopyConfig(source instance.Instance, config map[string]string) map[string]string {
+	copyConfig := map[string]string{}
+	for key, value := range source.LocalConfig() {
+		if instancetype.InstanceIncludeWhenCopying(key, false) {
+			copyConfig[key] = value
+		}
+	}
+
+	for key, value := range config {
+		copyConfig[key] = value
+	}
+
+	return copyConfig
+}
+
+// instanceCopySecrets returns the secrets referenced by a copy of the source instance with the given config and
+// devices overrides.
+func instanceCopySecrets(source instance.Instance, config map[string]string, devices map[string]map[string]string) []string {
+	copyConfig := instanceCopyConfig(source, config)
+
+	copyDevices := source.LocalDevices().Clone()
+	for key, value := range devices {
+		copyDevices[key] = value
+	}
+
+	return secrets.References(copyConfig, copyDevices)
+}
+
 func createFromCopy(s *state.State, r *http.Request, projectName string, profiles []api.Profile, req *api.InstancesPost) response.Response {
 	if s.DB.Cluster.LocalNodeIsEvacuated() {
 		return response.Forbidden(fmt.Errorf("Cluster member is evacuated"))
@@ -425,6 +456,18 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 		return response.SmartError(err)
 	}
 
+	// Check access to the secrets referenced by the copy, including those inherited from the source.
+	err = secretsCheckAccess(s, r, targetProject, instanceCopySecrets(source, req.Config, req.Devices))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the requestor may forward the guest logs of the copy to Loki, including when inherited from the source.
+	err = instanceLogsLokiCheckGuest(s, r, targetProject, req.Name, nil, instancetype.ExpandInstanceConfig(nil, instanceCopyConfig(source, req.Config), profiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// When clustered, use the node name, otherwise use the hostname.
 	if s.ServerClustered {
 		serverName := s.ServerName
@@ -648,6 +691,23 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		return response.SmartError(err)
 	}
 
+	// Check access to the secrets referenced by the imported instance.
+	backupDevices, err := shared.ApplyDeviceOverrides(deviceConfig.NewDevices(bInfo.Config.Container.Devices).CloneNative(), deviceConfig.NewDevices(bInfo.Config.Container.ExpandedDevices).CloneNative(), devices)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = secretsCheckAccess(s, r, projectName, secrets.References(bInfo.Config.Container.Config, deviceConfig.NewDevices(backupDevices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the requestor may forward the guest logs of the imported instance to Loki.
+	err = instanceLogsLokiCheckGuest(s, r, projectName, bInfo.Name, nil, bInfo.Config.Container.ExpandedConfig)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	bInfo.Project = projectName
 
 	// Override pool.
@@ -907,6 +967,12 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Check access to the secrets referenced by the instance.
+	err = secretsCheckAccess(s, r, targetProjectName, secrets.References(req.Config, deviceConfig.NewDevices(req.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var targetProject *api.Project
 	var profiles []api.Profile
 	var sourceInst *dbCluster.Instance
@@ -1063,6 +1129,28 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			logger.Debug("No name provided for new instance, using auto-generated name", logger.Ctx{"project": targetProjectName, "instance": req.Name})
 		}
 
+		// Check that the placement group of the instance exists.
+		if !clusterNotification {
+			err = placement.ValidateInstanceConfig(ctx, tx, targetProjectName, instancetype.ExpandInstanceConfig(nil, req.Config, profiles))
+			if err != nil {
+				return err
+			}
+		}
+
+		// Placement groups only apply to instances placed on a cluster member by this request.
+		var placementGroup string
+		if s.ServerClustered && !clusterNotification {
+			placementGroup = instancetype.ExpandInstanceConfig(nil, req.Config, profiles)["placement.group"]
+		}
+
+		// Check that a manually targeted member satisfies the placement group of the instance.
+		if placementGroup != "" && targetMemberInfo != nil {
+			_, err = placement.FilterCandidates(ctx, tx, targetProjectName, placementGroup, req.Name, []db.NodeInfo{*targetMemberInfo}, allMembers)
+			if err != nil {
+				return err
+			}
+		}
+
 		if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
 			if err != nil {
@@ -1096,6 +1184,14 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			// Only keep the members satisfying the placement group of the instance.
+			if placementGroup != "" {
+				candidateMembers, err = placement.FilterCandidates(ctx, tx, targetProjectName, placementGroup, req.Name, candidateMembers, allMembers)
+				if err != nil {
+					return err
+				}
+			}
+
 			return nil
 		}
 
@@ -1119,6 +1215,14 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Check that the requestor may forward the guest logs of the new instance to Loki.
+	if !clusterNotification {
+		err = instanceLogsLokiCheckGuest(s, r, targetProjectName, req.Name, nil, instancetype.ExpandInstanceConfig(nil, req.Config, profiles))
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 		// Run instance placement scriptlet if enabled and no cluster member selected yet.
 		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
@@ -10,10 +10,12 @@ type ClusterAction string
 
 // All supported lifecycle events for clusters.
 const (
-	ClusterEnabled            = ClusterAction(api.EventLifecycleClusterEnabled)
-	ClusterDisabled           = ClusterAction(api.EventLifecycleClusterDisabled)
-	ClusterCertificateUpdated = ClusterAction(api.EventLifecycleClusterCertificateUpdated)
-	ClusterTokenCreated       = ClusterAction(api.EventLifecycleClusterTokenCreated)
+	ClusterEnabled                 = ClusterAction(api.EventLifecycleClusterEnabled)
+	ClusterDisabled                = ClusterAction(api.EventLifecycleClusterDisabled)
+	ClusterCertificateUpdated      = ClusterAction(api.EventLifecycleClusterCertificateUpdated)
+	ClusterTokenCreated            = ClusterAction(api.EventLifecycleClusterTokenCreated)
+	ClusterDatabaseBackupCreated   = ClusterAction(api.EventLifecycleClusterDatabaseBackupCreated)
+	ClusterDatabaseBackupRetrieved = ClusterAction(api.EventLifecycleClusterDatabaseBackupRetrieved)
 )
 
 // Event creates the lifecycle event for an action on a cluster.
@@ -0,0 +1,27 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// IdentityTokenAction represents a lifecycle event action for identity tokens.
+type IdentityTokenAction string
+
+// All supported lifecycle events for identity tokens.
+const (
+	IdentityTokenCreated = IdentityTokenAction(api.EventLifecycleIdentityTokenCreated)
+	IdentityTokenDeleted = IdentityTokenAction(api.EventLifecycleIdentityTokenDeleted)
+)
+
+// Event creates the lifecycle event for an action on an identity token.
+func (a IdentityTokenAction) Event(authenticationMethod string, identifier string, tokenName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "auth", "identities", authenticationMethod, identifier, "tokens", tokenName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// PlacementGroupAction represents a lifecycle event action for placement groups.
+type PlacementGroupAction string
+
+// All supported lifecycle events for placement groups.
+const (
+	PlacementGroupCreated = PlacementGroupAction(api.EventLifecyclePlacementGroupCreated)
+	PlacementGroupDeleted = PlacementGroupAction(api.EventLifecyclePlacementGroupDeleted)
+	PlacementGroupUpdated = PlacementGroupAction(api.EventLifecyclePlacementGroupUpdated)
+)
+
+// Event creates the lifecycle event for an action on a placement group.
+func (a PlacementGroupAction) Event(name string, projectName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "placement-groups", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// SecretAction represents a lifecycle event action for secrets.
+type SecretAction string
+
+// All supported lifecycle events for secrets.
+const (
+	SecretCreated = SecretAction(api.EventLifecycleSecretCreated)
+	SecretDeleted = SecretAction(api.EventLifecycleSecretDeleted)
+	SecretUpdated = SecretAction(api.EventLifecycleSecretUpdated)
+)
+
+// Event creates the lifecycle event for an action on a secret.
+func (a SecretAction) Event(name string, projectName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "secrets", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -358,6 +358,29 @@ func (c *Client) HandleEvent(event api.Event) {
 	c.entries <- entry
 }
 
+// HandleInstanceLog sends an entry of the log stream of an instance.
+func (c *Client) HandleInstanceLog(projectName string, instanceName string, location string, logEntry api.InstanceLogEntry) {
+	labels := LabelSet{
+		"app":      "lxd",
+		"type":     "instance",
+		"location": location,
+		"instance": c.cfg.instance,
+		"name":     instanceName,
+		"project":  projectName,
+		"source":   logEntry.Source,
+	}
+
+	if logEntry.Origin != "" {
+		labels["origin"] = logEntry.Origin
+	}
+
+	select {
+	case c.entries <- entry{labels: labels, Entry: Entry{Timestamp: logEntry.Timestamp, Line: logEntry.Message}}:
+	case <-c.quit:
+	case <-c.ctx.Done():
+	}
+}
+
 func buildNestedContext(prefix string, m map[string]any) map[string]string {
 	labels := map[string]string{}
 
@@ -165,6 +165,10 @@ func main() {
 	forkproxyCmd := cmdForkproxy{global: &globalCmd}
 	app.AddCommand(forkproxyCmd.Command())
 
+	// forkserial sub-command
+	forkserialCmd := cmdForkserial{global: &globalCmd}
+	app.AddCommand(forkserialCmd.Command())
+
 	// forkstart sub-command
 	forkstartCmd := cmdForkstart{global: &globalCmd}
 	app.AddCommand(forkstartCmd.Command())
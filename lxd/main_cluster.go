@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/canonical/go-dqlite/client"
 	"github.com/spf13/cobra"
@@ -17,6 +18,7 @@ import (
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/lxd/util"
@@ -56,6 +58,14 @@ func (c *cmdCluster) Command() *cobra.Command {
 	clusterShow := cmdClusterShow{global: c.global}
 	cmd.AddCommand(clusterShow.Command())
 
+	// Back up the global database.
+	backupDatabase := cmdClusterBackupDatabase{global: c.global}
+	cmd.AddCommand(backupDatabase.Command())
+
+	// Restore the global database.
+	restoreDatabase := cmdClusterRestoreDatabase{global: c.global}
+	cmd.AddCommand(restoreDatabase.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -502,3 +512,222 @@ Do you want to proceed? (yes/no): `)
 
 	return nil
 }
+
+type cmdClusterBackupDatabase struct {
+	global *cmdGlobal
+}
+
+func (c *cmdClusterBackupDatabase) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "backup-database [<file>]"
+	cmd.Short = "Back up the global database"
+	cmd.Long = `Description:
+  Back up the global database
+
+  This writes a consistent snapshot of the global database to the given file
+  (or to lxd-database-<timestamp>.tar.gz in the current directory).
+  The backup can be restored with "lxd cluster restore-database".
+`
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdClusterBackupDatabase) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		_ = cmd.Help()
+		return fmt.Errorf("Invalid number of arguments")
+	}
+
+	target := fmt.Sprintf("lxd-database-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	if len(args) == 1 {
+		target = args[0]
+	}
+
+	client, err := lxd.ConnectLXDUnix("", nil)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to LXD daemon: %w", err)
+	}
+
+	target = shared.HostPathFollow(target)
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	_, err = client.GetClusterDatabaseBackup(&lxd.BackupFileRequest{BackupFile: file})
+	if err != nil {
+		_ = os.Remove(target)
+		return fmt.Errorf("Failed to fetch database backup: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to close database backup file: %w", err)
+	}
+
+	fmt.Printf("Global database backup written to %q\n", target)
+
+	return nil
+}
+
+type cmdClusterRestoreDatabase struct {
+	global             *cmdGlobal
+	flagMember         string
+	flagNonInteractive bool
+}
+
+func (c *cmdClusterRestoreDatabase) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "restore-database <file>"
+	cmd.Short = "Restore the global database from a backup"
+	cmd.Long = `Description:
+  Restore the global database from a backup
+
+  This replaces the content of the global database with the content of a backup
+  taken with "lxd cluster backup-database", "GET /1.0/cluster/database-backup" or
+  the "cluster.database_backup.schedule" configuration key.
+
+  The LXD daemon must be stopped. The backup is applied the next time the daemon
+  starts. If this server is clustered, it becomes the only database member of the
+  cluster, similar to "lxd cluster recover-from-quorum-loss".
+`
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.flagMember, "member", "", "Name of the cluster member in the backup that this server replaces")
+	cmd.Flags().BoolVarP(&c.flagNonInteractive, "quiet", "q", false, "Don't require user confirmation")
+
+	return cmd
+}
+
+func (c *cmdClusterRestoreDatabase) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		_ = cmd.Help()
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	// Make sure that the daemon is not running.
+	_, err := lxd.ConnectLXDUnix("", nil)
+	if err == nil {
+		return fmt.Errorf("The LXD daemon is running, please stop it first.")
+	}
+
+	file, err := os.Open(shared.HostPathFollow(args[0]))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	metadata, dump, err := dbCluster.ReadDatabaseBackup(file)
+	if err != nil {
+		return err
+	}
+
+	database, err := db.OpenNode(filepath.Join(sys.DefaultOS().VarDir, "database"), nil)
+	if err != nil {
+		return fmt.Errorf("Failed to open local database: %w", err)
+	}
+
+	var clusterAddress string
+	err = database.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
+		config, err := node.ConfigLoad(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		clusterAddress = config.ClusterAddress()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Standalone servers are recorded with a wildcard address in the global database.
+	address := clusterAddress
+	if address == "" {
+		address = "0.0.0.0"
+	}
+
+	member := c.flagMember
+	if member == "" {
+		for _, m := range metadata.Members {
+			if m.Address == address {
+				member = m.Name
+				break
+			}
+		}
+
+		if member == "" {
+			return fmt.Errorf("No cluster member with address %q found in the backup, use --member to select one", address)
+		}
+	}
+
+	if clusterAddress == "" && len(metadata.Members) > 1 {
+		return fmt.Errorf(`Can't restore a clustered database backup as server isn't clustered (missing "cluster.https_address" config)`)
+	}
+
+	// Prompt for confirmation unless --quiet was passed.
+	if !c.flagNonInteractive {
+		err := c.promptConfirmation(metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	patch, err := dbCluster.DatabaseRestorePatch(*metadata, dump, member, address)
+	if err != nil {
+		return err
+	}
+
+	// Keep a copy of the current global database around.
+	globalDir := filepath.Join(database.Dir(), "global")
+	if shared.PathExists(globalDir) {
+		err = shared.DirCopy(globalDir, filepath.Join(database.Dir(), "global.bak"))
+		if err != nil {
+			return fmt.Errorf("Failed to backup global database: %w", err)
+		}
+	}
+
+	if clusterAddress != "" {
+		err = cluster.Recover(database)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(database.Dir(), "patch.global.sql"), []byte(patch), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write global database patch: %w", err)
+	}
+
+	fmt.Printf("Global database restore of member %q scheduled for the next daemon start\n", member)
+
+	if len(metadata.Members) > 1 {
+		fmt.Println(`Remove former cluster members that won't come back with "lxc cluster remove <member-name> --force"`)
+	}
+
+	return nil
+}
+
+func (c *cmdClusterRestoreDatabase) promptConfirmation(metadata *dbCluster.DatabaseBackupMetadata) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf(`You are about to replace the content of the global database with a backup
+taken on %s by LXD %s.
+
+All changes made to the cluster since that time will be lost. If this server is
+clustered, it will become the only database member of the cluster and other
+database members must never be started again.
+
+Do you want to proceed? (yes/no): `, metadata.CreatedAt.Format(time.RFC3339), metadata.ServerVersion)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSuffix(input, "\n")
+
+	if !shared.ValueInSlice(strings.ToLower(input), []string{"yes"}) {
+		return fmt.Errorf("Restore operation aborted")
+	}
+
+	return nil
+}
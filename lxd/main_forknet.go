@@ -84,7 +84,7 @@ void forknet(void)
 	}
 
 	// Call the subcommands
-	if (strcmp(command, "info") == 0) {
+	if (strcmp(command, "info") == 0 || strcmp(command, "connect") == 0) {
 		int ns_fd, pidfd;
 		pid = atoi(cur);
 
@@ -106,6 +106,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -139,6 +141,13 @@ func (c *cmdForknet) Command() *cobra.Command {
 	cmdInfo.RunE = c.RunInfo
 	cmd.AddCommand(cmdInfo)
 
+	// connect
+	cmdConnect := &cobra.Command{}
+	cmdConnect.Use = "connect <PID> <PidFd> <port> <fd>"
+	cmdConnect.Args = cobra.ExactArgs(4)
+	cmdConnect.RunE = c.RunConnect
+	cmd.AddCommand(cmdConnect)
+
 	// detach
 	cmdDetach := &cobra.Command{}
 	cmdDetach.Use = "detach <netns file> <LXD PID> <ifname> <hostname>"
@@ -169,6 +178,48 @@ func (c *cmdForknet) RunInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// RunConnect connects to the TCP port on the loopback interface of the container's network namespace and sends
+// the connected socket over the unix socket passed as the given file descriptor.
+func (c *cmdForknet) RunConnect(cmd *cobra.Command, args []string) error {
+	port, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("Invalid port %q: %w", args[2], err)
+	}
+
+	sockFd, err := strconv.Atoi(args[3])
+	if err != nil {
+		return fmt.Errorf("Invalid file descriptor %q: %w", args[3], err)
+	}
+
+	var conn net.Conn
+	for _, host := range []string{"127.0.0.1", "::1"} {
+		conn, err = net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), 5*time.Second)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("Unexpected connection type %T", conn)
+	}
+
+	file, err := tcpConn.File()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return netutils.AbstractUnixSendFd(sockFd, int(file.Fd()))
+}
+
 func (c *cmdForknet) RunDetach(cmd *cobra.Command, args []string) error {
 	lxdPID := args[1]
 	ifName := args[2]
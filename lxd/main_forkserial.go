@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+type cmdForkserial struct {
+	global *cmdGlobal
+
+	connLock sync.Mutex
+	conn     net.Conn
+}
+
+// Command returns the cobra command for the forkserial sub-command.
+func (c *cmdForkserial) Command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forkserial <backend> <address>"
+	cmd.Short = "Connect the serial device of a container to its backend"
+	cmd.Long = `Description:
+  Connect the serial device of a container to its backend
+
+  This internal command is used to relay the terminal of a container serial
+  device to a Unix socket, a TCP address or a log file.
+
+  The terminal is passed as file descriptors 3 (primary) and 4 (secondary).
+  Both are kept open so that the terminal remains available while nothing
+  is connected on either side. The listener of the "pty", "unix" and "tcp"
+  backends is created by LXD and passed as file descriptor 5.
+`
+	cmd.RunE = c.Run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+// Run executes the forkserial sub-command.
+func (c *cmdForkserial) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) < 2 {
+		_ = cmd.Help()
+
+		if len(args) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	err := logger.InitLogger("", "lxd-forkserial", c.global.flagLogVerbose, c.global.flagLogDebug, nil)
+	if err != nil {
+		return err
+	}
+
+	backend := args[0]
+	address := args[1]
+
+	ptx := os.NewFile(3, "ptx")
+	pty := os.NewFile(4, "pty")
+	defer func() { _ = pty.Close() }()
+
+	if backend == "file" {
+		logFile, err := os.OpenFile(address, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("Failed opening %q: %w", address, err)
+		}
+
+		defer func() { _ = logFile.Close() }()
+
+		_, err = io.Copy(logFile, ptx)
+		return err
+	}
+
+	if !shared.ValueInSlice(backend, []string{"pty", "unix", "tcp"}) {
+		return fmt.Errorf("Unsupported backend %q", backend)
+	}
+
+	listenerFile := os.NewFile(5, "listener")
+	listener, err := net.FileListener(listenerFile)
+	_ = listenerFile.Close()
+	if err != nil {
+		return fmt.Errorf("Failed getting listener for %q: %w", address, err)
+	}
+
+	// The socket is removed by LXD when the device is stopped.
+	unixListener, ok := listener.(*net.UnixListener)
+	if ok {
+		unixListener.SetUnlinkOnClose(false)
+	}
+
+	defer func() { _ = listener.Close() }()
+
+	logger.Info("Started", logger.Ctx{"backend": backend, "address": address})
+
+	// Output of the container is sent to the connected client, if any, and dropped otherwise.
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := ptx.Read(buf)
+			if err != nil {
+				logger.Error("Failed reading from terminal", logger.Ctx{"err": err})
+				_ = listener.Close()
+				return
+			}
+
+			c.connLock.Lock()
+			if c.conn != nil {
+				_, err = c.conn.Write(buf[:n])
+				if err != nil {
+					_ = c.conn.Close()
+					c.conn = nil
+				}
+			}
+
+			c.connLock.Unlock()
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed accepting connection: %w", err)
+		}
+
+		// Serial ports only have a single client, a new connection replaces the previous one.
+		c.connLock.Lock()
+		if c.conn != nil {
+			_ = c.conn.Close()
+		}
+
+		c.conn = conn
+		c.connLock.Unlock()
+
+		go func() {
+			_, _ = io.Copy(ptx, conn)
+			_ = conn.Close()
+		}()
+	}
+}
@@ -8,6 +8,7 @@ type Metrics struct {
 	Filesystem     map[string]FilesystemMetrics `json:"filesystem" yaml:"filesystem"`
 	Memory         MemoryMetrics                `json:"memory" yaml:"memory"`
 	Network        map[string]NetworkMetrics    `json:"network" yaml:"network"`
+	Pressure       map[string]PressureMetrics   `json:"pressure" yaml:"pressure"`
 	ProcessesTotal uint64                       `json:"procs_total" yaml:"procs_total"`
 }
 
@@ -75,3 +76,9 @@ type NetworkMetrics struct {
 	TransmitErrors  uint64 `json:"network_transmit_errs" yaml:"network_transmit_errs"`
 	TransmitPackets uint64 `json:"network_transmit_packets" yaml:"network_transmit_packets"`
 }
+
+// PressureMetrics represents the pressure stall information of a resource ("cpu", "memory" or "io") for an instance.
+type PressureMetrics struct {
+	WaitingSeconds float64 `json:"pressure_waiting_seconds" yaml:"pressure_waiting_seconds"`
+	StalledSeconds float64 `json:"pressure_stalled_seconds" yaml:"pressure_stalled_seconds"`
+}
@@ -95,6 +95,10 @@ func (m *MetricSet) String() string {
 		GoGoroutines,
 		GoHeapObjects,
 		Instances,
+		StoragePoolInodes,
+		StoragePoolInodesUsed,
+		StorageVolumeSnapshots,
+		WarningsActive,
 	}
 
 	for _, metricType := range metricTypes {
@@ -279,6 +283,25 @@ func MetricSetFromAPI(metrics *Metrics, labels map[string]string) (*MetricSet, e
 		set.AddSamples(NetworkTransmitPacketsTotal, Sample{Value: float64(stats.TransmitPackets), Labels: labels})
 	}
 
+	// Pressure stall information
+	for resource, stats := range metrics.Pressure {
+		var waiting, stalled MetricType
+
+		switch resource {
+		case "cpu":
+			waiting, stalled = CPUPressureWaitingSecondsTotal, CPUPressureStalledSecondsTotal
+		case "memory":
+			waiting, stalled = MemoryPressureWaitingSecondsTotal, MemoryPressureStalledSecondsTotal
+		case "io":
+			waiting, stalled = IOPressureWaitingSecondsTotal, IOPressureStalledSecondsTotal
+		default:
+			continue
+		}
+
+		set.AddSamples(waiting, Sample{Value: stats.WaitingSeconds})
+		set.AddSamples(stalled, Sample{Value: stats.StalledSeconds})
+	}
+
 	// Procs stats
 	set.AddSamples(ProcsTotal, Sample{Value: float64(metrics.ProcessesTotal)})
 
@@ -20,10 +20,20 @@ const (
 	CPUSecondsTotal MetricType = iota
 	// CPUs represents the total number of effective CPUs.
 	CPUs
+	// CPUPressureStalledSecondsTotal represents the total time in seconds that all tasks were stalled waiting for CPU.
+	CPUPressureStalledSecondsTotal
+	// CPUPressureWaitingSecondsTotal represents the total time in seconds that some tasks were waiting for CPU.
+	CPUPressureWaitingSecondsTotal
+	// DiskDiscardedBytesTotal represents the discarded bytes for a disk.
+	DiskDiscardedBytesTotal
+	// DiskDiscardsCompletedTotal represents the completed discards for a disk.
+	DiskDiscardsCompletedTotal
 	// DiskReadBytesTotal represents the read bytes for a disk.
 	DiskReadBytesTotal
 	// DiskReadsCompletedTotal represents the completed for a disk.
 	DiskReadsCompletedTotal
+	// DiskThrottledSecondsTotal represents the time in seconds that IO to a disk was delayed by the IO controller.
+	DiskThrottledSecondsTotal
 	// DiskWrittenBytesTotal represents the written bytes for a disk.
 	DiskWrittenBytesTotal
 	// DiskWritesCompletedTotal represents the completed writes for a disk.
@@ -34,6 +44,10 @@ const (
 	FilesystemFreeBytes
 	// FilesystemSizeBytes represents the size in bytes of a filesystem.
 	FilesystemSizeBytes
+	// IOPressureStalledSecondsTotal represents the total time in seconds that all tasks were stalled waiting for IO.
+	IOPressureStalledSecondsTotal
+	// IOPressureWaitingSecondsTotal represents the total time in seconds that some tasks were waiting for IO.
+	IOPressureWaitingSecondsTotal
 	// MemoryActiveAnonBytes represents the amount of anonymous memory on active LRU list.
 	MemoryActiveAnonBytes
 	// MemoryActiveFileBytes represents the amount of file-backed memory on active LRU list.
@@ -74,6 +88,10 @@ const (
 	MemoryWritebackBytes
 	// MemoryOOMKillsTotal represents the amount of oom kills.
 	MemoryOOMKillsTotal
+	// MemoryPressureStalledSecondsTotal represents the total time in seconds that all tasks were stalled waiting for memory.
+	MemoryPressureStalledSecondsTotal
+	// MemoryPressureWaitingSecondsTotal represents the total time in seconds that some tasks were waiting for memory.
+	MemoryPressureWaitingSecondsTotal
 	// NetworkReceiveBytesTotal represents the amount of received bytes on a given interface.
 	NetworkReceiveBytesTotal
 	// NetworkReceiveDropTotal represents the amount of received dropped bytes on a given interface.
@@ -92,10 +110,18 @@ const (
 	NetworkTransmitPacketsTotal
 	// ProcsTotal represents the number of running processes.
 	ProcsTotal
+	// ProxyHTTPRequestsTotal represents the number of requests handled by proxy devices in HTTP mode.
+	ProxyHTTPRequestsTotal
 	// OperationsTotal represents the number of running operations.
 	OperationsTotal
 	// WarningsTotal represents the number of active warnings.
 	WarningsTotal
+	// WarningsActive represents the number of unresolved warnings by type.
+	WarningsActive
+	// WebhookDeliveriesTotal represents the number of notifications delivered to a webhook.
+	WebhookDeliveriesTotal
+	// WebhookDeadLettersTotal represents the number of notifications that couldn't be delivered to a webhook.
+	WebhookDeadLettersTotal
 	// UptimeSeconds represents the daemon uptime in seconds.
 	UptimeSeconds
 	// GoGoroutines represents the number of goroutines that currently exist..
@@ -146,142 +172,204 @@ const (
 	GoNextGCBytes
 	// Instances represents the instance count.
 	Instances
+	// StorageBucketQuotaBytes represents the quota of a storage bucket in bytes.
+	StorageBucketQuotaBytes
+	// StorageBucketUsedBytes represents the space used by a storage bucket in bytes.
+	StorageBucketUsedBytes
+	// StoragePoolInodes represents the number of inodes of a storage pool.
+	StoragePoolInodes
+	// StoragePoolInodesUsed represents the number of used inodes of a storage pool.
+	StoragePoolInodesUsed
+	// StoragePoolSizeBytes represents the size of a storage pool in bytes.
+	StoragePoolSizeBytes
+	// StoragePoolUsedBytes represents the space used in a storage pool in bytes.
+	StoragePoolUsedBytes
+	// StorageVolumeQuotaBytes represents the quota of a storage volume in bytes.
+	StorageVolumeQuotaBytes
+	// StorageVolumeSnapshots represents the number of snapshots of a storage volume.
+	StorageVolumeSnapshots
+	// StorageVolumeUsedBytes represents the space used by a storage volume in bytes.
+	StorageVolumeUsedBytes
 )
 
 // MetricNames associates a metric type to its name.
 var MetricNames = map[MetricType]string{
-	CPUSecondsTotal:             "lxd_cpu_seconds_total",
-	CPUs:                        "lxd_cpu_effective_total",
-	DiskReadBytesTotal:          "lxd_disk_read_bytes_total",
-	DiskReadsCompletedTotal:     "lxd_disk_reads_completed_total",
-	DiskWrittenBytesTotal:       "lxd_disk_written_bytes_total",
-	DiskWritesCompletedTotal:    "lxd_disk_writes_completed_total",
-	FilesystemAvailBytes:        "lxd_filesystem_avail_bytes",
-	FilesystemFreeBytes:         "lxd_filesystem_free_bytes",
-	FilesystemSizeBytes:         "lxd_filesystem_size_bytes",
-	GoAllocBytes:                "lxd_go_alloc_bytes",
-	GoAllocBytesTotal:           "lxd_go_alloc_bytes_total",
-	GoBuckHashSysBytes:          "lxd_go_buck_hash_sys_bytes",
-	GoFreesTotal:                "lxd_go_frees_total",
-	GoGCSysBytes:                "lxd_go_gc_sys_bytes",
-	GoGoroutines:                "lxd_go_goroutines",
-	GoHeapAllocBytes:            "lxd_go_heap_alloc_bytes",
-	GoHeapIdleBytes:             "lxd_go_heap_idle_bytes",
-	GoHeapInuseBytes:            "lxd_go_heap_inuse_bytes",
-	GoHeapObjects:               "lxd_go_heap_objects",
-	GoHeapReleasedBytes:         "lxd_go_heap_released_bytes",
-	GoHeapSysBytes:              "lxd_go_heap_sys_bytes",
-	GoLookupsTotal:              "lxd_go_lookups_total",
-	GoMallocsTotal:              "lxd_go_mallocs_total",
-	GoMCacheInuseBytes:          "lxd_go_mcache_inuse_bytes",
-	GoMCacheSysBytes:            "lxd_go_mcache_sys_bytes",
-	GoMSpanInuseBytes:           "lxd_go_mspan_inuse_bytes",
-	GoMSpanSysBytes:             "lxd_go_mspan_sys_bytes",
-	GoNextGCBytes:               "lxd_go_next_gc_bytes",
-	GoOtherSysBytes:             "lxd_go_other_sys_bytes",
-	GoStackInuseBytes:           "lxd_go_stack_inuse_bytes",
-	GoStackSysBytes:             "lxd_go_stack_sys_bytes",
-	GoSysBytes:                  "lxd_go_sys_bytes",
-	MemoryActiveAnonBytes:       "lxd_memory_Active_anon_bytes",
-	MemoryActiveFileBytes:       "lxd_memory_Active_file_bytes",
-	MemoryActiveBytes:           "lxd_memory_Active_bytes",
-	MemoryCachedBytes:           "lxd_memory_Cached_bytes",
-	MemoryDirtyBytes:            "lxd_memory_Dirty_bytes",
-	MemoryHugePagesFreeBytes:    "lxd_memory_HugepagesFree_bytes",
-	MemoryHugePagesTotalBytes:   "lxd_memory_HugepagesTotal_bytes",
-	MemoryInactiveAnonBytes:     "lxd_memory_Inactive_anon_bytes",
-	MemoryInactiveFileBytes:     "lxd_memory_Inactive_file_bytes",
-	MemoryInactiveBytes:         "lxd_memory_Inactive_bytes",
-	MemoryMappedBytes:           "lxd_memory_Mapped_bytes",
-	MemoryMemAvailableBytes:     "lxd_memory_MemAvailable_bytes",
-	MemoryMemFreeBytes:          "lxd_memory_MemFree_bytes",
-	MemoryMemTotalBytes:         "lxd_memory_MemTotal_bytes",
-	MemoryRSSBytes:              "lxd_memory_RSS_bytes",
-	MemoryShmemBytes:            "lxd_memory_Shmem_bytes",
-	MemorySwapBytes:             "lxd_memory_Swap_bytes",
-	MemoryUnevictableBytes:      "lxd_memory_Unevictable_bytes",
-	MemoryWritebackBytes:        "lxd_memory_Writeback_bytes",
-	MemoryOOMKillsTotal:         "lxd_memory_OOM_kills_total",
-	NetworkReceiveBytesTotal:    "lxd_network_receive_bytes_total",
-	NetworkReceiveDropTotal:     "lxd_network_receive_drop_total",
-	NetworkReceiveErrsTotal:     "lxd_network_receive_errs_total",
-	NetworkReceivePacketsTotal:  "lxd_network_receive_packets_total",
-	NetworkTransmitBytesTotal:   "lxd_network_transmit_bytes_total",
-	NetworkTransmitDropTotal:    "lxd_network_transmit_drop_total",
-	NetworkTransmitErrsTotal:    "lxd_network_transmit_errs_total",
-	NetworkTransmitPacketsTotal: "lxd_network_transmit_packets_total",
-	OperationsTotal:             "lxd_operations_total",
-	ProcsTotal:                  "lxd_procs_total",
-	UptimeSeconds:               "lxd_uptime_seconds",
-	WarningsTotal:               "lxd_warnings_total",
-	Instances:                   "lxd_instances",
+	CPUSecondsTotal:                   "lxd_cpu_seconds_total",
+	CPUs:                              "lxd_cpu_effective_total",
+	CPUPressureStalledSecondsTotal:    "lxd_cpu_pressure_stalled_seconds_total",
+	CPUPressureWaitingSecondsTotal:    "lxd_cpu_pressure_waiting_seconds_total",
+	DiskDiscardedBytesTotal:           "lxd_disk_discarded_bytes_total",
+	DiskDiscardsCompletedTotal:        "lxd_disk_discards_completed_total",
+	DiskReadBytesTotal:                "lxd_disk_read_bytes_total",
+	DiskReadsCompletedTotal:           "lxd_disk_reads_completed_total",
+	DiskThrottledSecondsTotal:         "lxd_disk_throttled_seconds_total",
+	DiskWrittenBytesTotal:             "lxd_disk_written_bytes_total",
+	DiskWritesCompletedTotal:          "lxd_disk_writes_completed_total",
+	FilesystemAvailBytes:              "lxd_filesystem_avail_bytes",
+	FilesystemFreeBytes:               "lxd_filesystem_free_bytes",
+	FilesystemSizeBytes:               "lxd_filesystem_size_bytes",
+	GoAllocBytes:                      "lxd_go_alloc_bytes",
+	GoAllocBytesTotal:                 "lxd_go_alloc_bytes_total",
+	GoBuckHashSysBytes:                "lxd_go_buck_hash_sys_bytes",
+	GoFreesTotal:                      "lxd_go_frees_total",
+	GoGCSysBytes:                      "lxd_go_gc_sys_bytes",
+	GoGoroutines:                      "lxd_go_goroutines",
+	GoHeapAllocBytes:                  "lxd_go_heap_alloc_bytes",
+	GoHeapIdleBytes:                   "lxd_go_heap_idle_bytes",
+	GoHeapInuseBytes:                  "lxd_go_heap_inuse_bytes",
+	GoHeapObjects:                     "lxd_go_heap_objects",
+	GoHeapReleasedBytes:               "lxd_go_heap_released_bytes",
+	GoHeapSysBytes:                    "lxd_go_heap_sys_bytes",
+	GoLookupsTotal:                    "lxd_go_lookups_total",
+	GoMallocsTotal:                    "lxd_go_mallocs_total",
+	GoMCacheInuseBytes:                "lxd_go_mcache_inuse_bytes",
+	GoMCacheSysBytes:                  "lxd_go_mcache_sys_bytes",
+	GoMSpanInuseBytes:                 "lxd_go_mspan_inuse_bytes",
+	GoMSpanSysBytes:                   "lxd_go_mspan_sys_bytes",
+	GoNextGCBytes:                     "lxd_go_next_gc_bytes",
+	GoOtherSysBytes:                   "lxd_go_other_sys_bytes",
+	GoStackInuseBytes:                 "lxd_go_stack_inuse_bytes",
+	GoStackSysBytes:                   "lxd_go_stack_sys_bytes",
+	GoSysBytes:                        "lxd_go_sys_bytes",
+	IOPressureStalledSecondsTotal:     "lxd_io_pressure_stalled_seconds_total",
+	IOPressureWaitingSecondsTotal:     "lxd_io_pressure_waiting_seconds_total",
+	MemoryActiveAnonBytes:             "lxd_memory_Active_anon_bytes",
+	MemoryActiveFileBytes:             "lxd_memory_Active_file_bytes",
+	MemoryActiveBytes:                 "lxd_memory_Active_bytes",
+	MemoryCachedBytes:                 "lxd_memory_Cached_bytes",
+	MemoryDirtyBytes:                  "lxd_memory_Dirty_bytes",
+	MemoryHugePagesFreeBytes:          "lxd_memory_HugepagesFree_bytes",
+	MemoryHugePagesTotalBytes:         "lxd_memory_HugepagesTotal_bytes",
+	MemoryInactiveAnonBytes:           "lxd_memory_Inactive_anon_bytes",
+	MemoryInactiveFileBytes:           "lxd_memory_Inactive_file_bytes",
+	MemoryInactiveBytes:               "lxd_memory_Inactive_bytes",
+	MemoryMappedBytes:                 "lxd_memory_Mapped_bytes",
+	MemoryMemAvailableBytes:           "lxd_memory_MemAvailable_bytes",
+	MemoryMemFreeBytes:                "lxd_memory_MemFree_bytes",
+	MemoryMemTotalBytes:               "lxd_memory_MemTotal_bytes",
+	MemoryRSSBytes:                    "lxd_memory_RSS_bytes",
+	MemoryShmemBytes:                  "lxd_memory_Shmem_bytes",
+	MemorySwapBytes:                   "lxd_memory_Swap_bytes",
+	MemoryUnevictableBytes:            "lxd_memory_Unevictable_bytes",
+	MemoryWritebackBytes:              "lxd_memory_Writeback_bytes",
+	MemoryOOMKillsTotal:               "lxd_memory_OOM_kills_total",
+	MemoryPressureStalledSecondsTotal: "lxd_memory_pressure_stalled_seconds_total",
+	MemoryPressureWaitingSecondsTotal: "lxd_memory_pressure_waiting_seconds_total",
+	NetworkReceiveBytesTotal:          "lxd_network_receive_bytes_total",
+	NetworkReceiveDropTotal:           "lxd_network_receive_drop_total",
+	NetworkReceiveErrsTotal:           "lxd_network_receive_errs_total",
+	NetworkReceivePacketsTotal:        "lxd_network_receive_packets_total",
+	NetworkTransmitBytesTotal:         "lxd_network_transmit_bytes_total",
+	NetworkTransmitDropTotal:          "lxd_network_transmit_drop_total",
+	NetworkTransmitErrsTotal:          "lxd_network_transmit_errs_total",
+	NetworkTransmitPacketsTotal:       "lxd_network_transmit_packets_total",
+	OperationsTotal:                   "lxd_operations_total",
+	ProcsTotal:                        "lxd_procs_total",
+	ProxyHTTPRequestsTotal:            "lxd_proxy_http_requests_total",
+	UptimeSeconds:                     "lxd_uptime_seconds",
+	WarningsTotal:                     "lxd_warnings_total",
+	WarningsActive:                    "lxd_warnings_active",
+	WebhookDeliveriesTotal:            "lxd_webhook_deliveries_total",
+	WebhookDeadLettersTotal:           "lxd_webhook_dead_letters_total",
+	Instances:                         "lxd_instances",
+	StorageBucketQuotaBytes:           "lxd_storage_bucket_quota_bytes",
+	StorageBucketUsedBytes:            "lxd_storage_bucket_used_bytes",
+	StoragePoolInodes:                 "lxd_storage_pool_inodes",
+	StoragePoolInodesUsed:             "lxd_storage_pool_inodes_used",
+	StoragePoolSizeBytes:              "lxd_storage_pool_size_bytes",
+	StoragePoolUsedBytes:              "lxd_storage_pool_used_bytes",
+	StorageVolumeQuotaBytes:           "lxd_storage_volume_quota_bytes",
+	StorageVolumeSnapshots:            "lxd_storage_volume_snapshots",
+	StorageVolumeUsedBytes:            "lxd_storage_volume_used_bytes",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
 var MetricHeaders = map[MetricType]string{
-	CPUSecondsTotal:             "# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.",
-	CPUs:                        "# HELP lxd_cpu_effective_total The total number of effective CPUs.",
-	DiskReadBytesTotal:          "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
-	DiskReadsCompletedTotal:     "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
-	DiskWrittenBytesTotal:       "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
-	DiskWritesCompletedTotal:    "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
-	FilesystemAvailBytes:        "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
-	FilesystemFreeBytes:         "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
-	FilesystemSizeBytes:         "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
-	GoAllocBytes:                "# HELP lxd_go_alloc_bytes Number of bytes allocated and still in use.",
-	GoAllocBytesTotal:           "# HELP lxd_go_alloc_bytes_total Total number of bytes allocated, even if freed.",
-	GoBuckHashSysBytes:          "# HELP lxd_go_buck_hash_sys_bytes Number of bytes used by the profiling bucket hash table.",
-	GoFreesTotal:                "# HELP lxd_go_frees_total Total number of frees.",
-	GoGCSysBytes:                "# HELP lxd_go_gc_sys_bytes Number of bytes used for garbage collection system metadata.",
-	GoGoroutines:                "# HELP lxd_go_goroutines Number of goroutines that currently exist.",
-	GoHeapAllocBytes:            "# HELP lxd_go_heap_alloc_bytes Number of heap bytes allocated and still in use.",
-	GoHeapIdleBytes:             "# HELP lxd_go_heap_idle_bytes Number of heap bytes waiting to be used.",
-	GoHeapInuseBytes:            "# HELP lxd_go_heap_inuse_bytes Number of heap bytes that are in use.",
-	GoHeapObjects:               "# HELP lxd_go_heap_objects Number of allocated objects.",
-	GoHeapReleasedBytes:         "# HELP lxd_go_heap_released_bytes Number of heap bytes released to OS.",
-	GoHeapSysBytes:              "# HELP lxd_go_heap_sys_bytes Number of heap bytes obtained from system.",
-	GoLookupsTotal:              "# HELP lxd_go_lookups_total Total number of pointer lookups.",
-	GoMallocsTotal:              "# HELP lxd_go_mallocs_total Total number of mallocs.",
-	GoMCacheInuseBytes:          "# HELP lxd_go_mcache_inuse_bytes Number of bytes in use by mcache structures.",
-	GoMCacheSysBytes:            "# HELP lxd_go_mcache_sys_bytes Number of bytes used for mcache structures obtained from system.",
-	GoMSpanInuseBytes:           "# HELP lxd_go_mspan_inuse_bytes Number of bytes in use by mspan structures.",
-	GoMSpanSysBytes:             "# HELP lxd_go_mspan_sys_bytes Number of bytes used for mspan structures obtained from system.",
-	GoNextGCBytes:               "# HELP lxd_go_next_gc_bytes Number of heap bytes when next garbage collection will take place.",
-	GoOtherSysBytes:             "# HELP lxd_go_other_sys_bytes Number of bytes used for other system allocations.",
-	GoStackInuseBytes:           "# HELP lxd_go_stack_inuse_bytes Number of bytes in use by the stack allocator.",
-	GoStackSysBytes:             "# HELP lxd_go_stack_sys_bytes Number of bytes obtained from system for stack allocator.",
-	GoSysBytes:                  "# HELP lxd_go_sys_bytes Number of bytes obtained from system.",
-	MemoryActiveAnonBytes:       "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
-	MemoryActiveFileBytes:       "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
-	MemoryActiveBytes:           "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
-	MemoryCachedBytes:           "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
-	MemoryDirtyBytes:            "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
-	MemoryHugePagesFreeBytes:    "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
-	MemoryHugePagesTotalBytes:   "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
-	MemoryInactiveAnonBytes:     "# HELP lxd_memory_Inactive_anon_bytes The amount of anonymous memory on inactive LRU list.",
-	MemoryInactiveFileBytes:     "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
-	MemoryInactiveBytes:         "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
-	MemoryMappedBytes:           "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
-	MemoryMemAvailableBytes:     "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
-	MemoryMemFreeBytes:          "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
-	MemoryMemTotalBytes:         "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
-	MemoryRSSBytes:              "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
-	MemoryShmemBytes:            "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
-	MemorySwapBytes:             "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
-	MemoryUnevictableBytes:      "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
-	MemoryWritebackBytes:        "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
-	MemoryOOMKillsTotal:         "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
-	NetworkReceiveBytesTotal:    "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
-	NetworkReceiveDropTotal:     "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
-	NetworkReceiveErrsTotal:     "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
-	NetworkReceivePacketsTotal:  "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
-	NetworkTransmitBytesTotal:   "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
-	NetworkTransmitDropTotal:    "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
-	NetworkTransmitErrsTotal:    "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
-	NetworkTransmitPacketsTotal: "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
-	OperationsTotal:             "# HELP lxd_operations_total The number of running operations",
-	ProcsTotal:                  "# HELP lxd_procs_total The number of running processes.",
-	UptimeSeconds:               "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
-	WarningsTotal:               "# HELP lxd_warnings_total The number of active warnings.",
-	Instances:                   "# HELP lxd_instances The number of instances.",
+	CPUSecondsTotal:                   "# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.",
+	CPUs:                              "# HELP lxd_cpu_effective_total The total number of effective CPUs.",
+	CPUPressureStalledSecondsTotal:    "# HELP lxd_cpu_pressure_stalled_seconds_total The total time in seconds that all tasks were stalled waiting for CPU.",
+	CPUPressureWaitingSecondsTotal:    "# HELP lxd_cpu_pressure_waiting_seconds_total The total time in seconds that some tasks were waiting for CPU.",
+	DiskDiscardedBytesTotal:           "# HELP lxd_disk_discarded_bytes_total The total number of bytes discarded.",
+	DiskDiscardsCompletedTotal:        "# HELP lxd_disk_discards_completed_total The total number of completed discards.",
+	DiskReadBytesTotal:                "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
+	DiskReadsCompletedTotal:           "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
+	DiskThrottledSecondsTotal:         "# HELP lxd_disk_throttled_seconds_total The total time in seconds that IO was delayed by the IO controller.",
+	DiskWrittenBytesTotal:             "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
+	DiskWritesCompletedTotal:          "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
+	FilesystemAvailBytes:              "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
+	FilesystemFreeBytes:               "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
+	FilesystemSizeBytes:               "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
+	GoAllocBytes:                      "# HELP lxd_go_alloc_bytes Number of bytes allocated and still in use.",
+	GoAllocBytesTotal:                 "# HELP lxd_go_alloc_bytes_total Total number of bytes allocated, even if freed.",
+	GoBuckHashSysBytes:                "# HELP lxd_go_buck_hash_sys_bytes Number of bytes used by the profiling bucket hash table.",
+	GoFreesTotal:                      "# HELP lxd_go_frees_total Total number of frees.",
+	GoGCSysBytes:                      "# HELP lxd_go_gc_sys_bytes Number of bytes used for garbage collection system metadata.",
+	GoGoroutines:                      "# HELP lxd_go_goroutines Number of goroutines that currently exist.",
+	GoHeapAllocBytes:                  "# HELP lxd_go_heap_alloc_bytes Number of heap bytes allocated and still in use.",
+	GoHeapIdleBytes:                   "# HELP lxd_go_heap_idle_bytes Number of heap bytes waiting to be used.",
+	GoHeapInuseBytes:                  "# HELP lxd_go_heap_inuse_bytes Number of heap bytes that are in use.",
+	GoHeapObjects:                     "# HELP lxd_go_heap_objects Number of allocated objects.",
+	GoHeapReleasedBytes:               "# HELP lxd_go_heap_released_bytes Number of heap bytes released to OS.",
+	GoHeapSysBytes:                    "# HELP lxd_go_heap_sys_bytes Number of heap bytes obtained from system.",
+	GoLookupsTotal:                    "# HELP lxd_go_lookups_total Total number of pointer lookups.",
+	GoMallocsTotal:                    "# HELP lxd_go_mallocs_total Total number of mallocs.",
+	GoMCacheInuseBytes:                "# HELP lxd_go_mcache_inuse_bytes Number of bytes in use by mcache structures.",
+	GoMCacheSysBytes:                  "# HELP lxd_go_mcache_sys_bytes Number of bytes used for mcache structures obtained from system.",
+	GoMSpanInuseBytes:                 "# HELP lxd_go_mspan_inuse_bytes Number of bytes in use by mspan structures.",
+	GoMSpanSysBytes:                   "# HELP lxd_go_mspan_sys_bytes Number of bytes used for mspan structures obtained from system.",
+	GoNextGCBytes:                     "# HELP lxd_go_next_gc_bytes Number of heap bytes when next garbage collection will take place.",
+	GoOtherSysBytes:                   "# HELP lxd_go_other_sys_bytes Number of bytes used for other system allocations.",
+	GoStackInuseBytes:                 "# HELP lxd_go_stack_inuse_bytes Number of bytes in use by the stack allocator.",
+	GoStackSysBytes:                   "# HELP lxd_go_stack_sys_bytes Number of bytes obtained from system for stack allocator.",
+	GoSysBytes:                        "# HELP lxd_go_sys_bytes Number of bytes obtained from system.",
+	IOPressureStalledSecondsTotal:     "# HELP lxd_io_pressure_stalled_seconds_total The total time in seconds that all tasks were stalled waiting for IO.",
+	IOPressureWaitingSecondsTotal:     "# HELP lxd_io_pressure_waiting_seconds_total The total time in seconds that some tasks were waiting for IO.",
+	MemoryActiveAnonBytes:             "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
+	MemoryActiveFileBytes:             "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
+	MemoryActiveBytes:                 "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
+	MemoryCachedBytes:                 "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
+	MemoryDirtyBytes:                  "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
+	MemoryHugePagesFreeBytes:          "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
+	MemoryHugePagesTotalBytes:         "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
+	MemoryInactiveAnonBytes:           "# HELP lxd_memory_Inactive_anon_bytes The amount of anonymous memory on inactive LRU list.",
+	MemoryInactiveFileBytes:           "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
+	MemoryInactiveBytes:               "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
+	MemoryMappedBytes:                 "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
+	MemoryMemAvailableBytes:           "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
+	MemoryMemFreeBytes:                "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
+	MemoryMemTotalBytes:               "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
+	MemoryRSSBytes:                    "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
+	MemoryShmemBytes:                  "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
+	MemorySwapBytes:                   "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
+	MemoryUnevictableBytes:            "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
+	MemoryWritebackBytes:              "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
+	MemoryOOMKillsTotal:               "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
+	MemoryPressureStalledSecondsTotal: "# HELP lxd_memory_pressure_stalled_seconds_total The total time in seconds that all tasks were stalled waiting for memory.",
+	MemoryPressureWaitingSecondsTotal: "# HELP lxd_memory_pressure_waiting_seconds_total The total time in seconds that some tasks were waiting for memory.",
+	NetworkReceiveBytesTotal:          "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
+	NetworkReceiveDropTotal:           "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
+	NetworkReceiveErrsTotal:           "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
+	NetworkReceivePacketsTotal:        "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
+	NetworkTransmitBytesTotal:         "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
+	NetworkTransmitDropTotal:          "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
+	NetworkTransmitErrsTotal:          "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
+	NetworkTransmitPacketsTotal:       "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
+	OperationsTotal:                   "# HELP lxd_operations_total The number of running operations",
+	ProcsTotal:                        "# HELP lxd_procs_total The number of running processes.",
+	ProxyHTTPRequestsTotal:            "# HELP lxd_proxy_http_requests_total The total number of requests handled by proxy devices in HTTP mode.",
+	UptimeSeconds:                     "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
+	WarningsTotal:                     "# HELP lxd_warnings_total The number of active warnings.",
+	WarningsActive:                    "# HELP lxd_warnings_active The number of unresolved warnings by type and severity.",
+	WebhookDeliveriesTotal:            "# HELP lxd_webhook_deliveries_total The number of notifications delivered to the webhook.",
+	WebhookDeadLettersTotal:           "# HELP lxd_webhook_dead_letters_total The number of notifications that couldn't be delivered to the webhook.",
+	Instances:                         "# HELP lxd_instances The number of instances.",
+	StorageBucketQuotaBytes:           "# HELP lxd_storage_bucket_quota_bytes The quota of the storage bucket in bytes.",
+	StorageBucketUsedBytes:            "# HELP lxd_storage_bucket_used_bytes The space used by the storage bucket in bytes.",
+	StoragePoolInodes:                 "# HELP lxd_storage_pool_inodes The number of inodes of the storage pool.",
+	StoragePoolInodesUsed:             "# HELP lxd_storage_pool_inodes_used The number of used inodes of the storage pool.",
+	StoragePoolSizeBytes:              "# HELP lxd_storage_pool_size_bytes The size of the storage pool in bytes.",
+	StoragePoolUsedBytes:              "# HELP lxd_storage_pool_used_bytes The space used in the storage pool in bytes.",
+	StorageVolumeQuotaBytes:           "# HELP lxd_storage_volume_quota_bytes The quota of the storage volume in bytes.",
+	StorageVolumeSnapshots:            "# HELP lxd_storage_volume_snapshots The number of snapshots of the storage volume.",
+	StorageVolumeUsedBytes:            "# HELP lxd_storage_volume_used_bytes The space used by the storage volume in bytes.",
 }
@@ -0,0 +1,255 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// Placement group policies.
+const (
+	// PolicySpread places the instances of a placement group on different failure domains.
+	PolicySpread = "spread"
+
+	// PolicyCompact places the instances of a placement group on the same failure domain.
+	PolicyCompact = "compact"
+)
+
+// Placement group rigors.
+const (
+	// RigorStrict fails placement if the policy can't be satisfied.
+	RigorStrict = "strict"
+
+	// RigorBestEffort picks the members violating the policy the least if it can't be satisfied.
+	RigorBestEffort = "best-effort"
+)
+
+// Placement group scopes.
+const (
+	// ScopeMember uses each cluster member as a failure domain.
+	ScopeMember = "member"
+
+	// ScopeClusterGroup uses each cluster group as a failure domain.
+	ScopeClusterGroup = "cluster-group"
+)
+
+// configKey is the instance configuration key referencing a placement group.
+const configKey = "placement.group"
+
+// ValidName checks the placement group name is valid.
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	return validate.IsHostname(name)
+}
+
+// ValidateConfig validates the configuration of a placement group.
+func ValidateConfig(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		"policy": validate.Optional(validate.IsOneOf(PolicySpread, PolicyCompact)),
+		"rigor":  validate.Optional(validate.IsOneOf(RigorStrict, RigorBestEffort)),
+		"scope":  validate.Optional(validate.IsOneOf(ScopeMember, ScopeClusterGroup)),
+	}
+
+	for k, v := range config {
+		if strings.HasPrefix(k, "user.") {
+			continue
+		}
+
+		validator, found := rules[k]
+		if !found {
+			return fmt.Errorf("Invalid placement group configuration key %q", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return fmt.Errorf("Invalid value for placement group configuration key %q: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+// configValue returns the value of the given placement group configuration key or its default.
+func configValue(group *api.PlacementGroup, key string, defaultValue string) string {
+	value := group.Config[key]
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Instances returns the instances referencing the given placement group in the given project, either directly
+// or through one of their profiles, mapped to the name of the cluster member they are located on.
+func Instances(ctx context.Context, tx *db.ClusterTx, projectName string, groupName string) (map[string]string, error) {
+	instances := map[string]string{}
+
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		config := instancetype.ExpandInstanceConfig(nil, inst.Config, inst.Profiles)
+		if config[configKey] == groupName {
+			instances[inst.Name] = inst.Node
+		}
+
+		return nil
+	}, cluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// Usage returns the number of instances per cluster member from a map of instance names to member names,
+// ignoring the given instance.
+func Usage(instances map[string]string, skipInstance string) map[string]int {
+	usage := map[string]int{}
+	for instanceName, memberName := range instances {
+		if instanceName == skipInstance {
+			continue
+		}
+
+		usage[memberName]++
+	}
+
+	return usage
+}
+
+// failureDomains returns the failure domains of a cluster member for the given scope.
+// With the cluster group scope, the failure domains of a member are the cluster groups it belongs to, ignoring
+// the default group which all members are part of. Members that aren't part of any other group are their own
+// failure domain.
+func failureDomains(scope string, memberName string, memberGroups []string) []string {
+	if scope == ScopeClusterGroup {
+		domains := make([]string, 0, len(memberGroups))
+		for _, groupName := range memberGroups {
+			if groupName == "default" {
+				continue
+			}
+
+			domains = append(domains, "group/"+groupName)
+		}
+
+		if len(domains) > 0 {
+			return domains
+		}
+	}
+
+	return []string{"member/" + memberName}
+}
+
+// Filter returns the candidate members on which a new instance of the given placement group can be placed.
+//
+// The members argument lists all cluster members and is used to find the cluster groups of the members hosting
+// the existing instances of the placement group, whose number per member is given by usage.
+//
+// With the strict rigor an error is returned if none of the candidates satisfy the policy. With the best-effort
+// rigor, the candidates violating the policy the least are returned instead.
+func Filter(group *api.PlacementGroup, candidates []db.NodeInfo, members []db.NodeInfo, usage map[string]int) ([]db.NodeInfo, error) {
+	policy := configValue(group, "policy", PolicySpread)
+	rigor := configValue(group, "rigor", RigorStrict)
+	scope := configValue(group, "scope", ScopeMember)
+
+	memberGroups := make(map[string][]string, len(members))
+	for _, member := range members {
+		memberGroups[member.Name] = member.Groups
+	}
+
+	// Score each candidate by the number of existing instances violating the policy if placed on it.
+	// With the spread policy, those are the instances sharing a failure domain with the candidate.
+	// With the compact policy, those are the instances not sharing a failure domain with the candidate.
+	bestScore := -1
+	scores := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		candidateDomains := failureDomains(scope, candidate.Name, candidate.Groups)
+
+		for memberName, count := range usage {
+			sharesDomain := false
+			for _, domain := range failureDomains(scope, memberName, memberGroups[memberName]) {
+				if shared.ValueInSlice(domain, candidateDomains) {
+					sharesDomain = true
+					break
+				}
+			}
+
+			if (policy == PolicySpread) == sharesDomain {
+				scores[i] += count
+			}
+		}
+
+		if bestScore < 0 || scores[i] < bestScore {
+			bestScore = scores[i]
+		}
+	}
+
+	if bestScore > 0 && rigor == RigorStrict {
+		var reason string
+		switch {
+		case policy == PolicySpread && scope == ScopeClusterGroup:
+			reason = "All candidate cluster members belong to a cluster group already hosting an instance of the group"
+		case policy == PolicySpread:
+			reason = "All candidate cluster members already host an instance of the group"
+		case scope == ScopeClusterGroup:
+			reason = "No candidate cluster member belongs to a cluster group hosting all the instances of the group"
+		default:
+			reason = "No candidate cluster member hosts all the instances of the group"
+		}
+
+		return nil, api.StatusErrorf(http.StatusConflict, "Placement group %q can't be satisfied: %s", group.Name, reason)
+	}
+
+	filtered := make([]db.NodeInfo, 0, len(candidates))
+	for i, candidate := range candidates {
+		if scores[i] == bestScore {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	return filtered, nil
+}
+
+// ValidateInstanceConfig checks that the placement group referenced by the expanded configuration of an instance
+// exists in the instance's project.
+func ValidateInstanceConfig(ctx context.Context, tx *db.ClusterTx, projectName string, config map[string]string) error {
+	groupName := config[configKey]
+	if groupName == "" {
+		return nil
+	}
+
+	_, _, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q doesn't exist in project %q", groupName, projectName)
+		}
+
+		return fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+	}
+
+	return nil
+}
+
+// FilterCandidates loads the placement group with the given name and returns the candidate members on which
+// the given instance can be placed according to it.
+func FilterCandidates(ctx context.Context, tx *db.ClusterTx, projectName string, groupName string, instanceName string, candidates []db.NodeInfo, members []db.NodeInfo) ([]db.NodeInfo, error) {
+	_, group, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+	}
+
+	instances, err := Instances(ctx, tx, projectName, groupName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances of placement group %q: %w", groupName, err)
+	}
+
+	return Filter(group, candidates, members, Usage(instances, instanceName))
+}
@@ -0,0 +1,119 @@
+package placement_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/placement"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var testMembers = []db.NodeInfo{
+	{Name: "server1", Groups: []string{"default", "rack1"}},
+	{Name: "server2", Groups: []string{"default", "rack1"}},
+	{Name: "server3", Groups: []string{"default", "rack2"}},
+	{Name: "server4", Groups: []string{"default"}},
+}
+
+func memberNames(members []db.NodeInfo) []string {
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+
+	return names
+}
+
+func TestFilter(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  map[string]string
+		usage   map[string]int
+		members []string
+		err     string
+	}{
+		{
+			name:    "spread without instances",
+			config:  map[string]string{},
+			usage:   map[string]int{},
+			members: []string{"server1", "server2", "server3", "server4"},
+		},
+		{
+			name:    "spread on members",
+			config:  map[string]string{"policy": "spread"},
+			usage:   map[string]int{"server1": 1, "server3": 1},
+			members: []string{"server2", "server4"},
+		},
+		{
+			name:    "spread on cluster groups",
+			config:  map[string]string{"policy": "spread", "scope": "cluster-group"},
+			usage:   map[string]int{"server1": 1},
+			members: []string{"server3", "server4"},
+		},
+		{
+			name:   "strict spread not satisfiable",
+			config: map[string]string{"policy": "spread", "scope": "cluster-group"},
+			usage:  map[string]int{"server1": 1, "server3": 1, "server4": 1},
+			err:    `Placement group "test" can't be satisfied: All candidate cluster members belong to a cluster group already hosting an instance of the group`,
+		},
+		{
+			name:    "best-effort spread not satisfiable",
+			config:  map[string]string{"policy": "spread", "rigor": "best-effort"},
+			usage:   map[string]int{"server1": 2, "server2": 1, "server3": 1, "server4": 1},
+			members: []string{"server2", "server3", "server4"},
+		},
+		{
+			name:    "compact on members",
+			config:  map[string]string{"policy": "compact"},
+			usage:   map[string]int{"server2": 2},
+			members: []string{"server2"},
+		},
+		{
+			name:    "compact on cluster groups",
+			config:  map[string]string{"policy": "compact", "scope": "cluster-group"},
+			usage:   map[string]int{"server2": 1},
+			members: []string{"server1", "server2"},
+		},
+		{
+			name:   "strict compact not satisfiable",
+			config: map[string]string{"policy": "compact"},
+			usage:  map[string]int{"server1": 1, "server2": 1},
+			err:    `Placement group "test" can't be satisfied: No candidate cluster member hosts all the instances of the group`,
+		},
+		{
+			name:    "best-effort compact not satisfiable",
+			config:  map[string]string{"policy": "compact", "rigor": "best-effort"},
+			usage:   map[string]int{"server1": 1, "server2": 2},
+			members: []string{"server2"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			group := &api.PlacementGroup{Name: "test", Config: c.config}
+
+			candidates, err := placement.Filter(group, testMembers, testMembers, c.usage)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.members, memberNames(candidates))
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, placement.ValidateConfig(map[string]string{"policy": "compact", "rigor": "best-effort", "scope": "cluster-group", "user.foo": "bar"}))
+	assert.EqualError(t, placement.ValidateConfig(map[string]string{"policy": "random"}), `Invalid value for placement group configuration key "policy": Invalid value "random" (not one of [spread compact])`)
+	assert.EqualError(t, placement.ValidateConfig(map[string]string{"foo": "bar"}), `Invalid placement group configuration key "foo"`)
+}
+
+func TestUsage(t *testing.T) {
+	usage := placement.Usage(map[string]string{"c1": "server1", "c2": "server1", "c3": "server2"}, "c2")
+	assert.Equal(t, map[string]int{"server1": 1, "server2": 1}, usage)
+}
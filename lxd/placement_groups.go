@@ -0,0 +1,562 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/placement"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var placementGroupsCmd = APIEndpoint{
+	Path: "placement-groups",
+
+	Get:  APIEndpointAction{Handler: placementGroupsGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: placementGroupsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreatePlacementGroups)},
+}
+
+var placementGroupCmd = APIEndpoint{
+	Path: "placement-groups/{name}",
+
+	Delete: APIEndpointAction{Handler: placementGroupDelete, AccessHandler: allowPermission(entity.TypePlacementGroup, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: placementGroupGet, AccessHandler: allowPermission(entity.TypePlacementGroup, auth.EntitlementCanView, "name")},
+	Put:    APIEndpointAction{Handler: placementGroupPut, AccessHandler: allowPermission(entity.TypePlacementGroup, auth.EntitlementCanEdit, "name")},
+	Patch:  APIEndpointAction{Handler: placementGroupPut, AccessHandler: allowPermission(entity.TypePlacementGroup, auth.EntitlementCanEdit, "name")},
+}
+
+// placementGroupUsedBy returns the URLs of the instances referencing the placement group.
+func placementGroupUsedBy(ctx context.Context, tx *db.ClusterTx, projectName string, groupName string) ([]string, error) {
+	instances, err := placement.Instances(ctx, tx, projectName, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make([]string, 0, len(instances))
+	for instanceName := range instances {
+		usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "instances", instanceName).Project(projectName).String())
+	}
+
+	sort.Strings(usedBy)
+
+	return usedBy, nil
+}
+
+// placementGroupEtag returns the values used to compute the ETag of a placement group.
+func placementGroupEtag(group *api.PlacementGroup) []any {
+	return []any{group.Name, group.Description, group.Config}
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/placement-groups placement-groups placement_groups_get
+//
+//  Get the placement groups
+//
+//  Returns a list of placement groups (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/placement-groups/db-replicas",
+//                "/1.0/placement-groups/web"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/placement-groups?recursion=1 placement-groups placement_groups_get_recursion1
+//
+//	Get the placement groups
+//
+//	Returns a list of placement groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of placement groups
+//	          items:
+//	            $ref: "#/definitions/PlacementGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypePlacementGroup)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.PlacementGroup{}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		groupNames, err := tx.GetPlacementGroupsByProject(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, groupName := range groupNames {
+			if !userHasPermission(entity.PlacementGroupURL(projectName, groupName)) {
+				continue
+			}
+
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "placement-groups", groupName).String())
+				continue
+			}
+
+			_, group, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+			if err != nil {
+				return err
+			}
+
+			group.UsedBy, err = placementGroupUsedBy(ctx, tx, projectName, groupName)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	for i := range resultMap {
+		resultMap[i].UsedBy = project.FilterUsedBy(s.Authorizer, r, resultMap[i].UsedBy)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/placement-groups placement-groups placement_groups_post
+//
+//	Add a placement group
+//
+//	Creates a new placement group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Placement group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/PlacementGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.PlacementGroupsPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = placement.ValidName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = placement.ValidateConfig(req.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+		}
+
+		_, _, err = tx.GetPlacementGroup(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The placement group already exists")
+		}
+
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = tx.CreatePlacementGroup(ctx, projectName, &req)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.PlacementGroupCreated.Event(req.Name, projectName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/placement-groups/{name} placement-groups placement_group_delete
+//
+//	Delete the placement group
+//
+//	Removes the placement group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+		if err != nil {
+			return err
+		}
+
+		usedBy, err := placementGroupUsedBy(ctx, tx, projectName, groupName)
+		if err != nil {
+			return err
+		}
+
+		if len(usedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "The placement group is currently in use")
+		}
+
+		return tx.DeletePlacementGroup(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.PlacementGroupDeleted.Event(groupName, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/placement-groups/{name} placement-groups placement_group_get
+//
+//	Get the placement group
+//
+//	Gets a specific placement group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Placement group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/PlacementGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var group *api.PlacementGroup
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, group, err = tx.GetPlacementGroup(ctx, projectName, groupName)
+		if err != nil {
+			return err
+		}
+
+		group.UsedBy, err = placementGroupUsedBy(ctx, tx, projectName, groupName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	group.UsedBy = project.FilterUsedBy(s.Authorizer, r, group.UsedBy)
+
+	return response.SyncResponseETag(true, group, placementGroupEtag(group))
+}
+
+// swagger:operation PATCH /1.0/placement-groups/{name} placement-groups placement_group_patch
+//
+//  Partially update the placement group
+//
+//  Updates a subset of the placement group configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: group
+//      description: Placement group configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/PlacementGroupPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/placement-groups/{name} placement-groups placement_group_put
+//
+//	Update the placement group
+//
+//	Updates the entire placement group configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Placement group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/PlacementGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func placementGroupPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.PlacementGroupPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Get the existing placement group.
+		id, group, err := tx.GetPlacementGroup(ctx, projectName, groupName)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		err = util.EtagCheck(r, placementGroupEtag(group))
+		if err != nil {
+			return api.StatusErrorf(http.StatusPreconditionFailed, "%s", err.Error())
+		}
+
+		if r.Method == http.MethodPatch {
+			// If config being updated via "patch" method, then merge all existing config with the keys that
+			// are present in the request config.
+			if req.Config == nil {
+				req.Config = map[string]string{}
+			}
+
+			for k, v := range group.Config {
+				_, ok := req.Config[k]
+				if !ok {
+					req.Config[k] = v
+				}
+			}
+		}
+
+		err = placement.ValidateConfig(req.Config)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%s", err.Error())
+		}
+
+		return tx.UpdatePlacementGroup(ctx, id, &req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.PlacementGroupUpdated.Event(groupName, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,51 @@
+package pressure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stats represent the pressure stall information of a resource.
+type Stats struct {
+	// Total time in microseconds that some tasks were waiting for the resource.
+	SomeTotal uint64
+
+	// Total time in microseconds that all tasks were stalled waiting for the resource.
+	FullTotal uint64
+}
+
+// Parse parses the content of a pressure stall information file, such as /proc/pressure/cpu or the cgroup2
+// cpu.pressure file. Each line has the format "some avg10=0.00 avg60=0.00 avg300=0.00 total=0". The "full" line
+// isn't available for CPU on older kernels, in which case FullTotal is left to zero.
+func Parse(content string) (*Stats, error) {
+	stats := &Stats{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			name, value, found := strings.Cut(field, "=")
+			if !found || name != "total" {
+				continue
+			}
+
+			total, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing %q: %w", line, err)
+			}
+
+			switch fields[0] {
+			case "some":
+				stats.SomeTotal = total
+			case "full":
+				stats.FullTotal = total
+			}
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,56 @@
+package pressure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Stats
+		wantErr bool
+	}{
+		{
+			name:    "some and full",
+			content: "some avg10=0.12 avg60=0.34 avg300=0.56 total=123456\nfull avg10=0.01 avg60=0.02 avg300=0.03 total=7890\n",
+			want:    &Stats{SomeTotal: 123456, FullTotal: 7890},
+		},
+		{
+			name:    "cpu without full line",
+			content: "some avg10=0.00 avg60=0.00 avg300=0.00 total=42\n",
+			want:    &Stats{SomeTotal: 42},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    &Stats{},
+		},
+		{
+			name:    "unknown fields and lines are ignored",
+			content: "some avg10=0.00 foo total=1\nother total=2\n",
+			want:    &Stats{SomeTotal: 1},
+		},
+		{
+			name:    "invalid total",
+			content: "some avg10=0.00 avg60=0.00 avg300=0.00 total=abc\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := Parse(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stats)
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package processes
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// kernelThreadFlag is the PF_KTHREAD flag of the processes which are kernel threads.
+const kernelThreadFlag = 0x00200000
+
+// userHZ is the frequency of the clock ticks used by the proc filesystem for the CPU times, which is fixed to
+// 100 on all the architectures supported by LXD.
+const userHZ = 100
+
+// Process is a process read from a proc filesystem.
+type Process struct {
+	// PID in the PID namespace of the proc filesystem.
+	PID int64
+
+	// PID in the innermost PID namespace of the process.
+	NSPID int64
+
+	// Identifier of the PID namespace of the process, empty if it can't be read.
+	PIDNamespace string
+
+	// Real user ID in the user namespace of the proc filesystem.
+	UID int64
+
+	// Command line, or the name of the process between brackets if it has none like kernel threads.
+	Command string
+
+	// Whether the process is a kernel thread.
+	KernelThread bool
+
+	// CPU time consumed in user and system mode.
+	CPUTime time.Duration
+
+	// Time elapsed since the process started.
+	Elapsed time.Duration
+
+	// Resident set size in bytes.
+	RSS int64
+}
+
+// CPUPercent returns the average CPU usage of the process since it started, in percent of one CPU.
+func (p *Process) CPUPercent() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return 100 * float64(p.CPUTime) / float64(p.Elapsed)
+}
+
+// ToAPI returns the API representation of the process, using the given PID and user name.
+func (p *Process) ToAPI(pid int64, user string) api.InstanceProcess {
+	return api.InstanceProcess{
+		PID:        pid,
+		User:       user,
+		Command:    p.Command,
+		CPUUsage:   p.CPUTime.Nanoseconds(),
+		CPUPercent: p.CPUPercent(),
+		MemoryRSS:  p.RSS,
+	}
+}
+
+// List returns the processes of the proc filesystem mounted at procPath, ordered by PID. The processes that exit
+// while being read are skipped.
+func List(procPath string) ([]Process, error) {
+	dents, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]int64, 0, len(dents))
+	for _, dent := range dents {
+		pid, err := strconv.ParseInt(dent.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		pids = append(pids, pid)
+	}
+
+	return ListPIDs(procPath, pids)
+}
+
+// ListPIDs returns the processes with the given PIDs from the proc filesystem mounted at procPath, ordered by PID.
+// The processes that exit while being read are skipped.
+func ListPIDs(procPath string, pids []int64) ([]Process, error) {
+	uptime, err := readUptime(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := []Process{}
+	for _, pid := range pids {
+		process, err := read(procPath, pid, uptime)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		processes = append(processes, *process)
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	return processes, nil
+}
+
+// CgroupPath returns the path, under the cgroup filesystem mounted at cgroupRoot, of the cgroup holding the
+// processes of the container whose init process has the given PID. The cgroup of the pids controller is used on
+// hosts using the legacy hierarchy. As systemd moves itself to the "init.scope" cgroup, its parent is returned
+// in this case like for the unified hierarchy.
+func CgroupPath(procPath string, cgroupRoot string, pid int64) (string, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.FormatInt(pid, 10), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+
+	var unified string
+	var legacy string
+	for _, line := range strings.Split(string(content), "\n") {
+		// Each line has the hierarchy ID, the comma separated controllers and the path of the cgroup.
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if fields[0] == "0" && fields[1] == "" {
+			unified = fields[2]
+		} else if shared.ValueInSlice("pids", strings.Split(fields[1], ",")) {
+			legacy = filepath.Join("pids", fields[2])
+		}
+	}
+
+	var path string
+	switch {
+	case legacy != "":
+		path = filepath.Join(cgroupRoot, legacy)
+	case unified != "" && shared.PathExists(filepath.Join(cgroupRoot, "unified")):
+		path = filepath.Join(cgroupRoot, "unified", unified)
+	case unified != "":
+		path = filepath.Join(cgroupRoot, unified)
+	default:
+		return "", fmt.Errorf("Failed finding the cgroup of process %d", pid)
+	}
+
+	return strings.TrimSuffix(path, "/init.scope"), nil
+}
+
+// CgroupPIDs returns the PIDs of the processes in the cgroup at cgroupPath and in its descendant cgroups. The
+// cgroups that are removed while being read are skipped.
+func CgroupPIDs(cgroupPath string) ([]int64, error) {
+	pids := []int64{}
+	err := filepath.WalkDir(cgroupPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && path != cgroupPath {
+				return nil
+			}
+
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, field := range strings.Fields(string(content)) {
+			pid, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid PID %q in %q: %w", field, path, err)
+			}
+
+			pids = append(pids, pid)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pids, nil
+}
+
+// readUptime returns the time elapsed since the boot.
+func readUptime(procPath string) (time.Duration, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("Invalid uptime %q", string(content))
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid uptime %q: %w", string(content), err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// read returns the process with the given PID.
+func read(procPath string, pid int64, uptime time.Duration) (*Process, error) {
+	dir := filepath.Join(procPath, strconv.FormatInt(pid, 10))
+	process := &Process{PID: pid, NSPID: pid}
+
+	// The fields after the name of the process in /proc/<pid>/stat are space separated, see proc(5).
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	nameEnd := bytes.LastIndexByte(stat, ')')
+	nameStart := bytes.IndexByte(stat, '(')
+	if nameStart < 0 || nameEnd < nameStart {
+		return nil, fmt.Errorf("Invalid stat of process %d", pid)
+	}
+
+	name := string(stat[nameStart+1 : nameEnd])
+	fields := strings.Fields(string(stat[nameEnd+1:]))
+
+	// The fields are numbered from the state, which is field 3.
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("Invalid stat of process %d", pid)
+	}
+
+	// Flags, user time, system time and start time.
+	var values [4]uint64
+	for i, field := range []int{9, 14, 15, 22} {
+		values[i], err = strconv.ParseUint(fields[field-3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid stat of process %d: %w", pid, err)
+		}
+	}
+
+	process.KernelThread = values[0]&kernelThreadFlag != 0
+	process.CPUTime = time.Duration(values[1]+values[2]) * time.Second / userHZ
+	process.Elapsed = uptime - time.Duration(values[3])*time.Second/userHZ
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+
+		values := strings.Fields(value)
+		if len(values) == 0 {
+			continue
+		}
+
+		switch key {
+		case "Uid":
+			process.UID, err = strconv.ParseInt(values[0], 10, 64)
+		case "NSpid":
+			process.NSPID, err = strconv.ParseInt(values[len(values)-1], 10, 64)
+		case "VmRSS":
+			process.RSS, err = strconv.ParseInt(values[0], 10, 64)
+			process.RSS *= 1024
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid status of process %d: %w", pid, err)
+		}
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+
+	process.Command = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	if process.Command == "" {
+		process.Command = "[" + name + "]"
+	}
+
+	// Reading the namespaces of other users' processes requires privileges.
+	process.PIDNamespace, _ = os.Readlink(filepath.Join(dir, "ns", "pid"))
+
+	return process, nil
+}
+
+// maxPasswdSize is the maximum number of bytes read from a passwd file.
+const maxPasswdSize = 4 * 1024 * 1024
+
+// Users returns the user names indexed by user ID from a passwd file. It returns an empty map if the file
+// doesn't exist, is a symlink or isn't a regular file, as the file may be controlled by an untrusted instance.
+func Users(passwdPath string) (map[int64]string, error) {
+	users := map[int64]string{}
+
+	// Don't follow symlinks and don't block on FIFOs.
+	f, err := os.OpenFile(passwdPath, os.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, unix.ELOOP) {
+			return users, nil
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return users, nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(f, maxPasswdSize))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		uid, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		_, ok := users[uid]
+		if !ok {
+			users[uid] = fields[0]
+		}
+	}
+
+	return users, nil
+}
+
+// UserName returns the name of the user with the given ID, or the ID itself if it has no name.
+func UserName(users map[int64]string, uid int64) string {
+	name, ok := users[uid]
+	if ok {
+		return name
+	}
+
+	return strconv.FormatInt(uid, 10)
+}
@@ -0,0 +1,168 @@
+package processes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// writeProcess writes the files of a process to a fake proc filesystem.
+func writeProcess(t *testing.T, procPath string, pid string, stat string, status string, cmdline string) {
+	dir := filepath.Join(procPath, pid)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0644))
+}
+
+func TestList(t *testing.T) {
+	procPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(procPath, "uptime"), []byte("110.00 200.00\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(procPath, "self"), 0755))
+
+	// The name of the process may contain spaces and brackets.
+	writeProcess(t, procPath, "42",
+		"42 (my (app) x) S 1 42 42 0 -1 4194560 1000 0 0 0 300 200 0 0 20 0 1 0 1000 1000000 1000 0 0\n",
+		"Name:\tmy (app) x\nUid:\t1000\t1000\t1000\t1000\nNSpid:\t42\t7\nVmRSS:\t  2048 kB\n",
+		"/usr/bin/app\x00--flag\x00")
+
+	// Kernel threads have no command line and no memory.
+	writeProcess(t, procPath, "2",
+		"2 (kthreadd) S 0 0 0 0 -1 2129984 0 0 0 0 0 0 0 0 20 0 1 0 2 0 0 0 0\n",
+		"Name:\tkthreadd\nUid:\t0\t0\t0\t0\nNSpid:\t2\n",
+		"")
+
+	processes, err := List(procPath)
+	require.NoError(t, err)
+	require.Len(t, processes, 2)
+
+	assert.Equal(t, int64(2), processes[0].PID)
+	assert.Equal(t, "[kthreadd]", processes[0].Command)
+	assert.True(t, processes[0].KernelThread)
+	assert.Equal(t, int64(0), processes[0].RSS)
+
+	p := processes[1]
+	assert.Equal(t, int64(42), p.PID)
+	assert.Equal(t, int64(7), p.NSPID)
+	assert.Equal(t, int64(1000), p.UID)
+	assert.Equal(t, "/usr/bin/app --flag", p.Command)
+	assert.False(t, p.KernelThread)
+	assert.Equal(t, 5*time.Second, p.CPUTime)
+	assert.Equal(t, 100*time.Second, p.Elapsed)
+	assert.Equal(t, int64(2048*1024), p.RSS)
+	assert.InDelta(t, 5.0, p.CPUPercent(), 0.001)
+
+	processAPI := p.ToAPI(p.NSPID, "app")
+	assert.Equal(t, int64(7), processAPI.PID)
+	assert.Equal(t, "app", processAPI.User)
+	assert.Equal(t, int64(5000000000), processAPI.CPUUsage)
+}
+
+func TestCgroupPath(t *testing.T) {
+	unifiedRoot := t.TempDir()
+	hybridRoot := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(hybridRoot, "unified"), 0755))
+
+	tests := []struct {
+		name       string
+		cgroup     string
+		cgroupRoot string
+		want       string
+	}{
+		{
+			name:       "Unified hierarchy",
+			cgroup:     "0::/lxc.payload.c1/init.scope\n",
+			cgroupRoot: unifiedRoot,
+			want:       filepath.Join(unifiedRoot, "lxc.payload.c1"),
+		},
+		{
+			name:       "Unified hierarchy without systemd",
+			cgroup:     "0::/lxc.payload.c1\n",
+			cgroupRoot: unifiedRoot,
+			want:       filepath.Join(unifiedRoot, "lxc.payload.c1"),
+		},
+		{
+			name:       "Hybrid hierarchy",
+			cgroup:     "12:pids:/lxc.payload.c1/init.scope\n4:cpu,cpuacct:/lxc.payload.c1\n0::/lxc.payload.c1/init.scope\n",
+			cgroupRoot: hybridRoot,
+			want:       filepath.Join(hybridRoot, "pids", "lxc.payload.c1"),
+		},
+		{
+			name:       "Unified hierarchy mounted as unified",
+			cgroup:     "1:name=systemd:/lxc.payload.c1\n0::/lxc.payload.c1\n",
+			cgroupRoot: hybridRoot,
+			want:       filepath.Join(hybridRoot, "unified", "lxc.payload.c1"),
+		},
+		{
+			name:       "No usable hierarchy",
+			cgroup:     "1:name=systemd:/lxc.payload.c1\n",
+			cgroupRoot: unifiedRoot,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			procPath := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(procPath, "42"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(procPath, "42", "cgroup"), []byte(test.cgroup), 0644))
+
+			path, err := CgroupPath(procPath, test.cgroupRoot, 42)
+			if test.want == "" {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, path)
+		})
+	}
+}
+
+func TestCgroupPIDs(t *testing.T) {
+	cgroupPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(cgroupPath, "system.slice", "ssh.service"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(cgroupPath, "init.scope"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupPath, "cgroup.procs"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupPath, "init.scope", "cgroup.procs"), []byte("100\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupPath, "system.slice", "ssh.service", "cgroup.procs"), []byte("200\n201\n"), 0644))
+
+	pids, err := CgroupPIDs(cgroupPath)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{100, 200, 201}, pids)
+
+	_, err = CgroupPIDs(filepath.Join(cgroupPath, "missing"))
+	assert.Error(t, err)
+}
+
+func TestUsers(t *testing.T) {
+	passwdPath := filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, os.WriteFile(passwdPath, []byte("root:x:0:0:root:/root:/bin/bash\n# comment\nubuntu:x:1000:1000::/home/ubuntu:/bin/bash\n"), 0644))
+
+	users, err := Users(passwdPath)
+	require.NoError(t, err)
+	assert.Equal(t, "root", UserName(users, 0))
+	assert.Equal(t, "ubuntu", UserName(users, 1000))
+	assert.Equal(t, "1001", UserName(users, 1001))
+
+	users, err = Users(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	// Symlinks and special files are ignored.
+	symlinkPath := filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, os.Symlink(passwdPath, symlinkPath))
+	users, err = Users(symlinkPath)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	fifoPath := filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, unix.Mkfifo(fifoPath, 0644))
+	users, err = Users(fifoPath)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -296,6 +298,12 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Check access to the secrets referenced by the profile.
+	err = secretsCheckAccess(s, r, p.Name, secrets.References(req.Config, deviceConfig.NewDevices(req.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// At this point we don't know the instance type, so just use instancetype.Any type for validation.
 	err = instance.ValidDevices(s, *p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
 	if err != nil {
@@ -526,29 +534,51 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
-	err = doProfileUpdate(s, *p, name, id, profile, req)
-
-	if err == nil && !isClusterNotification(r) {
-		// Notify all other nodes. If a node is down, it will be ignored.
-		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
-		if err != nil {
-			return response.SmartError(err)
-		}
+	// Check access to the secrets newly referenced by the profile.
+	err = secretsCheckAccess(s, r, p.Name, secrets.AddedReferences(profile.Config, deviceConfig.NewDevices(profile.Devices), req.Config, deviceConfig.NewDevices(req.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
 
-		err = notifier(func(client lxd.InstanceServer) error {
-			return client.UseProject(p.Name).UpdateProfile(name, profile.Writable(), "")
-		})
-		if err != nil {
-			return response.SmartError(err)
-		}
+	// Check that the requestor may forward the guest logs of the instances using the profile to Loki.
+	err = profileLogsLokiCheckGuest(s, r, p.Name, name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
 	}
 
+	err = profileUpdate(s, r, *p, name, id, profile, req)
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
 	return response.SmartError(err)
 }
 
+// profileUpdate updates a profile, notifies the other cluster members and records the configuration revision.
+func profileUpdate(s *state.State, r *http.Request, p api.Project, name string, id int64, profile *api.Profile, req api.ProfilePut) error {
+	err := doProfileUpdate(s, p, name, id, profile, req)
+	if err != nil {
+		return err
+	}
+
+	// Notify all other nodes. If a node is down, it will be ignored.
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		return client.UseProject(p.Name).UpdateProfile(name, profile.Writable(), "")
+	})
+	if err != nil {
+		return err
+	}
+
+	configHistoryRecord(s, r, entity.TypeProfile, int(id), profile.Writable(), req)
+
+	return nil
+}
+
 // swagger:operation PATCH /1.0/profiles/{name} profiles profile_patch
 //
 //	Partially update the profile
@@ -675,10 +705,29 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Check access to the secrets newly referenced by the profile.
+	err = secretsCheckAccess(s, r, p.Name, secrets.AddedReferences(profile.Config, deviceConfig.NewDevices(profile.Devices), req.Config, deviceConfig.NewDevices(req.Devices)))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the requestor may forward the guest logs of the instances using the profile to Loki.
+	err = profileLogsLokiCheckGuest(s, r, p.Name, name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
-	return response.SmartError(doProfileUpdate(s, *p, name, id, profile, req))
+	err = doProfileUpdate(s, *p, name, id, profile, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	configHistoryRecord(s, r, entity.TypeProfile, int(id), profile.Writable(), req)
+
+	return response.EmptySyncResponse
 }
 
 // swagger:operation POST /1.0/profiles/{name} profiles profile_post
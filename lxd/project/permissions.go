@@ -594,6 +594,24 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				return nil
 			}
 
+		case "restricted.devices.serial":
+			devicesChecks["serial"] = func(device map[string]string) error {
+				if restrictionValue != "allow" {
+					return fmt.Errorf("Serial devices are forbidden")
+				}
+
+				// Serial devices can't listen on arbitrary host addresses.
+				if device["backend"] == "tcp" {
+					return fmt.Errorf("Serial devices with the tcp backend are forbidden")
+				}
+
+				if device["listen"] != "" {
+					return fmt.Errorf("Serial devices can only listen on their default socket")
+				}
+
+				return nil
+			}
+
 		case "restricted.devices.nic":
 			devicesChecks["nic"] = func(device map[string]string) error {
 				// Check if the NICs are allowed at all.
@@ -633,6 +651,11 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 					return nil
 				}
 
+				// Always allow secrets disks, access to the secrets being checked separately.
+				if device["pool"] == "" && strings.HasPrefix(device["source"], "secrets:") {
+					return nil
+				}
+
 				switch restrictionValue {
 				case "block":
 					return fmt.Errorf("Disk devices are forbidden")
@@ -825,11 +848,13 @@ var allRestrictions = map[string]string{
 	"restricted.devices.usb":               "block",
 	"restricted.devices.pci":               "block",
 	"restricted.devices.proxy":             "block",
+	"restricted.devices.serial":            "block",
 	"restricted.devices.nic":               "managed",
 	"restricted.devices.disk":              "managed",
 	"restricted.devices.disk.paths":        "",
 	"restricted.idmap.uid":                 "",
 	"restricted.idmap.gid":                 "",
+	"restricted.instance.max_ttl":          "",
 	"restricted.networks.access":           "",
 	"restricted.snapshots":                 "block",
 }
@@ -1600,6 +1625,16 @@ func AllowSnapshotCreation(p *api.Project) error {
 	return nil
 }
 
+// InstanceMaxTTL returns the maximum lifetime of the instances in the project as an expiry expression, or an empty
+// string if the lifetime of the instances isn't restricted.
+func InstanceMaxTTL(p *api.Project) string {
+	if shared.IsFalseOrEmpty(p.Config["restricted"]) {
+		return ""
+	}
+
+	return p.Config["restricted.instance.max_ttl"]
+}
+
 // GetRestrictedClusterGroups returns a slice of restricted cluster groups for the given project.
 func GetRestrictedClusterGroups(p *api.Project) []string {
 	return shared.SplitNTrimSpace(p.Config["restricted.cluster.groups"], ",", -1, true)
@@ -24,6 +24,9 @@ const (
 	// This contains groups defined by the identity provider if the identity authenticated with OIDC.
 	CtxIdentityProviderGroups CtxKey = "identity_provider_groups"
 
+	// CtxIdentityToken is the name of the identity bearer token used to authenticate the request, if any.
+	CtxIdentityToken CtxKey = "identity_token"
+
 	// CtxForwardedAddress is the forwarded address field in request context.
 	CtxForwardedAddress CtxKey = "forwarded_address"
 
@@ -38,6 +41,10 @@ const (
 	// member.
 	CtxForwardedIdentityProviderGroups CtxKey = "identity_provider_groups"
 
+	// CtxForwardedIdentityToken is the name of the identity bearer token used to authenticate the request on
+	// another cluster member, if any.
+	CtxForwardedIdentityToken CtxKey = "forwarded_identity_token"
+
 	// CtxEffectiveProjectName is used to indicate that the effective project of a resource is different from the project
 	// specified in the URL. (For example, if a project has `features.networks=false`, any networks in this project actually
 	// belong to the default project).
@@ -61,4 +68,7 @@ const (
 	// HeaderForwardedIdentityProviderGroups is the forwarded identity provider groups field in request header.
 	// This will be a JSON marshalled []string.
 	HeaderForwardedIdentityProviderGroups = "X-LXD-forwarded-identity-provider-groups"
+
+	// HeaderForwardedIdentityToken is the forwarded identity bearer token name field in request header.
+	HeaderForwardedIdentityToken = "X-LXD-forwarded-identity-token"
 )
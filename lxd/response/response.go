@@ -524,7 +524,7 @@ func (r *forwardedResponse) Render(w http.ResponseWriter) error {
 	}
 
 	url := fmt.Sprintf("%s%s", info.Addresses[0], r.request.URL.RequestURI())
-	forwarded, err := http.NewRequest(r.request.Method, url, r.request.Body)
+	forwarded, err := http.NewRequestWithContext(r.request.Context(), r.request.Method, url, r.request.Body)
 	if err != nil {
 		return err
 	}
@@ -543,6 +543,8 @@ func (r *forwardedResponse) Render(w http.ResponseWriter) error {
 		return err
 	}
 
+	defer func() { _ = response.Body.Close() }()
+
 	for key := range response.Header {
 		w.Header().Set(key, response.Header.Get(key))
 	}
@@ -551,10 +553,35 @@ func (r *forwardedResponse) Render(w http.ResponseWriter) error {
 		w.WriteHeader(response.StatusCode)
 	}
 
+	// Flush the data as it's received so that streamed responses aren't held back.
+	flusher, ok := w.(http.Flusher)
+	if ok {
+		_, err = io.Copy(&flushWriter{w: w, flusher: flusher}, response.Body)
+		return err
+	}
+
 	_, err = io.Copy(w, response.Body)
 	return err
 }
 
+// flushWriter flushes the response writer after each write.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+// Write writes the data and flushes it.
+func (fw *flushWriter) Write(data []byte) (int, error) {
+	n, err := fw.w.Write(data)
+	if err != nil {
+		return n, err
+	}
+
+	fw.flusher.Flush()
+
+	return n, nil
+}
+
 func (r *forwardedResponse) String() string {
 	return fmt.Sprintf("request to %s", r.request.URL)
 }
@@ -0,0 +1,592 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/secrets"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var secretsCmd = APIEndpoint{
+	Path: "secrets",
+
+	Get:  APIEndpointAction{Handler: secretsGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: secretsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateSecrets)},
+}
+
+var secretCmd = APIEndpoint{
+	Path: "secrets/{name}",
+
+	Delete: APIEndpointAction{Handler: secretDelete, AccessHandler: allowPermission(entity.TypeSecret, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: secretGet, AccessHandler: allowPermission(entity.TypeSecret, auth.EntitlementCanView, "name")},
+	Put:    APIEndpointAction{Handler: secretPut, AccessHandler: allowPermission(entity.TypeSecret, auth.EntitlementCanEdit, "name")},
+	Patch:  APIEndpointAction{Handler: secretPut, AccessHandler: allowPermission(entity.TypeSecret, auth.EntitlementCanEdit, "name")},
+}
+
+// secretEtag returns the values used to compute the ETag of a secret.
+func secretEtag(secret *api.Secret) []any {
+	return []any{secret.Name, secret.Description, secret.LastUpdated}
+}
+
+// secretUsedBy returns the sorted URLs of the instances and profiles referencing the secret.
+func secretUsedBy(ctx context.Context, tx *db.ClusterTx, projectName string, secretName string) ([]string, error) {
+	usedBy, err := secrets.UsedBy(ctx, tx, projectName, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(usedBy)
+
+	return usedBy, nil
+}
+
+// secretEncrypt encrypts a secret value with the key of the secrets.
+func secretEncrypt(ctx context.Context, tx *db.ClusterTx, value string) ([]byte, error) {
+	key, err := secrets.Key(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets.Encrypt(key, []byte(value))
+}
+
+// secretsCheckAccess checks that the requestor can view the secrets newly referenced by an instance or profile,
+// so that secrets can't be read by injecting them into an instance without access to them.
+func secretsCheckAccess(s *state.State, r *http.Request, projectName string, names []string) error {
+	for _, name := range names {
+		err := s.Authorizer.CheckPermission(r.Context(), r, entity.SecretURL(projectName, name), auth.EntitlementCanView)
+		if err != nil {
+			if auth.IsDeniedError(err) {
+				return api.StatusErrorf(http.StatusForbidden, "Not authorized to use secret %q", name)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/secrets secrets secrets_get
+//
+//  Get the secrets
+//
+//  Returns a list of secrets (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/secrets/api-token",
+//                "/1.0/secrets/db-password"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/secrets?recursion=1 secrets secrets_get_recursion1
+//
+//	Get the secrets
+//
+//	Returns a list of secrets (structs), without their values.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of secrets
+//	          items:
+//	            $ref: "#/definitions/Secret"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func secretsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeSecret)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.Secret{}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		secretNames, err := tx.GetSecretNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, secretName := range secretNames {
+			if !userHasPermission(entity.SecretURL(projectName, secretName)) {
+				continue
+			}
+
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "secrets", secretName).String())
+				continue
+			}
+
+			_, secret, err := tx.GetSecret(ctx, projectName, secretName)
+			if err != nil {
+				return err
+			}
+
+			secret.UsedBy, err = secretUsedBy(ctx, tx, projectName, secretName)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *secret)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	for i := range resultMap {
+		resultMap[i].UsedBy = project.FilterUsedBy(s.Authorizer, r, resultMap[i].UsedBy)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/secrets secrets secrets_post
+//
+//	Add a secret
+//
+//	Creates a new secret. The value is encrypted and can't be retrieved through the API.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: secret
+//	    description: Secret
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SecretsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func secretsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.SecretsPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = secrets.ValidName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Value == "" {
+		return response.BadRequest(fmt.Errorf("Value is required"))
+	}
+
+	err = secrets.ValidValue(req.Value)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+		}
+
+		_, _, err = tx.GetSecret(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The secret already exists")
+		}
+
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		value, err := secretEncrypt(ctx, tx, req.Value)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateSecret(ctx, projectName, req.Name, req.Description, value)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.SecretCreated.Event(req.Name, projectName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/secrets/{name} secrets secret_delete
+//
+//	Delete the secret
+//
+//	Removes the secret.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func secretDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	secretName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetSecret(ctx, projectName, secretName)
+		if err != nil {
+			return err
+		}
+
+		usedBy, err := secretUsedBy(ctx, tx, projectName, secretName)
+		if err != nil {
+			return err
+		}
+
+		if len(usedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "The secret is currently in use")
+		}
+
+		return tx.DeleteSecret(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.SecretDeleted.Event(secretName, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/secrets/{name} secrets secret_get
+//
+//	Get the secret
+//
+//	Gets a specific secret, without its value.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Secret
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/Secret"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func secretGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	secretName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var secret *api.Secret
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, secret, err = tx.GetSecret(ctx, projectName, secretName)
+		if err != nil {
+			return err
+		}
+
+		secret.UsedBy, err = secretUsedBy(ctx, tx, projectName, secretName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	secret.UsedBy = project.FilterUsedBy(s.Authorizer, r, secret.UsedBy)
+
+	return response.SyncResponseETag(true, secret, secretEtag(secret))
+}
+
+// swagger:operation PATCH /1.0/secrets/{name} secrets secret_patch
+//
+//  Partially update the secret
+//
+//  Updates the description and/or the value of the secret, keeping the fields that aren't set.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: secret
+//      description: Secret configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/SecretPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/secrets/{name} secrets secret_put
+//
+//	Update the secret
+//
+//	Updates the description of the secret, and its value if set.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: secret
+//	    description: Secret configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SecretPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func secretPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	secretName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.SecretPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = secrets.ValidValue(req.Value)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Get the existing secret.
+		id, secret, err := tx.GetSecret(ctx, projectName, secretName)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		err = util.EtagCheck(r, secretEtag(secret))
+		if err != nil {
+			return api.StatusErrorf(http.StatusPreconditionFailed, "%s", err.Error())
+		}
+
+		if r.Method == http.MethodPatch && req.Description == "" {
+			req.Description = secret.Description
+		}
+
+		// The current value is kept if no new value is provided.
+		var value []byte
+		if req.Value != "" {
+			value, err = secretEncrypt(ctx, tx, req.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.UpdateSecret(ctx, id, req.Description, value)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.SecretUpdated.Event(secretName, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,297 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// SourcePrefix is the prefix of the source of disk devices exposing secrets as files.
+const SourcePrefix = "secrets:"
+
+// DevLXDConfigKey is the instance configuration key listing the secrets readable through devlxd.
+const DevLXDConfigKey = "security.devlxd.secrets"
+
+// ProxyCertificateKey is the proxy device option that can reference the secret holding the certificate of an
+// HTTP proxy.
+const ProxyCertificateKey = "http.tls.certificate"
+
+// MaxValueSize is the maximum size of the value of a secret.
+const MaxValueSize = 64 * 1024
+
+// ValidName checks the secret name is valid.
+// As secrets are exposed as files named after them, this also guarantees they are valid file names.
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	return validate.IsHostname(name)
+}
+
+// ValidValue checks the secret value is valid.
+func ValidValue(value string) error {
+	if len(value) > MaxValueSize {
+		return fmt.Errorf("Value is larger than %d bytes", MaxValueSize)
+	}
+
+	return nil
+}
+
+// ParseSource returns the names of the secrets referenced by a disk device source, and whether the source is a
+// secrets source.
+func ParseSource(source string) ([]string, bool) {
+	list, ok := strings.CutPrefix(source, SourcePrefix)
+	if !ok {
+		return nil, false
+	}
+
+	return shared.SplitNTrimSpace(list, ",", -1, true), true
+}
+
+// ValidateSource checks that a disk device source references valid secret names.
+func ValidateSource(source string) error {
+	names, ok := ParseSource(source)
+	if !ok {
+		return fmt.Errorf("Source %q isn't a secrets source", source)
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("No secret specified in source %q", source)
+	}
+
+	for _, name := range names {
+		err := ValidName(name)
+		if err != nil {
+			return fmt.Errorf("Invalid secret name %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// DevLXDNames returns the names of the secrets readable through devlxd according to an instance configuration.
+func DevLXDNames(config map[string]string) []string {
+	names := []string{}
+	for _, name := range shared.SplitNTrimSpace(config[DevLXDConfigKey], ",", -1, true) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// References returns the sorted names of the secrets referenced by an instance or profile configuration and
+// devices.
+func References(config map[string]string, devices deviceConfig.Devices) []string {
+	names := DevLXDNames(config)
+	for _, device := range devices {
+		var sourceNames []string
+		switch device["type"] {
+		case "disk":
+			sourceNames, _ = ParseSource(device["source"])
+		case "proxy":
+			sourceNames, _ = ParseSource(device[ProxyCertificateKey])
+		}
+
+		names = append(names, sourceNames...)
+	}
+
+	unique := []string{}
+	for _, name := range names {
+		if name != "" && !shared.ValueInSlice(name, unique) {
+			unique = append(unique, name)
+		}
+	}
+
+	sort.Strings(unique)
+
+	return unique
+}
+
+// AddedReferences returns the names of the secrets referenced by the new configuration and devices that aren't
+// referenced by the old ones.
+func AddedReferences(oldConfig map[string]string, oldDevices deviceConfig.Devices, newConfig map[string]string, newDevices deviceConfig.Devices) []string {
+	oldNames := References(oldConfig, oldDevices)
+
+	added := []string{}
+	for _, name := range References(newConfig, newDevices) {
+		if !shared.ValueInSlice(name, oldNames) {
+			added = append(added, name)
+		}
+	}
+
+	return added
+}
+
+// KeySize is the size of the key encrypting the values of secrets.
+const KeySize = 32
+
+// NewKey generates a random key to encrypt the values of secrets.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating the secrets key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Key returns the key encrypting the values of secrets, creating it on first use.
+// The key is independent from the cluster certificate, so that renewing or replacing the certificate doesn't
+// affect the secrets.
+func Key(ctx context.Context, tx *db.ClusterTx) ([]byte, error) {
+	key, err := tx.GetSecretsKey(ctx, NewKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading the secrets key: %w", err)
+	}
+
+	return key, nil
+}
+
+// aead returns the authenticated cipher of secrets using the given key.
+func aead(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("Secrets key must be %d bytes long", KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts a secret value with the given key.
+// The nonce is prepended to the returned ciphertext.
+func Encrypt(key []byte, value []byte) ([]byte, error) {
+	gcm, err := aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, value, nil), nil
+}
+
+// Decrypt decrypts a secret value encrypted by Encrypt with the same key.
+func Decrypt(key []byte, encrypted []byte) ([]byte, error) {
+	gcm, err := aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, fmt.Errorf("Encrypted secret value is too short")
+	}
+
+	nonce, ciphertext := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+
+	value, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decrypting secret value: %w", err)
+	}
+
+	return value, nil
+}
+
+// Values returns the decrypted values of the given secrets of a project.
+func Values(ctx context.Context, s *state.State, projectName string, names []string) (map[string][]byte, error) {
+	var key []byte
+	encryptedValues := make(map[string][]byte, len(names))
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		key, err = Key(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			encrypted, err := tx.GetSecretValue(ctx, projectName, name)
+			if err != nil {
+				return err
+			}
+
+			encryptedValues[name] = encrypted
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(names))
+	for name, encrypted := range encryptedValues {
+		value, err := Decrypt(key, encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading secret %q: %w", name, err)
+		}
+
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// UsedBy returns the URLs of the instances and profiles of a project referencing the given secret.
+// Instances only referencing the secret through their profiles aren't included.
+func UsedBy(ctx context.Context, tx *db.ClusterTx, projectName string, name string) ([]string, error) {
+	usedBy := []string{}
+
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		if shared.ValueInSlice(name, References(inst.Config, inst.Devices)) {
+			usedBy = append(usedBy, entity.InstanceURL(inst.Project, inst.Name).String())
+		}
+
+		return nil
+	}, cluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := cluster.GetProfiles(ctx, tx.Tx(), cluster.ProfileFilter{Project: &projectName})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		config, err := cluster.GetProfileConfig(ctx, tx.Tx(), profile.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		devices, err := cluster.GetProfileDevices(ctx, tx.Tx(), profile.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if shared.ValueInSlice(name, References(config, deviceConfig.NewDevices(cluster.DevicesToAPI(devices)))) {
+			usedBy = append(usedBy, entity.ProfileURL(profile.Project, profile.Name).String())
+		}
+	}
+
+	return usedBy, nil
+}
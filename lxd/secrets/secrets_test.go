@@ -0,0 +1,78 @@
+package secrets_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/secrets"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := secrets.NewKey()
+	require.NoError(t, err)
+
+	encrypted, err := secrets.Encrypt(key, []byte("s3cr3t"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "s3cr3t")
+
+	// Each encryption uses a different nonce.
+	encryptedAgain, err := secrets.Encrypt(key, []byte("s3cr3t"))
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encryptedAgain)
+
+	value, err := secrets.Decrypt(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(value))
+
+	// Another key can't decrypt the value.
+	otherKey, err := secrets.NewKey()
+	require.NoError(t, err)
+
+	_, err = secrets.Decrypt(otherKey, encrypted)
+	assert.Error(t, err)
+
+	// Keys of the wrong size are rejected.
+	_, err = secrets.Encrypt(key[:16], []byte("s3cr3t"))
+	assert.Error(t, err)
+
+	// Tampered values are rejected.
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = secrets.Decrypt(key, encrypted)
+	assert.Error(t, err)
+
+	_, err = secrets.Decrypt(key, []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestValidateSource(t *testing.T) {
+	assert.NoError(t, secrets.ValidateSource("secrets:db-password"))
+	assert.NoError(t, secrets.ValidateSource("secrets:db-password, api-token"))
+	assert.Error(t, secrets.ValidateSource("secrets:"))
+	assert.Error(t, secrets.ValidateSource("secrets:db-password,,api-token"))
+	assert.Error(t, secrets.ValidateSource("secrets:../etc"))
+	assert.Error(t, secrets.ValidateSource("/srv/secrets"))
+}
+
+func TestReferences(t *testing.T) {
+	config := map[string]string{
+		secrets.DevLXDConfigKey: "api-token, db-password",
+	}
+
+	devices := deviceConfig.Devices{
+		"creds": {"type": "disk", "source": "secrets:db-password,tls-key", "path": "/run/creds"},
+		"data":  {"type": "disk", "source": "/srv/data", "path": "/srv"},
+		"eth0":  {"type": "nic", "source": "secrets:ignored"},
+		"web":   {"type": "proxy", "http": "true", "http.tls": "true", secrets.ProxyCertificateKey: "secrets:web-cert"},
+		"acme":  {"type": "proxy", "http": "true", "http.tls": "true", secrets.ProxyCertificateKey: "acme"},
+		"ssh":   {"type": "proxy", "listen": "tcp:0.0.0.0:22"},
+	}
+
+	assert.Equal(t, []string{"api-token", "db-password", "tls-key", "web-cert"}, secrets.References(config, devices))
+	assert.Equal(t, []string{}, secrets.References(nil, nil))
+
+	added := secrets.AddedReferences(config, nil, config, devices)
+	assert.Equal(t, []string{"tls-key", "web-cert"}, added)
+}
@@ -26,11 +26,16 @@ var SnapshotScheduleAliases = map[string]string{
 }
 
 func snapshotIsScheduledNow(spec string, subjectID int64) bool {
+	return scheduleIsNow(spec, subjectID, time.Local)
+}
+
+// scheduleIsNow returns whether the schedule matches the current minute in the given time zone.
+func scheduleIsNow(spec string, subjectID int64, loc *time.Location) bool {
 	var result = false
 
 	specs := buildCronSpecs(spec, subjectID)
 	for _, curSpec := range specs {
-		isNow, err := cronSpecIsNow(curSpec)
+		isNow, err := cronSpecIsNow(curSpec, time.Now().In(loc))
 		if err == nil && isNow {
 			result = true
 		}
@@ -97,15 +102,12 @@ func getObfuscatedTimeValuesForSubject(subjectID int64) (string, string) {
 	return minuteResult, hourResult
 }
 
-func cronSpecIsNow(spec string) (bool, error) {
+func cronSpecIsNow(spec string, now time.Time) (bool, error) {
 	sched, err := cron.ParseStandard(spec)
 	if err != nil {
 		return false, fmt.Errorf("Could not parse cron '%s'", spec)
 	}
 
-	// Check if it's time to snapshot
-	now := time.Now()
-
 	// Truncate the time now back to the start of the minute.
 	// This is neded because the cron scheduler will add a minute to the scheduled time
 	// and we don't want the next scheduled time to roll over to the next minute and break
@@ -2746,7 +2746,10 @@ func (b *lxdBackend) CleanupInstancePaths(inst instance.Instance, op *operations
 }
 
 // BackupInstance creates an instance backup.
-func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+// If quiesce is set, it is called to freeze the instance while a temporary snapshot of its volume is taken, and the
+// function it returns is called to thaw the instance once the snapshot exists. The volume is then exported from
+// the temporary snapshot so that the instance is only frozen for the duration of the snapshot.
+func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, quiesce func() (func() error, error), op *operations.Operation) error {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots})
 	l.Debug("BackupInstance started")
 	defer l.Debug("BackupInstance finished")
@@ -2803,6 +2806,22 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 		}
 	}
 
+	if quiesce != nil {
+		// Optimized backups are exported from the volume itself by the storage drivers.
+		if optimized {
+			return fmt.Errorf("Optimized backups can't be taken while quiescing the instance")
+		}
+
+		snapVol, cleanup, err := b.quiescedVolumeSnapshot(inst, vol, dbVol.Config["volatile.uuid"], quiesce, op)
+		if err != nil {
+			return err
+		}
+
+		defer cleanup()
+
+		vol = snapVol
+	}
+
 	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
 
 	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, op)
@@ -2813,6 +2832,46 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 	return nil
 }
 
+// quiescedVolumeSnapshot takes a temporary snapshot of the instance volume while the instance is frozen by the
+// quiesce function. The snapshot is discarded if the instance can't be thawed, as its content can then no longer
+// be relied on. Returns the snapshot volume and a function to delete it.
+func (b *lxdBackend) quiescedVolumeSnapshot(inst instance.Instance, vol drivers.Volume, parentUUID string, quiesce func() (func() error, error), op *operations.Operation) (drivers.Volume, revert.Hook, error) {
+	snapVol := b.GetNewVolume(vol.Type(), vol.ContentType(), drivers.GetSnapshotVolumeName(vol.Name(), "backup-"+uuid.New().String()), vol.Config())
+	snapVol.SetParentUUID(parentUUID)
+
+	unlock, err := locking.Lock(context.TODO(), drivers.OperationLockName("CreateInstanceSnapshot", b.name, vol.Type(), vol.ContentType(), inst.Name()))
+	if err != nil {
+		return drivers.Volume{}, nil, err
+	}
+
+	defer unlock()
+
+	thaw, err := quiesce()
+	if err != nil {
+		return drivers.Volume{}, nil, err
+	}
+
+	err = b.driver.CreateVolumeSnapshot(snapVol, op)
+	thawErr := thaw()
+	if err != nil {
+		return drivers.Volume{}, nil, fmt.Errorf("Failed creating temporary snapshot: %w", err)
+	}
+
+	cleanup := func() {
+		err := b.driver.DeleteVolumeSnapshot(snapVol, op)
+		if err != nil {
+			b.logger.Warn("Failed deleting temporary snapshot", logger.Ctx{"volume": snapVol.Name(), "err": err})
+		}
+	}
+
+	if thawErr != nil {
+		cleanup()
+		return drivers.Volume{}, nil, thawErr
+	}
+
+	return snapVol, cleanup, nil
+}
+
 // GetInstanceUsage returns the disk usage of the instance's root volume.
 func (b *lxdBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
@@ -4709,6 +4768,58 @@ func (b *lxdBackend) ActivateBucket(projectName string, bucketName string, op *o
 	return miniod.EnsureRunning(b.state, bucketVol)
 }
 
+// GetBucketUsage returns the used and total size of a local bucket.
+// The usage of buckets on remote storage pools isn't known by LXD.
+func (b *lxdBackend) GetBucketUsage(projectName string, bucketName string) (*VolumeUsage, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.Driver().Info().Buckets {
+		return nil, fmt.Errorf("Storage pool does not support buckets")
+	}
+
+	if b.Driver().Info().Remote {
+		return nil, drivers.ErrNotSupported
+	}
+
+	var bucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, true, bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	val := VolumeUsage{}
+
+	bucketVolName := project.StorageVolume(projectName, bucket.Name)
+	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
+
+	// Get the usage.
+	val.Used, err = b.driver.GetVolumeUsage(bucketVol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the total size.
+	sizeStr, ok := bucket.Config["size"]
+	if ok {
+		total, err := units.ParseByteSizeString(sizeStr)
+		if err != nil {
+			return nil, err
+		}
+
+		if total >= 0 {
+			val.Total = total
+		}
+	}
+
+	return &val, nil
+}
+
 // GetBucketURL returns S3 URL for bucket.
 func (b *lxdBackend) GetBucketURL(bucketName string) *url.URL {
 	err := b.isStatusReady()
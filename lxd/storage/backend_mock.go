@@ -181,7 +181,7 @@ func (b *mockBackend) RefreshInstance(inst instance.Instance, src instance.Insta
 	return nil
 }
 
-func (b *mockBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+func (b *mockBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, quiesce func() (func() error, error), op *operations.Operation) error {
 	return nil
 }
 
@@ -277,6 +277,10 @@ func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
 	return nil
 }
 
+func (b *mockBackend) GetBucketUsage(projectName string, bucketName string) (*VolumeUsage, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error {
 	return nil
 }
@@ -1,11 +1,14 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // CompareConfigs compares two config maps and returns an error if they differ.
@@ -60,3 +63,115 @@ func CopyConfig(config map[string]string) map[string]string {
 
 	return copy
 }
+
+// ConfigChanges returns the keys that differ between two states of an entity, such as two api.InstancePut, sorted
+// by key. Nested fields are flattened into dot separated keys and lists are joined with commas.
+func ConfigChanges(before any, after any) ([]api.ConfigRevisionChange, error) {
+	beforeValues, err := flattenConfig(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterValues, err := flattenConfig(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(beforeValues)+len(afterValues))
+	for key := range beforeValues {
+		keys = append(keys, key)
+	}
+
+	for key := range afterValues {
+		_, ok := beforeValues[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	changes := []api.ConfigRevisionChange{}
+	for _, key := range keys {
+		if beforeValues[key] != afterValues[key] {
+			changes = append(changes, api.ConfigRevisionChange{Key: key, OldValue: beforeValues[key], NewValue: afterValues[key]})
+		}
+	}
+
+	return changes, nil
+}
+
+// flattenConfig converts a struct into a map of dot separated keys to values using its JSON representation.
+func flattenConfig(value any) (map[string]string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded any
+	err = decoder.Decode(&decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := map[string]string{}
+
+	var flatten func(prefix string, value any) error
+	flatten = func(prefix string, value any) error {
+		switch v := value.(type) {
+		case nil:
+			return nil
+		case map[string]any:
+			for key, child := range v {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+
+				err := flatten(key, child)
+				if err != nil {
+					return err
+				}
+			}
+		case []any:
+			if len(v) == 0 {
+				return nil
+			}
+
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					data, err := json.Marshal(item)
+					if err != nil {
+						return err
+					}
+
+					s = string(data)
+				}
+
+				items = append(items, s)
+			}
+
+			flat[prefix] = strings.Join(items, ",")
+		case string:
+			if v != "" {
+				flat[prefix] = v
+			}
+
+		default:
+			flat[prefix] = fmt.Sprint(v)
+		}
+
+		return nil
+	}
+
+	err = flatten("", decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return flat, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
 )
 
 func Test_CompareConfigsMismatch(t *testing.T) {
@@ -40,3 +41,33 @@ func Test_CompareConfigs(t *testing.T) {
 	err := util.CompareConfigs(config1, config2, []string{"foo"})
 	assert.NoError(t, err)
 }
+
+func Test_ConfigChanges(t *testing.T) {
+	before := api.InstancePut{
+		Config:   map[string]string{"limits.cpu": "2", "user.old": "yes"},
+		Devices:  map[string]map[string]string{"eth0": {"type": "nic", "network": "lxdbr0"}},
+		Profiles: []string{"default"},
+	}
+
+	after := api.InstancePut{
+		Config:      map[string]string{"limits.cpu": "4", "limits.memory": "1GiB"},
+		Devices:     map[string]map[string]string{"eth0": {"type": "nic", "network": "lxdbr1"}},
+		Profiles:    []string{"default", "web"},
+		Description: "Web server",
+	}
+
+	changes, err := util.ConfigChanges(before, before)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = util.ConfigChanges(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, []api.ConfigRevisionChange{
+		{Key: "config.limits.cpu", OldValue: "2", NewValue: "4"},
+		{Key: "config.limits.memory", OldValue: "", NewValue: "1GiB"},
+		{Key: "config.user.old", OldValue: "yes", NewValue: ""},
+		{Key: "description", OldValue: "", NewValue: "Web server"},
+		{Key: "devices.eth0.network", OldValue: "lxdbr0", NewValue: "lxdbr1"},
+		{Key: "profiles", OldValue: "default", NewValue: "default,web"},
+	}, changes)
+}
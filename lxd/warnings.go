@@ -501,6 +501,62 @@ func pruneResolvedWarnings(ctx context.Context, s *state.State) error {
 	return nil
 }
 
+// localWarningEntity identifies the entity a warning of the local member is about.
+// Warnings that aren't about an entity use an empty entity type and an ID of -1.
+type localWarningEntity struct {
+	entityType entity.Type
+	entityID   int
+}
+
+// localWarning is a warning to raise on the local member.
+type localWarning struct {
+	project string
+	message string
+}
+
+// syncLocalWarnings raises the given warnings of a type on the local member, unless the same warning is already
+// raised, and resolves the warnings of that type about the other entities.
+func syncLocalWarnings(ctx context.Context, s *state.State, typeCode warningtype.Type, warnings map[localWarningEntity]localWarning) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		existing, err := cluster.GetWarnings(ctx, tx.Tx(), cluster.WarningFilter{Node: &s.ServerName, TypeCode: &typeCode})
+		if err != nil {
+			return err
+		}
+
+		warned := map[localWarningEntity]bool{}
+		for _, warning := range existing {
+			if warning.Status == warningtype.StatusResolved {
+				continue
+			}
+
+			key := localWarningEntity{entityType: entity.Type(warning.EntityType), entityID: warning.EntityID}
+			w, ok := warnings[key]
+			if ok && w.project == warning.Project {
+				warned[key] = w.message == warning.LastMessage
+				continue
+			}
+
+			err = tx.UpdateWarningStatus(warning.UUID, warningtype.StatusResolved)
+			if err != nil {
+				return err
+			}
+		}
+
+		for key, w := range warnings {
+			if warned[key] {
+				continue
+			}
+
+			err = tx.UpsertWarningLocalNode(ctx, w.project, key.entityType, key.entityID, typeCode, w.message)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // getWarningEntityURL fetches the entity corresponding to the warning from the database, and generates a URL.
 func getWarningEntityURL(ctx context.Context, tx *sql.Tx, warning *cluster.Warning) (string, error) {
 	if warning.EntityID == -1 || warning.EntityType == "" {
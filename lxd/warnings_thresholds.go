@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/certificate"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// imageRefreshRecord records the outcome of an automatic refresh of an image in the database, so that the refresh
+// warnings are kept across restarts and can be checked by any member.
+func imageRefreshRecord(ctx context.Context, s *state.State, imageID int, refreshErr error) {
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.SetImageRefreshResult(ctx, imageID, time.Now(), refreshErr == nil)
+	})
+	if err != nil {
+		logger.Warn("Failed recording image refresh result", logger.Ctx{"imageID": imageID, "err": err})
+	}
+}
+
+// imageRefreshFailingSince returns the date since which the automatic refresh of an image has been failing, based on
+// its upload date and its last refreshes. The boolean is false if the last refresh succeeded.
+func imageRefreshFailingSince(uploadDate time.Time, refresh db.ImageRefresh) (time.Time, bool) {
+	if refresh.LastFailureDate.IsZero() || !refresh.LastFailureDate.After(refresh.LastSuccessDate) {
+		return time.Time{}, false
+	}
+
+	if refresh.LastSuccessDate.IsZero() {
+		return uploadDate, true
+	}
+
+	return refresh.LastSuccessDate, true
+}
+
+// thresholdWarningsCheck returns the warnings of a type that should be raised by the local member. The checks of
+// entities that aren't tied to a member are only done by the leader.
+type thresholdWarningsCheck func(ctx context.Context, s *state.State, isLeader bool) (map[localWarningEntity]localWarning, error)
+
+func thresholdWarningsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		isLeader := true
+		if s.ServerClustered {
+			leader, err := d.gateway.LeaderAddress()
+			if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				logger.Warn("Failed getting leader address for threshold warnings", logger.Ctx{"err": err})
+				return
+			}
+
+			isLeader = err != nil || leader == s.LocalConfig.ClusterAddress()
+		}
+
+		checks := map[warningtype.Type]thresholdWarningsCheck{
+			warningtype.StoragePoolNearlyFull:      storagePoolUsageWarnings,
+			warningtype.ProjectLimitsNearlyReached: projectLimitsWarnings,
+			warningtype.CertificateExpiring:        certificateExpiryWarnings,
+			warningtype.ImageNotRefreshed:          imageRefreshWarnings,
+		}
+
+		for typeCode, check := range checks {
+			warnings, err := check(ctx, s, isLeader)
+			if err != nil {
+				logger.Warn("Failed checking warning thresholds", logger.Ctx{"type": warningtype.TypeNames[typeCode], "err": err})
+				continue
+			}
+
+			err = syncLocalWarnings(ctx, s, typeCode, warnings)
+			if err != nil {
+				logger.Warn("Failed updating threshold warnings", logger.Ctx{"type": warningtype.TypeNames[typeCode], "err": err})
+			}
+		}
+	}
+
+	return f, task.Hourly()
+}
+
+// thresholdPercentage returns the usage as a percentage of the total, rounded down.
+func thresholdPercentage(usage int64, total int64) int64 {
+	return int64(float64(usage) / float64(total) * 100)
+}
+
+// storagePoolUsageWarnings returns warnings for the storage pools whose space or inode usage is above the threshold.
+// Local pools are checked by each member and remote pools by the leader.
+func storagePoolUsageWarnings(ctx context.Context, s *state.State, isLeader bool) (map[localWarningEntity]localWarning, error) {
+	warnings := map[localWarningEntity]localWarning{}
+
+	threshold := s.GlobalConfig.WarningsStoragePoolUsageThreshold()
+	if threshold <= 0 {
+		return warnings, nil
+	}
+
+	var dbPools map[int64]api.StoragePool
+	var dbPoolMembers map[int64]map[int64]db.StoragePoolNode
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolState := db.StoragePoolCreated
+		dbPools, dbPoolMembers, err = tx.GetStoragePools(ctx, &poolState)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage pools: %w", err)
+	}
+
+	for poolID, dbPool := range dbPools {
+		pool, err := storagePools.LoadByRecord(s, poolID, dbPool, dbPoolMembers[poolID])
+		if err != nil {
+			logger.Warn("Failed loading storage pool", logger.Ctx{"pool": dbPool.Name, "err": err})
+			continue
+		}
+
+		if pool.Driver().Info().Remote && !isLeader {
+			continue
+		}
+
+		res, err := pool.GetResources()
+		if err != nil {
+			logger.Debug("Failed getting storage pool resources", logger.Ctx{"pool": dbPool.Name, "err": err})
+			continue
+		}
+
+		var messages []string
+
+		if res.Space.Total > 0 && thresholdPercentage(int64(res.Space.Used), int64(res.Space.Total)) >= threshold {
+			messages = append(messages, fmt.Sprintf("Space usage is at %d%% (%s of %s)", thresholdPercentage(int64(res.Space.Used), int64(res.Space.Total)), units.GetByteSizeStringIEC(int64(res.Space.Used), 2), units.GetByteSizeStringIEC(int64(res.Space.Total), 2)))
+		}
+
+		if res.Inodes.Total > 0 && thresholdPercentage(int64(res.Inodes.Used), int64(res.Inodes.Total)) >= threshold {
+			messages = append(messages, fmt.Sprintf("Inode usage is at %d%% (%d of %d)", thresholdPercentage(int64(res.Inodes.Used), int64(res.Inodes.Total)), res.Inodes.Used, res.Inodes.Total))
+		}
+
+		if len(messages) > 0 {
+			warnings[localWarningEntity{entityType: entity.TypeStoragePool, entityID: int(poolID)}] = localWarning{message: strings.Join(messages, ", ")}
+		}
+	}
+
+	return warnings, nil
+}
+
+// projectLimitsWarnings returns warnings for the projects whose usage of one of their limits is above the threshold.
+func projectLimitsWarnings(ctx context.Context, s *state.State, isLeader bool) (map[localWarningEntity]localWarning, error) {
+	warnings := map[localWarningEntity]localWarning{}
+
+	threshold := s.GlobalConfig.WarningsProjectLimitsThreshold()
+	if threshold <= 0 || !isLeader {
+		return warnings, nil
+	}
+
+	globalConfig := s.GlobalConfig.Dump()
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projects, err := dbCluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, p := range projects {
+			config, err := dbCluster.GetProjectConfig(ctx, tx.Tx(), p.ID)
+			if err != nil {
+				return err
+			}
+
+			// Skip computing the usage of projects without limits.
+			hasLimits := false
+			for key := range config {
+				if strings.HasPrefix(key, "limits.") {
+					hasLimits = true
+					break
+				}
+			}
+
+			if !hasLimits {
+				continue
+			}
+
+			allocations, err := project.GetCurrentAllocations(globalConfig, ctx, tx, p.Name)
+			if err != nil {
+				return fmt.Errorf("Failed getting usage of project %q: %w", p.Name, err)
+			}
+
+			resources := make([]string, 0, len(allocations))
+			for resource := range allocations {
+				resources = append(resources, resource)
+			}
+
+			sort.Strings(resources)
+
+			var messages []string
+			for _, resource := range resources {
+				allocation := allocations[resource]
+				if allocation.Limit <= 0 {
+					continue
+				}
+
+				percentage := thresholdPercentage(allocation.Usage, allocation.Limit)
+				if percentage < threshold {
+					continue
+				}
+
+				usage := fmt.Sprintf("%d of %d", allocation.Usage, allocation.Limit)
+				if resource == "disk" || resource == "memory" {
+					usage = fmt.Sprintf("%s of %s", units.GetByteSizeStringIEC(allocation.Usage, 2), units.GetByteSizeStringIEC(allocation.Limit, 2))
+				}
+
+				messages = append(messages, fmt.Sprintf("Usage of limits.%s is at %d%% (%s)", resource, percentage, usage))
+			}
+
+			if len(messages) > 0 {
+				warnings[localWarningEntity{entityType: entity.TypeProject, entityID: p.ID}] = localWarning{project: p.Name, message: strings.Join(messages, ", ")}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// certificateExpiryWarnings returns warnings for the server certificate and the trusted certificates that expire
+// within the threshold. The certificates shared by the cluster are checked by the leader.
+func certificateExpiryWarnings(ctx context.Context, s *state.State, isLeader bool) (map[localWarningEntity]localWarning, error) {
+	warnings := map[localWarningEntity]localWarning{}
+
+	threshold := s.GlobalConfig.WarningsCertificateExpiryThreshold()
+	if threshold <= 0 || !isLeader {
+		return warnings, nil
+	}
+
+	expiryMessage := func(kind string, notAfter time.Time) string {
+		if notAfter.Before(time.Now()) {
+			return fmt.Sprintf("The %s expired at %s", kind, notAfter.UTC().Format(time.RFC3339))
+		}
+
+		return fmt.Sprintf("The %s expires at %s", kind, notAfter.UTC().Format(time.RFC3339))
+	}
+
+	limit := time.Now().Add(threshold)
+
+	serverCert, err := s.ServerCert().PublicKeyX509()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing server certificate: %w", err)
+	}
+
+	if serverCert.NotAfter.Before(limit) {
+		kind := "server certificate"
+		if s.ServerClustered {
+			kind = "cluster certificate"
+		}
+
+		warnings[localWarningEntity{entityID: -1}] = localWarning{message: expiryMessage(kind, serverCert.NotAfter)}
+	}
+
+	var certs []dbCluster.Certificate
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		certs, err = dbCluster.GetCertificates(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading trusted certificates: %w", err)
+	}
+
+	for _, dbCert := range certs {
+		// The certificates of the cluster members are managed by LXD.
+		if dbCert.Type == certificate.TypeServer {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(dbCert.Certificate))
+		if block == nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			logger.Debug("Failed parsing trusted certificate", logger.Ctx{"fingerprint": dbCert.Fingerprint, "err": err})
+			continue
+		}
+
+		if cert.NotAfter.Before(limit) {
+			warnings[localWarningEntity{entityType: entity.TypeCertificate, entityID: dbCert.ID}] = localWarning{message: expiryMessage(fmt.Sprintf("trusted certificate %q", dbCert.Name), cert.NotAfter)}
+		}
+	}
+
+	return warnings, nil
+}
+
+// imageRefreshWarnings returns warnings for the images whose automatic refresh has been failing for longer than the
+// threshold. As the refresh results are stored in the database, the images are checked by the leader.
+func imageRefreshWarnings(ctx context.Context, s *state.State, isLeader bool) (map[localWarningEntity]localWarning, error) {
+	warnings := map[localWarningEntity]localWarning{}
+
+	threshold := s.GlobalConfig.WarningsImageRefreshThreshold()
+	if threshold <= 0 || !isLeader {
+		return warnings, nil
+	}
+
+	var images []dbCluster.Image
+	var refreshes map[int]db.ImageRefresh
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		autoUpdate := true
+		images, err = dbCluster.GetImages(ctx, tx.Tx(), dbCluster.ImageFilter{AutoUpdate: &autoUpdate})
+		if err != nil {
+			return err
+		}
+
+		refreshes, err = tx.GetImageRefreshes(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading images: %w", err)
+	}
+
+	for _, image := range images {
+		failingSince, failing := imageRefreshFailingSince(image.UploadDate, refreshes[image.ID])
+		if !failing || time.Since(failingSince) < threshold {
+			continue
+		}
+
+		warnings[localWarningEntity{entityType: entity.TypeImage, entityID: image.ID}] = localWarning{
+			project: image.Project,
+			message: fmt.Sprintf("The image %q couldn't be refreshed since %s", image.Fingerprint[:12], failingSince.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db"
+)
+
+func TestImageRefreshFailingSince(t *testing.T) {
+	uploadDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	success := uploadDate.Add(24 * time.Hour)
+	failure := uploadDate.Add(48 * time.Hour)
+
+	tests := []struct {
+		name             string
+		refresh          db.ImageRefresh
+		wantFailing      bool
+		wantFailingSince time.Time
+	}{
+		{
+			name:    "never refreshed",
+			refresh: db.ImageRefresh{},
+		},
+		{
+			name:    "only successful refreshes",
+			refresh: db.ImageRefresh{LastSuccessDate: success},
+		},
+		{
+			name:             "only failed refreshes",
+			refresh:          db.ImageRefresh{LastFailureDate: failure},
+			wantFailing:      true,
+			wantFailingSince: uploadDate,
+		},
+		{
+			name:             "failed after a successful refresh",
+			refresh:          db.ImageRefresh{LastSuccessDate: success, LastFailureDate: failure},
+			wantFailing:      true,
+			wantFailingSince: success,
+		},
+		{
+			name:    "succeeded after a failed refresh",
+			refresh: db.ImageRefresh{LastSuccessDate: failure, LastFailureDate: success},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failingSince, failing := imageRefreshFailingSince(uploadDate, tt.refresh)
+			assert.Equal(t, tt.wantFailing, failing)
+			assert.Equal(t, tt.wantFailingSince, failingSince)
+		})
+	}
+}
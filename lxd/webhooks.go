@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/webhooks"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// setupWebhooks applies the webhooks configuration and attaches the webhook client to the internal event listener
+// if any webhook is configured. The client is created on the first call during the daemon start up.
+func (d *Daemon) setupWebhooks(configs map[string]webhooks.Config) error {
+	if d.webhookClient == nil {
+		client, err := util.HTTPClient("", d.proxy)
+		if err != nil {
+			return err
+		}
+
+		d.webhookClient = webhooks.NewClient(d.shutdownCtx, client)
+	}
+
+	err := d.webhookClient.Configure(configs)
+	if err != nil {
+		return err
+	}
+
+	if len(configs) == 0 {
+		d.internalListener.RemoveHandler("webhooks")
+		return nil
+	}
+
+	d.internalListener.AddHandler("webhooks", d.webhookClient.HandleEvent)
+
+	return nil
+}
+
+// webhookWarningsTask notifies the webhooks of the warnings that were raised since the previous run. Each member
+// notifies its own warnings and the leader also notifies the warnings that aren't tied to a member.
+func webhookWarningsTask(d *Daemon) (task.Func, task.Schedule) {
+	// Unresolved warnings that were already notified, indexed by UUID. The warnings present on the first run are
+	// considered as notified to avoid repeating them on every restart.
+	var notified map[string]bool
+
+	f := func(ctx context.Context) {
+		if !d.webhookClient.WantsWarnings() {
+			notified = nil
+			return
+		}
+
+		s := d.State()
+
+		isLeader := true
+		if s.ServerClustered {
+			leader, err := d.gateway.LeaderAddress()
+			if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				logger.Warn("Failed getting leader address for webhook warnings", logger.Ctx{"err": err})
+				return
+			}
+
+			isLeader = err != nil || leader == s.LocalConfig.ClusterAddress()
+		}
+
+		var warnings []dbCluster.Warning
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			warnings, err = dbCluster.GetWarnings(ctx, tx.Tx())
+
+			return err
+		})
+		if err != nil {
+			logger.Warn("Failed getting warnings for webhooks", logger.Ctx{"err": err})
+			return
+		}
+
+		active := map[string]bool{}
+		for _, warning := range warnings {
+			if warning.Status == warningtype.StatusResolved {
+				continue
+			}
+
+			if warning.Node != s.ServerName && (warning.Node != "" || !isLeader) {
+				continue
+			}
+
+			active[warning.UUID] = true
+
+			if notified == nil || notified[warning.UUID] {
+				continue
+			}
+
+			d.webhookClient.HandleWarning(warning.ToAPI())
+		}
+
+		notified = active
+	}
+
+	return f, task.Every(30 * time.Second)
+}
@@ -0,0 +1,431 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+const (
+	// TypeLifecycle is the notification type of lifecycle events.
+	TypeLifecycle = "lifecycle"
+
+	// TypeWarning is the notification type of warnings.
+	TypeWarning = "warning"
+)
+
+// Severities are the warning severities in increasing order.
+var Severities = []string{"low", "moderate", "high"}
+
+const (
+	queueSize      = 1024
+	timeout        = 10 * time.Second
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+)
+
+// Config represents the configuration of a webhook.
+type Config struct {
+	URL              string
+	Types            []string
+	Projects         []string
+	LifecycleActions []string
+	WarningSeverity  string
+	Template         string
+	Secret           string
+	Retries          int
+}
+
+// Notification is the data sent to a webhook. It's encoded as JSON unless the webhook has a payload template, in
+// which case it's the data of the template.
+type Notification struct {
+	Type      string              `json:"type"`
+	Timestamp time.Time           `json:"timestamp"`
+	Location  string              `json:"location,omitempty"`
+	Project   string              `json:"project,omitempty"`
+	Lifecycle *api.EventLifecycle `json:"lifecycle,omitempty"`
+	Warning   *api.Warning        `json:"warning,omitempty"`
+}
+
+// Client sends notifications to the configured webhooks.
+type Client struct {
+	ctx    context.Context
+	client *http.Client
+
+	mu       sync.Mutex
+	webhooks map[string]*webhook
+
+	// Delivery counters indexed by webhook name, kept across reconfigurations.
+	countersMu  sync.Mutex
+	deliveries  map[string]uint64
+	deadLetters map[string]uint64
+}
+
+// webhook is a configured webhook with its queue of pending notifications.
+type webhook struct {
+	name     string
+	config   Config
+	template *template.Template
+	queue    chan Notification
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewClient returns a Client without any webhook.
+func NewClient(ctx context.Context, client *http.Client) *Client {
+	return &Client{
+		ctx:         ctx,
+		client:      client,
+		webhooks:    map[string]*webhook{},
+		deliveries:  map[string]uint64{},
+		deadLetters: map[string]uint64{},
+	}
+}
+
+// ParseTemplate parses a payload template.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+
+			return string(data), nil
+		},
+	}).Option("missingkey=zero").Parse(text)
+}
+
+// Configure replaces the webhooks of the client. Webhooks whose configuration is unchanged keep their pending
+// notifications.
+func (c *Client) Configure(configs map[string]Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, w := range c.webhooks {
+		newConfig, ok := configs[name]
+		if ok && reflect.DeepEqual(newConfig, w.config) {
+			continue
+		}
+
+		w.stop()
+		delete(c.webhooks, name)
+	}
+
+	for name, config := range configs {
+		_, ok := c.webhooks[name]
+		if ok {
+			continue
+		}
+
+		w := &webhook{
+			name:   name,
+			config: config,
+			queue:  make(chan Notification, queueSize),
+		}
+
+		if config.Template != "" {
+			var err error
+			w.template, err = ParseTemplate(config.Template)
+			if err != nil {
+				return fmt.Errorf("Invalid payload template of webhook %q: %w", name, err)
+			}
+		}
+
+		w.ctx, w.cancel = context.WithCancel(c.ctx)
+		w.wg.Add(1)
+		go c.run(w)
+
+		c.webhooks[name] = w
+	}
+
+	return nil
+}
+
+// Stop stops all the webhooks.
+func (c *Client) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, w := range c.webhooks {
+		w.stop()
+		delete(c.webhooks, name)
+	}
+}
+
+// WantsWarnings returns whether any webhook is notified of warnings.
+func (c *Client) WantsWarnings() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, w := range c.webhooks {
+		if shared.ValueInSlice(TypeWarning, w.config.Types) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HandleEvent handles the event received from the internal event listener.
+func (c *Client) HandleEvent(event api.Event) {
+	if event.Type != api.EventTypeLifecycle {
+		return
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return
+	}
+
+	c.notify(Notification{
+		Type:      TypeLifecycle,
+		Timestamp: event.Timestamp,
+		Location:  event.Location,
+		Project:   lifecycleEvent.Project,
+		Lifecycle: &lifecycleEvent,
+	})
+}
+
+// HandleWarning notifies the webhooks of a new warning.
+func (c *Client) HandleWarning(warning api.Warning) {
+	c.notify(Notification{
+		Type:      TypeWarning,
+		Timestamp: warning.LastSeenAt,
+		Location:  warning.Location,
+		Project:   warning.Project,
+		Warning:   &warning,
+	})
+}
+
+// notify queues the notification for the webhooks whose filters match it.
+func (c *Client) notify(n Notification) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, w := range c.webhooks {
+		if !w.matches(n) {
+			continue
+		}
+
+		select {
+		case w.queue <- n:
+		default:
+			logger.Warn("Dropping webhook notification as the queue is full", logger.Ctx{"webhook": w.name, "type": n.Type})
+			c.countDeadLetter(w.name)
+		}
+	}
+}
+
+// Metrics returns the delivery counters of the webhooks.
+func (c *Client) Metrics() *metrics.MetricSet {
+	set := metrics.NewMetricSet(nil)
+
+	c.countersMu.Lock()
+	defer c.countersMu.Unlock()
+
+	names := make([]string, 0, len(c.deliveries)+len(c.deadLetters))
+	for name := range c.deliveries {
+		names = append(names, name)
+	}
+
+	for name := range c.deadLetters {
+		_, ok := c.deliveries[name]
+		if !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		labels := map[string]string{"webhook": name}
+		set.AddSamples(metrics.WebhookDeliveriesTotal, metrics.Sample{Labels: labels, Value: float64(c.deliveries[name])})
+		set.AddSamples(metrics.WebhookDeadLettersTotal, metrics.Sample{Labels: labels, Value: float64(c.deadLetters[name])})
+	}
+
+	return set
+}
+
+func (c *Client) countDelivery(name string) {
+	c.countersMu.Lock()
+	c.deliveries[name]++
+	c.countersMu.Unlock()
+}
+
+func (c *Client) countDeadLetter(name string) {
+	c.countersMu.Lock()
+	c.deadLetters[name]++
+	c.countersMu.Unlock()
+}
+
+// run sends the queued notifications of the webhook until it's stopped.
+func (c *Client) run(w *webhook) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case n := <-w.queue:
+			err := c.deliver(w, n)
+			if err != nil {
+				logger.Warn("Failed delivering webhook notification", logger.Ctx{"webhook": w.name, "type": n.Type, "err": err})
+				c.countDeadLetter(w.name)
+				continue
+			}
+
+			c.countDelivery(w.name)
+		}
+	}
+}
+
+// deliver sends a notification to the webhook, retrying with an exponential back-off.
+func (c *Client) deliver(w *webhook, n Notification) error {
+	body, err := w.payload(n)
+	if err != nil {
+		return err
+	}
+
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		var status int
+		status, err = c.send(w, body)
+		if err == nil {
+			return nil
+		}
+
+		// Only retry 429s, 500s and connection-level errors.
+		if status > 0 && status != http.StatusTooManyRequests && status/100 != 5 {
+			return err
+		}
+
+		if attempt >= w.config.Retries {
+			return fmt.Errorf("Giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// send makes a single delivery attempt and returns the HTTP status code, if any.
+func (c *Client) send(w *webhook, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent)
+
+	// The signature allows the receiver to check that the notification comes from LXD.
+	if w.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.config.Secret))
+		_, _ = mac.Write(body)
+		req.Header.Set("X-LXD-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("Webhook returned HTTP status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// stop stops sending notifications to the webhook and drops the pending ones.
+func (w *webhook) stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// matches returns whether the notification passes the filters of the webhook.
+func (w *webhook) matches(n Notification) bool {
+	if !shared.ValueInSlice(n.Type, w.config.Types) {
+		return false
+	}
+
+	if len(w.config.Projects) > 0 && !shared.ValueInSlice(n.Project, w.config.Projects) {
+		return false
+	}
+
+	switch n.Type {
+	case TypeLifecycle:
+		if len(w.config.LifecycleActions) == 0 {
+			return true
+		}
+
+		for _, pattern := range w.config.LifecycleActions {
+			match, _ := path.Match(pattern, n.Lifecycle.Action)
+			if match {
+				return true
+			}
+		}
+
+		return false
+	case TypeWarning:
+		return severityIndex(n.Warning.Severity) >= severityIndex(w.config.WarningSeverity)
+	}
+
+	return false
+}
+
+// payload returns the body sent to the webhook for the notification.
+func (w *webhook) payload(n Notification) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(n)
+	}
+
+	var buf bytes.Buffer
+	err := w.template.Execute(&buf, n)
+	if err != nil {
+		return nil, fmt.Errorf("Failed rendering payload template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// severityIndex returns the position of the severity in Severities, the lowest severity if unknown.
+func severityIndex(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func lifecycleEvent(t *testing.T, action string, project string) api.Event {
+	metadata, err := json.Marshal(api.EventLifecycle{Action: action, Project: project, Source: "/1.0/instances/c1"})
+	require.NoError(t, err)
+
+	return api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now(), Location: "none", Metadata: metadata}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	w := &webhook{config: Config{
+		Types:            []string{TypeLifecycle, TypeWarning},
+		Projects:         []string{"foo"},
+		LifecycleActions: []string{"instance-*", "image-created"},
+		WarningSeverity:  "moderate",
+	}}
+
+	cases := []struct {
+		title        string
+		notification Notification
+		match        bool
+	}{
+		{"matching action pattern", Notification{Type: TypeLifecycle, Project: "foo", Lifecycle: &api.EventLifecycle{Action: "instance-started"}}, true},
+		{"matching action", Notification{Type: TypeLifecycle, Project: "foo", Lifecycle: &api.EventLifecycle{Action: "image-created"}}, true},
+		{"other action", Notification{Type: TypeLifecycle, Project: "foo", Lifecycle: &api.EventLifecycle{Action: "image-deleted"}}, false},
+		{"other project", Notification{Type: TypeLifecycle, Project: "bar", Lifecycle: &api.EventLifecycle{Action: "instance-started"}}, false},
+		{"higher severity", Notification{Type: TypeWarning, Project: "foo", Warning: &api.Warning{Severity: "high"}}, true},
+		{"lower severity", Notification{Type: TypeWarning, Project: "foo", Warning: &api.Warning{Severity: "low"}}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			assert.Equal(t, c.match, w.matches(c.notification))
+		})
+	}
+}
+
+func TestClientDelivery(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	signatures := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		signatures <- r.Header.Get("X-LXD-Signature-256")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewClient(ctx, server.Client())
+	err := c.Configure(map[string]Config{"test": {
+		URL:      server.URL,
+		Types:    []string{TypeLifecycle},
+		Template: `{"text": {{ json .Lifecycle.Action }}}`,
+		Secret:   "secret",
+	}})
+	require.NoError(t, err)
+	defer c.Stop()
+
+	c.HandleEvent(lifecycleEvent(t, "instance-started", "default"))
+
+	body := <-bodies
+	assert.Equal(t, `{"text": "instance-started"}`, string(body))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), <-signatures)
+
+	assert.Eventually(t, func() bool {
+		c.countersMu.Lock()
+		defer c.countersMu.Unlock()
+
+		return c.deliveries["test"] == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestClientDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewClient(ctx, server.Client())
+	err := c.Configure(map[string]Config{"test": {URL: server.URL, Types: []string{TypeLifecycle}, Retries: 3}})
+	require.NoError(t, err)
+	defer c.Stop()
+
+	// Client errors aren't retried.
+	c.HandleEvent(lifecycleEvent(t, "instance-started", "default"))
+
+	assert.Eventually(t, func() bool {
+		c.countersMu.Lock()
+		defer c.countersMu.Unlock()
+
+		return c.deadLetters["test"] == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,37 @@
+package api
+
+// AuthAccessModel represents the full access model of the server. It contains the authorization groups and their
+// permissions, the identity provider group mappings and the group memberships of identities.
+//
+// swagger:model
+//
+// API extension: auth_access_model.
+type AuthAccessModel struct {
+	// List of authorization groups and their permissions
+	Groups []AuthGroupsPost `json:"groups" yaml:"groups"`
+
+	// List of identity provider groups and the authorization groups they are mapped to
+	IdentityProviderGroups []IdentityProviderGroup `json:"identity_provider_groups" yaml:"identity_provider_groups"`
+
+	// List of identities and the authorization groups they are a member of
+	Identities []AuthAccessModelIdentity `json:"identities" yaml:"identities"`
+}
+
+// AuthAccessModelIdentity represents the group memberships of an identity in the access model.
+//
+// swagger:model
+//
+// API extension: auth_access_model.
+type AuthAccessModelIdentity struct {
+	// Authentication method of the identity
+	// Example: oidc
+	AuthenticationMethod string `json:"authentication_method" yaml:"authentication_method"`
+
+	// Identifier of the identity
+	// Example: jane.doe@example.com
+	Identifier string `json:"id" yaml:"id"`
+
+	// List of groups the identity is a member of
+	// Example: ["foo", "bar"]
+	Groups []string `json:"groups" yaml:"groups"`
+}
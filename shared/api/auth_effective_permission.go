@@ -0,0 +1,45 @@
+package api
+
+// IdentityEffectivePermission represents the result of evaluating an entitlement of an identity on an entity.
+//
+// swagger:model
+//
+// API extension: auth_effective_permissions.
+type IdentityEffectivePermission struct {
+	// URL of the entity
+	// Example: /1.0/instances/c1?project=default
+	EntityURL string `json:"entity_url" yaml:"entity_url"`
+
+	// Entitlement that was evaluated
+	// Example: can_edit
+	Entitlement string `json:"entitlement" yaml:"entitlement"`
+
+	// Whether the identity has the entitlement on the entity
+	// Example: true
+	Allowed bool `json:"allowed" yaml:"allowed"`
+
+	// Explanation of the decision
+	// Example: Granted by the permissions of the groups of the identity
+	Reason string `json:"reason" yaml:"reason"`
+
+	// List of group permissions granting the entitlement
+	Grants []IdentityPermissionGrant `json:"grants" yaml:"grants"`
+}
+
+// IdentityPermissionGrant represents a permission of a group that grants an entitlement to an identity.
+//
+// swagger:model
+//
+// API extension: auth_effective_permissions.
+type IdentityPermissionGrant struct {
+	// Name of the group
+	// Example: instance-operators
+	Group string `json:"group" yaml:"group"`
+
+	// List of identity provider groups mapped to the group (empty if the identity is a direct member of the group)
+	// Example: ["sales"]
+	IdentityProviderGroups []string `json:"identity_provider_groups" yaml:"identity_provider_groups"`
+
+	// Permission of the group granting the entitlement
+	Permission Permission `json:"permission" yaml:"permission"`
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"time"
+)
+
+// IdentityTokensPost represents the fields of a new identity bearer token.
+//
+// swagger:model
+//
+// API extension: auth_identity_tokens.
+type IdentityTokensPost struct {
+	// Name of the token
+	// Example: ci-runner
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the token
+	// Example: Token used by the CI pipeline
+	Description string `json:"description" yaml:"description"`
+
+	// Expiry date of the token (no expiry if unset)
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// List of addresses or subnets the token can be used from (any address if empty)
+	// Example: ["10.0.0.0/24", "192.0.2.10"]
+	AllowedAddresses []string `json:"allowed_addresses" yaml:"allowed_addresses"`
+
+	// List of permissions the token is restricted to (all permissions of the identity if empty).
+	// The token can never grant more than the permissions of the identity.
+	Permissions []Permission `json:"permissions" yaml:"permissions"`
+}
+
+// IdentityToken represents an identity bearer token.
+//
+// swagger:model
+//
+// API extension: auth_identity_tokens.
+type IdentityToken struct {
+	// Name of the token
+	// Example: ci-runner
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the token
+	// Example: Token used by the CI pipeline
+	Description string `json:"description" yaml:"description"`
+
+	// Creation date of the token
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Expiry date of the token (no expiry if unset)
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// Last time the token was used to authenticate (approximate, unset if never used)
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`
+
+	// List of addresses or subnets the token can be used from (any address if empty)
+	// Example: ["10.0.0.0/24", "192.0.2.10"]
+	AllowedAddresses []string `json:"allowed_addresses" yaml:"allowed_addresses"`
+
+	// List of permissions the token is restricted to (all permissions of the identity if empty)
+	Permissions []Permission `json:"permissions" yaml:"permissions"`
+}
+
+// IdentityTokenSecret represents the secret of a newly created identity bearer token.
+// The secret is only returned once, on creation.
+//
+// swagger:model
+//
+// API extension: auth_identity_tokens.
+type IdentityTokenSecret struct {
+	// Name of the token
+	// Example: ci-runner
+	Name string `json:"name" yaml:"name"`
+
+	// Secret to send in the Authorization header as a bearer token
+	// Example: lxd-token-4f9a3d...
+	Secret string `json:"secret" yaml:"secret"`
+
+	// Expiry date of the token (no expiry if unset)
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"time"
+)
+
+// ConfigRevision represents a recorded change to the configuration of an instance, profile or project.
+//
+// swagger:model
+//
+// API extension: config_history.
+type ConfigRevision struct {
+	// Revision number, increasing with each change
+	// Example: 3
+	Revision int64 `json:"revision" yaml:"revision"`
+
+	// When the change was made
+	// Example: 2021-03-23T20:00:00-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Who made the change
+	Requestor *EventLifecycleRequestor `json:"requestor" yaml:"requestor"`
+
+	// List of changed keys
+	Changes []ConfigRevisionChange `json:"changes" yaml:"changes"`
+}
+
+// ConfigRevisionChange represents the change of a single key in a configuration revision.
+//
+// swagger:model
+//
+// API extension: config_history.
+type ConfigRevisionChange struct {
+	// Changed key, prefixed with "config." or "devices.<device>." for configuration and device keys
+	// Example: config.limits.cpu
+	Key string `json:"key" yaml:"key"`
+
+	// Value before the change, empty if the key was added
+	// Example: 2
+	OldValue string `json:"old_value" yaml:"old_value"`
+
+	// Value after the change, empty if the key was removed
+	// Example: 4
+	NewValue string `json:"new_value" yaml:"new_value"`
+}
+
+// ConfigHistoryPost represents a request to roll back the configuration to a previous revision.
+//
+// swagger:model
+//
+// API extension: config_history.
+type ConfigHistoryPost struct {
+	// Revision to roll back to
+	// Example: 2
+	Revision int64 `json:"revision" yaml:"revision"`
+}
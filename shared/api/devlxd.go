@@ -23,3 +23,28 @@ type DevLXDGet struct {
 	// Example: lxd01
 	Location string `json:"location" yaml:"location"`
 }
+
+// DevLXDSnapshotsPost represents the fields available for a snapshot requested by the instance through devlxd.
+//
+// API extension: devlxd_actions.
+type DevLXDSnapshotsPost struct {
+	// Snapshot name (generated if empty)
+	// Example: pre-upgrade
+	Name string `json:"name" yaml:"name"`
+}
+
+// DevLXDDNSRecord represents a DNS record managed by the instance through devlxd.
+//
+// API extension: devlxd_actions.
+type DevLXDDNSRecord struct {
+	// Forward DNS zone of the record (optional if the instance only has one)
+	// Example: lxd.example.net
+	Zone string `json:"zone" yaml:"zone"`
+
+	// Name of the record, either the instance name or ending with it
+	// Example: www.c1
+	Name string `json:"name" yaml:"name"`
+
+	// Entries in the record
+	Entries []NetworkZoneRecordEntry `json:"entries" yaml:"entries"`
+}
@@ -6,6 +6,8 @@ const (
 	EventLifecycleCertificateDeleted                = "certificate-deleted"
 	EventLifecycleCertificateUpdated                = "certificate-updated"
 	EventLifecycleClusterCertificateUpdated         = "cluster-certificate-updated"
+	EventLifecycleClusterDatabaseBackupCreated      = "cluster-database-backup-created"
+	EventLifecycleClusterDatabaseBackupRetrieved    = "cluster-database-backup-retrieved"
 	EventLifecycleClusterDisabled                   = "cluster-disabled"
 	EventLifecycleClusterEnabled                    = "cluster-enabled"
 	EventLifecycleClusterGroupCreated               = "cluster-group-created"
@@ -41,6 +43,7 @@ const (
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
 	EventLifecycleInstanceFilePushed                = "instance-file-pushed"
 	EventLifecycleInstanceFileRetrieved             = "instance-file-retrieved"
+	EventLifecycleInstanceHealthChanged             = "instance-health-changed"
 	EventLifecycleInstanceLogDeleted                = "instance-log-deleted"
 	EventLifecycleInstanceLogRetrieved              = "instance-log-retrieved"
 	EventLifecycleInstanceMetadataRetrieved         = "instance-metadata-retrieved"
@@ -54,6 +57,8 @@ const (
 	EventLifecycleInstanceRestarted                 = "instance-restarted"
 	EventLifecycleInstanceRestored                  = "instance-restored"
 	EventLifecycleInstanceResumed                   = "instance-resumed"
+	EventLifecycleInstanceScheduleStarted           = "instance-schedule-started"
+	EventLifecycleInstanceScheduleStopped           = "instance-schedule-stopped"
 	EventLifecycleInstanceShutdown                  = "instance-shutdown"
 	EventLifecycleInstanceSnapshotCreated           = "instance-snapshot-created"
 	EventLifecycleInstanceSnapshotDeleted           = "instance-snapshot-deleted"
@@ -62,6 +67,7 @@ const (
 	EventLifecycleInstanceStarted                   = "instance-started"
 	EventLifecycleInstanceStopped                   = "instance-stopped"
 	EventLifecycleInstanceUpdated                   = "instance-updated"
+	EventLifecycleInstanceWatchdogTriggered         = "instance-watchdog-triggered"
 	EventLifecycleNetworkACLCreated                 = "network-acl-created"
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
 	EventLifecycleNetworkACLRenamed                 = "network-acl-renamed"
@@ -86,6 +92,9 @@ const (
 	EventLifecycleNetworkZoneRecordUpdated          = "network-zone-record-updated"
 	EventLifecycleNetworkZoneUpdated                = "network-zone-updated"
 	EventLifecycleOperationCancelled                = "operation-cancelled"
+	EventLifecyclePlacementGroupCreated             = "placement-group-created"
+	EventLifecyclePlacementGroupDeleted             = "placement-group-deleted"
+	EventLifecyclePlacementGroupUpdated             = "placement-group-updated"
 	EventLifecycleProfileCreated                    = "profile-created"
 	EventLifecycleProfileDeleted                    = "profile-deleted"
 	EventLifecycleProfileRenamed                    = "profile-renamed"
@@ -94,6 +103,9 @@ const (
 	EventLifecycleProjectDeleted                    = "project-deleted"
 	EventLifecycleProjectRenamed                    = "project-renamed"
 	EventLifecycleProjectUpdated                    = "project-updated"
+	EventLifecycleSecretCreated                     = "secret-created"
+	EventLifecycleSecretDeleted                     = "secret-deleted"
+	EventLifecycleSecretUpdated                     = "secret-updated"
 	EventLifecycleStoragePoolCreated                = "storage-pool-created"
 	EventLifecycleStoragePoolDeleted                = "storage-pool-deleted"
 	EventLifecycleStoragePoolUpdated                = "storage-pool-updated"
@@ -121,6 +133,8 @@ const (
 	EventLifecycleWarningReset                      = "warning-reset"
 	EventLifecycleIdentityCreated                   = "identity-created"
 	EventLifecycleIdentityUpdated                   = "identity-updated"
+	EventLifecycleIdentityTokenCreated              = "identity-token-created"
+	EventLifecycleIdentityTokenDeleted              = "identity-token-deleted"
 	EventLifecycleAuthGroupCreated                  = "auth-group-created"
 	EventLifecycleAuthGroupUpdated                  = "auth-group-updated"
 	EventLifecycleAuthGroupRenamed                  = "auth-group-renamed"
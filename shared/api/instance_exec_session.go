@@ -0,0 +1,96 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceExecSessionStatusRunning indicates that the command of an exec session is running.
+const InstanceExecSessionStatusRunning = "Running"
+
+// InstanceExecSessionStatusExited indicates that the command of an exec session has exited.
+const InstanceExecSessionStatusExited = "Exited"
+
+// InstanceExecSessionsPost represents the fields required to start a persistent exec session.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions.
+type InstanceExecSessionsPost struct {
+	// Command and its arguments
+	// Example: ["bash"]
+	Command []string `json:"command" yaml:"command"`
+
+	// Additional environment to pass to the command
+	// Example: {"FOO": "BAR"}
+	Environment map[string]string `json:"environment" yaml:"environment"`
+
+	// Terminal width in characters
+	// Example: 80
+	Width int `json:"width" yaml:"width"`
+
+	// Terminal height in rows
+	// Example: 24
+	Height int `json:"height" yaml:"height"`
+
+	// UID of the user to spawn the command as
+	// Example: 1000
+	User uint32 `json:"user" yaml:"user"`
+
+	// GID of the user to spawn the command as
+	// Example: 1000
+	Group uint32 `json:"group" yaml:"group"`
+
+	// Current working directory for the command
+	// Example: /home/foo/
+	Cwd string `json:"cwd" yaml:"cwd"`
+}
+
+// InstanceExecSessionAttachPost represents the fields required to attach to an exec session.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions.
+type InstanceExecSessionAttachPost struct {
+	// Terminal width in characters
+	// Example: 80
+	Width int `json:"width" yaml:"width"`
+
+	// Terminal height in rows
+	// Example: 24
+	Height int `json:"height" yaml:"height"`
+}
+
+// InstanceExecSession represents a persistent exec session.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions.
+type InstanceExecSession struct {
+	// Identifier of the session
+	// Example: 6916c8a6-9b7d-4abd-90b3-aedfec7ec7da
+	ID string `json:"id" yaml:"id"`
+
+	// Command and its arguments
+	// Example: ["bash"]
+	Command []string `json:"command" yaml:"command"`
+
+	// Status of the command (Running or Exited)
+	// Example: Running
+	Status string `json:"status" yaml:"status"`
+
+	// Exit code of the command (only set once the command has exited)
+	// Example: 0
+	ExitCode int `json:"exit_code" yaml:"exit_code"`
+
+	// Whether a client is currently attached to the session
+	// Example: false
+	Attached bool `json:"attached" yaml:"attached"`
+
+	// When the session was created
+	// Example: 2021-03-23T20:00:00-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the command exited
+	// Example: 2021-03-23T21:00:00-04:00
+	ExitedAt time.Time `json:"exited_at" yaml:"exited_at"`
+}
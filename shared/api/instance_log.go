@@ -0,0 +1,42 @@
+package api
+
+import (
+	"time"
+)
+
+const (
+	// InstanceLogSourceConsole is the source of the log entries read from the instance console.
+	InstanceLogSourceConsole = "console"
+
+	// InstanceLogSourceLXD is the source of the log entries read from the log files kept by LXD for the instance.
+	InstanceLogSourceLXD = "lxd"
+
+	// InstanceLogSourceGuest is the source of the log entries read from the journal or syslog of the guest.
+	InstanceLogSourceGuest = "guest"
+)
+
+// InstanceLogSources are the sources of the instance log stream.
+var InstanceLogSources = []string{InstanceLogSourceConsole, InstanceLogSourceLXD, InstanceLogSourceGuest}
+
+// InstanceLogEntry represents a line of the instance log stream.
+//
+// swagger:model
+//
+// API extension: instance_logs_stream.
+type InstanceLogEntry struct {
+	// Time of the log entry, the time it was read if the source has no timestamps
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Source of the log entry (console, lxd or guest)
+	// Example: lxd
+	Source string `json:"source" yaml:"source"`
+
+	// Origin of the log entry within the source (log file name or guest program)
+	// Example: lxc.log
+	Origin string `json:"origin,omitempty" yaml:"origin,omitempty"`
+
+	// Content of the log entry
+	// Example: Started container
+	Message string `json:"message" yaml:"message"`
+}
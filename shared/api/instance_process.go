@@ -0,0 +1,32 @@
+package api
+
+// InstanceProcess represents a process running in an instance.
+//
+// swagger:model
+//
+// API extension: instance_processes.
+type InstanceProcess struct {
+	// PID of the process in the instance
+	// Example: 123
+	PID int64 `json:"pid" yaml:"pid"`
+
+	// Name of the user running the process, or its ID if it has no name
+	// Example: root
+	User string `json:"user" yaml:"user"`
+
+	// Command line of the process
+	// Example: /sbin/init
+	Command string `json:"command" yaml:"command"`
+
+	// CPU time consumed by the process (in nanoseconds)
+	// Example: 3637691016
+	CPUUsage int64 `json:"cpu_usage" yaml:"cpu_usage"`
+
+	// Average CPU usage of the process since it started (in percent of one CPU)
+	// Example: 1.5
+	CPUPercent float64 `json:"cpu_percent" yaml:"cpu_percent"`
+
+	// Resident memory of the process (in bytes)
+	// Example: 73248768
+	MemoryRSS int64 `json:"memory_rss" yaml:"memory_rss"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceStatePut represents the modifiable fields of a LXD instance's state.
 //
 // swagger:model
@@ -56,6 +60,43 @@ type InstanceState struct {
 
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
+
+	// Health check information (only set when a health check is configured and the instance is running)
+	//
+	// API extension: instance_health_checks
+	Health *InstanceStateHealth `json:"health,omitempty" yaml:"health,omitempty"`
+}
+
+// InstanceHealthStatusStarting indicates that the health check of an instance hasn't succeeded yet since it started.
+const InstanceHealthStatusStarting = "Starting"
+
+// InstanceHealthStatusHealthy indicates that the last health check of an instance succeeded.
+const InstanceHealthStatusHealthy = "Healthy"
+
+// InstanceHealthStatusUnhealthy indicates that the health check of an instance failed too many times in a row.
+const InstanceHealthStatusUnhealthy = "Unhealthy"
+
+// InstanceStateHealth represents the health check section of a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: instance_health_checks.
+type InstanceStateHealth struct {
+	// Health status (Starting, Healthy or Unhealthy)
+	// Example: Healthy
+	Status string `json:"status" yaml:"status"`
+
+	// Number of consecutive failed checks
+	// Example: 0
+	Failures int `json:"failures" yaml:"failures"`
+
+	// When the last check ran
+	// Example: 2021-03-23T20:00:00-04:00
+	LastCheckedAt time.Time `json:"last_checked_at" yaml:"last_checked_at"`
+
+	// Error of the last check (if it failed)
+	// Example: Connection refused
+	LastError string `json:"last_error" yaml:"last_error"`
 }
 
 // InstanceStateDisk represents the disk information section of a LXD instance's state.
@@ -0,0 +1,67 @@
+package api
+
+// PlacementGroupsPost represents the fields of a new LXD placement group
+//
+// swagger:model
+//
+// API extension: instance_placement_groups.
+type PlacementGroupsPost struct {
+	PlacementGroupPut `yaml:",inline"`
+
+	// The name of the placement group
+	// Example: db-replicas
+	Name string `json:"name" yaml:"name"`
+}
+
+// PlacementGroupPut represents the modifiable fields of a LXD placement group
+//
+// swagger:model
+//
+// API extension: instance_placement_groups.
+type PlacementGroupPut struct {
+	// Description of the placement group
+	// Example: Database replicas
+	Description string `json:"description" yaml:"description"`
+
+	// Placement group configuration map (refer to doc/explanation/clustering.md)
+	// Example: {"policy": "spread", "rigor": "strict", "scope": "member"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// PlacementGroup represents a placement group.
+//
+// swagger:model
+//
+// API extension: instance_placement_groups.
+type PlacementGroup struct {
+	// The name of the placement group
+	// Example: db-replicas
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the placement group
+	// Example: Database replicas
+	Description string `json:"description" yaml:"description"`
+
+	// Placement group configuration map (refer to doc/explanation/clustering.md)
+	// Example: {"policy": "spread", "rigor": "strict", "scope": "member"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of instances using this placement group
+	// Read only: true
+	// Example: ["/1.0/instances/db1", "/1.0/instances/db2"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full PlacementGroup struct into a PlacementGroupPut struct (filters read-only fields).
+func (group *PlacementGroup) Writable() PlacementGroupPut {
+	return PlacementGroupPut{
+		Description: group.Description,
+		Config:      group.Config,
+	}
+}
+
+// SetWritable sets applicable values from PlacementGroupPut struct to PlacementGroup struct.
+func (group *PlacementGroup) SetWritable(put PlacementGroupPut) {
+	group.Description = put.Description
+	group.Config = put.Config
+}
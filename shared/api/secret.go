@@ -0,0 +1,65 @@
+package api
+
+import (
+	"time"
+)
+
+// SecretsPost represents the fields of a new LXD secret
+//
+// swagger:model
+//
+// API extension: secrets.
+type SecretsPost struct {
+	SecretPut `yaml:",inline"`
+
+	// The name of the secret
+	// Example: db-password
+	Name string `json:"name" yaml:"name"`
+}
+
+// SecretPut represents the modifiable fields of a LXD secret
+//
+// swagger:model
+//
+// API extension: secrets.
+type SecretPut struct {
+	// Description of the secret
+	// Example: Password of the database
+	Description string `json:"description" yaml:"description"`
+
+	// Value of the secret, which can't be retrieved through the API (the current value is kept if empty on update)
+	// Example: s3cr3t
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Secret represents a secret. Its value is never returned.
+//
+// swagger:model
+//
+// API extension: secrets.
+type Secret struct {
+	// The name of the secret
+	// Example: db-password
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the secret
+	// Example: Password of the database
+	Description string `json:"description" yaml:"description"`
+
+	// When the value of the secret was last set
+	// Read only: true
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	LastUpdated time.Time `json:"last_updated" yaml:"last_updated"`
+
+	// List of URLs of instances and profiles using this secret
+	// Read only: true
+	// Example: ["/1.0/instances/c1", "/1.0/profiles/db"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full Secret struct into a SecretPut struct (filters read-only fields).
+func (secret *Secret) Writable() SecretPut {
+	return SecretPut{
+		Description: secret.Description,
+	}
+}
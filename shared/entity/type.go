@@ -90,6 +90,12 @@ const (
 
 	// TypeIdentityProviderGroup represents identity provider group resources.
 	TypeIdentityProviderGroup Type = "identity_provider_group"
+
+	// TypePlacementGroup represents placement group resources.
+	TypePlacementGroup Type = "placement_group"
+
+	// TypeSecret represents secret resources.
+	TypeSecret Type = "secret"
 )
 
 const (
@@ -123,6 +129,8 @@ var entityTypes = []Type{
 	TypeIdentity,
 	TypeAuthGroup,
 	TypeIdentityProviderGroup,
+	TypePlacementGroup,
+	TypeSecret,
 }
 
 // String implements fmt.Stringer for Type.
@@ -283,6 +291,10 @@ func (t Type) path() ([]string, error) {
 		return []string{"auth", "groups", pathPlaceholder}, nil
 	case TypeIdentityProviderGroup:
 		return []string{"auth", "identity-provider-groups", pathPlaceholder}, nil
+	case TypePlacementGroup:
+		return []string{"placement-groups", pathPlaceholder}, nil
+	case TypeSecret:
+		return []string{"secrets", pathPlaceholder}, nil
 	default:
 		return nil, fmt.Errorf("Missing path definition for entity type %q", t)
 	}
@@ -430,6 +442,16 @@ func NetworkZoneURL(projectName string, networkZoneName string) *api.URL {
 	return TypeNetworkZone.urlMust(projectName, "", networkZoneName)
 }
 
+// PlacementGroupURL returns an *api.URL to a placement group.
+func PlacementGroupURL(projectName string, placementGroupName string) *api.URL {
+	return TypePlacementGroup.urlMust(projectName, "", placementGroupName)
+}
+
+// SecretURL returns an *api.URL to a secret.
+func SecretURL(projectName string, secretName string) *api.URL {
+	return TypeSecret.urlMust(projectName, "", secretName)
+}
+
 // StoragePoolURL returns an *api.URL to a storage pool.
 func StoragePoolURL(storagePoolName string) *api.URL {
 	return TypeStoragePool.urlMust("", "", storagePoolName)
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kballard/go-shellquote"
@@ -610,6 +611,20 @@ func IsCron(aliases []string) func(value string) error {
 	}
 }
 
+// IsTimezone validates whether the value is a time zone name from the IANA time zone database.
+func IsTimezone(value string) error {
+	if value == "" {
+		return fmt.Errorf("Time zone cannot be empty")
+	}
+
+	_, err := time.LoadLocation(value)
+	if err != nil {
+		return fmt.Errorf("Invalid time zone %q: %w", value, err)
+	}
+
+	return nil
+}
+
 // IsListenAddress returns a validator for a listen address.
 func IsListenAddress(allowDNS bool, allowWildcard bool, requirePort bool) func(value string) error {
 	return func(value string) error {
@@ -405,6 +405,30 @@ var APIExtensions = []string{
 	"instances_files_modify_permissions",
 	"image_restriction_nesting",
 	"container_syscall_intercept_finit_module",
+	"cluster_database_backup",
+	"instance_placement_groups",
+	"auth_identity_tokens",
+	"auth_effective_permissions",
+	"auth_access_model",
+	"instance_port_forward",
+	"instance_exec_sessions",
+	"instance_health_checks",
+	"instance_boot_dependencies",
+	"instance_power_schedules",
+	"instance_expiry",
+	"config_history",
+	"device_watchdog",
+	"device_serial",
+	"snapshots_quiesce",
+	"proxy_http",
+	"metrics_pressure",
+	"metrics_storage",
+	"warnings_thresholds",
+	"webhooks",
+	"instance_logs_stream",
+	"instance_processes",
+	"secrets",
+	"devlxd_actions",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
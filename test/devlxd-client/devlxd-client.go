@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -152,6 +153,43 @@ func devlxdState(ready bool) {
 	}
 }
 
+func devlxdAction(c http.Client, method string, path string, payload any) {
+	var body bytes.Buffer
+	if payload != nil {
+		err := json.NewEncoder(&body).Encode(payload)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://meshuggah-rocks%s", path), &body)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	value, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Println(strings.TrimSpace(string(value)))
+
+	if resp.StatusCode != http.StatusOK {
+		os.Exit(1)
+	}
+}
+
 func main() {
 	c := http.Client{Transport: devLxdTransport}
 	raw, err := c.Get("http://meshuggah-rocks/")
@@ -204,6 +242,26 @@ func main() {
 			os.Exit(0)
 		}
 
+		if os.Args[1] == "snapshot" {
+			payload := api.DevLXDSnapshotsPost{}
+			if len(os.Args) > 2 {
+				payload.Name = os.Args[2]
+			}
+
+			devlxdAction(c, "POST", "/1.0/snapshots", payload)
+			os.Exit(0)
+		}
+
+		if os.Args[1] == "set-config" {
+			devlxdAction(c, "PATCH", "/1.0/config", map[string]string{os.Args[2]: os.Args[3]})
+			os.Exit(0)
+		}
+
+		if os.Args[1] == "restart" {
+			devlxdAction(c, "POST", "/1.0/restart", nil)
+			os.Exit(0)
+		}
+
 		var path string
 		if os.Args[1] == "devices" {
 			path = "devices"
@@ -285,6 +285,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_unix_char "container devices - unix-char"
     run_test test_container_devices_unix_block "container devices - unix-block"
     run_test test_container_devices_tpm "container devices - tpm"
+    run_test test_container_devices_serial "container devices - serial"
     run_test test_container_move "container server-side move"
     run_test test_container_syscall_interception "container syscall interception"
     run_test test_security "security features"
@@ -299,6 +300,11 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_cloud_init "cloud-init"
     run_test test_exec "exec"
     run_test test_exec_exit_code "exec exit code"
+    run_test test_exec_sessions "exec sessions"
+    run_test test_instance_health_checks "instance health checks"
+    run_test test_instance_expiry "instance expiry"
+    run_test test_apply "lxc apply"
+    run_test test_config_history "configuration history"
     run_test test_concurrent_exec "concurrent exec"
     run_test test_concurrent "concurrent startup"
     run_test test_snapshots "container snapshots"
@@ -314,6 +320,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_snapshot_config "container snapshot configuration"
     run_test test_server_config "server configuration"
     run_test test_filemanip "file manipulations"
+    run_test test_port_forward "port forwarding"
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
     run_test test_network_forward "network address forwards"
@@ -346,6 +353,9 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
     run_test test_console "console"
+    run_test test_instance_logs "instance logs"
+    run_test test_instance_processes "instance processes"
+    run_test test_secrets "secrets"
     run_test test_query "query"
     run_test test_storage_local_volume_handling "storage local volume handling"
     run_test test_backup_import "backup import"
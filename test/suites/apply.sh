@@ -0,0 +1,77 @@
+test_apply() {
+  ensure_import_testimage
+
+  cat > "${TEST_DIR}/stack.yaml" <<EOS
+name: test-stack
+project: default
+profiles:
+- name: stack-profile
+  config:
+    limits.cpu: "1"
+storage_volumes:
+- pool: $(lxc profile device get default root pool)
+  name: stack-vol
+instances:
+- name: stack-c1
+  image: testimage
+  profiles: [default, stack-profile]
+  config:
+    user.foo: bar
+- name: stack-c2
+  image: testimage
+EOS
+
+  # Invalid stacks are rejected.
+  ! echo "profiles: []" | lxc apply -f - || false
+  ! echo "name: test-stack
+unknown: true" | lxc apply -f - || false
+
+  # A dry run doesn't change anything.
+  lxc apply -f "${TEST_DIR}/stack.yaml" --dry-run | grep -Fx '+ profile stack-profile'
+  ! lxc profile show stack-profile || false
+
+  lxc apply -f "${TEST_DIR}/stack.yaml"
+  [ "$(lxc profile get stack-profile user.lxd.stack)" = "test-stack" ]
+  [ "$(lxc config get stack-c1 user.foo)" = "bar" ]
+  [ "$(lxc config get stack-c1 user.lxd.stack)" = "test-stack" ]
+  lxc storage volume show "$(lxc profile device get default root pool)" stack-vol
+  lxc apply -f "${TEST_DIR}/stack.yaml" | grep -Fx 'The stack is up to date'
+
+  # Changes are shown and applied, keys that aren't in the stack are left alone.
+  lxc config set stack-c1 user.local=1
+  sed -i 's/limits.cpu: "1"/limits.cpu: "2"/' "${TEST_DIR}/stack.yaml"
+  lxc apply -f "${TEST_DIR}/stack.yaml" --dry-run | grep -Fx '    limits.cpu: "1" -> "2"'
+  lxc apply -f "${TEST_DIR}/stack.yaml"
+  [ "$(lxc profile get stack-profile limits.cpu)" = "2" ]
+  [ "$(lxc config get stack-c1 user.local)" = "1" ]
+
+  # A failing change reverts the previous ones.
+  cat "${TEST_DIR}/stack.yaml" - > "${TEST_DIR}/stack-broken.yaml" <<EOS
+- name: stack-broken
+  image: testimage
+  config:
+    limits.memory: invalid
+EOS
+  sed -i 's/limits.cpu: "2"/limits.cpu: "3"/' "${TEST_DIR}/stack-broken.yaml"
+  ! lxc apply -f "${TEST_DIR}/stack-broken.yaml" || false
+  [ "$(lxc profile get stack-profile limits.cpu)" = "2" ]
+
+  # Removed resources are only deleted when pruning.
+  sed -i '/^- name: stack-c2$/,$d' "${TEST_DIR}/stack.yaml"
+  lxc apply -f "${TEST_DIR}/stack.yaml" | grep -Fx 'The stack is up to date'
+  lxc info stack-c2
+  lxc apply -f "${TEST_DIR}/stack.yaml" --prune --dry-run | grep -Fx -- '- instance stack-c2'
+  lxc apply -f "${TEST_DIR}/stack.yaml" --prune
+  ! lxc info stack-c2 || false
+  lxc info stack-c1
+
+  # Unlabelled resources are never pruned.
+  lxc init testimage stack-c3
+  lxc apply -f "${TEST_DIR}/stack.yaml" --prune | grep -Fx 'The stack is up to date'
+  lxc info stack-c3
+
+  lxc delete -f stack-c1 stack-c3
+  lxc profile delete stack-profile
+  lxc storage volume delete "$(lxc profile device get default root pool)" stack-vol
+  rm "${TEST_DIR}/stack.yaml" "${TEST_DIR}/stack-broken.yaml"
+}
@@ -96,6 +96,26 @@ EOF
 )
   lxc auth identity info oidc: | grep -Fz "${expected}"
 
+  ### IDENTITY TOKENS ###
+  ! lxc auth identity token create "tls/${tls_user_fingerprint}" "invalid name" || false # Invalid name
+  ! lxc auth identity token create "tls/${tls_user_fingerprint}" test-token --allowed-address not-an-address || false # Invalid address
+  token="$(lxc auth identity token create "tls/${tls_user_fingerprint}" test-token --permission "project default can_view" --quiet)"
+  ! lxc auth identity token create "tls/${tls_user_fingerprint}" test-token || false # Already exists
+  lxc auth identity token list "tls/${tls_user_fingerprint}" --format csv | grep -Fq 'test-token,'
+  lxc auth identity token show "tls/${tls_user_fingerprint}" test-token | grep -Fq 'entitlement: can_view'
+
+  # The token is narrowed down to viewing the default project.
+  [ "$(curl -s -k -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0/projects/default" | jq -r '.status_code')" = "200" ]
+  [ "$(curl -s -k -H "Authorization: Bearer ${token}" -X DELETE "https://${LXD_ADDR}/1.0/images/aliases/testimage" | jq -r '.error_code')" = "403" ]
+  [ "$(curl -s -k -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "trusted" ]
+
+  # Tokens can't be used to create other tokens.
+  [ "$(curl -s -k -H "Authorization: Bearer ${token}" -X POST -d '{"name": "other-token"}' "https://${LXD_ADDR}/1.0/auth/identities/tls/${tls_user_fingerprint}/tokens" | jq -r '.error_code')" = "403" ]
+
+  # Revoked tokens can't be used anymore.
+  lxc auth identity token delete "tls/${tls_user_fingerprint}" test-token
+  [ "$(curl -s -k -H "Authorization: Bearer ${token}" "https://${LXD_ADDR}/1.0" | jq -r '.metadata.auth')" = "untrusted" ]
+
   ### IDENTITY PROVIDER GROUP MANAGEMENT ###
   lxc auth identity-provider-group create test-idp-group
   ! lxc auth identity-provider-group group add test-idp-group not-found || false # Group not found
@@ -103,6 +123,43 @@ EOF
   lxc auth identity-provider-group group remove test-idp-group test-group
   ! lxc auth identity-provider-group group remove test-idp-group test-group || false # Group not mapped
 
+  ### EFFECTIVE PERMISSIONS ###
+  lxc auth can-i oidc/test-user@example.com can_edit project default | grep -Fx 'no'
+  lxc auth group permission add test-group project default operator
+  lxc auth can-i oidc/test-user@example.com can_edit project default | grep -Fx 'yes'
+  lxc auth can-i oidc/test-user@example.com can_edit project default --format csv | grep -Fq 'test-group,,project,/1.0/projects/default,operator'
+  lxc auth can-i oidc/test-user@example.com can_edit server | grep -Fx 'no'
+  lxc auth can-i "tls/${tls_user_fingerprint}" can_edit server | grep -Fq 'is not restricted'
+  ! lxc auth can-i oidc/test-user@example.com not_an_entitlement project default || false # Invalid entitlement
+
+  # Permissions granted through identity provider group mappings.
+  lxc auth group create test-idp-mapped-group
+  lxc auth group permission add test-idp-mapped-group server admin
+  lxc auth identity-provider-group group add test-idp-group test-idp-mapped-group
+  lxc auth can-i oidc/test-user@example.com can_edit server | grep -Fx 'no'
+  lxc auth can-i oidc/test-user@example.com can_edit server --identity-provider-group test-idp-group --format csv | grep -Fq 'test-idp-mapped-group,test-idp-group,server,/1.0,admin'
+
+  # Identities can check their own permissions, but not with other identity provider groups.
+  lxc auth can-i oidc:oidc/test-user@example.com can_edit server | grep -Fx 'no'
+  ! lxc auth can-i oidc:oidc/test-user@example.com can_edit server --identity-provider-group test-idp-group || false
+  lxc auth identity-provider-group group remove test-idp-group test-idp-mapped-group
+  lxc auth group delete test-idp-mapped-group
+  lxc auth group permission remove test-group project default operator
+
+  ### ACCESS MODEL ###
+  lxc auth export > "${TEST_DIR}/access-model.yaml"
+  grep -Fq 'name: test-group' "${TEST_DIR}/access-model.yaml"
+  lxc auth apply --file "${TEST_DIR}/access-model.yaml" | grep -Fx 'The access model is up to date'
+
+  # Add a group through the access model and check that it is only removed when pruning.
+  lxc auth group create test-access-model-group
+  lxc auth apply --file "${TEST_DIR}/access-model.yaml" | grep -Fx 'The access model is up to date'
+  lxc auth apply --file "${TEST_DIR}/access-model.yaml" --prune --dry-run | grep -Fx -- '- group test-access-model-group'
+  lxc auth group show test-access-model-group
+  lxc auth apply --file "${TEST_DIR}/access-model.yaml" --prune
+  ! lxc auth group show test-access-model-group || false
+  rm "${TEST_DIR}/access-model.yaml"
+
   ### PERMISSION INSPECTION ###
   list_output="$(lxc auth permission list --format csv)"
 
@@ -118,7 +175,7 @@ EOF
   echo "${list_output}" | grep -Fq 'server,/1.0,"admin,can_create_groups,can_create_identities,can_create_identity_provider_groups,can_create_projects,can_create_storage_pools,can_delete_groups,can_delete_identities,can_delete_identity_provider_groups,can_delete_projects,can_delete_storage_pools,can_edit,can_edit_groups,can_edit_identities,can_edit_identity_provider_groups,can_edit_projects,can_edit_storage_pools,can_override_cluster_target_restriction,can_view_groups,can_view_identities,can_view_identity_provider_groups,can_view_metrics,can_view_permissions,can_view_privileged_events,can_view_projects,can_view_resources,can_view_warnings,permission_manager,project_manager,storage_pool_manager,viewer"'
 
   list_output="$(lxc auth permission list entity_type=project --format csv --max-entitlements 0)"
-  echo "${list_output}" | grep -Fq 'project,/1.0/projects/default,"can_create_image_aliases,can_create_images,can_create_instances,can_create_network_acls,can_create_network_zones,can_create_networks,can_create_profiles,can_create_storage_buckets,can_create_storage_volumes,can_delete,can_delete_image_aliases,can_delete_images,can_delete_instances,can_delete_network_acls,can_delete_network_zones,can_delete_networks,can_delete_profiles,can_delete_storage_buckets,can_delete_storage_volumes,can_edit,can_edit_image_aliases,can_edit_images,can_edit_instances,can_edit_network_acls,can_edit_network_zones,can_edit_networks,can_edit_profiles,can_edit_storage_buckets,can_edit_storage_volumes,can_operate_instances,can_view,can_view_events,can_view_image_aliases,can_view_images,can_view_instances,can_view_metrics,can_view_network_acls,can_view_network_zones,can_view_networks,can_view_operations,can_view_profiles,can_view_storage_buckets,can_view_storage_volumes,image_alias_manager,image_manager,instance_manager,network_acl_manager,network_manager,network_zone_manager,operator,profile_manager,storage_bucket_manager,storage_volume_manager,viewer"'
+  echo "${list_output}" | grep -Fq 'project,/1.0/projects/default,"can_create_image_aliases,can_create_images,can_create_instances,can_create_network_acls,can_create_network_zones,can_create_networks,can_create_placement_groups,can_create_profiles,can_create_secrets,can_create_storage_buckets,can_create_storage_volumes,can_delete,can_delete_image_aliases,can_delete_images,can_delete_instances,can_delete_network_acls,can_delete_network_zones,can_delete_networks,can_delete_placement_groups,can_delete_profiles,can_delete_secrets,can_delete_storage_buckets,can_delete_storage_volumes,can_edit,can_edit_image_aliases,can_edit_images,can_edit_instances,can_edit_network_acls,can_edit_network_zones,can_edit_networks,can_edit_placement_groups,can_edit_profiles,can_edit_secrets,can_edit_storage_buckets,can_edit_storage_volumes,can_operate_instances,can_view,can_view_events,can_view_image_aliases,can_view_images,can_view_instances,can_view_metrics,can_view_network_acls,can_view_network_zones,can_view_networks,can_view_operations,can_view_placement_groups,can_view_profiles,can_view_secrets,can_view_storage_buckets,can_view_storage_volumes,image_alias_manager,image_manager,instance_manager,network_acl_manager,network_manager,network_zone_manager,operator,placement_group_manager,profile_manager,secret_manager,storage_bucket_manager,storage_volume_manager,viewer"'
 
   # Test max entitlements flag doesn't apply to entitlements that are assigned.
   lxc auth group permission add test-group server viewer
@@ -221,6 +278,14 @@ fine_grained_authorization() {
 
   # Check we can view the warning we just created.
   [ "$(lxc_remote query oidc:/1.0/warnings?recursion=1 | jq -r '[.[] | select(.last_message == "authorization warning")] | length')" = 1 ]
+
+  echo "==> Checking the access model only lists the identities that can be viewed..."
+  lxc auth group permission add test-group server can_view_permissions
+  [ "$(lxc_remote query oidc:/1.0/auth/access-model | jq '.identities | length')" = 0 ]
+  lxc auth group permission add test-group server can_view_identities
+  [ "$(lxc_remote query oidc:/1.0/auth/access-model | jq '.identities | length')" -gt 0 ]
+  lxc auth group permission remove test-group server can_view_identities
+  lxc auth group permission remove test-group server can_view_permissions
 }
 
 user_is_not_server_admin() {
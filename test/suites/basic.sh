@@ -523,6 +523,22 @@ test_basic_usage() {
   [ "$(lxc config get configtest boot.host_shutdown_timeout)" -eq 15 ]
   lxc delete configtest
 
+  # Test boot.depends_on config setting
+  lxc init testimage configtest --config boot.depends_on=db,cache --config boot.depends_on.timeout=10
+  [ "$(lxc config get configtest boot.depends_on)" = "db,cache" ]
+  ! lxc config set configtest boot.depends_on "db/snap0" || false
+  ! lxc config set configtest boot.depends_on.timeout -1 || false
+  lxc delete configtest
+
+  # Test boot.schedule config settings
+  lxc init testimage configtest --config boot.schedule.start="0 8 * * 1-5" --config boot.schedule.stop="@daily"
+  [ "$(lxc config get configtest boot.schedule.start)" = "0 8 * * 1-5" ]
+  lxc config set configtest boot.schedule.timezone Europe/London
+  ! lxc config set configtest boot.schedule.timezone Invalid/Zone || false
+  ! lxc config set configtest boot.schedule.stop "@startup" || false
+  lxc config set configtest boot.schedule.stop "@never"
+  lxc delete configtest
+
   # Test deleting multiple images
   # Start 3 containers to create 3 different images
   lxc launch testimage c1
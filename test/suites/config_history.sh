@@ -0,0 +1,60 @@
+test_config_history() {
+  ensure_import_testimage
+
+  lxc init testimage c1
+  lxc config set c1 user.foo=bar
+  lxc config set c1 limits.cpu=1 user.foo=baz
+  lxc config unset c1 user.foo
+
+  # Every change is recorded as a revision, after a baseline revision with the initial configuration.
+  [ "$(lxc config history c1 -f csv | wc -l)" = "4" ]
+  lxc config history c1 -f csv | grep -F '+ config.user.foo: bar'
+  lxc config history c1 -f csv | grep -F '~ config.user.foo: bar -> baz'
+  lxc config history c1 -f csv | grep -F -- '- config.user.foo: baz'
+  ! lxc config history c1 -f csv | grep -F 'volatile' || false
+
+  # Rolling back restores the recorded configuration and is recorded as a new revision.
+  lxc config rollback c1 3
+  [ "$(lxc config get c1 user.foo)" = "baz" ]
+  [ "$(lxc config get c1 limits.cpu)" = "1" ]
+  [ "$(lxc config history c1 -f csv | wc -l)" = "5" ]
+  ! lxc config rollback c1 42 || false
+
+  # The first change can be rolled back to the baseline revision.
+  lxc config rollback c1 1
+  [ "$(lxc config get c1 user.foo)" = "" ]
+  [ "$(lxc config get c1 limits.cpu)" = "" ]
+
+  # Profiles keep a history too.
+  lxc profile create p1
+  lxc profile set p1 user.foo=bar
+  lxc profile set p1 user.foo=baz
+  lxc profile history p1 -f csv | grep -F '~ config.user.foo: bar -> baz'
+  lxc profile rollback p1 2
+  [ "$(lxc profile get p1 user.foo)" = "bar" ]
+  lxc profile rollback p1 1
+  [ "$(lxc profile get p1 user.foo)" = "" ]
+
+  # And so do projects.
+  lxc project create foo
+  lxc project set foo user.foo=bar
+  lxc project set foo features.images=false
+  lxc project history foo -f csv | grep -F '~ config.features.images: true -> false'
+  lxc project rollback foo 2
+  [ "$(lxc project get foo features.images)" = "true" ]
+
+  # The number of revisions kept is limited.
+  lxc config set core.config_history_retention=2
+  lxc config set c1 user.bar=1
+  [ "$(lxc config history c1 -f csv | wc -l)" = "2" ]
+
+  # No revisions are recorded when the history is disabled.
+  lxc config set core.config_history_retention=0
+  lxc config set c1 user.bar=2
+  ! lxc config history c1 -f csv | grep -F 'user.bar: 1 -> 2' || false
+
+  lxc config unset core.config_history_retention
+  lxc delete c1
+  lxc profile delete p1
+  lxc project delete foo
+}
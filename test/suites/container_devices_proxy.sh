@@ -8,6 +8,7 @@ test_container_devices_proxy() {
   container_devices_proxy_unix_udp
   container_devices_proxy_unix_tcp
   container_devices_proxy_with_overlapping_forward_net
+  container_devices_proxy_http
 }
 
 container_devices_proxy_validation() {
@@ -743,4 +744,80 @@ container_devices_proxy_with_overlapping_forward_net() {
   # Final cleanup
   lxc delete -f proxyTester
   lxc network delete "${netName}"
-}
\ No newline at end of file
+}
+container_devices_proxy_http() {
+  echo "====> Testing HTTP proxying"
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  HOST_TCP_PORT=$(local_tcp_port)
+  lxc launch testimage proxyTester1
+  lxc launch testimage proxyTester2
+
+  # Check invalid HTTP mode configurations are rejected.
+  ! lxc config device add proxyTester1 proxyDev proxy http=true nat=true "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http=true "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:10.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http=true "listen=unix:${LXD_DIR}/proxy.sock" connect=tcp:127.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http.tls=true "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http=true http.tls=true "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http=true http.tls.certificate=acme "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+
+  # Serve a different response from each instance.
+  NSENTER_PIDS=""
+  for i in 1 2; do
+    nsenter -n -U -t "$(lxc query /1.0/containers/proxyTester${i}/state | jq .pid)" -- socat tcp-listen:4321,reuseaddr,fork "system:echo HTTP/1.0 200 OK; echo; echo proxyTester${i}" &
+    NSENTER_PIDS="${NSENTER_PIDS} $!"
+  done
+
+  lxc config device add proxyTester1 proxyDev proxy http=true http.hostnames=one.example.com "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321
+  lxc config device add proxyTester2 proxyDev proxy http=true "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321
+
+  # Check the host name can't be routed to two instances.
+  ! lxc config device add proxyTester2 proxyDev2 proxy http=true http.hostnames=one.example.com "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+
+  # Check the TLS setting must match on a shared listen address.
+  ! lxc config device add proxyTester2 proxyDev2 proxy http=true http.tls=true http.tls.certificate=acme http.hostnames=two.example.com "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+
+  # Check the listen address can't be shared with the instances of another project.
+  lxc project create proxy-http -c features.images=false -c features.profiles=false
+  lxc launch testimage proxyTester3 --project proxy-http
+  ! lxc config device add proxyTester3 proxyDev proxy http=true http.hostnames=three.example.com "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 --project proxy-http || false
+  lxc delete -f proxyTester3 --project proxy-http
+  lxc project delete proxy-http
+  sleep 0.5
+
+  # Check requests are routed by host name, with the default route as fallback.
+  [ "$(curl -s -H "Host: one.example.com" "http://127.0.0.1:${HOST_TCP_PORT}")" = "proxyTester1" ]
+  [ "$(curl -s -H "Host: other.example.com" "http://127.0.0.1:${HOST_TCP_PORT}")" = "proxyTester2" ]
+
+  # Check the requests are counted.
+  lxc query "/1.0/metrics" | grep "^lxd_proxy_http_requests_total" | grep -F 'host="one.example.com"' | grep -F 'name="proxyTester1"'
+
+  # Check removing the default route.
+  lxc config device remove proxyTester2 proxyDev
+  [ "$(curl -s -o /dev/null -w "%{http_code}" -H "Host: other.example.com" "http://127.0.0.1:${HOST_TCP_PORT}")" = "404" ]
+
+  # Check TLS termination requires a certificate issued through ACME.
+  lxc config device remove proxyTester1 proxyDev
+  ! lxc config device add proxyTester1 proxyDev proxy http=true http.tls=true http.tls.certificate=acme "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+
+  # Check TLS termination with a certificate from a secret.
+  openssl req -x509 -newkey ec -pkeyopt ec_paramgen_curve:secp384r1 -sha384 -nodes -days 1 -subj "/CN=one.example.com" -keyout "${TEST_DIR}/proxy.key" -out "${TEST_DIR}/proxy.crt"
+  cat "${TEST_DIR}/proxy.crt" "${TEST_DIR}/proxy.key" | lxc secret create proxy-cert
+  ! lxc config device add proxyTester1 proxyDev proxy http=true http.tls.certificate=secrets:proxy-cert "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+  ! lxc config device add proxyTester1 proxyDev proxy http=true http.tls=true http.tls.certificate=proxy-cert "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 || false
+  lxc config device add proxyTester1 proxyDev proxy http=true http.tls=true http.tls.certificate=secrets:proxy-cert "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321
+  lxc secret show proxy-cert | grep -F "/1.0/instances/proxyTester1"
+  sleep 0.5
+  [ "$(curl -s --cacert "${TEST_DIR}/proxy.crt" --resolve "one.example.com:${HOST_TCP_PORT}:127.0.0.1" "https://one.example.com:${HOST_TCP_PORT}")" = "proxyTester1" ]
+
+  # Check requests whose Host header doesn't match the TLS server name are rejected.
+  [ "$(curl -s --cacert "${TEST_DIR}/proxy.crt" -o /dev/null -w "%{http_code}" --resolve "one.example.com:${HOST_TCP_PORT}:127.0.0.1" -H "Host: two.example.com" "https://one.example.com:${HOST_TCP_PORT}")" = "421" ]
+  lxc config device remove proxyTester1 proxyDev
+  lxc secret delete proxy-cert
+  rm "${TEST_DIR}/proxy.crt" "${TEST_DIR}/proxy.key"
+
+  # shellcheck disable=SC2086
+  kill ${NSENTER_PIDS} 2>/dev/null || true
+  lxc delete -f proxyTester1 proxyTester2
+}
@@ -0,0 +1,51 @@
+test_container_devices_serial() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+  ctName="ct$$"
+  lxc launch testimage "${ctName}"
+
+  # Check adding a device with no path
+  ! lxc config device add "${ctName}" test-dev-invalid serial || false
+
+  # Check the listen option requires a socket backend
+  ! lxc config device add "${ctName}" test-dev-invalid serial path=/dev/ttyS5 listen=/tmp/serial.sock || false
+
+  # Add device with the default backend
+  lxc config device add "${ctName}" test-dev1 serial path=/dev/ttyS5
+  lxc exec "${ctName}" -- stat /dev/ttyS5
+  [ -S "${LXD_DIR}/devices/${ctName}/serial.test-dev1.sock" ]
+
+  # Add device exposed on a Unix socket
+  lxc config device add "${ctName}" test-dev2 serial path=/dev/ttyS6 backend=unix listen="${TEST_DIR}/serial.sock"
+  lxc exec "${ctName}" -- stat /dev/ttyS6
+  [ -S "${TEST_DIR}/serial.sock" ]
+
+  # Check the console refuses unknown serial devices
+  ! lxc console "${ctName}" --device missing </dev/null || false
+
+  # Remove devices
+  lxc config device rm "${ctName}" test-dev1
+  lxc config device rm "${ctName}" test-dev2
+  ! lxc exec "${ctName}" -- stat /dev/ttyS5 || false
+  ! lxc exec "${ctName}" -- stat /dev/ttyS6 || false
+  [ ! -e "${TEST_DIR}/serial.sock" ]
+
+  # Check that existing sockets aren't replaced or removed
+  python3 -c "import socket; socket.socket(socket.AF_UNIX).bind('${TEST_DIR}/other.sock')"
+  ! lxc config device add "${ctName}" test-dev3 serial path=/dev/ttyS7 backend=unix listen="${TEST_DIR}/other.sock" || false
+  [ -S "${TEST_DIR}/other.sock" ]
+  rm "${TEST_DIR}/other.sock"
+
+  # Check restricted projects only allow the default socket
+  lxc project create restricted -c features.images=false -c restricted=true -c restricted.devices.serial=allow
+  lxc profile device add default root disk path="/" pool="lxdtest-$(basename "${LXD_DIR}")" --project restricted
+  lxc init testimage c1 --project restricted
+  ! lxc config device add c1 test-dev1 serial path=/dev/ttyS5 backend=unix listen="${LXD_DIR}/unix.socket" --project restricted || false
+  ! lxc config device add c1 test-dev1 serial path=/dev/ttyS5 backend=tcp listen=127.0.0.1:4555 --project restricted || false
+  lxc config device add c1 test-dev1 serial path=/dev/ttyS5 backend=unix --project restricted
+  lxc delete c1 --project restricted
+  lxc project delete restricted
+
+  # Clean up
+  lxc rm -f "${ctName}"
+}
@@ -135,6 +135,44 @@ EOF
   hwaddr=$(lxc config get devlxd volatile.eth0.hwaddr)
   lxc exec devlxd -- devlxd-client devices | jq -r .eth0.hwaddr | grep -Fx "${hwaddr}"
 
+  # Guest-initiated actions are disabled by default.
+  ! lxc exec devlxd -- devlxd-client snapshot || false
+  ! lxc exec devlxd -- devlxd-client set-config user.status ok || false
+
+  # Snapshots can be requested once allowed.
+  lxc config set devlxd security.devlxd.actions=snapshot,config
+  lxc exec devlxd -- devlxd-client snapshot pre-upgrade | grep -Fx "pre-upgrade"
+  lxc info devlxd | grep -F "pre-upgrade"
+
+  # Snapshots are rate limited.
+  ! lxc exec devlxd -- devlxd-client snapshot || false
+  ! lxc exec devlxd -- devlxd-client restart || false
+
+  # Only the allowed user keys can be changed.
+  ! lxc exec devlxd -- devlxd-client set-config user.status ok || false
+  lxc config set devlxd security.devlxd.config_keys=user.status,user.app.*
+  lxc exec devlxd -- devlxd-client set-config user.status ok
+  lxc exec devlxd -- devlxd-client set-config user.app.version 2
+  [ "$(lxc config get devlxd user.status)" = "ok" ]
+  [ "$(lxc config get devlxd user.app.version)" = "2" ]
+  ! lxc exec devlxd -- devlxd-client set-config user.other foo || false
+  ! lxc exec devlxd -- devlxd-client set-config limits.cpu 1 || false
+  ! lxc config set devlxd security.devlxd.config_keys=limits.cpu || false
+
+  # Empty values unset the keys.
+  lxc exec devlxd -- devlxd-client set-config user.status ""
+  [ -z "$(lxc config get devlxd user.status)" ]
+
+  # Values are limited in size.
+  ! lxc exec devlxd -- devlxd-client set-config user.status "$(head -c 5000 /dev/zero | tr '\0' 'a')" || false
+
+  # Configuration changes are rate limited.
+  for i in $(seq 10); do
+    lxc exec devlxd -- devlxd-client set-config user.app.version "${i}" || true
+  done
+
+  [ "$(lxc config get devlxd user.app.version)" != "10" ]
+
   lxc delete devlxd --force
   kill -9 ${monitorDevlxdPID} || true
 
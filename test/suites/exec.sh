@@ -41,6 +41,53 @@ test_exec() {
   lxc delete "${name}"
 }
 
+test_exec_sessions() {
+  ensure_import_testimage
+
+  lxc launch testimage x1
+
+  # Detached sessions keep running without a client.
+  session=$(lxc exec x1 --detach -- sh -c 'echo session-started; sleep 3600')
+  lxc exec x1 --list-sessions --format csv | grep -F "${session},sh -c echo session-started; sleep 3600,Running,false,"
+  [ "$(lxc query "/1.0/instances/x1/exec-sessions/${session}" | jq -r .status)" = "Running" ]
+
+  # Attaching replays the output of the session until stdin is closed.
+  sleep 2 | lxc exec x1 --attach "${session}" | grep -F "session-started"
+  [ "$(lxc query "/1.0/instances/x1/exec-sessions/${session}" | jq -r .attached)" = "false" ]
+
+  # Terminating a session kills its command and removes it.
+  lxc exec x1 --terminate "${session}"
+  ! lxc query "/1.0/instances/x1/exec-sessions/${session}" || false
+  ! lxc exec x1 --attach "${session}" < /dev/null || false
+
+  # Exited sessions keep their output and exit code.
+  session=$(lxc exec x1 --detach -- sh -c 'echo session-done; exit 3')
+  sleep 1
+  [ "$(lxc query "/1.0/instances/x1/exec-sessions/${session}" | jq -r .status)" = "Exited" ]
+  [ "$(lxc query "/1.0/instances/x1/exec-sessions/${session}" | jq -r .exit_code)" = "3" ]
+  sleep 2 | lxc exec x1 --attach "${session}" | grep -F "session-done"
+
+  # Attached sessions end with the exit code of the command.
+  code=0
+  sleep 2 | lxc exec x1 --session -- sh -c 'sleep 1; exit 5' || code=$?
+  [ "${code}" = "5" ]
+  lxc exec x1 --terminate "${session}"
+
+  # The number of sessions of an instance is limited.
+  for _ in $(seq 32); do
+    lxc exec x1 --detach -- sleep 3600 >/dev/null
+  done
+
+  ! lxc exec x1 --detach -- sleep 3600 || false
+
+  # Deleting the instance removes its sessions.
+  lxc delete x1 --force
+  lxc launch testimage x1
+  [ "$(lxc query /1.0/instances/x1/exec-sessions | jq length)" = "0" ]
+
+  lxc delete x1 --force
+}
+
 test_concurrent_exec() {
   if [ -z "${LXD_CONCURRENT:-}" ]; then
     echo "==> SKIP: LXD_CONCURRENT isn't set"
@@ -0,0 +1,55 @@
+test_instance_expiry() {
+  ensure_import_testimage
+
+  # Prints the message of the active expiry warning of an instance.
+  expiry_warning() {
+    lxc query "/1.0/warnings?recursion=1" | jq -r --arg url "/1.0/instances/${1}" '.[] | select(.type == "Instance is about to expire" and .status != "resolved" and (.entity_url | split("?")[0]) == $url) | .last_message'
+  }
+
+  # Invalid expiries are rejected.
+  ! lxc init testimage c1 -c instance.expiry=tomorrow || false
+  ! lxc init testimage c1 -c instance.expiry=1d -c instance.expiry.since=started || false
+  ! lxc project create p1 -c restricted.instance.max_ttl=forever || false
+
+  lxc init testimage c1 -c instance.expiry=12H
+  lxc launch testimage c2 -c instance.expiry=2000-01-01T00:00:00Z
+  lxc init testimage c3 -c instance.expiry=2000-01-01T00:00:00Z -c security.protection.delete=true
+
+  # Expired instances get a warning first and are only deleted once it was raised for a day.
+  for _ in $(seq 90); do
+    [ -n "$(expiry_warning c2)" ] && break
+    sleep 1
+  done
+
+  expiry_warning c2 | grep -F "will be stopped and deleted once this warning was raised for 24 hours"
+  lxc info c2 | grep -F "Status: RUNNING"
+  lxc info c3
+
+  # Instances expiring within a day get a warning.
+  expiry_warning c1 | grep -F "will then be stopped and deleted"
+  expiry_warning c3 | grep -F "is protected from deletion"
+
+  # Removing the expiry of an expired instance cancels its deletion.
+  lxc config unset c2 instance.expiry
+  for _ in $(seq 90); do
+    [ -z "$(expiry_warning c2)" ] && break
+    sleep 1
+  done
+
+  [ -z "$(expiry_warning c2)" ]
+  lxc delete c2 --force
+
+  # Removing the expiry resolves the warning.
+  lxc config unset c1 instance.expiry
+  for _ in $(seq 90); do
+    [ -z "$(expiry_warning c1)" ] && break
+    sleep 1
+  done
+
+  [ -z "$(expiry_warning c1)" ]
+
+  lxc delete c1
+  lxc config unset c3 instance.expiry
+  lxc config unset c3 security.protection.delete
+  lxc delete c3
+}
@@ -0,0 +1,54 @@
+test_instance_health_checks() {
+  ensure_import_testimage
+
+  lxc launch testimage c1
+
+  # Checks requiring a command or a port can't be enabled without them.
+  ! lxc config set c1 health.check=exec || false
+  ! lxc config set c1 health.check=tcp || false
+  ! lxc config set c1 health.check=http health.check.port=100000 || false
+  ! lxc config set c1 health.check=exec health.check.command="true" health.check.interval=5 || false
+
+  # No health is reported without a health check.
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .health)" = "null" ]
+
+  # Passing checks make the instance healthy.
+  lxc config set c1 health.check=exec health.check.command="true" health.check.interval=10 health.check.retries=1
+  for _ in $(seq 30); do
+    [ "$(lxc query /1.0/instances/c1/state | jq -r .health.status)" = "Healthy" ] && break
+    sleep 1
+  done
+
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .health.status)" = "Healthy" ]
+  lxc info c1 | grep -xF "Health: HEALTHY"
+
+  # Failing checks make the instance unhealthy.
+  lxc config set c1 health.check.command="false"
+  for _ in $(seq 30); do
+    [ "$(lxc query /1.0/instances/c1/state | jq -r .health.status)" = "Unhealthy" ] && break
+    sleep 1
+  done
+
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .health.status)" = "Unhealthy" ]
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .health.last_error)" = "Command exited with status 1" ]
+
+  # Unhealthy instances are restarted (/dev is recreated when the container starts).
+  pid="$(lxc query /1.0/instances/c1/state | jq -r .pid)"
+  lxc config set c1 health.action=restart
+  lxc config set c1 health.check.command="sh -c 'test -e /dev/unhealthy && exit 1; exit 0'"
+  lxc exec c1 -- touch /dev/unhealthy
+  for _ in $(seq 60); do
+    newPid="$(lxc query /1.0/instances/c1/state | jq -r .pid)"
+    [ "${newPid}" != "${pid}" ] && [ "${newPid}" != "-1" ] && break
+    sleep 1
+  done
+
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .pid)" != "${pid}" ]
+  lxc list c1 -c s --format csv | grep -xF "RUNNING"
+
+  # Removing the health check stops reporting the health.
+  lxc config unset c1 health.check
+  [ "$(lxc query /1.0/instances/c1/state | jq -r .health)" = "null" ]
+
+  lxc delete c1 --force
+}
@@ -0,0 +1,46 @@
+test_instance_logs() {
+  ensure_import_testimage
+
+  lxc init testimage logs1
+
+  # The configuration files aren't part of the logs.
+  ! lxc logs logs1 --source lxd | grep '\[lxd/lxc.conf\]' || false
+
+  lxc start logs1
+
+  # Make sure there's something in the console ringbuffer.
+  echo 'some console content' | lxc exec logs1 -- tee /dev/console
+
+  lxc logs logs1 | grep '\[console\] some console content'
+  lxc logs logs1 --source console | grep 'some console content'
+  ! lxc logs logs1 --source lxd | grep 'some console content' || false
+  lxc logs logs1 --source lxd | grep '\[lxd/lxc.log\]'
+
+  # The console output has no timestamps and is skipped when only recent entries are requested.
+  ! lxc logs logs1 --since 1h --source console | grep 'some console content' || false
+
+  # JSON output.
+  lxc logs logs1 --source console --format json | jq -e 'select(.message == "some console content") | .source == "console"'
+
+  # Follow the new console output.
+  lxc logs logs1 --source console --follow > "${TEST_DIR}/logs1.out" &
+  pid=$!
+  sleep 2
+  echo 'some new console content' | lxc exec logs1 -- tee /dev/console
+  sleep 3
+  kill -9 "${pid}"
+  grep -c 'some console content' "${TEST_DIR}/logs1.out" | grep -x 1
+  grep 'some new console content' "${TEST_DIR}/logs1.out"
+  rm "${TEST_DIR}/logs1.out"
+
+  # Invalid sources are rejected.
+  ! lxc logs logs1 --source foo || false
+  ! lxc query "/1.0/instances/logs1/logs/stream?source=foo" || false
+
+  # Forwarding to Loki is configured per instance.
+  lxc config set logs1 logs.loki=guest,lxd
+  ! lxc config set logs1 logs.loki=foo || false
+  lxc config unset logs1 logs.loki
+
+  lxc delete --force logs1
+}
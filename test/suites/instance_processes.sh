@@ -0,0 +1,33 @@
+test_instance_processes() {
+  ensure_import_testimage
+
+  lxc init testimage top1
+
+  # The processes of stopped instances can't be listed.
+  ! lxc query /1.0/instances/top1/processes || false
+  ! lxc top top1 --once --refresh 1 || false
+
+  lxc start top1
+  lxc exec top1 -- sleep 1234 &
+  pid=$!
+  sleep 1
+
+  # The PIDs are those of the container and the users are resolved in the container.
+  lxc query /1.0/instances/top1/processes | jq -e '.[] | select(.pid == 1) | .user == "root"'
+  lxc query /1.0/instances/top1/processes | jq -e '[.[] | select(.command == "sleep 1234")] | length == 1'
+
+  # The processes of the host aren't included.
+  ! lxc query /1.0/instances/top1/processes | jq -e ".[] | select(.command | contains(\"lxd\"))" || false
+
+  lxc top top1 --once --refresh 1 --format csv | grep ',sleep 1234$'
+  lxc top top1 --once --refresh 1 --format json | jq -e '[.[] | select(.command == "sleep 1234")] | length == 1'
+  lxc top --once --refresh 1 --format csv | grep '^top1,container,'
+  lxc top --once --refresh 1 --sort memory --format json | jq -e '[.[] | select(.name == "top1")] | length == 1'
+
+  # Invalid flags are rejected.
+  ! lxc top --once --sort foo || false
+  ! lxc top --once --refresh 0 || false
+
+  kill -9 "${pid}" || true
+  lxc delete --force top1
+}
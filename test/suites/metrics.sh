@@ -125,6 +125,20 @@ test_metrics() {
   curl -k -s -X GET "https://${metrics_addr}/1.0/metrics" | grep "lxd_filesystem_avail_bytes" | grep "type=\"container\""
   curl -k -s -X GET "https://${metrics_addr}/1.0/metrics?project=default" | grep "lxd_filesystem_avail_bytes" | grep "type=\"container\""
 
+  # Storage metrics should contain the pool and the instance volumes once refreshed in the background.
+  lxc config set core.metrics_storage_interval=1
+  sleep 12
+  pool="$(lxc profile device get default root pool)"
+  curl -k -s -X GET "https://${metrics_addr}/1.0/metrics" | grep "lxd_storage_pool_size_bytes" | grep "pool=\"${pool}\""
+  curl -k -s -X GET "https://${metrics_addr}/1.0/metrics" | grep "lxd_storage_volume_snapshots" | grep "name=\"c1\"" | grep "project=\"default\""
+  curl -k -s -X GET "https://${metrics_addr}/1.0/metrics?project=foo" | grep "lxd_storage_volume_snapshots" | grep "name=\"c3\""
+  ! curl -k -s -X GET "https://${metrics_addr}/1.0/metrics?project=foo" | grep "lxd_storage_pool_size_bytes" || false
+
+  # Storage metrics can be disabled.
+  lxc config set core.metrics_storage_interval=0
+  ! curl -k -s -X GET "https://${metrics_addr}/1.0/metrics" | grep "lxd_storage_" || false
+  lxc config unset core.metrics_storage_interval
+
   lxc delete -f c1 c2
   lxc delete -f c3 --project foo
   lxc delete -f c4 --project foo2
@@ -0,0 +1,25 @@
+test_port_forward() {
+  ensure_import_testimage
+
+  lxc launch testimage pf1
+
+  # Serve a fixed reply inside the container.
+  lxc exec pf1 -- sh -c 'nohup sh -c "while true; do echo foo | nc -l -p 1234; done" >/dev/null 2>&1 &'
+
+  ! lxc port-forward pf1 1234:http || false # Invalid port
+  ! lxc port-forward pf1 1234:0 || false # Invalid port
+  ! lxc port-forward nonexistent 1234 || false # Missing instance
+
+  cmd=$(unset -f lxc; command -v lxc)
+  $cmd port-forward pf1 127.0.0.1:12345:1234 &
+  forwardPID=$!
+  sleep 1
+
+  # Each connection is tunnelled separately.
+  [ "$(timeout 5 bash -c 'exec 3<>/dev/tcp/127.0.0.1/12345 && head -n1 <&3')" = "foo" ]
+  sleep 1
+  [ "$(timeout 5 bash -c 'exec 3<>/dev/tcp/127.0.0.1/12345 && head -n1 <&3')" = "foo" ]
+
+  kill -9 "${forwardPID}"
+  lxc delete pf1 --force
+}
@@ -0,0 +1,46 @@
+test_secrets() {
+  ensure_import_testimage
+
+  # Secrets need a value and a valid name.
+  ! printf "" | lxc secret create foo || false
+  ! printf "bar" | lxc secret create foo_bar || false
+
+  printf "s3cr3t" | lxc secret create foo --description "Test secret"
+  lxc secret list | grep -F "foo"
+  lxc secret show foo | grep -F "description: Test secret"
+
+  # The value is never returned by the API.
+  ! lxc query /1.0/secrets/foo | jq -e 'has("value")' || false
+  ! lxc secret show foo | grep -F "s3cr3t" || false
+
+  # Secrets are exposed to instances through a read-only tmpfs.
+  lxc init testimage secrets1
+  lxc config device add secrets1 secrets disk source=secrets:foo path=/run/secrets
+  ! lxc config device add secrets1 missing disk source=secrets:missing path=/run/missing || false
+  ! lxc config device add secrets1 shifted disk source=secrets:foo path=/run/shifted shift=true || false
+  lxc start secrets1
+  [ "$(lxc exec secrets1 -- cat /run/secrets/foo)" = "s3cr3t" ]
+  [ "$(lxc exec secrets1 -- stat -c %a /run/secrets/foo)" = "400" ]
+  ! lxc exec secrets1 -- touch /run/secrets/bar || false
+
+  # Secrets in use can't be deleted.
+  lxc secret show foo | grep -F "/1.0/instances/secrets1"
+  ! lxc secret delete foo || false
+
+  # Updates are applied on the next start.
+  printf "upd4ted" | lxc secret set foo
+  lxc restart secrets1 --force
+  [ "$(lxc exec secrets1 -- cat /run/secrets/foo)" = "upd4ted" ]
+
+  # Secrets remain readable after the server certificate is regenerated.
+  lxc stop --force secrets1
+  shutdown_lxd "${LXD_DIR}"
+  rm "${LXD_DIR}/server.crt" "${LXD_DIR}/server.key"
+  respawn_lxd "${LXD_DIR}" true
+  lxc start secrets1
+  [ "$(lxc exec secrets1 -- cat /run/secrets/foo)" = "upd4ted" ]
+
+  lxc delete --force secrets1
+  lxc secret delete foo
+  ! lxc secret show foo || false
+}
@@ -6,6 +6,7 @@ test_server_config() {
   test_server_config_password
   test_server_config_access
   test_server_config_storage
+  test_server_config_webhooks
 
   kill_lxd "${LXD_SERVERCONFIG_DIR}"
 }
@@ -21,6 +22,30 @@ test_server_config_password() {
   lxc config show | grep -q -v "trust_password"
 }
 
+test_server_config_webhooks() {
+  lxc config set webhooks.foo.url=http://127.0.0.1:1/hook webhooks.foo.secret=s3cr3t webhooks.foo.lifecycle.actions="instance-*"
+
+  # The secret is hidden.
+  config=$(lxc config show)
+  echo "${config}" | grep -F "webhooks.foo.url: http://127.0.0.1:1/hook"
+  echo "${config}" | grep -F "webhooks.foo.lifecycle.actions: instance-*"
+  ! echo "${config}" | grep -F "s3cr3t" || false
+
+  # Invalid values are rejected.
+  ! lxc config set webhooks.foo.types=foo || false
+  ! lxc config set webhooks.foo.warnings.severity=critical || false
+  ! lxc config set webhooks.foo.template="{{ .Type" || false
+  ! lxc config set webhooks.foo.bar=baz || false
+  ! lxc config set webhooks.foo.bar.url=http://127.0.0.1:1/hook || false
+  lxc config set webhooks.foo.template='{"text": {{ json .Type }}}'
+
+  lxc config unset webhooks.foo.url
+  lxc config unset webhooks.foo.secret
+  lxc config unset webhooks.foo.lifecycle.actions
+  lxc config unset webhooks.foo.template
+  ! lxc config show | grep -F "webhooks.foo" || false
+}
+
 test_server_config_access() {
   # test untrusted server GET
   my_curl -X GET "https://$(cat "${LXD_SERVERCONFIG_DIR}/lxd.addr")/1.0" | grep -v -q environment